@@ -2,9 +2,17 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"pickletlgbot/internal/domain/event"
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/reminder"
+	"pickletlgbot/internal/domain/role"
+	"pickletlgbot/internal/domain/series"
+	"pickletlgbot/internal/domain/settings"
+	"pickletlgbot/internal/domain/tournament"
+	"pickletlgbot/internal/domain/user"
 	"strconv"
 	"strings"
 	"time"
@@ -12,7 +20,7 @@ import (
 
 // handleAdminCommand обрабатывает команды администратора
 func (h *Handlers) handleAdminCommand(msg *Message) {
-	if !h.isAdmin(msg.From.ID) {
+	if !h.isStaff(msg.From.ID) {
 		if err := h.client.SendMessage(msg.ChatID, "❌ У вас нет прав администратора"); err != nil {
 			h.logger.Error("failed to send admin access denied message", "chat_id", msg.ChatID, "error", err)
 		}
@@ -26,6 +34,23 @@ func (h *Handlers) handleAdminCommand(msg *Message) {
 
 	command := parts[0]
 
+	// Создание и удаление локаций, роли и настройки игроков - глобальные действия, просмотр и отчет
+	// о должниках доступны в любой локации (должники дополнительно фильтруются по правам на события)
+	perm, ok := adminCommandPermissions[command]
+	if !ok {
+		return
+	}
+	target := role.Global
+	if perm == role.PermView || perm == role.PermModerate || perm == role.PermManagePayments {
+		target = role.Anywhere
+	}
+	if !h.can(context.Background(), msg.From.ID, perm, target) {
+		if err := h.client.SendMessage(msg.ChatID, accessDeniedMessage); err != nil {
+			h.logger.Error("failed to send access denied message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
 	switch command {
 	case "/admin":
 		text, keyboard := h.formatter.FormatAdminMenu()
@@ -42,6 +67,24 @@ func (h *Handlers) handleAdminCommand(msg *Message) {
 			h.logger.Error("failed to send create location prompt", "chat_id", msg.ChatID, "error", err)
 		}
 
+	case "/admin_level":
+		h.handleAdminLevelCommand(msg, parts[1:])
+
+	case "/admin_rating_recalc":
+		h.handleAdminRatingRecalc(msg)
+
+	case "/admin_roles":
+		h.showRoles(context.Background(), msg.ChatID, 0)
+
+	case "/admin_debtors":
+		h.showDebtors(context.Background(), msg.ChatID, 0, msg.From.ID)
+
+	case "/admin_pass":
+		h.handleAdminPassCommand(msg, parts[1:])
+
+	case "/admin_user":
+		h.handleAdminUserCommand(msg, parts[1:])
+
 	case "/admin_delete_location":
 		text := h.formatter.FormatDeleteLocationPrompt()
 		if err := h.client.SendMessage(msg.ChatID, text); err != nil {
@@ -81,8 +124,10 @@ func (h *Handlers) handleAdminCallback(ctx context.Context, cb *CallbackQuery) {
 		h.handleAdminListEvents(ctx, cb, event.EventTypeCompetition)
 	case "admin:events:moderation":
 		h.handleAdminModerationList(ctx, cb)
-	case "admin:create_event":
+	case "admin:create_event", "admin:create_series":
 		h.handleAdminCreateEvent(ctx, cb)
+	case "admin:series":
+		h.handleAdminSeriesList(ctx, cb)
 	case "admin:menu":
 		text, keyboard := h.formatter.FormatAdminMenu()
 		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
@@ -90,21 +135,96 @@ func (h *Handlers) handleAdminCallback(ctx context.Context, cb *CallbackQuery) {
 		}
 	case "admin:set_channel":
 		h.handleAdminSetChannelStart(cb)
+	case "admin:pending_timeout":
+		h.handleAdminPendingTimeoutStart(ctx, cb)
+	case "admin:event_reminders":
+		h.handleAdminEventRemindersStart(ctx, cb)
 	case "admin:delete_event":
 		h.handleAdminDeleteEventList(ctx, cb)
+	case "admin:no_show_policy":
+		h.handleAdminNoShowPolicyStart(ctx, cb)
+	case "admin:cancel_policy":
+		h.handleAdminCancelPolicyStart(ctx, cb)
+	case "admin:courts":
+		h.handleAdminCourtLocations(ctx, cb)
+	case "admin:roles":
+		delete(h.grantingRoles, cb.Message.ChatID)
+		h.showRoles(ctx, cb.Message.ChatID, cb.Message.MessageID)
+	case "admin:roles:add":
+		h.handleAdminStartGrantRole(cb)
+	case "admin:debtors":
+		delete(h.enteringPayments, cb.Message.ChatID)
+		h.showDebtors(ctx, cb.Message.ChatID, cb.Message.MessageID, cb.From.ID)
+	case "admin:pass:loc_done":
+		h.handleAdminIssuePass(ctx, cb)
+	case "admin:pass:cancel":
+		h.handleAdminCancelIssuePass(ctx, cb)
+	case "admin:ban:ld":
+		h.handleAdminRestrictionLocationsDone(cb)
+	case "admin:ban:cancel":
+		h.handleAdminCancelRestriction(ctx, cb)
+	case "admin:promos":
+		h.handleAdminPromos(ctx, cb)
+	case "admin:promo_new":
+		h.handleAdminStartCreatePromo(cb)
 	default:
+		// Роли (формат: admin:roles:role:{role}, admin:roles:loc:{locationID|all}, admin:roles:del:{grantID})
+		if strings.HasPrefix(cb.Data, "admin:roles:role:") {
+			h.handleAdminPickRole(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:roles:loc:") {
+			h.handleAdminPickRoleLocation(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:roles:del:") {
+			h.handleAdminRevokeRole(ctx, cb)
+			return
+		}
 		// Обработка динамических callback'ов для удаления (формат: admin:delete:{locationID})
 		if strings.HasPrefix(cb.Data, "admin:delete:") {
 			h.handleAdminConfirmDeleteLocation(ctx, cb)
 		}
-		// Обработка выбора локации для создания события (формат: admin:create_event:loc:{locationID})
-		if strings.HasPrefix(cb.Data, "admin:create_event:loc:") {
+		// Обработка выбора локации для создания события или серии (формат: admin:create_event:loc:{locationID})
+		if strings.HasPrefix(cb.Data, "admin:create_event:loc:") || strings.HasPrefix(cb.Data, "admin:create_series:loc:") {
 			h.handleAdminSelectLocationForEvent(ctx, cb)
 		}
 		// Обработка выбора типа события (формат: admin:create_event:type:{locationID}:{type})
 		if strings.HasPrefix(cb.Data, "admin:create_event:type:") {
 			h.handleAdminSelectEventType(ctx, cb)
 		}
+		// Обработка выбора формата соревнования (формат: admin:create_event:doubles:{0|1})
+		if strings.HasPrefix(cb.Data, "admin:create_event:doubles:") {
+			h.handleAdminSelectEventDoubles(ctx, cb)
+			return
+		}
+		// Выбор кортов при создании события (формат: admin:create_event:court:{номер}, admin:create_event:courts_done)
+		if strings.HasPrefix(cb.Data, "admin:create_event:court:") {
+			h.handleAdminToggleEventCourt(ctx, cb)
+			return
+		}
+		if cb.Data == "admin:create_event:courts_done" {
+			h.handleAdminEventCourtsDone(ctx, cb)
+			return
+		}
+		// Выбор способа оплаты при создании события (формат: admin:create_event:pay:{mode})
+		if strings.HasPrefix(cb.Data, "admin:create_event:pay:") {
+			h.handleAdminSelectPaymentMode(ctx, cb)
+			return
+		}
+		// Корты локаций (формат: admin:courts:{locationID}, admin:court:{action}:{locationID}...)
+		if strings.HasPrefix(cb.Data, "admin:courts:") {
+			h.handleAdminCourts(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:court:add:") {
+			h.handleAdminStartAddCourt(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:court:del:") {
+			h.handleAdminDeleteCourt(ctx, cb)
+			return
+		}
 		// Обработка модерации регистраций для события (формат: admin:event:moderation:{eventID})
 		if strings.HasPrefix(cb.Data, "admin:event:moderation:") {
 			h.handleAdminEventModeration(ctx, cb)
@@ -115,14 +235,223 @@ func (h *Handlers) handleAdminCallback(ctx context.Context, cb *CallbackQuery) {
 			h.handleAdminEventDetails(ctx, cb)
 			return
 		}
-		// Обработка модерации регистрации (формат: admin:reg:{userID} или admin:reg:approve:{eventID}:{userID})
+		// Обработка модерации регистрации (формат: admin:reg:{eventID}:{userID} или admin:reg:approve:{eventID}:{userID})
 		if strings.HasPrefix(cb.Data, "admin:reg:") {
 			h.handleAdminRegistrationModeration(ctx, cb)
+			return
+		}
+		// Абонементы (формат: admin:pass:new:{userID}, admin:pass:type:{type}, admin:pass:loc:{locationID}, admin:pass:del:{passID})
+		if strings.HasPrefix(cb.Data, "admin:pass:new:") {
+			h.handleAdminStartIssuePass(cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:pass:type:") {
+			h.handleAdminPassType(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:pass:loc:") {
+			h.handleAdminPassToggleLocation(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:pass:del:") {
+			h.handleAdminDeletePass(ctx, cb)
+			return
+		}
+		// Карточка игрока и ограничения (формат: admin:usr:{userID}, admin:ban:{full|until|loc}:{userID},
+		// admin:ban:lt:{locationID}, admin:ban:lift:{restrictionID})
+		if strings.HasPrefix(cb.Data, "admin:usr:") {
+			h.handleAdminUserCard(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:ban:lift:") {
+			h.handleAdminLiftRestriction(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:ban:lt:") {
+			h.handleAdminRestrictionToggleLocation(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:ban:full:") || strings.HasPrefix(cb.Data, "admin:ban:until:") || strings.HasPrefix(cb.Data, "admin:ban:loc:") {
+			h.handleAdminStartRestriction(ctx, cb)
+			return
+		}
+		// Промокоды (формат: admin:promo_kind:{kind}, admin:promo_scope:{scope}, admin:promo_evt:{eventID}, admin:promo_off:{codeID}, admin:promo:{codeID})
+		if strings.HasPrefix(cb.Data, "admin:promo_kind:") {
+			h.handleAdminPromoKind(cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:promo_scope:") {
+			h.handleAdminPromoScope(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:promo_evt:") {
+			h.handleAdminPromoEvent(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:promo_off:") {
+			h.handleAdminDeactivatePromo(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:promo:") {
+			h.handleAdminPromo(ctx, cb)
+			return
+		}
+		// Гости (формат: admin:guests:{eventID}, admin:guest:{ok|no|pay}:{eventID}:{guestID})
+		if strings.HasPrefix(cb.Data, "admin:guests:") {
+			h.handleAdminGuests(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:guest:") {
+			h.handleAdminGuestAction(ctx, cb)
+			return
 		}
-		// Обработка подтверждения удаления события (формат: admin:delete_event:confirm:{eventID})
+		// Оплаты (формат: admin:pay:{eventID}, admin:pay:u:{eventID}:{userID}, admin:pay:{full|part|refund}:{method})
+		if strings.HasPrefix(cb.Data, "admin:pay:u:") {
+			h.handleAdminPlayerPayments(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:pay:full:") || strings.HasPrefix(cb.Data, "admin:pay:part:") ||
+			strings.HasPrefix(cb.Data, "admin:pay:refund:") {
+			h.handleAdminPaymentAction(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:pay:") {
+			h.handleAdminEventPayments(ctx, cb)
+			return
+		}
+		// Редактирование события (формат: admin:edit:{eventID}, admin:edit:field:{field}:{eventID},
+		// admin:edit:loc:{locationID}, admin:edit:type:{type}, admin:edit:doubles:{0|1}, admin:edit:pay:{mode})
+		if strings.HasPrefix(cb.Data, "admin:edit:field:") {
+			h.handleAdminEditEventField(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:edit:loc:") {
+			h.handleAdminEditEventLocation(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:edit:type:") {
+			h.handleAdminEditEventType(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:edit:court:") {
+			h.handleAdminEditToggleCourt(ctx, cb)
+			return
+		}
+		if cb.Data == "admin:edit:courts_done" {
+			h.handleAdminEditCourtsDone(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:edit:doubles:") {
+			h.handleAdminEditEventDoubles(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:edit:pay:") {
+			h.handleAdminEditEventPaymentMode(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:edit:") {
+			h.handleAdminEditEvent(ctx, cb)
+			return
+		}
+		// Посещаемость (формат: admin:att:{eventID}, admin:att:t:{eventID}:{userID},
+		// admin:att:rest:{eventID}, admin:att:link:{eventID})
+		if strings.HasPrefix(cb.Data, "admin:att:t:") {
+			h.handleAdminToggleAttendance(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:att:rest:") {
+			h.handleAdminMarkRestNoShow(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:att:link:") {
+			h.handleAdminCheckInLink(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:att:") {
+			h.handleAdminAttendance(ctx, cb)
+			return
+		}
+		// Турнир соревнования (формат: admin:trn:{eventID}, admin:trn:{action}:...)
+		if strings.HasPrefix(cb.Data, "admin:trn:new:") {
+			h.handleAdminCreateTournament(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:seed:") {
+			h.handleAdminTournamentSeed(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:m:") {
+			h.handleAdminTournamentMatch(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:fix:") {
+			h.handleAdminTournamentFix(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:pub:") {
+			h.handleAdminTournamentPublish(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:delok:") {
+			h.handleAdminTournamentDelete(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:del:") {
+			h.handleAdminTournamentDeleteConfirm(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:trn:") {
+			h.handleAdminTournament(ctx, cb)
+			return
+		}
+		// Уровень игрока (формат: admin:level:{userID}, admin:level:set:{userID}:{level})
+		if strings.HasPrefix(cb.Data, "admin:level:set:") {
+			h.handleAdminSetLevel(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:level:") {
+			h.handleAdminLevelPicker(ctx, cb)
+			return
+		}
+		// Допуск игрока к событию другого уровня (формат: admin:lvok:{eventID}:{userID})
+		if strings.HasPrefix(cb.Data, "admin:lvok:") {
+			h.handleAdminLevelOverride(ctx, cb)
+			return
+		}
+		// Публикация черновика (формат: admin:publish:{eventID})
+		if strings.HasPrefix(cb.Data, "admin:publish:") {
+			h.handleAdminPublishEvent(ctx, cb)
+		}
+		// Запрос подтверждения отмены события (формат: admin:cancel_event:{eventID})
+		if strings.HasPrefix(cb.Data, "admin:cancel_event:") {
+			h.handleAdminCancelEventConfirm(ctx, cb)
+		}
+		// Обработка подтверждения отмены события (формат: admin:delete_event:confirm:{eventID})
 		if strings.HasPrefix(cb.Data, "admin:delete_event:confirm:") {
 			h.handleAdminConfirmDeleteEvent(ctx, cb)
 		}
+		// Обработка серий (формат: admin:series:{seriesID} или admin:series:stop:{seriesID})
+		if strings.HasPrefix(cb.Data, "admin:series:stop:") {
+			h.handleAdminStopSeries(ctx, cb)
+			return
+		}
+		if strings.HasPrefix(cb.Data, "admin:series:") {
+			h.handleAdminSeriesDetails(ctx, cb)
+			return
+		}
+		// Обработка занятий серии (формат: admin:occ:{action}:...)
+		if strings.HasPrefix(cb.Data, "admin:occ:cancel:") {
+			h.handleAdminCancelOccurrence(ctx, cb)
+		}
+		if strings.HasPrefix(cb.Data, "admin:occ:edit:") {
+			h.handleAdminEditOccurrence(ctx, cb)
+		}
+		if strings.HasPrefix(cb.Data, "admin:occ:field:") {
+			h.handleAdminOccurrenceField(ctx, cb)
+		}
+		if strings.HasPrefix(cb.Data, "admin:occ:apply:") {
+			h.handleAdminApplyOccurrenceEdit(ctx, cb)
+		}
 	}
 }
 
@@ -152,12 +481,17 @@ func (h *Handlers) handleAdminSelectLocationForEvent(ctx context.Context, cb *Ca
 	}
 
 	// Сохраняем выбранную локацию для создания события
+	recurring := strings.HasPrefix(cb.Data, "admin:create_series:")
 	h.creatingEvents[cb.Message.ChatID] = &EventCreationState{
 		Step:       "type",
 		LocationID: locationID,
+		Recurring:  recurring,
 	}
 
 	text := fmt.Sprintf("📅 Создание события для локации: %s\n\nВыберите тип события:", loc.Name)
+	if recurring {
+		text = fmt.Sprintf("🔁 Создание серии для локации: %s\n\nВыберите тип занятий:", loc.Name)
+	}
 	keyboard := NewInlineKeyboardMarkup(
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("🏋️ Тренировка", "admin:create_event:type:training"),
@@ -208,13 +542,53 @@ func (h *Handlers) handleAdminSelectEventType(ctx context.Context, cb *CallbackQ
 	// Обновляем состояние
 	state.Step = "max_players"
 	state.EventType = eventType
+	state.Doubles = false
 
-	typeName := "Тренировка"
+	// Для соревнования сначала выбираем категорию: одиночная или парная
 	if eventType == event.EventTypeCompetition {
-		typeName = "Соревнование"
+		state.Step = "doubles"
+		keyboard := NewInlineKeyboardMarkup(
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("👤 Одиночный разряд", "admin:create_event:doubles:0"),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("👥 Парный разряд", "admin:create_event:doubles:1"),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
+			),
+		)
+		text := "📅 Тип события: Соревнование\n\nВыберите категорию:"
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message for doubles prompt", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	text := "📅 Тип события: Тренировка\n\nВведите количество мест:"
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for max players prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminSelectEventDoubles обрабатывает выбор категории соревнования (одиночная или парная)
+func (h *Handlers) handleAdminSelectEventDoubles(ctx context.Context, cb *CallbackQuery) {
+	state := h.creatingEvents[cb.Message.ChatID]
+	if state == nil || state.Step != "doubles" {
+		h.logger.Error("event creation state not found", "chat_id", cb.Message.ChatID)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
 	}
 
-	text := fmt.Sprintf("📅 Тип события: %s\n\nВведите количество мест:", typeName)
+	state.Doubles = strings.TrimPrefix(cb.Data, "admin:create_event:doubles:") == "1"
+	state.Step = "max_players"
+
+	text := "📅 Тип события: Соревнование (одиночный разряд)\n\nВведите количество мест:"
+	if state.Doubles {
+		text = "📅 Тип события: Соревнование (парный разряд)\n\nВведите количество команд:"
+	}
 	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
 		h.logger.Error("failed to edit message for max players prompt", "chat_id", cb.Message.ChatID, "error", err)
 	}
@@ -229,12 +603,38 @@ func (h *Handlers) handleAdminCreateEventStep(ctx context.Context, msg *Message,
 		h.handleAdminEnterEventName(ctx, msg, state)
 	case "date":
 		h.handleAdminEnterEventDate(ctx, msg, state)
+	case "duration":
+		h.handleAdminEnterEventDuration(ctx, msg, state)
+	case "courts":
+		// Корты выбираются кнопками
+		if err := h.client.SendMessage(msg.ChatID, "🎾 Выберите корты кнопками выше и нажмите «Готово»"); err != nil {
+			h.logger.Error("failed to send court picker hint", "chat_id", msg.ChatID, "error", err)
+		}
 	case "trainer":
 		h.handleAdminEnterTrainer(ctx, msg, state)
+	case "payment_mode":
+		// Способ оплаты выбирается кнопками
+		if err := h.client.SendMessage(msg.ChatID, "💳 Выберите способ оплаты кнопками выше"); err != nil {
+			h.logger.Error("failed to send payment mode hint", "chat_id", msg.ChatID, "error", err)
+		}
 	case "payment_phone":
 		h.handleAdminEnterPaymentPhone(ctx, msg, state)
 	case "price":
 		h.handleAdminEnterPrice(ctx, msg, state)
+	case "level":
+		h.handleAdminEnterLevelRange(ctx, msg, state)
+	case "pending_timeout":
+		h.handleAdminEnterPendingTimeout(ctx, msg, state)
+	case "weekdays":
+		h.handleAdminEnterSeriesWeekdays(ctx, msg, state)
+	case "time":
+		h.handleAdminEnterSeriesTime(ctx, msg, state)
+	case "start_date":
+		h.handleAdminEnterSeriesStartDate(ctx, msg, state)
+	case "series_end":
+		h.handleAdminEnterSeriesEnd(ctx, msg, state)
+	case "weeks_ahead":
+		h.handleAdminEnterSeriesWeeksAhead(ctx, msg, state)
 	default:
 		// Неожиданный шаг, очищаем состояние
 		delete(h.creatingEvents, msg.ChatID)
@@ -258,6 +658,9 @@ func (h *Handlers) handleAdminEnterMaxPlayers(ctx context.Context, msg *Message,
 	state.Step = "name"
 
 	text := fmt.Sprintf("👥 Количество мест: %d\n\nВведите название события:", maxPlayers)
+	if state.Doubles {
+		text = fmt.Sprintf("👥 Количество команд: %d\n\nВведите название события:", maxPlayers)
+	}
 	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
 		h.logger.Error("failed to send event name prompt", "chat_id", msg.ChatID, "error", err)
 	}
@@ -274,6 +677,16 @@ func (h *Handlers) handleAdminEnterEventName(ctx context.Context, msg *Message,
 	}
 
 	state.EventName = eventName
+
+	if state.Recurring {
+		state.Step = "weekdays"
+		text := fmt.Sprintf("📝 Название: %s\n\nВведите дни недели через запятую (пн, вт, ср, чт, пт, сб, вс):\n\nПример: вт, чт", eventName)
+		if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+			h.logger.Error("failed to send series weekdays prompt", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
 	state.Step = "date"
 
 	text := fmt.Sprintf("📝 Название: %s\n\nВведите дату и время начала события в формате:\n📅 ДД.ММ.ГГГГ ЧЧ:ММ\n\nПример: 15.01.2026 18:00", eventName)
@@ -286,23 +699,12 @@ func (h *Handlers) handleAdminEnterEventName(ctx context.Context, msg *Message,
 func (h *Handlers) handleAdminEnterEventDate(ctx context.Context, msg *Message, state *EventCreationState) {
 	dateStr := strings.TrimSpace(msg.Text)
 
-	// Парсим дату в формате "02.01.2006 15:04"
-	eventDate, err := time.Parse("02.01.2006 15:04", dateStr)
+	eventDate, err := parseEventDate(dateStr)
 	if err != nil {
-		// Пробуем альтернативный формат "02.01.2006 15:4" (без ведущего нуля в минутах)
-		eventDate, err = time.Parse("02.01.2006 15:4", dateStr)
-		if err != nil {
-			// Пробуем формат без времени
-			eventDate, err = time.Parse("02.01.2006", dateStr)
-			if err != nil {
-				if sendErr := h.client.SendMessage(msg.ChatID, "❌ Неверный формат даты. Используйте формат:\n📅 ДД.ММ.ГГГГ ЧЧ:ММ\n\nПример: 15.01.2026 18:00"); sendErr != nil {
-					h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
-				}
-				return
-			}
-			// Если время не указано, устанавливаем на 18:00 по умолчанию
-			eventDate = time.Date(eventDate.Year(), eventDate.Month(), eventDate.Day(), 18, 0, 0, 0, eventDate.Location())
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Неверный формат даты. Используйте формат:\n📅 ДД.ММ.ГГГГ ЧЧ:ММ\n\nПример: 15.01.2026 18:00"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
 		}
+		return
 	}
 
 	// Проверяем, что дата не в прошлом
@@ -314,121 +716,446 @@ func (h *Handlers) handleAdminEnterEventDate(ctx context.Context, msg *Message,
 	}
 
 	state.EventDate = eventDate
-	state.Step = "trainer"
+	state.Step = "duration"
 
-	text := fmt.Sprintf("🗓️ Дата: %s\n\nВведите имя тренера:", eventDate.Format("02.01.2006 15:04"))
+	text := fmt.Sprintf("🗓️ Дата: %s\n\n%s", eventDate.Format("02.01.2006 15:04"), durationPrompt)
 	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
-		h.logger.Error("failed to send trainer prompt", "chat_id", msg.ChatID, "error", err)
+		h.logger.Error("failed to send duration prompt", "chat_id", msg.ChatID, "error", err)
 	}
 }
 
-// handleAdminEnterTrainer обрабатывает ввод тренера
-func (h *Handlers) handleAdminEnterTrainer(ctx context.Context, msg *Message, state *EventCreationState) {
-	trainer := strings.TrimSpace(msg.Text)
-	if trainer == "" {
-		if err := h.client.SendMessage(msg.ChatID, "❌ Имя тренера не может быть пустым. Введите имя тренера:"); err != nil {
-			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
-		}
-		return
-	}
-
-	state.Trainer = trainer
-	state.Step = "payment_phone"
+// durationPrompt - подсказка для ввода длительности события
+var durationPrompt = fmt.Sprintf("⏱ Введите длительность события в минутах (например, 90) или \"-\" — %d мин. по умолчанию:", int(event.DefaultDuration/time.Minute))
 
-	text := fmt.Sprintf("👨‍🏫 Тренер: %s\n\nВведите номер телефона для оплаты (например, +79991234567):", trainer)
-	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
-		h.logger.Error("failed to send payment phone prompt", "chat_id", msg.ChatID, "error", err)
+// parseDuration разбирает длительность в минутах ("-" - длительность по умолчанию)
+func parseDuration(input string) (time.Duration, error) {
+	input = strings.TrimSpace(input)
+	if input == "-" {
+		return 0, nil
+	}
+	minutes, err := strconv.Atoi(input)
+	if err != nil || minutes <= 0 {
+		return 0, event.ErrDurationInvalid
 	}
+	return time.Duration(minutes) * time.Minute, nil
 }
 
-// handleAdminEnterPaymentPhone обрабатывает ввод телефона для оплаты
-func (h *Handlers) handleAdminEnterPaymentPhone(ctx context.Context, msg *Message, state *EventCreationState) {
-	paymentPhone := strings.TrimSpace(msg.Text)
-	if paymentPhone == "" {
-		if err := h.client.SendMessage(msg.ChatID, "❌ Номер телефона не может быть пустым. Введите номер телефона:"); err != nil {
-			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+// handleAdminEnterEventDuration обрабатывает ввод длительности события и предлагает свободные корты
+func (h *Handlers) handleAdminEnterEventDuration(ctx context.Context, msg *Message, state *EventCreationState) {
+	duration, err := parseDuration(msg.Text)
+	if err != nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите длительность в минутах (целое положительное число) или \"-\":"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
 		}
 		return
 	}
+	state.Duration = duration
 
-	state.PaymentPhone = paymentPhone
-	state.Step = "price"
-
-	text := fmt.Sprintf("📱 Телефон для оплаты: %s\n\nВведите стоимость тренировки (в рублях, только число):", paymentPhone)
-	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
-		h.logger.Error("failed to send price prompt", "chat_id", msg.ChatID, "error", err)
-	}
-}
-
-// handleAdminEnterPrice обрабатывает ввод цены и создает событие
-func (h *Handlers) handleAdminEnterPrice(ctx context.Context, msg *Message, state *EventCreationState) {
-	priceStr := strings.TrimSpace(msg.Text)
-	price, err := strconv.Atoi(priceStr)
-	if err != nil || price < 0 {
-		if err := h.client.SendMessage(msg.ChatID, "❌ Введите корректную стоимость (положительное число в рублях):"); err != nil {
-			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+	// Занятия серии создаются по расписанию, корты для них не бронируются
+	if state.Recurring {
+		state.Step = "start_date"
+		text := fmt.Sprintf("⏱ Длительность: %s\n\nВведите дату начала серии в формате ДД.ММ.ГГГГ или отправьте \"-\", чтобы начать с сегодняшнего дня:", formatDuration(state.effectiveDuration()))
+		if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+			h.logger.Error("failed to send series start date prompt", "chat_id", msg.ChatID, "error", err)
 		}
 		return
 	}
 
-	state.Price = price
-
-	// Удаляем состояние перед созданием события
-	delete(h.creatingEvents, msg.ChatID)
-
-	// Создаем событие
-	evt, err := h.eventService.Create(ctx, event.CreateEventInput{
-		Name:         state.EventName,
-		Type:         state.EventType,
-		Date:         state.EventDate,
-		MaxPlayers:   state.MaxPlayers,
-		LocationID:   state.LocationID,
-		Trainer:      state.Trainer,
-		Description:  "",
-		PaymentPhone: state.PaymentPhone,
-		Price:        state.Price,
-	})
+	courts, err := h.locationService.ListCourts(ctx, state.LocationID)
+	if err != nil {
+		h.logger.Error("failed to list courts", "location_id", string(state.LocationID), "chat_id", msg.ChatID, "error", err)
+	}
+	// Если у локации нет кортов, бронировать нечего
+	if len(courts) == 0 {
+		h.sendTrainerPrompt(msg.ChatID, state)
+		return
+	}
 
+	free, err := h.eventService.FreeCourts(ctx, state.LocationID, state.EventDate, state.Duration, "")
 	if err != nil {
-		h.logger.Error("failed to create event", "event_name", state.EventName, "location_id", string(state.LocationID), "chat_id", msg.ChatID, "error", err)
-		if sendErr := h.client.SendMessage(msg.ChatID, fmt.Sprintf("❌ Ошибка создания события: %v", err)); sendErr != nil {
+		h.logger.Error("failed to find free courts", "location_id", string(state.LocationID), "chat_id", msg.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка получения свободных кортов. Введите длительность еще раз:"); sendErr != nil {
 			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
 		}
 		return
 	}
+	state.FreeCourts = free
+	state.CourtIDs = nil
+	state.Step = "courts"
 
-	typeName := "Тренировка"
-	if state.EventType == event.EventTypeCompetition {
-		typeName = "Соревнование"
-	}
-
-	text := fmt.Sprintf("✅ %s успешно создано!\n\n📅 Название: %s\n🗓️ Дата: %s\n👥 Мест: %d\n👨‍🏫 Тренер: %s\n🔑 ID: %s",
-		typeName, evt.Name, evt.Date.Format("02.01.2006 15:04"), evt.MaxPlayers, evt.Trainer, string(evt.ID))
-	keyboard := NewInlineKeyboardMarkup(
-		NewInlineKeyboardRow(
-			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
-		),
-	)
+	text, keyboard := h.formatter.FormatCourtPicker(eventSlotHeader(state.EventDate, state.Duration), free, nil, "admin:create_event:")
 	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
-		h.logger.Error("failed to send event created message", "chat_id", msg.ChatID, "error", err)
+		h.logger.Error("failed to send court picker", "chat_id", msg.ChatID, "error", err)
 	}
+}
 
-	// Публикуем анонс в канал (если настроен)
-	h.publishEventToChannel(ctx, evt)
+// effectiveDuration возвращает введенную длительность события с учетом значения по умолчанию
+func (s *EventCreationState) effectiveDuration() time.Duration {
+	slot := &event.Event{Duration: s.Duration}
+	return slot.EffectiveDuration()
 }
 
-// handleAdminStartCreateLocation начинает процесс создания локации
-func (h *Handlers) handleAdminStartCreateLocation(ctx context.Context, cb *CallbackQuery) {
-	// Создаем состояние для создания локации
-	h.creatingLocations[cb.Message.ChatID] = &LocationCreationState{
-		Step: "name",
+// eventSlotHeader возвращает строку с временем проведения события для выбора кортов
+func eventSlotHeader(date time.Time, duration time.Duration) string {
+	slot := &event.Event{Date: date, Duration: duration}
+	return fmt.Sprintf("🗓️ %s–%s", date.Format("02.01.2006 15:04"), slot.EndsAt().Format("15:04"))
+}
+
+// handleAdminToggleEventCourt отмечает или снимает корт при создании события (формат: admin:create_event:court:{номер})
+func (h *Handlers) handleAdminToggleEventCourt(ctx context.Context, cb *CallbackQuery) {
+	state := h.creatingEvents[cb.Message.ChatID]
+	if state == nil || state.Step != "courts" {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
 	}
 
-	text := "📍 Создание новой локации\n\nВведите название локации:"
-	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
-		h.logger.Error("failed to edit message for location name prompt", "chat_id", cb.Message.ChatID, "error", err)
+	court, ok := courtByIndex(state.FreeCourts, strings.TrimPrefix(cb.Data, "admin:create_event:court:"))
+	if !ok {
+		h.logger.Warn("invalid court index", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
 	}
-}
+	state.CourtIDs = toggleCourt(state.CourtIDs, court.ID)
+
+	text, keyboard := h.formatter.FormatCourtPicker(eventSlotHeader(state.EventDate, state.Duration), state.FreeCourts, state.CourtIDs, "admin:create_event:")
+	if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit court picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminEventCourtsDone завершает выбор кортов и переходит к вводу тренера (формат: admin:create_event:courts_done)
+func (h *Handlers) handleAdminEventCourtsDone(ctx context.Context, cb *CallbackQuery) {
+	state := h.creatingEvents[cb.Message.ChatID]
+	if state == nil || state.Step != "courts" {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := eventSlotHeader(state.EventDate, state.Duration) + "\n\n🎾 Корты: " + html.EscapeString(courtNamesOf(state.FreeCourts, state.CourtIDs))
+	if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, nil); err != nil {
+		h.logger.Error("failed to edit court picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+	h.sendTrainerPrompt(cb.Message.ChatID, state)
+}
+
+// sendTrainerPrompt переводит создание события к вводу тренера
+func (h *Handlers) sendTrainerPrompt(chatID int64, state *EventCreationState) {
+	state.Step = "trainer"
+	if err := h.client.SendMessage(chatID, "👨‍🏫 Введите имя тренера:"); err != nil {
+		h.logger.Error("failed to send trainer prompt", "chat_id", chatID, "error", err)
+	}
+}
+
+// courtByIndex возвращает корт по номеру из callback data
+func courtByIndex(courts []location.Court, index string) (location.Court, bool) {
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(courts) {
+		return location.Court{}, false
+	}
+	return courts[i], true
+}
+
+// toggleCourt добавляет корт в выбранные или убирает его оттуда
+func toggleCourt(selected []location.CourtID, id location.CourtID) []location.CourtID {
+	for i, courtID := range selected {
+		if courtID == id {
+			return append(selected[:i:i], selected[i+1:]...)
+		}
+	}
+	return append(selected, id)
+}
+
+// courtNamesOf возвращает названия выбранных кортов через запятую ("без корта", если не выбрано ни одного)
+func courtNamesOf(courts []location.Court, selected []location.CourtID) string {
+	var names []string
+	for _, c := range courts {
+		for _, id := range selected {
+			if c.ID == id {
+				names = append(names, c.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "без корта"
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseEventDate парсит дату события в формате "02.01.2006 15:04" (без времени - 18:00)
+func parseEventDate(input string) (time.Time, error) {
+	eventDate, err := time.Parse("02.01.2006 15:04", input)
+	if err == nil {
+		return eventDate, nil
+	}
+	// Пробуем альтернативный формат "02.01.2006 15:4" (без ведущего нуля в минутах)
+	eventDate, err = time.Parse("02.01.2006 15:4", input)
+	if err == nil {
+		return eventDate, nil
+	}
+	// Пробуем формат без времени
+	eventDate, err = time.Parse("02.01.2006", input)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Если время не указано, устанавливаем на 18:00 по умолчанию
+	return time.Date(eventDate.Year(), eventDate.Month(), eventDate.Day(), 18, 0, 0, 0, eventDate.Location()), nil
+}
+
+// handleAdminEnterTrainer обрабатывает ввод тренера
+func (h *Handlers) handleAdminEnterTrainer(ctx context.Context, msg *Message, state *EventCreationState) {
+	trainer := strings.TrimSpace(msg.Text)
+	if trainer == "" {
+		if err := h.client.SendMessage(msg.ChatID, "❌ Имя тренера не может быть пустым. Введите имя тренера:"); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.Trainer = trainer
+	state.Step = "payment_mode"
+
+	text, keyboard := h.formatter.FormatPaymentModePicker(fmt.Sprintf("👨‍🏫 Тренер: %s", trainer), "admin:create_event:pay:", "", paymentProviderToken() != "")
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send payment mode picker", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminSelectPaymentMode обрабатывает выбор способа оплаты при создании события (формат: admin:create_event:pay:{mode})
+func (h *Handlers) handleAdminSelectPaymentMode(ctx context.Context, cb *CallbackQuery) {
+	state := h.creatingEvents[cb.Message.ChatID]
+	if state == nil || state.Step != "payment_mode" {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	mode := event.PaymentMode(strings.TrimPrefix(cb.Data, "admin:create_event:pay:"))
+	if !mode.Valid() {
+		h.logger.Warn("invalid payment mode", "mode", string(mode), "chat_id", cb.Message.ChatID)
+		return
+	}
+	state.PaymentMode = mode
+
+	text := "💳 Оплата: " + paymentModeLabels[mode]
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, nil); err != nil {
+		h.logger.Error("failed to edit payment mode picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+
+	// Онлайн-оплата не требует реквизитов - сразу переходим к стоимости
+	if mode.Online() {
+		state.Step = "price"
+		if err := h.client.SendMessage(cb.Message.ChatID, pricePrompt(mode)); err != nil {
+			h.logger.Error("failed to send price prompt", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.Step = "payment_phone"
+	if err := h.client.SendMessage(cb.Message.ChatID, "Введите номер телефона для оплаты (например, +79991234567):"); err != nil {
+		h.logger.Error("failed to send payment phone prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// pricePrompt возвращает подсказку для ввода стоимости в валюте способа оплаты
+func pricePrompt(mode event.PaymentMode) string {
+	if mode == event.PaymentModeStars {
+		return "Введите стоимость тренировки (в звездах Telegram, только число больше нуля):"
+	}
+	if mode.Online() {
+		return "Введите стоимость тренировки (в рублях, только число больше нуля):"
+	}
+	return "Введите стоимость тренировки (в рублях, только число):"
+}
+
+// handleAdminEnterPaymentPhone обрабатывает ввод телефона для оплаты
+func (h *Handlers) handleAdminEnterPaymentPhone(ctx context.Context, msg *Message, state *EventCreationState) {
+	paymentPhone := strings.TrimSpace(msg.Text)
+	if paymentPhone == "" {
+		if err := h.client.SendMessage(msg.ChatID, "❌ Номер телефона не может быть пустым. Введите номер телефона:"); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.PaymentPhone = paymentPhone
+	state.Step = "price"
+
+	text := fmt.Sprintf("📱 Телефон для оплаты: %s\n\n%s", paymentPhone, pricePrompt(state.PaymentMode))
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send price prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterPrice обрабатывает ввод цены и создает событие
+func (h *Handlers) handleAdminEnterPrice(ctx context.Context, msg *Message, state *EventCreationState) {
+	priceStr := strings.TrimSpace(msg.Text)
+	price, err := strconv.Atoi(priceStr)
+	if err != nil || price < 0 || (state.PaymentMode.Online() && price == 0) {
+		if err := h.client.SendMessage(msg.ChatID, "❌ Введите корректную стоимость. "+pricePrompt(state.PaymentMode)); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.Price = price
+	state.Step = "level"
+
+	text := fmt.Sprintf("💰 Стоимость: %s\n\n%s", formatAmount(state.PaymentMode, price), levelRangePrompt)
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send level range prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// levelRangePrompt - подсказка для ввода диапазона уровней
+const levelRangePrompt = "🎯 Введите допустимый уровень игроков: \"3.0-4.0\", \"от 3.0\", \"до 4.0\" или \"-\" без ограничения:"
+
+// parseLevelRange разбирает диапазон уровней: "-", "от 3.0", "до 4.0" или "3.0-4.0"
+func parseLevelRange(input string) (user.Level, user.Level, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "-" {
+		return user.LevelUnset, user.LevelUnset, nil
+	}
+
+	var min, max user.Level
+	var err error
+	switch {
+	case strings.HasPrefix(input, "от "):
+		min, err = user.ParseLevel(strings.TrimPrefix(input, "от "))
+	case strings.HasPrefix(input, "до "):
+		max, err = user.ParseLevel(strings.TrimPrefix(input, "до "))
+	default:
+		bounds := strings.FieldsFunc(input, func(r rune) bool { return r == '-' || r == '–' })
+		if len(bounds) != 2 {
+			return user.LevelUnset, user.LevelUnset, event.ErrLevelRangeInvalid
+		}
+		if min, err = user.ParseLevel(bounds[0]); err == nil {
+			max, err = user.ParseLevel(bounds[1])
+		}
+	}
+	if err != nil {
+		return user.LevelUnset, user.LevelUnset, err
+	}
+	if err := event.ValidateLevelRange(min, max); err != nil {
+		return user.LevelUnset, user.LevelUnset, err
+	}
+	return min, max, nil
+}
+
+// handleAdminEnterLevelRange обрабатывает ввод диапазона уровней события
+func (h *Handlers) handleAdminEnterLevelRange(ctx context.Context, msg *Message, state *EventCreationState) {
+	min, max, err := parseLevelRange(msg.Text)
+	if err != nil {
+		if err := h.client.SendMessage(msg.ChatID, "❌ Некорректный диапазон уровней.\n\n"+levelRangePrompt); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.MinLevel = min
+	state.MaxLevel = max
+	state.Step = "pending_timeout"
+
+	text := fmt.Sprintf("🎯 Уровень: %s\n\nВведите время брони без оплаты в минутах (0 — не снимать бронь) или отправьте \"-\", чтобы использовать общую настройку:", formatLevelRange(min, max))
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send pending timeout prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterPendingTimeout обрабатывает ввод времени брони и создает событие
+func (h *Handlers) handleAdminEnterPendingTimeout(ctx context.Context, msg *Message, state *EventCreationState) {
+	input := strings.TrimSpace(msg.Text)
+	if input != "-" {
+		minutes, err := strconv.Atoi(input)
+		if err != nil || minutes < 0 {
+			if err := h.client.SendMessage(msg.ChatID, "❌ Введите количество минут (целое число) или \"-\":"); err != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+			}
+			return
+		}
+		state.PendingTimeout = time.Duration(minutes) * time.Minute
+	}
+
+	// Удаляем состояние перед созданием события
+	delete(h.creatingEvents, msg.ChatID)
+
+	if state.Recurring {
+		h.createSeries(ctx, msg, state)
+		return
+	}
+
+	// Создаем событие
+	evt, err := h.eventService.Create(ctx, event.CreateEventInput{
+		Name:           state.EventName,
+		Type:           state.EventType,
+		Date:           state.EventDate,
+		MaxPlayers:     state.MaxPlayers,
+		LocationID:     state.LocationID,
+		Trainer:        state.Trainer,
+		Description:    "",
+		PaymentMode:    state.PaymentMode,
+		PaymentPhone:   state.PaymentPhone,
+		Price:          state.Price,
+		PendingTimeout: state.PendingTimeout,
+		MinLevel:       state.MinLevel,
+		MaxLevel:       state.MaxLevel,
+		Doubles:        state.Doubles,
+		Duration:       state.Duration,
+		CourtIDs:       state.CourtIDs,
+		CreatedBy:      msg.From.ID,
+		Draft:          true,
+	})
+
+	if err != nil {
+		h.logger.Error("failed to create event", "event_name", state.EventName, "location_id", string(state.LocationID), "chat_id", msg.ChatID, "error", err)
+		errorMsg := courtErrorMessage(err)
+		if errorMsg == "" {
+			errorMsg = fmt.Sprintf("❌ Ошибка создания события: %v", err)
+		}
+		if sendErr := h.client.SendMessage(msg.ChatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	typeName := "Тренировка"
+	if state.EventType == event.EventTypeCompetition {
+		typeName = "Соревнование"
+		if state.Doubles {
+			typeName = "Парное соревнование"
+		}
+	}
+
+	text := fmt.Sprintf("✅ %s создано как черновик!\n\n📅 Название: %s\n🗓️ Дата: %s–%s\n👥 Мест: %d\n👨‍🏫 Тренер: %s\n🔑 ID: %s\n\nЧерновик видят только администраторы. Опубликуйте событие, чтобы открыть запись и отправить анонс в канал.",
+		typeName, evt.Name, evt.Date.Format("02.01.2006 15:04"), formatEndTime(evt), evt.MaxPlayers, evt.Trainer, string(evt.ID))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📢 Опубликовать", fmt.Sprintf("admin:publish:%s", string(evt.ID))),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send event created message", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminStartCreateLocation начинает процесс создания локации
+func (h *Handlers) handleAdminStartCreateLocation(ctx context.Context, cb *CallbackQuery) {
+	// Создаем состояние для создания локации
+	h.creatingLocations[cb.Message.ChatID] = &LocationCreationState{
+		Step: "name",
+	}
+
+	text := "📍 Создание новой локации\n\nВведите название локации:"
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for location name prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
 
 // handleAdminCreateLocationStep обрабатывает шаги создания локации
 func (h *Handlers) handleAdminCreateLocationStep(ctx context.Context, msg *Message, state *LocationCreationState) {
@@ -628,6 +1355,19 @@ func (h *Handlers) handleAdminCreateEvent(ctx context.Context, cb *CallbackQuery
 		return
 	}
 
+	// Показываем только локации, в которых пользователь может создавать события
+	grants, err := h.roleService.Grants(ctx, cb.From.ID)
+	if err != nil {
+		h.logger.Error("failed to get user roles", "user_id", cb.From.ID, "error", err)
+	}
+	available := make([]location.Location, 0, len(locations))
+	for _, loc := range locations {
+		if role.Allows(grants, role.PermManageEvents, role.AtLocation(loc.ID)) {
+			available = append(available, loc)
+		}
+	}
+	locations = available
+
 	if len(locations) == 0 {
 		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Нет доступных локаций. Сначала создайте локацию."); err != nil {
 			h.logger.Error("failed to send no locations message", "chat_id", cb.Message.ChatID, "error", err)
@@ -636,12 +1376,18 @@ func (h *Handlers) handleAdminCreateEvent(ctx context.Context, cb *CallbackQuery
 	}
 
 	text := "📅 Выберите локацию для тренировки:"
+	prefix := "admin:create_event:loc:"
+	if cb.Data == "admin:create_series" {
+		text = "🔁 Выберите локацию для серии занятий:"
+		prefix = "admin:create_series:loc:"
+	}
+
 	var rows [][]InlineKeyboardButton
 	for _, loc := range locations {
 		rows = append(rows, NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData(
 				loc.Name,
-				fmt.Sprintf("admin:create_event:loc:%s", string(loc.ID)),
+				prefix+string(loc.ID),
 			),
 		))
 	}
@@ -665,12 +1411,13 @@ func (h *Handlers) handleAdminListEvents(ctx context.Context, cb *CallbackQuery,
 		}
 		return
 	}
+	allEvents = h.visibleEvents(ctx, cb.From.ID, allEvents, role.PermView)
 
-	// Фильтруем по типу
+	// Фильтруем по типу (отмененные и завершенные не показываем)
 	var filteredEvents []event.Event
 	locationIDs := make(map[location.LocationID]bool)
 	for _, evt := range allEvents {
-		if evt.Type == eventType {
+		if evt.Type == eventType && evt.Status.IsActive() {
 			filteredEvents = append(filteredEvents, evt)
 			locationIDs[evt.LocationID] = true
 		}
@@ -702,6 +1449,15 @@ func (h *Handlers) handleAdminListAllEvents(ctx context.Context, cb *CallbackQue
 		return
 	}
 
+	// Отмененные и завершенные события не показываем
+	activeEvents := make([]event.Event, 0, len(allEvents))
+	for _, evt := range h.visibleEvents(ctx, cb.From.ID, allEvents, role.PermView) {
+		if evt.Status.IsActive() {
+			activeEvents = append(activeEvents, evt)
+		}
+	}
+	allEvents = activeEvents
+
 	// Собираем уникальные LocationID
 	locationIDs := make(map[location.LocationID]bool)
 	for _, evt := range allEvents {
@@ -742,7 +1498,7 @@ func (h *Handlers) handleAdminEventDetails(ctx context.Context, cb *CallbackQuer
 		return
 	}
 
-	text, keyboard := h.formatter.FormatEventDetails(*evt)
+	text, keyboard := h.formatter.FormatEventDetails(*evt, h.eventCourtNames(ctx, evt))
 	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 		h.logger.Error("failed to edit message with event details", "chat_id", cb.Message.ChatID, "error", err)
 	}
@@ -800,14 +1556,23 @@ func (h *Handlers) handleAdminEventModeration(ctx context.Context, cb *CallbackQ
 			userSurname = usr.Surname
 		}
 
+		// В парной категории заявка подается командой - показываем напарника
+		var partnerName string
+		if partnerID := evt.Partner(reg.UserID); partnerID != 0 {
+			if partner, err := h.userService.GetByTelegramID(ctx, partnerID); err == nil && partner != nil {
+				partnerName = partner.Name + " " + partner.Surname
+			}
+		}
+
 		registrationsWithUsers = append(registrationsWithUsers, RegistrationWithUser{
 			Registration: reg,
 			UserName:     userName,
 			UserSurname:  userSurname,
+			PartnerName:  partnerName,
 		})
 	}
 
-	text, keyboard := h.formatter.FormatPendingRegistrations(evt.Name, registrationsWithUsers)
+	text, keyboard := h.formatter.FormatPendingRegistrations(evt.Name, registrationsWithUsers, string(evt.ID))
 	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 		h.logger.Error("failed to edit message with pending registrations", "chat_id", cb.Message.ChatID, "error", err)
 	}
@@ -827,7 +1592,7 @@ func (h *Handlers) handleAdminModerationList(ctx context.Context, cb *CallbackQu
 	// Находим события с pending регистрациями
 	var eventsWithPending []event.Event
 	locationIDs := make(map[location.LocationID]bool)
-	for _, evt := range allEvents {
+	for _, evt := range h.visibleEvents(ctx, cb.From.ID, allEvents, role.PermModerate) {
 		pending, err := h.eventService.ListPendingRegistrations(ctx, evt.ID)
 		if err != nil {
 			continue
@@ -910,70 +1675,98 @@ func (h *Handlers) handleAdminModerationList(ctx context.Context, cb *CallbackQu
 func (h *Handlers) handleAdminRegistrationModeration(ctx context.Context, cb *CallbackQuery) {
 	parts := strings.Split(cb.Data, ":")
 
-	// Формат: admin:reg:{userID} - показать детали регистрации
-	if len(parts) == 3 && parts[1] == "reg" {
-		userIDStr := parts[2]
+	// Формат: admin:reg:{eventID}:{userID} - показать детали регистрации
+	if len(parts) == 4 && parts[1] == "reg" {
+		eventID := event.EventID(parts[2])
 		var userID int64
-		fmt.Sscanf(userIDStr, "%d", &userID)
+		fmt.Sscanf(parts[3], "%d", &userID)
 
-		// Находим событие с этой регистрацией
-		allEvents, err := h.eventService.List(ctx)
+		evt, err := h.eventService.Get(ctx, eventID)
 		if err != nil {
-			h.logger.Error("failed to list events", "chat_id", cb.Message.ChatID, "error", err)
+			h.logger.Error("failed to get event", "event_id", string(eventID), "chat_id", cb.Message.ChatID, "error", err)
 			return
 		}
 
-		for _, evt := range allEvents {
-			if reg, exists := evt.Registrations[userID]; exists && reg.Status == event.RegistrationStatusPending {
-				// Получаем данные пользователя для отображения имени и фамилии
-				usr, err := h.userService.GetByTelegramID(ctx, userID)
-				if err != nil {
-					h.logger.Error("failed to get user", "user_id", userID, "error", err)
-				}
+		reg, exists := evt.Registrations[userID]
+		if !exists || reg.Status != event.RegistrationStatusPending {
+			return
+		}
+		// Получаем данные пользователя для отображения имени и фамилии
+		usr, err := h.userService.GetByTelegramID(ctx, userID)
+		if err != nil {
+			h.logger.Error("failed to get user", "user_id", userID, "error", err)
+		}
 
-				var userName, userSurname string
-				if usr != nil {
-					userName = usr.Name
-					userSurname = usr.Surname
-				}
+		var userName, userSurname string
+		var level user.Level
+		if usr != nil {
+			userName = usr.Name
+			userSurname = usr.Surname
+			level = usr.Level
+		}
 
-				text, keyboard := h.formatter.FormatRegistrationModeration(evt.Name, userID, userName, userSurname, string(evt.ID))
-				if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
-					h.logger.Error("failed to edit message with registration moderation", "chat_id", cb.Message.ChatID, "error", err)
-				}
-				return
-			}
+		text, keyboard := h.formatter.FormatRegistrationModeration(evt.Name, userID, userName, userSurname, level, string(evt.ID))
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with registration moderation", "chat_id", cb.Message.ChatID, "error", err)
 		}
 		return
 	}
 
-	// Формат: admin:reg:approve:{eventID}:{userID} или admin:reg:reject:{eventID}:{userID}
-	if len(parts) == 5 && (parts[2] == "approve" || parts[2] == "reject") {
+	// Формат: admin:reg:{approve|paid|reject}:{eventID}:{userID}; paid - подтверждение с отметкой об оплате переводом
+	if len(parts) == 5 && (parts[2] == "approve" || parts[2] == "paid" || parts[2] == "reject") {
 		eventID := event.EventID(parts[3])
 		var userID int64
 		fmt.Sscanf(parts[4], "%d", &userID)
 
-		if parts[2] == "approve" {
+		if parts[2] == "approve" || parts[2] == "paid" {
 			// Получаем данные пользователя для вывода имени и фамилии
 			usr, err := h.userService.GetByTelegramID(ctx, userID)
 			if err != nil {
 				h.logger.Error("failed to get user", "user_id", userID, "error", err)
 			}
 
-			err = h.eventService.ApproveRegistration(ctx, eventID, userID)
+			err = retryOnConflict(func() error {
+				return h.eventService.ApproveRegistration(ctx, eventID, userID)
+			})
 			if err != nil {
 				h.logger.Error("failed to approve registration", "event_id", string(eventID), "user_id", userID, "error", err)
-				if sendErr := h.client.SendMessage(cb.Message.ChatID, fmt.Sprintf("❌ Ошибка подтверждения: %v", err)); sendErr != nil {
+				errorMsg := fmt.Sprintf("❌ Ошибка подтверждения: %v", err)
+				if err == event.ErrEventFull {
+					errorMsg = "❌ Нет свободных мест, подтвердить заявку нельзя"
+				} else if errors.Is(err, event.ErrTeamIncomplete) {
+					errorMsg = "❌ Напарник еще не принял приглашение в команду"
+				} else if errors.Is(err, event.ErrRegistrationLateCancelled) {
+					errorMsg = "❌ Игрок отменил запись после срока бесплатной отмены, подтвердить ее нельзя"
+				}
+				if sendErr := h.client.SendMessage(cb.Message.ChatID, errorMsg); sendErr != nil {
 					h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
 				}
 				return
 			}
 
+			h.notifyTeamModerated(ctx, eventID, userID, true)
+
 			// Формируем сообщение с именем и фамилией пользователя
 			message := "✅ Регистрация подтверждена"
 			if usr != nil {
 				message = fmt.Sprintf("✅ Регистрация подтверждена\n\n👤 Пользователь: %s %s", usr.Name, usr.Surname)
 			}
+			// Подтвержденное участие оплачивается абонементом, если он подходит; при подтверждении
+			// с отметкой об оплате переводом абонемент не списывается, чтобы участие не оплачивалось дважды
+			if evt, err := h.eventService.Get(ctx, eventID); err == nil && evt != nil {
+				for _, memberID := range evt.TeamMembers(userID) {
+					if parts[2] == "paid" {
+						if h.recordModerationPayment(ctx, cb.Message.ChatID, cb.From.ID, evt, memberID) {
+							message += "\n💳 Оплата переводом отмечена"
+						}
+						continue
+					}
+					if p := h.chargePass(ctx, evt, memberID); p != nil {
+						h.notifyPassCharged(evt, memberID, p)
+						message += "\n🎟 Оплачено абонементом"
+					}
+				}
+			}
 
 			adminMenuKeyboard := NewInlineKeyboardMarkup(
 				NewInlineKeyboardRow(
@@ -984,7 +1777,11 @@ func (h *Handlers) handleAdminRegistrationModeration(ctx context.Context, cb *Ca
 				h.logger.Error("failed to send success message", "chat_id", cb.Message.ChatID, "error", err)
 			}
 		} else {
-			err := h.eventService.RejectRegistration(ctx, eventID, userID)
+			var promoted []event.EventRegistration
+			err := retryOnConflict(func() (err error) {
+				promoted, err = h.eventService.RejectRegistration(ctx, eventID, userID)
+				return err
+			})
 			if err != nil {
 				h.logger.Error("failed to reject registration", "event_id", string(eventID), "user_id", userID, "error", err)
 				if sendErr := h.client.SendMessage(cb.Message.ChatID, fmt.Sprintf("❌ Ошибка отклонения: %v", err)); sendErr != nil {
@@ -992,6 +1789,19 @@ func (h *Handlers) handleAdminRegistrationModeration(ctx context.Context, cb *Ca
 				}
 				return
 			}
+			h.notifyTeamModerated(ctx, eventID, userID, false)
+
+			// Отклоненная заявка не должна расходовать абонемент и промокод
+			if evt, err := h.eventService.Get(ctx, eventID); err == nil && evt != nil {
+				for _, memberID := range evt.TeamMembers(userID) {
+					if p := h.returnPass(ctx, evt, memberID); p != nil {
+						h.notifyPassReturned(evt, memberID, p)
+					}
+					h.releasePromo(ctx, eventID, memberID)
+				}
+			}
+			h.notifyWaitlistPromoted(ctx, eventID, promoted)
+
 			adminMenuKeyboard := NewInlineKeyboardMarkup(
 				NewInlineKeyboardRow(
 					NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
@@ -1021,14 +1831,23 @@ func (h *Handlers) handleAdminRegistrationModeration(ctx context.Context, cb *Ca
 					userSurname = usr.Surname
 				}
 
+				// В парной категории заявка подается командой - показываем напарника
+				var partnerName string
+				if partnerID := evt.Partner(reg.UserID); partnerID != 0 {
+					if partner, err := h.userService.GetByTelegramID(ctx, partnerID); err == nil && partner != nil {
+						partnerName = partner.Name + " " + partner.Surname
+					}
+				}
+
 				registrationsWithUsers = append(registrationsWithUsers, RegistrationWithUser{
 					Registration: reg,
 					UserName:     userName,
 					UserSurname:  userSurname,
+					PartnerName:  partnerName,
 				})
 			}
 
-			text, keyboard := h.formatter.FormatPendingRegistrations(evt.Name, registrationsWithUsers)
+			text, keyboard := h.formatter.FormatPendingRegistrations(evt.Name, registrationsWithUsers, string(evt.ID))
 			if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 				h.logger.Error("failed to edit message with pending registrations", "chat_id", cb.Message.ChatID, "error", err)
 			}
@@ -1037,8 +1856,39 @@ func (h *Handlers) handleAdminRegistrationModeration(ctx context.Context, cb *Ca
 	}
 }
 
+// notifyTeamModerated сообщает игроку (в парной категории - обоим игрокам команды) решение по заявке
+func (h *Handlers) notifyTeamModerated(ctx context.Context, eventID event.EventID, userID int64, approved bool) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for moderation notification", "event_id", string(eventID), "error", err)
+		return
+	}
+
+	title := "✅ Регистрация подтверждена"
+	if !approved {
+		title = "❌ Заявка отклонена администратором"
+	}
+	if evt.Doubles {
+		title += " (для всей команды)"
+	}
+	text := fmt.Sprintf("%s\n\n📅 %s\n🗓️ %s", title, evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 Открыть событие", fmt.Sprintf("event:%s", string(evt.ID))),
+		),
+	)
+	for _, memberID := range evt.TeamMembers(userID) {
+		if err := h.client.SendMessageWithKeyboard(memberID, text, keyboard); err != nil {
+			h.logger.Error("failed to notify player about moderation", "user_id", memberID, "event_id", string(eventID), "error", err)
+		}
+	}
+}
+
 // publishEventToChannel публикует анонс события во все настроенные каналы
 func (h *Handlers) publishEventToChannel(ctx context.Context, evt *event.Event) {
+	// Подписчикам сообщаем в фоне: рассылка ограничена по частоте и может занять время
+	h.enqueueSubscriberNotice(evt.ID)
+
 	channelIDs, err := h.settingsService.GetChannelIDs(ctx)
 	if err != nil || len(channelIDs) == 0 {
 		return
@@ -1051,16 +1901,48 @@ func (h *Handlers) publishEventToChannel(ctx context.Context, evt *event.Event)
 
 	text, keyboard := h.formatter.FormatChannelEventAnnouncement(evt, locationName, h.client.Username())
 	for _, channelID := range channelIDs {
-		if err := h.client.SendMessageWithKeyboard(channelID, text, keyboard); err != nil {
+		messageID, err := h.client.SendMessageWithKeyboardGetID(channelID, text, keyboard)
+		if err != nil {
 			h.logger.Error("failed to publish event to channel", "channel_id", channelID, "event_id", string(evt.ID), "error", err)
+			continue
+		}
+
+		// Запоминаем сообщение, чтобы обновлять анонс при изменении события
+		announcement := event.Announcement{EventID: evt.ID, ChatID: channelID, MessageID: messageID}
+		if err := h.eventService.AddAnnouncement(ctx, announcement); err != nil {
+			h.logger.Error("failed to save event announcement", "channel_id", channelID, "event_id", string(evt.ID), "error", err)
 		}
 	}
 }
 
-// publishEventCancelledToChannel публикует уведомление об отмене события во все каналы
-func (h *Handlers) publishEventCancelledToChannel(ctx context.Context, evt *event.Event) {
-	channelIDs, err := h.settingsService.GetChannelIDs(ctx)
-	if err != nil || len(channelIDs) == 0 {
+// updateEventAnnouncements обновляет опубликованные в каналах анонсы события
+func (h *Handlers) updateEventAnnouncements(ctx context.Context, evt *event.Event) {
+	announcements, err := h.eventService.ListAnnouncements(ctx, evt.ID)
+	if err != nil {
+		h.logger.Error("failed to list event announcements", "event_id", string(evt.ID), "error", err)
+		return
+	}
+	if len(announcements) == 0 {
+		return
+	}
+
+	var locationName string
+	if loc, err := h.locationService.Get(ctx, evt.LocationID); err == nil && loc != nil {
+		locationName = loc.Name
+	}
+
+	text, keyboard := h.formatter.FormatChannelEventAnnouncement(evt, locationName, h.client.Username())
+	for _, a := range announcements {
+		if err := h.client.EditMessageHTMLAndMarkup(a.ChatID, a.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to update event announcement", "channel_id", a.ChatID, "message_id", a.MessageID, "event_id", string(evt.ID), "error", err)
+		}
+	}
+}
+
+// publishEventCancelledToChannel публикует уведомление об отмене события во все каналы
+func (h *Handlers) publishEventCancelledToChannel(ctx context.Context, evt *event.Event) {
+	channelIDs, err := h.settingsService.GetChannelIDs(ctx)
+	if err != nil || len(channelIDs) == 0 {
 		return
 	}
 
@@ -1137,7 +2019,231 @@ func (h *Handlers) handleSetChannelInput(ctx context.Context, msg *Message) {
 	}
 }
 
-// handleAdminDeleteEventList показывает список событий для удаления
+// handleAdminPendingTimeoutStart показывает текущие настройки брони и ждет ввода новых
+func (h *Handlers) handleAdminPendingTimeoutStart(ctx context.Context, cb *CallbackQuery) {
+	timeout, err := h.settingsService.GetPendingTimeout(ctx)
+	if err != nil {
+		h.logger.Error("failed to get pending timeout", "error", err)
+	}
+	lead, err := h.settingsService.GetPaymentReminderLead(ctx)
+	if err != nil {
+		h.logger.Error("failed to get payment reminder lead", "error", err)
+	}
+
+	h.settingPendingTimeout[cb.Message.ChatID] = true
+	text := fmt.Sprintf("⏱ Бронь без оплаты\n\n"+
+		"Сейчас: бронь снимается через %d мин, напоминание — за %d мин до снятия.\n\n"+
+		"Отправьте два числа через пробел: время брони и время напоминания в минутах.\n"+
+		"Например: 30 10\n\n"+
+		"0 в качестве времени брони отключает автоматическое снятие.\n"+
+		"Для отмены отправьте /cancel",
+		int(timeout/time.Minute), int(lead/time.Minute))
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for pending timeout setup", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleSetPendingTimeoutInput обрабатывает ввод времени брони и напоминания
+func (h *Handlers) handleSetPendingTimeoutInput(ctx context.Context, msg *Message) {
+	delete(h.settingPendingTimeout, msg.ChatID)
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+
+	if msg.Text == "/cancel" {
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send admin menu", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	fields := strings.Fields(msg.Text)
+	var values []int
+	for _, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil || v < 0 {
+			values = nil
+			break
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 || len(values) > 2 {
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, "❌ Некорректный ввод. Ожидалось одно или два неотрицательных числа, например: 30 10", keyboard); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	if err := h.settingsService.SetPendingTimeout(ctx, time.Duration(values[0])*time.Minute); err != nil {
+		h.logger.Error("failed to save pending timeout", "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка сохранения настройки"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if len(values) == 2 {
+		if err := h.settingsService.SetPaymentReminderLead(ctx, time.Duration(values[1])*time.Minute); err != nil {
+			h.logger.Error("failed to save payment reminder lead", "error", err)
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка сохранения настройки"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+	}
+
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, "✅ Настройки брони сохранены", keyboard); err != nil {
+		h.logger.Error("failed to send success message", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEventRemindersStart показывает текущие напоминания о событиях и ждет ввода новых
+func (h *Handlers) handleAdminEventRemindersStart(ctx context.Context, cb *CallbackQuery) {
+	offsets, err := h.settingsService.GetEventReminderOffsets(ctx)
+	if err != nil {
+		h.logger.Error("failed to get event reminder offsets", "error", err)
+	}
+
+	current := "отключены"
+	if len(offsets) > 0 {
+		names := make([]string, 0, len(offsets))
+		for _, offset := range offsets {
+			names = append(names, formatDuration(offset))
+		}
+		current = "за " + strings.Join(names, ", ")
+	}
+
+	h.settingEventReminders[cb.Message.ChatID] = true
+	text := fmt.Sprintf("🔔 Напоминания о событиях\n\n"+
+		"Сейчас: %s до начала.\n\n"+
+		"Отправьте интервалы через пробел, например: 24h 2h\n"+
+		"Чтобы отключить напоминания, отправьте \"-\".\n"+
+		"Для отмены отправьте /cancel", current)
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for event reminders setup", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleSetEventRemindersInput обрабатывает ввод интервалов напоминаний о событиях
+func (h *Handlers) handleSetEventRemindersInput(ctx context.Context, msg *Message) {
+	delete(h.settingEventReminders, msg.ChatID)
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+
+	if msg.Text == "/cancel" {
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send admin menu", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	var offsets []time.Duration
+	if strings.TrimSpace(msg.Text) != "-" {
+		var err error
+		offsets, err = reminder.ParseOffsets(msg.Text)
+		if err != nil || len(offsets) == 0 {
+			if err := h.client.SendMessageWithKeyboard(msg.ChatID, "❌ Некорректный ввод. Ожидались интервалы вида 24h 2h 30m", keyboard); err != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+			}
+			return
+		}
+	}
+
+	if err := h.settingsService.SetEventReminderOffsets(ctx, offsets); err != nil {
+		h.logger.Error("failed to save event reminder offsets", "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка сохранения настройки"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, "✅ Напоминания о событиях сохранены", keyboard); err != nil {
+		h.logger.Error("failed to send success message", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminPublishEvent публикует черновик и отправляет анонс в канал (формат: admin:publish:{eventID})
+func (h *Handlers) handleAdminPublishEvent(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid publish event callback data", "callback_data", cb.Data)
+		return
+	}
+
+	eventID := event.EventID(parts[2])
+	var evt *event.Event
+	err := retryOnConflict(func() (err error) {
+		evt, err = h.eventService.Publish(ctx, eventID)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to publish event", "event_id", string(eventID), "error", err)
+		errorMsg := fmt.Sprintf("❌ Ошибка публикации: %v", err)
+		if err == event.ErrInvalidStatusTransition {
+			errorMsg = "❌ Опубликовать можно только черновик"
+		}
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	// Публикуем анонс в канал (если настроен)
+	h.publishEventToChannel(ctx, evt)
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 К событию", fmt.Sprintf("admin:event:%s", string(evt.ID))),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+	text := fmt.Sprintf("📢 Событие «%s» опубликовано, запись открыта", evt.Name)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message after event publish", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminCancelEventConfirm спрашивает подтверждение отмены события (формат: admin:cancel_event:{eventID})
+func (h *Handlers) handleAdminCancelEventConfirm(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid cancel event callback data", "callback_data", cb.Data)
+		return
+	}
+
+	evt, err := h.eventService.Get(ctx, event.EventID(parts[2]))
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := fmt.Sprintf("🚫 Отменить событие «%s» (%s)?\n\nВсе записавшиеся игроки получат уведомление.", evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✅ Да, отменить", fmt.Sprintf("admin:delete_event:confirm:%s", string(evt.ID))),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:event:%s", string(evt.ID))),
+		),
+	)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with cancel confirmation", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminDeleteEventList показывает список событий для отмены
 func (h *Handlers) handleAdminDeleteEventList(ctx context.Context, cb *CallbackQuery) {
 	events, err := h.eventService.List(ctx)
 	if err != nil {
@@ -1148,13 +2254,22 @@ func (h *Handlers) handleAdminDeleteEventList(ctx context.Context, cb *CallbackQ
 		return
 	}
 
+	// Отменить можно только черновик или опубликованное событие
+	var active []event.Event
+	for _, evt := range h.visibleEvents(ctx, cb.From.ID, events, role.PermManageEvents) {
+		if evt.Status.IsActive() {
+			active = append(active, evt)
+		}
+	}
+	events = active
+
 	if len(events) == 0 {
 		keyboard := NewInlineKeyboardMarkup(
 			NewInlineKeyboardRow(
 				NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
 			),
 		)
-		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, "📋 Нет событий для удаления", keyboard); err != nil {
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, "📋 Нет событий для отмены", keyboard); err != nil {
 			h.logger.Error("failed to edit message", "chat_id", cb.Message.ChatID, "error", err)
 		}
 		return
@@ -1162,7 +2277,7 @@ func (h *Handlers) handleAdminDeleteEventList(ctx context.Context, cb *CallbackQ
 
 	var rows [][]InlineKeyboardButton
 	for _, evt := range events {
-		label := fmt.Sprintf("🗑️ %s | %s", evt.Name, evt.Date.Format("02.01.2006 15:04"))
+		label := fmt.Sprintf("🚫 %s | %s", evt.Name, evt.Date.Format("02.01.2006 15:04"))
 		if len(label) > 60 {
 			label = label[:57] + "..."
 		}
@@ -1175,12 +2290,12 @@ func (h *Handlers) handleAdminDeleteEventList(ctx context.Context, cb *CallbackQ
 	))
 
 	keyboard := NewInlineKeyboardMarkup(rows...)
-	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, "🗑️ Выберите событие для удаления:", keyboard); err != nil {
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, "🚫 Выберите событие для отмены:", keyboard); err != nil {
 		h.logger.Error("failed to edit message with event deletion list", "chat_id", cb.Message.ChatID, "error", err)
 	}
 }
 
-// handleAdminConfirmDeleteEvent удаляет событие и уведомляет канал
+// handleAdminConfirmDeleteEvent отменяет событие (регистрации сохраняются) и уведомляет игроков и канал
 func (h *Handlers) handleAdminConfirmDeleteEvent(ctx context.Context, cb *CallbackQuery) {
 	parts := strings.Split(cb.Data, ":")
 	if len(parts) != 4 {
@@ -1189,32 +2304,2420 @@ func (h *Handlers) handleAdminConfirmDeleteEvent(ctx context.Context, cb *Callba
 	}
 
 	eventID := event.EventID(parts[3])
-	evt, err := h.eventService.Get(ctx, eventID)
-	if err != nil || evt == nil {
-		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+	var evt *event.Event
+	err := retryOnConflict(func() (err error) {
+		evt, err = h.eventService.Cancel(ctx, eventID)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to cancel event", "event_id", string(eventID), "error", err)
+		errorMsg := fmt.Sprintf("❌ Ошибка отмены: %v", err)
+		if err == event.ErrEventNotFound {
+			errorMsg = "❌ Событие не найдено"
+		} else if err == event.ErrInvalidStatusTransition {
+			errorMsg = "❌ Событие уже отменено или завершено"
+		}
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, errorMsg); sendErr != nil {
 			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
 		}
 		return
 	}
 
-	if err := h.eventService.Delete(ctx, eventID); err != nil {
-		h.logger.Error("failed to delete event", "event_id", string(eventID), "error", err)
-		if sendErr := h.client.SendMessage(cb.Message.ChatID, fmt.Sprintf("❌ Ошибка удаления: %v", err)); sendErr != nil {
-			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+	// Уведомляем записавшихся игроков и канал об отмене
+	h.notifyEventCancelled(ctx, evt)
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID,
+		fmt.Sprintf("✅ Событие «%s» отменено, игроки уведомлены", evt.Name), keyboard); err != nil {
+		h.logger.Error("failed to edit message after event cancellation", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterSeriesWeekdays обрабатывает ввод дней недели серии
+func (h *Handlers) handleAdminEnterSeriesWeekdays(ctx context.Context, msg *Message, state *EventCreationState) {
+	weekdays, err := parseWeekdays(msg.Text)
+	if err != nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Не удалось распознать дни недели. Введите через запятую, например: вт, чт"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
 		}
 		return
 	}
 
-	// Уведомляем канал об отмене
-	h.publishEventCancelledToChannel(ctx, evt)
+	state.Weekdays = weekdays
+	state.Step = "time"
+
+	text := fmt.Sprintf("📆 Дни: %s\n\nВведите время начала занятий в формате ЧЧ:ММ:\n\nПример: 18:00", formatWeekdays(weekdays))
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send series time prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterSeriesTime обрабатывает ввод времени начала занятий серии
+func (h *Handlers) handleAdminEnterSeriesTime(ctx context.Context, msg *Message, state *EventCreationState) {
+	startTime, err := parseTimeOfDay(msg.Text)
+	if err != nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Неверный формат времени. Используйте ЧЧ:ММ, например: 18:00"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	state.StartTime = startTime
+	state.Step = "duration"
+
+	text := fmt.Sprintf("🕒 Время: %02d:%02d\n\n%s", startTime.Hour, startTime.Minute, durationPrompt)
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send duration prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterSeriesStartDate обрабатывает ввод даты начала серии
+func (h *Handlers) handleAdminEnterSeriesStartDate(ctx context.Context, msg *Message, state *EventCreationState) {
+	input := strings.TrimSpace(msg.Text)
+
+	now := time.Now()
+	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if input != "-" {
+		parsed, err := time.Parse("02.01.2006", input)
+		if err != nil {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Неверный формат даты. Используйте ДД.ММ.ГГГГ, например: 15.01.2026"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		if parsed.Before(startDate) {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Дата начала не может быть в прошлом. Введите корректную дату:"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		startDate = parsed
+	}
+
+	state.StartDate = startDate
+	state.Step = "series_end"
+
+	text := fmt.Sprintf("🗓️ Начало: %s\n\nКогда закончить серию?\n"+
+		"• дата последнего занятия в формате ДД.ММ.ГГГГ\n"+
+		"• или количество занятий (число)\n"+
+		"• или \"-\" — без ограничения", startDate.Format("02.01.2006"))
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send series end prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterSeriesEnd обрабатывает ввод окончания серии (дата или количество занятий)
+func (h *Handlers) handleAdminEnterSeriesEnd(ctx context.Context, msg *Message, state *EventCreationState) {
+	input := strings.TrimSpace(msg.Text)
+
+	var endText string
+	switch {
+	case input == "-":
+		endText = "без ограничения"
+	default:
+		if count, err := strconv.Atoi(input); err == nil {
+			if count <= 0 {
+				if sendErr := h.client.SendMessage(msg.ChatID, "❌ Количество занятий должно быть положительным:"); sendErr != nil {
+					h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+				}
+				return
+			}
+			state.Count = count
+			endText = fmt.Sprintf("%d занятий", count)
+			break
+		}
+
+		until, err := time.Parse("02.01.2006", input)
+		if err != nil || until.Before(state.StartDate) {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите дату не раньше начала серии (ДД.ММ.ГГГГ), количество занятий или \"-\":"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		state.Until = until
+		endText = "до " + until.Format("02.01.2006")
+	}
+
+	state.Step = "weeks_ahead"
+
+	text := fmt.Sprintf("🏁 Окончание: %s\n\nНа сколько недель вперед создавать занятия? Отправьте число или \"-\" (по умолчанию %d):", endText, series.DefaultWeeksAhead)
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send series weeks ahead prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminEnterSeriesWeeksAhead обрабатывает ввод горизонта создания занятий серии
+func (h *Handlers) handleAdminEnterSeriesWeeksAhead(ctx context.Context, msg *Message, state *EventCreationState) {
+	input := strings.TrimSpace(msg.Text)
+
+	weeks := series.DefaultWeeksAhead
+	if input != "-" {
+		v, err := strconv.Atoi(input)
+		if err != nil || v <= 0 || v > 12 {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите число недель от 1 до 12 или \"-\":"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		weeks = v
+	}
+
+	state.WeeksAhead = weeks
+	state.Step = "trainer"
+
+	text := fmt.Sprintf("📆 Занятия создаются на %d нед. вперед\n\nВведите имя тренера:", weeks)
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send trainer prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// createSeries создает серию по данным мастера и публикует созданные занятия
+func (h *Handlers) createSeries(ctx context.Context, msg *Message, state *EventCreationState) {
+	s, created, err := h.seriesService.Create(ctx, series.CreateSeriesInput{
+		Name:           state.EventName,
+		Type:           state.EventType,
+		MaxPlayers:     state.MaxPlayers,
+		LocationID:     state.LocationID,
+		Trainer:        state.Trainer,
+		PaymentMode:    state.PaymentMode,
+		PaymentPhone:   state.PaymentPhone,
+		Price:          state.Price,
+		PendingTimeout: state.PendingTimeout,
+		MinLevel:       state.MinLevel,
+		MaxLevel:       state.MaxLevel,
+		Doubles:        state.Doubles,
+		Duration:       state.Duration,
+		CreatedBy:      msg.From.ID,
+		Recurrence: series.Recurrence{
+			Weekdays:  state.Weekdays,
+			Time:      state.StartTime,
+			StartDate: state.StartDate,
+			Until:     state.Until,
+			Count:     state.Count,
+		},
+		WeeksAhead: state.WeeksAhead,
+	})
+	if err != nil && s == nil {
+		h.logger.Error("failed to create series", "series_name", state.EventName, "location_id", string(state.LocationID), "chat_id", msg.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, fmt.Sprintf("❌ Ошибка создания серии: %v", err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if err != nil {
+		// Серия сохранена, недостающие занятия будут созданы фоновой задачей
+		h.logger.Error("failed to generate series events", "series_id", string(s.ID), "error", err)
+	}
 
+	text := fmt.Sprintf("✅ Серия создана!\n\n📅 Название: %s\n📆 %s в %02d:%02d\n👥 Мест: %d\n👨‍🏫 Тренер: %s\n\nСоздано занятий: %d",
+		s.Name, formatWeekdays(s.Recurrence.Weekdays), s.Recurrence.Time.Hour, s.Recurrence.Time.Minute, s.MaxPlayers, s.Trainer, len(created))
 	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔁 Серии", "admin:series"),
+		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
 		),
 	)
-	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID,
-		fmt.Sprintf("✅ Событие «%s» удалено", evt.Name), keyboard); err != nil {
-		h.logger.Error("failed to edit message after event deletion", "chat_id", cb.Message.ChatID, "error", err)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send series created message", "chat_id", msg.ChatID, "error", err)
+	}
+
+	for i := range created {
+		h.publishEventToChannel(ctx, &created[i])
+	}
+}
+
+// handleAdminSeriesList показывает список серий
+func (h *Handlers) handleAdminSeriesList(ctx context.Context, cb *CallbackQuery) {
+	list, err := h.seriesService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list series", "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка серий"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	grants, err := h.roleService.Grants(ctx, cb.From.ID)
+	if err != nil {
+		h.logger.Error("failed to get user roles", "user_id", cb.From.ID, "error", err)
+	}
+	visible := make([]series.EventSeries, 0, len(list))
+	for i := range list {
+		if role.Allows(grants, role.PermManageEvents, role.ForSeries(&list[i])) {
+			visible = append(visible, list[i])
+		}
+	}
+
+	text, keyboard := h.formatter.FormatSeriesList(visible)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with series list", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminSeriesDetails показывает детали серии (формат: admin:series:{seriesID})
+func (h *Handlers) handleAdminSeriesDetails(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid series callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	s, err := h.seriesService.Get(ctx, series.SeriesID(parts[2]))
+	if err != nil || s == nil {
+		h.logger.Error("failed to get series", "series_id", parts[2], "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Серия не найдена"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	events, err := h.eventService.ListBySeries(ctx, string(s.ID))
+	if err != nil {
+		h.logger.Error("failed to list series events", "series_id", string(s.ID), "error", err)
+	}
+
+	text, keyboard := h.formatter.FormatSeriesDetails(s, events)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with series details", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminStopSeries завершает серию и отменяет будущие занятия (формат: admin:series:stop:{seriesID})
+func (h *Handlers) handleAdminStopSeries(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid stop series callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	cancelled, err := h.seriesService.Stop(ctx, series.SeriesID(parts[3]), time.Now())
+	if err != nil {
+		h.logger.Error("failed to stop series", "series_id", parts[3], "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, fmt.Sprintf("❌ Ошибка остановки серии: %v", err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+	}
+
+	for i := range cancelled {
+		h.notifyEventCancelled(ctx, &cancelled[i])
+	}
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 К сериям", "admin:series"),
+		),
+	)
+	text := fmt.Sprintf("✅ Серия остановлена, отменено занятий: %d", len(cancelled))
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message after series stop", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminCancelOccurrence отменяет занятие серии.
+// Формат: admin:occ:cancel:{eventID} (выбор области) или admin:occ:cancel:{one|future}:{eventID}
+func (h *Handlers) handleAdminCancelOccurrence(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	switch len(parts) {
+	case 4:
+		eventID := parts[3]
+		text := "🚫 Отмена занятия серии\n\nК каким занятиям применить отмену?"
+		keyboard := NewInlineKeyboardMarkup(
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("Только это занятие", fmt.Sprintf("admin:occ:cancel:%s:%s", series.ScopeOccurrence, eventID)),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("Это и все последующие", fmt.Sprintf("admin:occ:cancel:%s:%s", series.ScopeFuture, eventID)),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:event:%s", eventID)),
+			),
+		)
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with cancel scope selection", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	case 5:
+	default:
+		h.logger.Warn("invalid cancel occurrence callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	scope := series.Scope(parts[3])
+	eventID := event.EventID(parts[4])
+
+	cancelled, err := h.seriesService.CancelOccurrences(ctx, eventID, scope)
+	if err != nil {
+		h.logger.Error("failed to cancel series occurrences", "event_id", string(eventID), "scope", string(scope), "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, fmt.Sprintf("❌ Ошибка отмены: %v", err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+	}
+
+	for i := range cancelled {
+		h.notifyEventCancelled(ctx, &cancelled[i])
+	}
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+	text := fmt.Sprintf("✅ Отменено занятий: %d", len(cancelled))
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message after occurrence cancel", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminEditOccurrence показывает выбор поля для изменения занятия серии (формат: admin:occ:edit:{eventID})
+func (h *Handlers) handleAdminEditOccurrence(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid edit occurrence callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	eventID := parts[3]
+
+	text := "✏️ Изменение занятия серии\n\nЧто изменить?"
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📝 Название", fmt.Sprintf("admin:occ:field:name:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🕒 Время начала", fmt.Sprintf("admin:occ:field:time:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("👥 Количество мест", fmt.Sprintf("admin:occ:field:max:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:event:%s", eventID)),
+		),
+	)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with occurrence fields", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminOccurrenceField запрашивает новое значение поля (формат: admin:occ:field:{field}:{eventID})
+func (h *Handlers) handleAdminOccurrenceField(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid occurrence field callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	var prompt string
+	switch parts[3] {
+	case "name":
+		prompt = "Введите новое название:"
+	case "time":
+		prompt = "Введите новое время начала в формате ЧЧ:ММ:"
+	case "max":
+		prompt = "Введите новое количество мест:"
+	default:
+		h.logger.Warn("invalid occurrence field", "field", parts[3], "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	h.editingOccurrences[cb.Message.ChatID] = &OccurrenceEditState{
+		EventID: event.EventID(parts[4]),
+		Field:   parts[3],
+	}
+
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, "✏️ "+prompt+"\n\nДля отмены отправьте /cancel"); err != nil {
+		h.logger.Error("failed to edit message for occurrence field prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminOccurrenceEditInput обрабатывает ввод нового значения и предлагает выбрать область изменения
+func (h *Handlers) handleAdminOccurrenceEditInput(ctx context.Context, msg *Message, state *OccurrenceEditState) {
+	input := strings.TrimSpace(msg.Text)
+
+	if input == "/cancel" {
+		delete(h.editingOccurrences, msg.ChatID)
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send admin menu", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	switch state.Field {
+	case "name":
+		if input == "" {
+			if err := h.client.SendMessage(msg.ChatID, "❌ Название не может быть пустым. Введите название:"); err != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+			}
+			return
+		}
+		state.Input = series.UpdateSeriesInput{Name: &input}
+	case "time":
+		t, err := parseTimeOfDay(input)
+		if err != nil {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Неверный формат времени. Используйте ЧЧ:ММ, например: 18:00"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		state.Input = series.UpdateSeriesInput{Time: &t}
+	case "max":
+		maxPlayers, err := strconv.Atoi(input)
+		if err != nil || maxPlayers <= 0 {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите корректное количество мест (положительное число):"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		state.Input = series.UpdateSeriesInput{MaxPlayers: &maxPlayers}
+	}
+
+	text := "✏️ К каким занятиям применить изменение?"
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("Только это занятие", fmt.Sprintf("admin:occ:apply:%s", series.ScopeOccurrence)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("Это и все последующие", fmt.Sprintf("admin:occ:apply:%s", series.ScopeFuture)),
+		),
+	)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send occurrence scope selection", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminApplyOccurrenceEdit применяет изменение занятий серии (формат: admin:occ:apply:{one|future})
+func (h *Handlers) handleAdminApplyOccurrenceEdit(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid apply occurrence callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	state := h.editingOccurrences[cb.Message.ChatID]
+	if state == nil {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.editingOccurrences, cb.Message.ChatID)
+
+	updated, err := h.seriesService.UpdateOccurrences(ctx, state.EventID, state.Input, series.Scope(parts[3]))
+	if err != nil {
+		h.logger.Error("failed to update series occurrences", "event_id", string(state.EventID), "scope", parts[3], "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, fmt.Sprintf("❌ Ошибка изменения: %v", err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	for i := range updated {
+		if state.Input.Time != nil {
+			h.notifyEventChanged(ctx, &updated[i], []string{
+				fmt.Sprintf("🗓️ Новое время: %s", updated[i].Date.Format("02.01.2006 15:04")),
+			})
+		}
+		h.updateEventAnnouncements(ctx, &updated[i])
+	}
+
+	// При увеличении количества мест освободившиеся места занимает лист ожидания
+	if state.Input.MaxPlayers != nil {
+		for _, evt := range updated {
+			var promoted []event.EventRegistration
+			err := retryOnConflict(func() (err error) {
+				promoted, err = h.eventService.PromoteWaitlist(ctx, evt.ID)
+				return err
+			})
+			if err != nil {
+				h.logger.Error("failed to promote waitlist", "event_id", string(evt.ID), "error", err)
+				continue
+			}
+			h.notifyWaitlistPromoted(ctx, evt.ID, promoted)
+		}
+	}
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 К занятию", fmt.Sprintf("admin:event:%s", string(state.EventID))),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+	text := fmt.Sprintf("✅ Изменено занятий: %d", len(updated))
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message after occurrence update", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// weekdayNames - короткие названия дней недели
+var weekdayNames = map[time.Weekday]string{
+	time.Monday:    "пн",
+	time.Tuesday:   "вт",
+	time.Wednesday: "ср",
+	time.Thursday:  "чт",
+	time.Friday:    "пт",
+	time.Saturday:  "сб",
+	time.Sunday:    "вс",
+}
+
+// parseWeekdays парсит список дней недели вида "вт, чт" или "2,4" (1 - понедельник, 7 - воскресенье)
+func parseWeekdays(input string) ([]time.Weekday, error) {
+	seen := make(map[time.Weekday]bool)
+	var weekdays []time.Weekday
+	for _, part := range strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return r == ',' || r == ' ' || r == ';'
+	}) {
+		wd, ok := time.Weekday(-1), false
+		if n, err := strconv.Atoi(part); err == nil && n >= 1 && n <= 7 {
+			wd, ok = time.Weekday(n%7), true
+		}
+		for day, name := range weekdayNames {
+			if part == name {
+				wd, ok = day, true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", part)
+		}
+		if !seen[wd] {
+			seen[wd] = true
+			weekdays = append(weekdays, wd)
+		}
+	}
+	if len(weekdays) == 0 {
+		return nil, series.ErrWeekdaysRequired
+	}
+	return weekdays, nil
+}
+
+// formatWeekdays форматирует дни недели, начиная с понедельника
+func formatWeekdays(weekdays []time.Weekday) string {
+	set := make(map[time.Weekday]bool, len(weekdays))
+	for _, wd := range weekdays {
+		set[wd] = true
+	}
+	var names []string
+	for i := 1; i <= 7; i++ {
+		wd := time.Weekday(i % 7)
+		if set[wd] {
+			names = append(names, weekdayNames[wd])
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseTimeOfDay парсит время в формате ЧЧ:ММ
+func parseTimeOfDay(input string) (series.TimeOfDay, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(input))
+	if err != nil {
+		return series.TimeOfDay{}, err
+	}
+	return series.TimeOfDay{Hour: t.Hour(), Minute: t.Minute()}, nil
+}
+
+// eventEditPrompts - подсказки для ввода нового значения поля события
+var eventEditPrompts = map[string]string{
+	"name":     "Введите новое название:",
+	"date":     "Введите новую дату и время в формате ДД.ММ.ГГГГ ЧЧ:ММ:",
+	"duration": durationPrompt,
+	"max":      "Введите новое количество мест:",
+	"trainer":  "Введите имя тренера:",
+	"price":    "Введите стоимость (в рублях или в звездах для Telegram Stars, только число):",
+	"phone":    "Введите номер телефона для оплаты (например, +79991234567):",
+	"desc":     "Введите описание (или \"-\", чтобы удалить описание):",
+	"level":    levelRangePrompt,
+	"cancel":   "Введите срок бесплатной отмены записи в часах и что делать после него: «запрет» или «штраф», например: 12 штраф.\n0 — отменять можно в любой момент, \"-\" — использовать общую настройку:",
+}
+
+// handleAdminEditEvent показывает выбор поля для редактирования события (формат: admin:edit:{eventID})
+func (h *Handlers) handleAdminEditEvent(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid edit event callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	evt, err := h.eventService.Get(ctx, event.EventID(parts[2]))
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	eventID := string(evt.ID)
+	text := fmt.Sprintf("✏️ Редактирование события «%s»\n\nЧто изменить?", evt.Name)
+	rows := [][]InlineKeyboardButton{
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📝 Название", fmt.Sprintf("admin:edit:field:name:%s", eventID)),
+			NewInlineKeyboardButtonData("📅 Тип", fmt.Sprintf("admin:edit:field:type:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🗓️ Дата и время", fmt.Sprintf("admin:edit:field:date:%s", eventID)),
+			NewInlineKeyboardButtonData("📍 Локация", fmt.Sprintf("admin:edit:field:loc:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("⏱ Длительность", fmt.Sprintf("admin:edit:field:duration:%s", eventID)),
+			NewInlineKeyboardButtonData("🎾 Корты", fmt.Sprintf("admin:edit:field:courts:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("👥 Количество мест", fmt.Sprintf("admin:edit:field:max:%s", eventID)),
+			NewInlineKeyboardButtonData("👨‍🏫 Тренер", fmt.Sprintf("admin:edit:field:trainer:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("💰 Стоимость", fmt.Sprintf("admin:edit:field:price:%s", eventID)),
+			NewInlineKeyboardButtonData("💳 Способ оплаты", fmt.Sprintf("admin:edit:field:pay:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📱 Телефон для оплаты", fmt.Sprintf("admin:edit:field:phone:%s", eventID)),
+			NewInlineKeyboardButtonData("↩️ Отмена записи", fmt.Sprintf("admin:edit:field:cancel:%s", eventID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📝 Описание", fmt.Sprintf("admin:edit:field:desc:%s", eventID)),
+			NewInlineKeyboardButtonData("🎯 Уровень", fmt.Sprintf("admin:edit:field:level:%s", eventID)),
+		),
+	}
+	if evt.Type == event.EventTypeCompetition {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("👥 Категория (одиночная/парная)", fmt.Sprintf("admin:edit:field:doubles:%s", eventID)),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:event:%s", eventID)),
+	))
+	keyboard := NewInlineKeyboardMarkup(rows...)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with event edit fields", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminEditEventField запрашивает новое значение поля события (формат: admin:edit:field:{field}:{eventID})
+func (h *Handlers) handleAdminEditEventField(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid edit event field callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	field, eventID := parts[3], event.EventID(parts[4])
+
+	switch field {
+	case "loc":
+		locations, err := h.locationService.List(ctx)
+		if err != nil {
+			h.logger.Error("failed to list locations for event edit", "chat_id", cb.Message.ChatID, "error", err)
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка локаций"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+
+		var rows [][]InlineKeyboardButton
+		for _, loc := range locations {
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData(loc.Name, "admin:edit:loc:"+string(loc.ID)),
+			))
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:edit:%s", string(eventID))),
+		))
+
+		h.editingEvents[cb.Message.ChatID] = &EventEditState{EventID: eventID, Field: field}
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, "📍 Выберите новую локацию:", NewInlineKeyboardMarkup(rows...)); err != nil {
+			h.logger.Error("failed to edit message with locations for event edit", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	case "type":
+		keyboard := NewInlineKeyboardMarkup(
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🏋️ Тренировка", "admin:edit:type:"+string(event.EventTypeTraining)),
+				NewInlineKeyboardButtonData("🏆 Соревнование", "admin:edit:type:"+string(event.EventTypeCompetition)),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:edit:%s", string(eventID))),
+			),
+		)
+
+		h.editingEvents[cb.Message.ChatID] = &EventEditState{EventID: eventID, Field: field}
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, "📅 Выберите тип события:", keyboard); err != nil {
+			h.logger.Error("failed to edit message with event types", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	case "courts":
+		evt, err := h.eventService.Get(ctx, eventID)
+		if err != nil || evt == nil {
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+		free, err := h.eventService.FreeCourts(ctx, evt.LocationID, evt.Date, evt.Duration, evt.ID)
+		if err != nil {
+			h.logger.Error("failed to find free courts", "event_id", string(eventID), "chat_id", cb.Message.ChatID, "error", err)
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения свободных кортов"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+		if len(free) == 0 && len(evt.CourtIDs) == 0 {
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, "🎾 У локации события нет свободных кортов. Добавить корты можно в меню «Локации» → «Корты»."); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+
+		state := &EventEditState{EventID: eventID, Field: field, FreeCourts: free, CourtIDs: append([]location.CourtID(nil), evt.CourtIDs...)}
+		h.editingEvents[cb.Message.ChatID] = state
+		text, keyboard := h.formatter.FormatCourtPicker(eventSlotHeader(evt.Date, evt.Duration), state.FreeCourts, state.CourtIDs, "admin:edit:")
+		if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with court picker", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	case "doubles":
+		keyboard := NewInlineKeyboardMarkup(
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("👤 Одиночный разряд", "admin:edit:doubles:0"),
+				NewInlineKeyboardButtonData("👥 Парный разряд", "admin:edit:doubles:1"),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:edit:%s", string(eventID))),
+			),
+		)
+
+		h.editingEvents[cb.Message.ChatID] = &EventEditState{EventID: eventID, Field: field}
+		text := "👥 Выберите категорию соревнования:\n\nКатегорию можно изменить, только пока на событие никто не записан."
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with doubles choice", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	case "pay":
+		evt, err := h.eventService.Get(ctx, eventID)
+		if err != nil || evt == nil {
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+
+		h.editingEvents[cb.Message.ChatID] = &EventEditState{EventID: eventID, Field: field}
+		header := "💳 Сейчас: " + paymentModeLabels[evt.EffectivePaymentMode()]
+		text, keyboard := h.formatter.FormatPaymentModePicker(header, "admin:edit:pay:", fmt.Sprintf("admin:edit:%s", string(eventID)), paymentProviderToken() != "")
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with payment modes", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	default:
+		prompt, ok := eventEditPrompts[field]
+		if !ok {
+			h.logger.Warn("invalid event edit field", "field", field, "chat_id", cb.Message.ChatID)
+			return
+		}
+
+		h.editingEvents[cb.Message.ChatID] = &EventEditState{EventID: eventID, Field: field}
+		if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, "✏️ "+prompt+"\n\nДля отмены отправьте /cancel"); err != nil {
+			h.logger.Error("failed to edit message for event field prompt", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	}
+}
+
+// handleAdminEditEventLocation применяет выбранную локацию (формат: admin:edit:loc:{locationID})
+func (h *Handlers) handleAdminEditEventLocation(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid edit event location callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	state := h.editingEvents[cb.Message.ChatID]
+	if state == nil || state.Field != "loc" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.editingEvents, cb.Message.ChatID)
+
+	locationID := location.LocationID(parts[3])
+	h.applyEventEdit(ctx, cb.Message.ChatID, state.EventID, event.UpdateEventInput{LocationID: &locationID})
+}
+
+// handleAdminEditEventType применяет выбранный тип события (формат: admin:edit:type:{type})
+func (h *Handlers) handleAdminEditEventType(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid edit event type callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	state := h.editingEvents[cb.Message.ChatID]
+	if state == nil || state.Field != "type" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.editingEvents, cb.Message.ChatID)
+
+	eventType := event.EventType(parts[3])
+	if eventType != event.EventTypeTraining && eventType != event.EventTypeCompetition {
+		h.logger.Warn("invalid event type", "type", parts[3], "chat_id", cb.Message.ChatID)
+		return
+	}
+	h.applyEventEdit(ctx, cb.Message.ChatID, state.EventID, event.UpdateEventInput{Type: &eventType})
+}
+
+// handleAdminEditEventDoubles применяет выбранную категорию соревнования (формат: admin:edit:doubles:{0|1})
+func (h *Handlers) handleAdminEditEventDoubles(ctx context.Context, cb *CallbackQuery) {
+	state := h.editingEvents[cb.Message.ChatID]
+	if state == nil || state.Field != "doubles" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.editingEvents, cb.Message.ChatID)
+
+	doubles := strings.TrimPrefix(cb.Data, "admin:edit:doubles:") == "1"
+	h.applyEventEdit(ctx, cb.Message.ChatID, state.EventID, event.UpdateEventInput{Doubles: &doubles})
+}
+
+// handleAdminEditEventPaymentMode применяет выбранный способ оплаты (формат: admin:edit:pay:{mode})
+func (h *Handlers) handleAdminEditEventPaymentMode(ctx context.Context, cb *CallbackQuery) {
+	state := h.editingEvents[cb.Message.ChatID]
+	if state == nil || state.Field != "pay" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.editingEvents, cb.Message.ChatID)
+
+	mode := event.PaymentMode(strings.TrimPrefix(cb.Data, "admin:edit:pay:"))
+	h.applyEventEdit(ctx, cb.Message.ChatID, state.EventID, event.UpdateEventInput{PaymentMode: &mode})
+}
+
+// handleAdminEditToggleCourt отмечает или снимает корт при редактировании события (формат: admin:edit:court:{номер})
+func (h *Handlers) handleAdminEditToggleCourt(ctx context.Context, cb *CallbackQuery) {
+	state := h.editingEvents[cb.Message.ChatID]
+	if state == nil || state.Field != "courts" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	court, ok := courtByIndex(state.FreeCourts, strings.TrimPrefix(cb.Data, "admin:edit:court:"))
+	if !ok {
+		h.logger.Warn("invalid court index", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	state.CourtIDs = toggleCourt(state.CourtIDs, court.ID)
+
+	evt, err := h.eventService.Get(ctx, state.EventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	text, keyboard := h.formatter.FormatCourtPicker(eventSlotHeader(evt.Date, evt.Duration), state.FreeCourts, state.CourtIDs, "admin:edit:")
+	if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit court picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminEditCourtsDone сохраняет выбранные корты события (формат: admin:edit:courts_done)
+func (h *Handlers) handleAdminEditCourtsDone(ctx context.Context, cb *CallbackQuery) {
+	state := h.editingEvents[cb.Message.ChatID]
+	if state == nil || state.Field != "courts" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.editingEvents, cb.Message.ChatID)
+
+	// Корты, которых нет среди свободных (например, удаленные), при сохранении отпадают
+	var courtIDs []location.CourtID
+	for _, c := range state.FreeCourts {
+		for _, id := range state.CourtIDs {
+			if c.ID == id {
+				courtIDs = append(courtIDs, id)
+			}
+		}
+	}
+	h.applyEventEdit(ctx, cb.Message.ChatID, state.EventID, event.UpdateEventInput{CourtIDs: &courtIDs})
+}
+
+// handleAdminEventEditInput обрабатывает ввод нового значения поля события
+func (h *Handlers) handleAdminEventEditInput(ctx context.Context, msg *Message, state *EventEditState) {
+	input := strings.TrimSpace(msg.Text)
+
+	if input == "/cancel" {
+		delete(h.editingEvents, msg.ChatID)
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send admin menu", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	var in event.UpdateEventInput
+	var errorMsg string
+	switch state.Field {
+	case "name":
+		if input == "" {
+			errorMsg = "❌ Название не может быть пустым. Введите название:"
+		}
+		in.Name = &input
+	case "date":
+		eventDate, err := parseEventDate(input)
+		if err != nil {
+			errorMsg = "❌ Неверный формат даты. Используйте формат:\n📅 ДД.ММ.ГГГГ ЧЧ:ММ\n\nПример: 15.01.2026 18:00"
+		} else if eventDate.Before(time.Now()) {
+			errorMsg = "❌ Дата события не может быть в прошлом. Введите корректную дату:"
+		}
+		in.Date = &eventDate
+	case "duration":
+		duration, err := parseDuration(input)
+		if err != nil {
+			errorMsg = "❌ Введите длительность в минутах (целое положительное число) или \"-\":"
+		}
+		in.Duration = &duration
+	case "max":
+		maxPlayers, err := strconv.Atoi(input)
+		if err != nil || maxPlayers <= 0 {
+			errorMsg = "❌ Введите корректное количество мест (положительное число):"
+		}
+		in.MaxPlayers = &maxPlayers
+	case "trainer":
+		if input == "" {
+			errorMsg = "❌ Имя тренера не может быть пустым. Введите имя тренера:"
+		}
+		in.Trainer = &input
+	case "price":
+		price, err := strconv.Atoi(input)
+		if err != nil || price < 0 {
+			errorMsg = "❌ Введите корректную стоимость (положительное число в рублях):"
+		}
+		in.Price = &price
+	case "phone":
+		if input == "" {
+			errorMsg = "❌ Номер телефона не может быть пустым. Введите номер телефона:"
+		}
+		in.PaymentPhone = &input
+	case "desc":
+		if input == "-" {
+			input = ""
+		}
+		in.Description = &input
+	case "level":
+		min, max, err := parseLevelRange(input)
+		if err != nil {
+			errorMsg = "❌ Некорректный диапазон уровней.\n\n" + levelRangePrompt
+		}
+		in.MinLevel = &min
+		in.MaxLevel = &max
+	case "cancel":
+		var deadline time.Duration
+		var late event.LateCancelMode
+		switch input {
+		case "-":
+			// Общая настройка: нулевой срок и пустой режим
+		case "0":
+			late = event.LateCancelNone
+		default:
+			var ok bool
+			deadline, late, ok = parseCancelRule(input)
+			if !ok || deadline == 0 {
+				errorMsg = "❌ Введите срок в часах и режим, например: 12 штраф, или 0, или \"-\":"
+			}
+		}
+		in.CancelDeadline = &deadline
+		in.LateCancel = &late
+	default:
+		// Локация, тип и способ оплаты выбираются кнопками
+		errorMsg = "❌ Выберите значение кнопкой выше или отправьте /cancel"
+	}
+
+	if errorMsg != "" {
+		if err := h.client.SendMessage(msg.ChatID, errorMsg); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	delete(h.editingEvents, msg.ChatID)
+	h.applyEventEdit(ctx, msg.ChatID, state.EventID, in)
+}
+
+// applyEventEdit сохраняет изменения события, уведомляет игроков о переносе и обновляет анонсы в каналах
+func (h *Handlers) applyEventEdit(ctx context.Context, chatID int64, eventID event.EventID, in event.UpdateEventInput) {
+	before, err := h.eventService.Get(ctx, eventID)
+	if err != nil || before == nil {
+		if sendErr := h.client.SendMessage(chatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	var updated *event.Event
+	err = retryOnConflict(func() (err error) {
+		updated, err = h.eventService.Update(ctx, eventID, in)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to update event", "event_id", string(eventID), "chat_id", chatID, "error", err)
+		errorMsg := fmt.Sprintf("❌ Ошибка изменения: %v", err)
+		if err == event.ErrEventNotEditable {
+			errorMsg = "❌ Отмененное или завершенное событие нельзя изменить"
+		} else if err == event.ErrDateInPast {
+			errorMsg = "❌ Дата события не может быть в прошлом"
+		} else if errors.Is(err, event.ErrTeamFormatLocked) {
+			errorMsg = "❌ Категорию нельзя изменить: на событие уже есть записи"
+		} else if errors.Is(err, event.ErrDoublesOnlyCompetition) {
+			errorMsg = "❌ Парный разряд доступен только для соревнований"
+		} else if errors.Is(err, event.ErrOnlinePaymentPriceRequired) {
+			errorMsg = "❌ Для онлайн-оплаты сначала укажите стоимость больше нуля"
+		} else if errors.Is(err, event.ErrPaymentModeInvalid) {
+			errorMsg = "❌ Неизвестный способ оплаты"
+		} else if errors.Is(err, event.ErrConflict) {
+			errorMsg = conflictMessage
+		} else if msg := courtErrorMessage(err); msg != "" {
+			errorMsg = msg
+		}
+		if sendErr := h.client.SendMessage(chatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	// Перенос по времени или месту - лично сообщаем каждому записавшемуся
+	var changes []string
+	if !updated.Date.Equal(before.Date) {
+		changes = append(changes, fmt.Sprintf("🗓️ Дата: %s → <b>%s</b>",
+			before.Date.Format("02.01.2006 15:04"), updated.Date.Format("02.01.2006 15:04")))
+	}
+	if updated.LocationID != before.LocationID {
+		changes = append(changes, fmt.Sprintf("📍 Место: %s → <b>%s</b>",
+			h.locationName(ctx, before.LocationID), h.locationName(ctx, updated.LocationID)))
+	}
+	if len(changes) > 0 {
+		h.notifyEventChanged(ctx, updated, changes)
+	}
+
+	h.updateEventAnnouncements(ctx, updated)
+
+	// При увеличении количества мест освободившиеся места занимает лист ожидания
+	if in.MaxPlayers != nil {
+		var promoted []event.EventRegistration
+		err := retryOnConflict(func() (err error) {
+			promoted, err = h.eventService.PromoteWaitlist(ctx, eventID)
+			return err
+		})
+		if err != nil {
+			h.logger.Error("failed to promote waitlist", "event_id", string(eventID), "error", err)
+		} else {
+			h.notifyWaitlistPromoted(ctx, eventID, promoted)
+		}
+	}
+
+	text, keyboard := h.formatter.FormatEventDetails(*updated, h.eventCourtNames(ctx, updated))
+	text = "✅ Событие обновлено\n\n" + text
+	if len(changes) > 0 {
+		text += "\n📨 Записавшиеся игроки уведомлены об изменениях"
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send updated event details", "chat_id", chatID, "error", err)
+	}
+}
+
+// courtErrorMessage возвращает текст ошибки бронирования кортов или пустую строку, если ошибка другая
+func courtErrorMessage(err error) string {
+	var conflict *event.CourtConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return fmt.Sprintf("❌ Корт «%s» уже занят событием «%s» (%s–%s). Выберите другое время или корт.",
+			conflict.CourtName, conflict.EventName, conflict.Date.Format("02.01.2006 15:04"), conflict.EndsAt.Format("15:04"))
+	case errors.Is(err, event.ErrCourtNotInLocation):
+		return "❌ Выбранный корт не относится к локации события"
+	case errors.Is(err, event.ErrDurationInvalid):
+		return "❌ Некорректная длительность события"
+	default:
+		return ""
+	}
+}
+
+// eventCourtNames возвращает названия кортов, забронированных событием
+func (h *Handlers) eventCourtNames(ctx context.Context, evt *event.Event) []string {
+	if len(evt.CourtIDs) == 0 {
+		return nil
+	}
+	courts, err := h.locationService.ListCourts(ctx, evt.LocationID)
+	if err != nil {
+		h.logger.Error("failed to list courts", "location_id", string(evt.LocationID), "error", err)
+	}
+	names := make([]string, 0, len(evt.CourtIDs))
+	for _, id := range evt.CourtIDs {
+		name := "удаленный корт"
+		for _, c := range courts {
+			if c.ID == id {
+				name = c.Name
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// locationName возвращает название локации или ее ID, если локация не найдена
+func (h *Handlers) locationName(ctx context.Context, locationID location.LocationID) string {
+	if loc, err := h.locationService.Get(ctx, locationID); err == nil && loc != nil {
+		return loc.Name
+	}
+	return string(locationID)
+}
+
+// handleAdminAttendance показывает чек-лист посещаемости подтвержденных игроков (формат: admin:att:{eventID})
+func (h *Handlers) handleAdminAttendance(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid attendance callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	evt, err := h.eventService.Get(ctx, event.EventID(parts[2]))
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.showAttendanceChecklist(ctx, cb, evt)
+}
+
+// handleAdminToggleAttendance переключает отметку игрока: не отмечен → пришел → не пришел (формат: admin:att:t:{eventID}:{userID})
+func (h *Handlers) handleAdminToggleAttendance(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid attendance toggle callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	eventID := event.EventID(parts[3])
+	userID, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid user ID in attendance toggle", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	next := event.AttendancePresent
+	switch evt.Registrations[userID].Attendance {
+	case event.AttendancePresent:
+		next = event.AttendanceNoShow
+	case event.AttendanceNoShow:
+		next = event.AttendanceUnknown
+	}
+
+	err = retryOnConflict(func() (err error) {
+		evt, err = h.eventService.MarkAttendance(ctx, eventID, userID, next)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to mark attendance", "event_id", string(eventID), "user_id", userID, "error", err)
+		h.sendAttendanceError(cb.Message.ChatID, err)
+		return
+	}
+
+	h.showAttendanceChecklist(ctx, cb, evt)
+}
+
+// handleAdminMarkRestNoShow отмечает неявку всем подтвержденным игрокам без отметки (формат: admin:att:rest:{eventID})
+func (h *Handlers) handleAdminMarkRestNoShow(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid attendance rest callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	eventID := event.EventID(parts[3])
+
+	var evt *event.Event
+	err := retryOnConflict(func() (err error) {
+		evt, err = h.eventService.MarkUnmarkedNoShow(ctx, eventID)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to mark unmarked players as no-show", "event_id", string(eventID), "error", err)
+		h.sendAttendanceError(cb.Message.ChatID, err)
+		return
+	}
+
+	h.showAttendanceChecklist(ctx, cb, evt)
+}
+
+// handleAdminCheckInLink отправляет ссылку для самостоятельной отметки игроков на месте (формат: admin:att:link:{eventID})
+func (h *Handlers) handleAdminCheckInLink(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid check-in link callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	evt, err := h.eventService.Get(ctx, event.EventID(parts[3]))
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := h.formatter.FormatCheckInLink(evt, h.client.Username())
+	if err := h.client.SendMessage(cb.Message.ChatID, text); err != nil {
+		h.logger.Error("failed to send check-in link", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// showAttendanceChecklist собирает имена подтвержденных игроков и показывает чек-лист
+func (h *Handlers) showAttendanceChecklist(ctx context.Context, cb *CallbackQuery, evt *event.Event) {
+	var entries []AttendanceEntry
+	for telegramID, reg := range evt.Registrations {
+		if reg.Status != event.RegistrationStatusApproved {
+			continue
+		}
+		name := fmt.Sprintf("ID %d", telegramID)
+		if usr, err := h.userService.GetByTelegramID(ctx, telegramID); err == nil && usr != nil {
+			name = strings.TrimSpace(usr.Name + " " + usr.Surname)
+		}
+		entries = append(entries, AttendanceEntry{
+			UserID:      telegramID,
+			Name:        name,
+			Attendance:  reg.Attendance,
+			CheckedInAt: reg.CheckedInAt,
+		})
+	}
+
+	text, keyboard := h.formatter.FormatAttendanceChecklist(evt, entries)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with attendance checklist", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// sendAttendanceError сообщает администратору об ошибке отметки посещаемости
+func (h *Handlers) sendAttendanceError(chatID int64, err error) {
+	errorMsg := fmt.Sprintf("❌ Ошибка отметки: %v", err)
+	if err == event.ErrAttendanceNotTracked {
+		errorMsg = "❌ Посещаемость отмечается только для опубликованных и прошедших событий"
+	} else if err == event.ErrRegistrationNotApproved || err == event.ErrRegistrationNotFound {
+		errorMsg = "❌ Отмечать можно только подтвержденных игроков"
+	} else if errors.Is(err, event.ErrConflict) {
+		errorMsg = conflictMessage
+	}
+	if sendErr := h.client.SendMessage(chatID, errorMsg); sendErr != nil {
+		h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+	}
+}
+
+// handleAdminNoShowPolicyStart показывает текущую политику неявок и ждет ввода новой
+func (h *Handlers) handleAdminNoShowPolicyStart(ctx context.Context, cb *CallbackQuery) {
+	policy, err := h.settingsService.GetNoShowPolicy(ctx)
+	if err != nil {
+		h.logger.Error("failed to get no-show policy", "error", err)
+	}
+
+	current := "выключена"
+	if policy.Enabled() {
+		current = fmt.Sprintf("%d неявок за %d дн. — блокировка записи на %d дн.",
+			policy.Limit, int(policy.Window/(24*time.Hour)), int(policy.BlockFor/(24*time.Hour)))
+	}
+
+	h.settingNoShowPolicy[cb.Message.ChatID] = true
+	text := fmt.Sprintf("🚷 Политика неявок\n\n"+
+		"Сейчас: %s\n\n"+
+		"Отправьте три числа через пробел: количество неявок, период в днях и срок блокировки в днях.\n"+
+		"Например: 3 30 14 — три неявки за 30 дней блокируют самостоятельную запись на 14 дней.\n"+
+		"Поздние отмены записи считаются неявками.\n\n"+
+		"0 отключает блокировку.\n"+
+		"Для отмены отправьте /cancel",
+		current)
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for no-show policy setup", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleSetNoShowPolicyInput обрабатывает ввод политики неявок
+func (h *Handlers) handleSetNoShowPolicyInput(ctx context.Context, msg *Message) {
+	delete(h.settingNoShowPolicy, msg.ChatID)
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+
+	if msg.Text == "/cancel" {
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send admin menu", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	var values []int
+	for _, f := range strings.Fields(msg.Text) {
+		v, err := strconv.Atoi(f)
+		if err != nil || v < 0 {
+			values = nil
+			break
+		}
+		values = append(values, v)
+	}
+
+	var policy event.NoShowPolicy
+	switch {
+	case len(values) == 1 && values[0] == 0:
+		// Отключаем блокировку, период и срок оставляем по умолчанию
+		policy = event.NoShowPolicy{
+			Window:   settings.DefaultNoShowWindowDays * 24 * time.Hour,
+			BlockFor: settings.DefaultNoShowBlockDays * 24 * time.Hour,
+		}
+	case len(values) == 3 && (values[0] == 0 || (values[1] > 0 && values[2] > 0)):
+		policy = event.NoShowPolicy{
+			Limit:    values[0],
+			Window:   time.Duration(values[1]) * 24 * time.Hour,
+			BlockFor: time.Duration(values[2]) * 24 * time.Hour,
+		}
+	default:
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, "❌ Некорректный ввод. Ожидалось три положительных числа, например: 3 30 14, или 0 для отключения", keyboard); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	if err := h.settingsService.SetNoShowPolicy(ctx, policy); err != nil {
+		h.logger.Error("failed to save no-show policy", "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка сохранения настройки"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := "✅ Блокировка за неявки отключена"
+	if policy.Enabled() {
+		text = "✅ Политика неявок сохранена"
+	}
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send success message", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// lateCancelModes - режимы поздней отмены, которые администратор указывает словом
+var lateCancelModes = map[string]event.LateCancelMode{
+	"запрет":  event.LateCancelBlock,
+	"block":   event.LateCancelBlock,
+	"штраф":   event.LateCancelPenalty,
+	"penalty": event.LateCancelPenalty,
+}
+
+// parseCancelRule разбирает правило отмены записи "<часы> [запрет|штраф]"; без режима возвращается пустой режим
+func parseCancelRule(input string) (time.Duration, event.LateCancelMode, bool) {
+	fields := strings.Fields(strings.ToLower(input))
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, "", false
+	}
+	hours, err := strconv.Atoi(fields[0])
+	if err != nil || hours < 0 {
+		return 0, "", false
+	}
+	var mode event.LateCancelMode
+	if len(fields) == 2 {
+		var ok bool
+		if mode, ok = lateCancelModes[fields[1]]; !ok {
+			return 0, "", false
+		}
+	}
+	return time.Duration(hours) * time.Hour, mode, true
+}
+
+// handleAdminCancelPolicyStart показывает текущее правило отмены записи и ждет ввода нового
+func (h *Handlers) handleAdminCancelPolicyStart(ctx context.Context, cb *CallbackQuery) {
+	policy, err := h.settingsService.GetCancellationPolicy(ctx)
+	if err != nil {
+		h.logger.Error("failed to get cancellation policy", "error", err)
+	}
+
+	h.settingCancelPolicy[cb.Message.ChatID] = true
+	text := fmt.Sprintf("↩️ Отмена записи\n\n"+
+		"Сейчас: %s\n\n"+
+		"Отправьте срок бесплатной отмены в часах и что делать после него:\n"+
+		"• запрет — отменить запись можно только через администратора\n"+
+		"• штраф — отмена засчитывается как поздняя: оплата не возвращается, отмена считается неявкой\n"+
+		"Например: 24 штраф\n\n"+
+		"0 отключает срок. Для отдельного события правило можно изменить в его настройках.\n"+
+		"Для отмены отправьте /cancel",
+		describeCancellationPolicy(policy))
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for cancellation policy setup", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleSetCancelPolicyInput обрабатывает ввод правила отмены записи
+func (h *Handlers) handleSetCancelPolicyInput(ctx context.Context, msg *Message) {
+	delete(h.settingCancelPolicy, msg.ChatID)
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+		),
+	)
+
+	if msg.Text == "/cancel" {
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send admin menu", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	deadline, late, ok := parseCancelRule(msg.Text)
+	if !ok {
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, "❌ Некорректный ввод. Ожидалось число часов и режим, например: 24 штраф, или 0 для отключения", keyboard); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	if err := h.settingsService.SetCancellationPolicy(ctx, event.CancellationPolicy{Deadline: deadline, Late: late}); err != nil {
+		h.logger.Error("failed to save cancellation policy", "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка сохранения настройки"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	policy, err := h.settingsService.GetCancellationPolicy(ctx)
+	if err != nil {
+		h.logger.Error("failed to get cancellation policy", "error", err)
+	}
+	text := "✅ Правило отмены записи сохранено: " + describeCancellationPolicy(policy)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send success message", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminLevelCommand устанавливает уровень игрока командой /admin_level <telegram_id> <уровень>
+func (h *Handlers) handleAdminLevelCommand(msg *Message, args []string) {
+	ctx := context.Background()
+	if len(args) != 2 {
+		if err := h.client.SendMessage(msg.ChatID, "Использование: /admin_level <telegram_id> <уровень>\nНапример: /admin_level 123456789 3.5\nЧтобы сбросить уровень, укажите 0"); err != nil {
+			h.logger.Error("failed to send admin level usage", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Некорректный Telegram ID"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	level := user.LevelUnset
+	if args[1] != "0" {
+		level, err = user.ParseLevel(args[1])
+		if err != nil {
+			if sendErr := h.client.SendMessage(msg.ChatID, levelErrorMessage(err)); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+	}
+
+	usr, err := h.userService.SetLevel(ctx, userID, level)
+	if err != nil {
+		h.logger.Error("failed to set user level", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, levelErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.sendLevelChanged(msg.ChatID, usr)
+}
+
+// handleAdminLevelPicker показывает выбор уровня игрока (формат: admin:level:{userID})
+func (h *Handlers) handleAdminLevelPicker(ctx context.Context, cb *CallbackQuery) {
+	userID, err := strconv.ParseInt(strings.TrimPrefix(cb.Data, "admin:level:"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid level callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	usr, err := h.userService.GetByTelegramID(ctx, userID)
+	if err != nil || usr == nil {
+		h.logger.Error("failed to get user", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Игрок не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatLevelPicker(usr)
+	if err := h.client.SendMessageWithKeyboard(cb.Message.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send level picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminSetLevel сохраняет выбранный уровень игрока (формат: admin:level:set:{userID}:{level})
+func (h *Handlers) handleAdminSetLevel(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid set level callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	userID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid user id in set level callback", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	tenths, err := strconv.Atoi(parts[4])
+	if err != nil {
+		h.logger.Warn("invalid level in set level callback", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	usr, err := h.userService.SetLevel(ctx, userID, user.Level(tenths))
+	if err != nil {
+		h.logger.Error("failed to set user level", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, levelErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatLevelPicker(usr)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit level picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+	h.sendLevelChanged(cb.Message.ChatID, usr)
+}
+
+// sendLevelChanged подтверждает администратору смену уровня и уведомляет игрока
+func (h *Handlers) sendLevelChanged(adminChatID int64, usr *user.User) {
+	levelStr := "сброшен"
+	if usr.Level.IsSet() {
+		levelStr = usr.Level.String()
+	}
+	if err := h.client.SendMessage(adminChatID, fmt.Sprintf("✅ Уровень игрока %s %s: %s", usr.Name, usr.Surname, levelStr)); err != nil {
+		h.logger.Error("failed to send level confirmation", "chat_id", adminChatID, "error", err)
+	}
+	if usr.Level.IsSet() {
+		if err := h.client.SendMessage(usr.TelegramID, fmt.Sprintf("🎯 Администратор установил ваш уровень: %s", levelStr)); err != nil {
+			h.logger.Error("failed to notify user about level", "user_id", usr.TelegramID, "error", err)
+		}
+	}
+}
+
+// levelErrorMessage возвращает текст ошибки при установке уровня
+func levelErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, user.ErrInvalidLevel):
+		return fmt.Sprintf("❌ Уровень должен быть от %s до %s с шагом %s", user.Level(user.MinLevel), user.Level(user.MaxLevel), user.Level(user.LevelStep))
+	case errors.Is(err, user.ErrUserNotFound):
+		return "❌ Игрок не найден"
+	default:
+		return "❌ Ошибка сохранения уровня"
+	}
+}
+
+// handleAdminLevelOverride записывает игрока на событие в обход ограничения по уровню
+// (формат: admin:lvok:{eventID}:{userID})
+func (h *Handlers) handleAdminLevelOverride(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid level override callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	eventID := event.EventID(parts[2])
+	userID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid user id in level override callback", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	err = retryOnConflict(func() error {
+		return h.eventService.RegisterUserByAdmin(ctx, eventID, userID)
+	})
+	if err != nil {
+		h.logger.Error("failed to register user by admin", "event_id", string(eventID), "user_id", userID, "error", err)
+		errorMsg := "❌ Ошибка записи игрока"
+		switch {
+		case errors.Is(err, event.ErrUserAlreadyRegistered):
+			errorMsg = "⚠️ Игрок уже записан на это событие"
+		case errors.Is(err, event.ErrEventNotOpen):
+			errorMsg = "❌ Запись на это событие закрыта"
+		case errors.Is(err, event.ErrConflict):
+			errorMsg = conflictMessage
+		}
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, "✅ Игрок допущен и записан на событие"); err != nil {
+		h.logger.Error("failed to edit level request message", "chat_id", cb.Message.ChatID, "error", err)
+	}
+
+	// Личный чат игрока совпадает с его Telegram ID
+	h.sendRegistrationResult(ctx, eventID, userID, userID, 0)
+}
+
+// tournamentFormats - форматы турнира по коду из callback data
+var tournamentFormats = map[string]tournament.CreateInput{
+	"rr1": {Format: tournament.FormatRoundRobin, Pools: 1},
+	"rr2": {Format: tournament.FormatRoundRobin, Pools: 2},
+	"rr4": {Format: tournament.FormatRoundRobin, Pools: 4},
+	"se":  {Format: tournament.FormatSingleElimination},
+	"de":  {Format: tournament.FormatDoubleElimination},
+}
+
+// handleAdminTournament показывает турнир соревнования или выбор формата, если турнира еще нет
+// (формат: admin:trn:{eventID})
+func (h *Handlers) handleAdminTournament(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "admin:trn:"))
+	h.showAdminTournament(ctx, cb.Message.ChatID, cb.Message.MessageID, eventID)
+}
+
+// showAdminTournament показывает турнир администратору; при messageID = 0 отправляет новое сообщение
+func (h *Handlers) showAdminTournament(ctx context.Context, chatID int64, messageID int, eventID event.EventID) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	t, err := h.tournamentService.GetByEvent(ctx, eventID)
+	if err != nil {
+		h.logger.Error("failed to get tournament", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения турнира"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	var text string
+	var keyboard *InlineKeyboardMarkup
+	if t == nil {
+		text, keyboard = h.formatter.FormatTournamentFormatChoice(evt, approvedCount(evt), false)
+	} else {
+		text, keyboard = h.formatter.FormatTournamentForAdmin(evt, t)
+	}
+
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with tournament", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send tournament", "chat_id", chatID, "error", err)
+	}
+}
+
+// approvedCount возвращает количество подтвержденных участников события
+func approvedCount(evt *event.Event) int {
+	approved := 0
+	for _, reg := range evt.Registrations {
+		if reg.Status == event.RegistrationStatusApproved {
+			approved++
+		}
+	}
+	return approved
+}
+
+// handleAdminTournamentSeed переключает способ посева на экране выбора формата (формат: admin:trn:seed:{0|1}:{eventID})
+func (h *Handlers) handleAdminTournamentSeed(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid tournament seed callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	evt, err := h.eventService.Get(ctx, event.EventID(parts[4]))
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event", "event_id", parts[4], "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatTournamentFormatChoice(evt, approvedCount(evt), parts[3] == "1")
+	if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with tournament format choice", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminCreateTournament создает турнир выбранного формата
+// (формат: admin:trn:new:{code}:{eventID}[:r], суффикс r - посев по рейтингу)
+func (h *Handlers) handleAdminCreateTournament(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 && len(parts) != 6 {
+		h.logger.Warn("invalid create tournament callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	in, ok := tournamentFormats[parts[3]]
+	if !ok {
+		h.logger.Warn("unknown tournament format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	in.EventID = event.EventID(parts[4])
+	in.SeedByRating = len(parts) == 6 && parts[5] == "r"
+
+	if _, err := h.tournamentService.Create(ctx, in); err != nil {
+		h.logger.Error("failed to create tournament", "event_id", parts[4], "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, tournamentErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.showAdminTournament(ctx, cb.Message.ChatID, cb.Message.MessageID, in.EventID)
+}
+
+// handleAdminTournamentMatch запрашивает счет матча (формат: admin:trn:m:{eventID}:{matchID})
+func (h *Handlers) handleAdminTournamentMatch(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid tournament match callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	eventID := event.EventID(parts[3])
+	matchID, err := strconv.Atoi(parts[4])
+	if err != nil {
+		h.logger.Warn("invalid match id in callback", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	t, err := h.tournamentService.GetByEvent(ctx, eventID)
+	if err != nil || t == nil || t.Match(matchID) == nil {
+		h.logger.Error("failed to get tournament match", "event_id", string(eventID), "match_id", matchID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Матч не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	m := t.Match(matchID)
+
+	h.enteringScores[cb.Message.ChatID] = &ScoreEntryState{EventID: eventID, MatchID: matchID}
+	text := fmt.Sprintf("🏓 Матч #%d\n%s — %s\n\nВведите счет в формате 11:7 (первым - очки участника слева) или \"-\" для отмены:",
+		m.ID, tournamentSideName(t, m.A), tournamentSideName(t, m.B))
+	if err := h.client.SendMessage(cb.Message.ChatID, text); err != nil {
+		h.logger.Error("failed to send score prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// parseScore разбирает счет матча вида "11:7", "11-7" или "11 7"
+func parseScore(input string) (int, int, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ':' || r == '-' || r == ' ' || r == '–'
+	})
+	if len(fields) != 2 {
+		return 0, 0, tournament.ErrInvalidScore
+	}
+	a, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, tournament.ErrInvalidScore
+	}
+	b, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, tournament.ErrInvalidScore
+	}
+	return a, b, nil
+}
+
+// handleAdminTournamentScoreInput сохраняет введенный счет матча и продвигает участников по сетке
+func (h *Handlers) handleAdminTournamentScoreInput(ctx context.Context, msg *Message, state *ScoreEntryState) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "-" {
+		delete(h.enteringScores, msg.ChatID)
+		h.showAdminTournament(ctx, msg.ChatID, 0, state.EventID)
+		return
+	}
+
+	scoreA, scoreB, err := parseScore(input)
+	if err != nil {
+		if err := h.client.SendMessage(msg.ChatID, "❌ Введите счет в формате 11:7 (ничьих не бывает) или \"-\" для отмены:"); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	before, err := h.tournamentService.GetByEvent(ctx, state.EventID)
+	if err != nil || before == nil {
+		delete(h.enteringScores, msg.ChatID)
+		h.logger.Error("failed to get tournament", "event_id", string(state.EventID), "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Турнир не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	t, err := h.tournamentService.RecordScore(ctx, before.ID, state.MatchID, scoreA, scoreB)
+	if err != nil {
+		h.logger.Error("failed to record match score", "event_id", string(state.EventID), "match_id", state.MatchID, "error", err)
+		if errors.Is(err, tournament.ErrInvalidScore) {
+			// Даем ввести счет еще раз
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Некорректный счет: ничьих не бывает. Введите счет еще раз или \"-\" для отмены:"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		delete(h.enteringScores, msg.ChatID)
+		if sendErr := h.client.SendMessage(msg.ChatID, tournamentErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	delete(h.enteringScores, msg.ChatID)
+
+	evt, err := h.eventService.Get(ctx, state.EventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event", "event_id", string(state.EventID), "error", err)
+		return
+	}
+
+	text, keyboard := h.formatter.FormatTournamentForAdmin(evt, t)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send tournament", "chat_id", msg.ChatID, "error", err)
+	}
+
+	h.recalculateRatings(ctx)
+	h.notifyTournamentMatchesReady(ctx, evt, before, t)
+	if before.Status != tournament.StatusFinished && t.Status == tournament.StatusFinished {
+		h.publishTournamentToChannels(ctx, evt, t)
+	}
+}
+
+// notifyTournamentMatchesReady сообщает игрокам о матчах, которые стали готовы к игре после ввода счета
+func (h *Handlers) notifyTournamentMatchesReady(ctx context.Context, evt *event.Event, before, after *tournament.Tournament) {
+	wasReady := make(map[int]bool)
+	for _, m := range before.ReadyMatches() {
+		wasReady[m.ID] = true
+	}
+	for _, m := range after.ReadyMatches() {
+		if wasReady[m.ID] {
+			continue
+		}
+		text, keyboard := h.formatter.FormatTournamentMatchReady(evt, after, &m)
+		for _, side := range []int{m.A, m.B} {
+			p := after.Participant(side)
+			if p == nil {
+				continue
+			}
+			for _, userID := range p.UserIDs {
+				if err := h.client.SendMessageWithKeyboard(userID, text, keyboard); err != nil {
+					h.logger.Error("failed to notify player about tournament match", "user_id", userID, "match_id", m.ID, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// publishTournamentToChannels отправляет сетку и результаты турнира во все каналы
+func (h *Handlers) publishTournamentToChannels(ctx context.Context, evt *event.Event, t *tournament.Tournament) int {
+	channelIDs, err := h.settingsService.GetChannelIDs(ctx)
+	if err != nil || len(channelIDs) == 0 {
+		return 0
+	}
+
+	text := h.formatter.FormatTournament(evt, t)
+	sent := 0
+	for _, channelID := range channelIDs {
+		if err := h.client.SendMessage(channelID, text); err != nil {
+			h.logger.Error("failed to publish tournament to channel", "channel_id", channelID, "event_id", string(evt.ID), "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// handleAdminTournamentFix показывает сыгранные матчи для исправления счета (формат: admin:trn:fix:{eventID})
+func (h *Handlers) handleAdminTournamentFix(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "admin:trn:fix:"))
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event", "event_id", string(eventID), "error", err)
+		return
+	}
+	t, err := h.tournamentService.GetByEvent(ctx, eventID)
+	if err != nil || t == nil {
+		h.logger.Error("failed to get tournament", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Турнир не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatTournamentPlayedMatches(evt, t)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with played matches", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminTournamentPublish публикует текущую сетку турнира в каналы (формат: admin:trn:pub:{eventID})
+func (h *Handlers) handleAdminTournamentPublish(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "admin:trn:pub:"))
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event", "event_id", string(eventID), "error", err)
+		return
+	}
+	t, err := h.tournamentService.GetByEvent(ctx, eventID)
+	if err != nil || t == nil {
+		h.logger.Error("failed to get tournament", "event_id", string(eventID), "error", err)
+		return
+	}
+
+	text := "📢 Сетка турнира опубликована в канале"
+	if h.publishTournamentToChannels(ctx, evt, t) == 0 {
+		text = "⚠️ Не удалось опубликовать: канал не настроен или недоступен"
+	}
+	if err := h.client.SendMessage(cb.Message.ChatID, text); err != nil {
+		h.logger.Error("failed to send tournament publish result", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminTournamentDeleteConfirm запрашивает подтверждение удаления турнира (формат: admin:trn:del:{eventID})
+func (h *Handlers) handleAdminTournamentDeleteConfirm(ctx context.Context, cb *CallbackQuery) {
+	eventID := strings.TrimPrefix(cb.Data, "admin:trn:del:")
+	text := "🗑 Удалить турнир вместе со всеми результатами матчей?\n\nПосле удаления можно создать турнир заново, например в другом формате."
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✅ Да, удалить", fmt.Sprintf("admin:trn:delok:%s", eventID)),
+			NewInlineKeyboardButtonData("❌ Нет", fmt.Sprintf("admin:trn:%s", eventID)),
+		),
+	)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message for tournament delete confirmation", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminTournamentDelete удаляет турнир (формат: admin:trn:delok:{eventID})
+func (h *Handlers) handleAdminTournamentDelete(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "admin:trn:delok:"))
+	t, err := h.tournamentService.GetByEvent(ctx, eventID)
+	if err == nil && t != nil {
+		err = h.tournamentService.Delete(ctx, t.ID)
+	}
+	if err != nil {
+		h.logger.Error("failed to delete tournament", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка удаления турнира"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.recalculateRatings(ctx)
+	h.showAdminTournament(ctx, cb.Message.ChatID, cb.Message.MessageID, eventID)
+}
+
+// recalculateRatings пересчитывает рейтинг игроков после изменения результатов турниров.
+// Ошибка пересчета не мешает основному действию: рейтинг можно пересчитать позже командой /admin_rating_recalc
+func (h *Handlers) recalculateRatings(ctx context.Context) {
+	if _, err := h.ratingService.Recalculate(ctx); err != nil {
+		h.logger.Error("failed to recalculate ratings", "error", err)
+	}
+}
+
+// handleAdminRatingRecalc заново пересчитывает рейтинг всех игроков по результатам турниров
+func (h *Handlers) handleAdminRatingRecalc(msg *Message) {
+	ctx := context.Background()
+	matches, err := h.ratingService.Recalculate(ctx)
+	if err != nil {
+		h.logger.Error("failed to recalculate ratings", "chat_id", msg.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка пересчета рейтинга"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.SendMessage(msg.ChatID, fmt.Sprintf("✅ Рейтинг пересчитан. Учтено матчей: %d", matches)); err != nil {
+		h.logger.Error("failed to send rating recalculation result", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// tournamentErrorMessage возвращает текст ошибки турнира для администратора
+func tournamentErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, tournament.ErrNotEnoughParticipants):
+		return fmt.Sprintf("❌ Для турнира нужно минимум %d подтвержденных участника", tournament.MinParticipants)
+	case errors.Is(err, tournament.ErrInvalidPools):
+		return "❌ Слишком мало участников для такого количества групп: в каждой группе нужно минимум два"
+	case errors.Is(err, tournament.ErrTournamentExists):
+		return "⚠️ Турнир для этого соревнования уже создан"
+	case errors.Is(err, tournament.ErrNotCompetition):
+		return "❌ Турнир можно провести только для соревнования"
+	case errors.Is(err, event.ErrEventNotOpen):
+		return "❌ Событие отменено"
+	case errors.Is(err, tournament.ErrMatchNotReady):
+		return "❌ Участники матча еще не определены"
+	case errors.Is(err, tournament.ErrMatchLocked):
+		return "❌ Нельзя сменить победителя: следующие матчи уже сыграны. Сначала исправьте их."
+	case errors.Is(err, tournament.ErrMatchNotFound):
+		return "❌ Матч не найден"
+	default:
+		return "❌ Ошибка турнира"
+	}
+}
+
+// handleAdminCourtLocations показывает выбор локации для управления кортами (формат: admin:courts)
+func (h *Handlers) handleAdminCourtLocations(ctx context.Context, cb *CallbackQuery) {
+	locations, err := h.locationService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list locations for courts", "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка локаций"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatCourtLocations(locations)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with court locations", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminCourts показывает корты локации (формат: admin:courts:{locationID})
+func (h *Handlers) handleAdminCourts(ctx context.Context, cb *CallbackQuery) {
+	locationID := location.LocationID(strings.TrimPrefix(cb.Data, "admin:courts:"))
+	h.showCourts(ctx, cb.Message.ChatID, cb.Message.MessageID, locationID)
+}
+
+// showCourts показывает корты локации; при messageID = 0 отправляет новое сообщение
+func (h *Handlers) showCourts(ctx context.Context, chatID int64, messageID int, locationID location.LocationID) {
+	loc, err := h.locationService.Get(ctx, locationID)
+	if err != nil || loc == nil {
+		h.logger.Error("failed to get location", "location_id", string(locationID), "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Локация не найдена"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+	courts, err := h.locationService.ListCourts(ctx, locationID)
+	if err != nil {
+		h.logger.Error("failed to list courts", "location_id", string(locationID), "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения списка кортов"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatCourts(loc, courts)
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with courts", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send courts", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminStartAddCourt запрашивает название нового корта (формат: admin:court:add:{locationID})
+func (h *Handlers) handleAdminStartAddCourt(ctx context.Context, cb *CallbackQuery) {
+	h.addingCourts[cb.Message.ChatID] = location.LocationID(strings.TrimPrefix(cb.Data, "admin:court:add:"))
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, "🎾 Введите название корта (например, «Корт 1»):\n\nДля отмены отправьте /cancel"); err != nil {
+		h.logger.Error("failed to edit message for court name prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminAddCourtInput обрабатывает ввод названия нового корта
+func (h *Handlers) handleAdminAddCourtInput(ctx context.Context, msg *Message, locationID location.LocationID) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		delete(h.addingCourts, msg.ChatID)
+		h.showCourts(ctx, msg.ChatID, 0, locationID)
+		return
+	}
+
+	if _, err := h.locationService.AddCourt(ctx, locationID, input); err != nil {
+		errorMsg := "❌ Ошибка добавления корта"
+		switch {
+		case errors.Is(err, location.ErrCourtNameRequired):
+			errorMsg = "❌ Название корта не может быть пустым. Введите название:"
+		case errors.Is(err, location.ErrCourtNameTaken):
+			errorMsg = "❌ Корт с таким названием уже есть. Введите другое название:"
+		default:
+			delete(h.addingCourts, msg.ChatID)
+			h.logger.Error("failed to add court", "location_id", string(locationID), "chat_id", msg.ChatID, "error", err)
+		}
+		if sendErr := h.client.SendMessage(msg.ChatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	delete(h.addingCourts, msg.ChatID)
+	h.showCourts(ctx, msg.ChatID, 0, locationID)
+}
+
+// handleAdminDeleteCourt удаляет корт, если он не забронирован предстоящими событиями
+// (формат: admin:court:del:{locationID}:{номер})
+func (h *Handlers) handleAdminDeleteCourt(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid delete court callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	locationID := location.LocationID(parts[3])
+
+	courts, err := h.locationService.ListCourts(ctx, locationID)
+	if err != nil {
+		h.logger.Error("failed to list courts", "location_id", string(locationID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка кортов"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	court, ok := courtByIndex(courts, parts[4])
+	if !ok {
+		h.logger.Warn("invalid court index", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	events, err := h.eventService.ListByLocation(ctx, locationID)
+	if err != nil {
+		h.logger.Error("failed to list events for court", "location_id", string(locationID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка проверки бронирований корта"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	now := time.Now()
+	for _, evt := range events {
+		if evt.Status.IsActive() && evt.EndsAt().After(now) && evt.ReservesCourt(court.ID) {
+			text := fmt.Sprintf("❌ Корт «%s» забронирован событием «%s» (%s). Сначала снимите бронь корта в событии.",
+				court.Name, evt.Name, evt.Date.Format("02.01.2006 15:04"))
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, text); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+	}
+
+	if err := h.locationService.DeleteCourt(ctx, court.ID); err != nil {
+		h.logger.Error("failed to delete court", "court_id", string(court.ID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка удаления корта"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	h.showCourts(ctx, cb.Message.ChatID, cb.Message.MessageID, locationID)
+}
+
+// showRoles показывает выданные роли; при messageID = 0 отправляет новое сообщение
+func (h *Handlers) showRoles(ctx context.Context, chatID int64, messageID int) {
+	grants, err := h.roleService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list roles", "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения списка ролей"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	names := make(map[int64]string)
+	for _, g := range grants {
+		if _, ok := names[g.TelegramID]; !ok {
+			names[g.TelegramID] = h.playerName(ctx, g.TelegramID)
+		}
+	}
+
+	text, keyboard := h.formatter.FormatRoles(grants, names, h.locationNames(ctx))
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with roles", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send roles", "chat_id", chatID, "error", err)
+	}
+}
+
+// locationNames возвращает названия локаций по ID
+func (h *Handlers) locationNames(ctx context.Context) map[location.LocationID]string {
+	names := make(map[location.LocationID]string)
+	locations, err := h.locationService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list locations", "error", err)
+		return names
+	}
+	for _, loc := range locations {
+		names[loc.ID] = loc.Name
+	}
+	return names
+}
+
+// handleAdminStartGrantRole запрашивает Telegram ID пользователя, которому назначается роль
+func (h *Handlers) handleAdminStartGrantRole(cb *CallbackQuery) {
+	h.grantingRoles[cb.Message.ChatID] = &RoleGrantState{}
+	text := "👑 Введите Telegram ID пользователя, которому нужно назначить роль.\n\n" +
+		"Пользователь должен хотя бы раз написать боту, чтобы получать уведомления.\n\nДля отмены отправьте /cancel"
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message for role user prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminRoleUserInput обрабатывает ввод Telegram ID пользователя и предлагает выбрать роль
+func (h *Handlers) handleAdminRoleUserInput(ctx context.Context, msg *Message, state *RoleGrantState) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		delete(h.grantingRoles, msg.ChatID)
+		h.showRoles(ctx, msg.ChatID, 0)
+		return
+	}
+
+	telegramID, err := strconv.ParseInt(input, 10, 64)
+	if err != nil || telegramID <= 0 {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Некорректный Telegram ID. Введите число или /cancel для отмены:"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	state.TelegramID = telegramID
+
+	text, keyboard := h.formatter.FormatRolePicker(h.playerName(ctx, telegramID), telegramID)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send role picker", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminPickRole обрабатывает выбор роли (формат: admin:roles:role:{role})
+func (h *Handlers) handleAdminPickRole(ctx context.Context, cb *CallbackQuery) {
+	state := h.grantingRoles[cb.Message.ChatID]
+	if state == nil || state.TelegramID == 0 {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	r := role.Role(strings.TrimPrefix(cb.Data, "admin:roles:role:"))
+	if !r.Valid() {
+		h.logger.Warn("unknown role in callback", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	state.Role = r
+
+	// Владелец всегда действует во всех локациях
+	if r == role.RoleOwner {
+		h.grantRole(ctx, cb, state, "")
+		return
+	}
+
+	locations, err := h.locationService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list locations", "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка локаций"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	text, keyboard := h.formatter.FormatRoleLocationPicker(r, locations)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with role location picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminPickRoleLocation обрабатывает выбор локации роли (формат: admin:roles:loc:{locationID|all})
+func (h *Handlers) handleAdminPickRoleLocation(ctx context.Context, cb *CallbackQuery) {
+	state := h.grantingRoles[cb.Message.ChatID]
+	if state == nil || state.TelegramID == 0 || state.Role == "" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	locationID := location.LocationID(strings.TrimPrefix(cb.Data, "admin:roles:loc:"))
+	if locationID == "all" {
+		locationID = ""
+	}
+	h.grantRole(ctx, cb, state, locationID)
+}
+
+// grantRole выдает роль из состояния, уведомляет пользователя и показывает обновленный список ролей
+func (h *Handlers) grantRole(ctx context.Context, cb *CallbackQuery, state *RoleGrantState, locationID location.LocationID) {
+	delete(h.grantingRoles, cb.Message.ChatID)
+
+	grant, err := h.roleService.Grant(ctx, cb.From.ID, role.GrantInput{
+		TelegramID: state.TelegramID,
+		Role:       state.Role,
+		LocationID: locationID,
+	})
+	if err != nil {
+		h.logger.Error("failed to grant role", "telegram_id", state.TelegramID, "role", string(state.Role), "location_id", string(locationID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, roleErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.SendMessage(grant.TelegramID, h.formatter.FormatRoleGranted(grant, h.locationName(ctx, grant.LocationID))); err != nil {
+		h.logger.Error("failed to notify user about granted role", "telegram_id", grant.TelegramID, "error", err)
+	}
+	h.showRoles(ctx, cb.Message.ChatID, cb.Message.MessageID)
+}
+
+// handleAdminRevokeRole снимает роль (формат: admin:roles:del:{grantID})
+func (h *Handlers) handleAdminRevokeRole(ctx context.Context, cb *CallbackQuery) {
+	grantID := role.GrantID(strings.TrimPrefix(cb.Data, "admin:roles:del:"))
+
+	grant, err := h.roleService.Revoke(ctx, cb.From.ID, grantID)
+	if err != nil {
+		h.logger.Error("failed to revoke role", "grant_id", string(grantID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, roleErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := fmt.Sprintf("ℹ️ С вас снята роль «%s»", roleLabels[grant.Role])
+	if err := h.client.SendMessage(grant.TelegramID, text); err != nil {
+		h.logger.Error("failed to notify user about revoked role", "telegram_id", grant.TelegramID, "error", err)
+	}
+	h.showRoles(ctx, cb.Message.ChatID, cb.Message.MessageID)
+}
+
+// roleErrorMessage возвращает понятное сообщение об ошибке назначения или снятия роли
+func roleErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, role.ErrAlreadyGranted):
+		return "❌ У пользователя уже есть эта роль"
+	case errors.Is(err, role.ErrOwnerScoped):
+		return "❌ Роль владельца нельзя ограничить локацией"
+	case errors.Is(err, role.ErrLastOwner):
+		return "❌ Нельзя снять роль с последнего владельца"
+	case errors.Is(err, role.ErrGrantNotFound):
+		return "❌ Роль уже снята"
+	case errors.Is(err, role.ErrPermissionDenied):
+		return accessDeniedMessage
+	default:
+		return "❌ Ошибка изменения ролей"
 	}
 }
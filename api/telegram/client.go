@@ -31,6 +31,49 @@ func (c *Client) SendMessageWithKeyboard(chatID int64, text string, keyboard *In
 	return err
 }
 
+// SendMessageWithKeyboardGetID отправляет сообщение с клавиатурой и возвращает ID отправленного сообщения
+func (c *Client) SendMessageWithKeyboardGetID(chatID int64, text string, keyboard *InlineKeyboardMarkup) (int, error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML // Включаем HTML форматирование
+	msg.ReplyMarkup = convertInlineKeyboard(keyboard)
+	sent, err := c.bot.Send(msg)
+	if err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+// SendContactRequest отправляет сообщение с кнопкой «поделиться контактом» и кнопкой отмены вместо обычной клавиатуры
+func (c *Client) SendContactRequest(chatID int64, text, shareText, cancelText string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	keyboard := tgbotapi.NewOneTimeReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonContact(shareText)),
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(cancelText)),
+	)
+	msg.ReplyMarkup = keyboard
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// SendMessageRemoveKeyboard отправляет сообщение и убирает клавиатуру, показанную SendContactRequest
+func (c *Client) SendMessageRemoveKeyboard(chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	_, err := c.bot.Send(msg)
+	return err
+}
+
+// SendDocument отправляет файл с подписью (подпись в HTML)
+func (c *Client) SendDocument(chatID int64, fileName string, data []byte, caption string) error {
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	doc.Caption = caption
+	doc.ParseMode = tgbotapi.ModeHTML
+	_, err := c.bot.Send(doc)
+	return err
+}
+
 // EditMessageText редактирует текстовое сообщение
 func (c *Client) EditMessageText(chatID int64, messageID int, text string) error {
 	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
@@ -45,6 +88,14 @@ func (c *Client) EditMessageTextAndMarkup(chatID int64, messageID int, text stri
 	return err
 }
 
+// EditMessageHTMLAndMarkup редактирует сообщение с HTML форматированием и клавиатурой
+func (c *Client) EditMessageHTMLAndMarkup(chatID int64, messageID int, text string, keyboard *InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, convertInlineKeyboard(keyboard))
+	edit.ParseMode = tgbotapi.ModeHTML
+	_, err := c.bot.Send(edit)
+	return err
+}
+
 // AnswerCallbackQuery отвечает на callback query
 func (c *Client) AnswerCallbackQuery(callbackQueryID string) error {
 	answer := tgbotapi.NewCallback(callbackQueryID, "")
@@ -52,11 +103,32 @@ func (c *Client) AnswerCallbackQuery(callbackQueryID string) error {
 	return err
 }
 
+// SendInvoice отправляет счет на оплату через Telegram Payments (amount - в минимальных единицах валюты)
+func (c *Client) SendInvoice(chatID int64, title, description, payload, providerToken, currency string, amount int) error {
+	invoice := tgbotapi.NewInvoice(chatID, title, description, payload, providerToken, "", currency, []tgbotapi.LabeledPrice{
+		{Label: title, Amount: amount},
+	})
+	invoice.SuggestedTipAmounts = []int{}
+	_, err := c.bot.Send(invoice)
+	return err
+}
+
+// AnswerPreCheckoutQuery подтверждает или отклоняет оплату перед списанием
+func (c *Client) AnswerPreCheckoutQuery(preCheckoutQueryID string, ok bool, errorMessage string) error {
+	answer := tgbotapi.PreCheckoutConfig{
+		PreCheckoutQueryID: preCheckoutQueryID,
+		OK:                 ok,
+		ErrorMessage:       errorMessage,
+	}
+	_, err := c.bot.Request(answer)
+	return err
+}
+
 // GetUpdatesChan возвращает канал обновлений
 func (c *Client) GetUpdatesChan() <-chan *Update {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
-	u.AllowedUpdates = []string{"message", "callback_query", "my_chat_member"}
+	u.AllowedUpdates = []string{"message", "callback_query", "my_chat_member", "pre_checkout_query"}
 	updates := c.bot.GetUpdatesChan(u)
 
 	updateChan := make(chan *Update)
@@ -77,9 +149,10 @@ func (c *Client) Username() string {
 
 // Update представляет обновление от Telegram
 type Update struct {
-	Message        *Message
-	CallbackQuery  *CallbackQuery
-	MyChatMember   *ChatMemberUpdate
+	Message          *Message
+	CallbackQuery    *CallbackQuery
+	MyChatMember     *ChatMemberUpdate
+	PreCheckoutQuery *PreCheckoutQuery
 }
 
 // ChatMemberUpdate представляет изменение статуса бота в чате/канале
@@ -94,7 +167,33 @@ type Message struct {
 	MessageID         int
 	Text              string
 	From              *User
-	ForwardFromChatID int64 // ID канала, из которого переслано сообщение (0 если не пересылка)
+	ForwardFromChatID int64              // ID канала, из которого переслано сообщение (0 если не пересылка)
+	SuccessfulPayment *SuccessfulPayment // Сведения об успешной оплате счета (nil для обычных сообщений)
+	Contact           *Contact           // Контакт, которым поделился пользователь (nil для обычных сообщений)
+}
+
+// Contact представляет контакт, отправленный пользователем
+type Contact struct {
+	PhoneNumber string
+	UserID      int64 // Telegram ID владельца контакта (0, если контакт не из Telegram)
+}
+
+// PreCheckoutQuery представляет запрос на подтверждение оплаты перед списанием
+type PreCheckoutQuery struct {
+	ID             string
+	From           *User
+	Currency       string
+	TotalAmount    int
+	InvoicePayload string
+}
+
+// SuccessfulPayment представляет сведения об успешной оплате
+type SuccessfulPayment struct {
+	Currency                string
+	TotalAmount             int
+	InvoicePayload          string
+	TelegramPaymentChargeID string
+	ProviderPaymentChargeID string
 }
 
 // CallbackQuery представляет callback query от Telegram
@@ -182,6 +281,21 @@ func convertUpdate(update tgbotapi.Update) *Update {
 		if update.Message.ForwardFromChat != nil {
 			msg.ForwardFromChatID = update.Message.ForwardFromChat.ID
 		}
+		if payment := update.Message.SuccessfulPayment; payment != nil {
+			msg.SuccessfulPayment = &SuccessfulPayment{
+				Currency:                payment.Currency,
+				TotalAmount:             payment.TotalAmount,
+				InvoicePayload:          payment.InvoicePayload,
+				TelegramPaymentChargeID: payment.TelegramPaymentChargeID,
+				ProviderPaymentChargeID: payment.ProviderPaymentChargeID,
+			}
+		}
+		if contact := update.Message.Contact; contact != nil {
+			msg.Contact = &Contact{
+				PhoneNumber: contact.PhoneNumber,
+				UserID:      contact.UserID,
+			}
+		}
 		result.Message = msg
 	}
 
@@ -192,6 +306,16 @@ func convertUpdate(update tgbotapi.Update) *Update {
 		}
 	}
 
+	if update.PreCheckoutQuery != nil {
+		result.PreCheckoutQuery = &PreCheckoutQuery{
+			ID:             update.PreCheckoutQuery.ID,
+			From:           convertUser(update.PreCheckoutQuery.From),
+			Currency:       update.PreCheckoutQuery.Currency,
+			TotalAmount:    update.PreCheckoutQuery.TotalAmount,
+			InvoicePayload: update.PreCheckoutQuery.InvoicePayload,
+		}
+	}
+
 	if update.CallbackQuery != nil {
 		result.CallbackQuery = &CallbackQuery{
 			ID:   update.CallbackQuery.ID,
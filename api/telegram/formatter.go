@@ -2,9 +2,22 @@ package telegram
 
 import (
 	"fmt"
+	"html"
 	"pickletlgbot/internal/domain/event"
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/pass"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/domain/promo"
+	"pickletlgbot/internal/domain/rating"
+	"pickletlgbot/internal/domain/reminder"
+	"pickletlgbot/internal/domain/restriction"
+	"pickletlgbot/internal/domain/role"
+	"pickletlgbot/internal/domain/series"
+	"pickletlgbot/internal/domain/subscription"
+	"pickletlgbot/internal/domain/tournament"
 	"pickletlgbot/internal/domain/user"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -26,6 +39,15 @@ func (f *Formatter) FormatMainMenu() (string, *InlineKeyboardMarkup) {
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("📅 Список событий", "events"),
 		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔔 Напоминания", "reminders"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📬 Подписка на события", "subs"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("👤 Профиль", "profile"),
+		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("👨‍ Администратор", "admin"),
 		),
@@ -92,7 +114,10 @@ func (f *Formatter) FormatAdminMenu() (string, *InlineKeyboardMarkup) {
 			NewInlineKeyboardButtonData("➕ Создать событие", "admin:create_event"),
 		),
 		NewInlineKeyboardRow(
-			NewInlineKeyboardButtonData("🗑️ Удалить событие", "admin:delete_event"),
+			NewInlineKeyboardButtonData("🔁 Создать серию", "admin:create_series"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🚫 Отменить событие", "admin:delete_event"),
 		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("➕ Создать локацию", "admin:create_location"),
@@ -100,6 +125,9 @@ func (f *Formatter) FormatAdminMenu() (string, *InlineKeyboardMarkup) {
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("📋 Список событий", "admin:list_events"),
 		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔁 Серии событий", "admin:series"),
+		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("📋 Список локаций", "admin:list_locations"),
 		),
@@ -109,6 +137,27 @@ func (f *Formatter) FormatAdminMenu() (string, *InlineKeyboardMarkup) {
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("📢 Настроить канал", "admin:set_channel"),
 		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("⏱ Бронь без оплаты", "admin:pending_timeout"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔔 Напоминания о событиях", "admin:event_reminders"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🚷 Политика неявок", "admin:no_show_policy"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("↩️ Отмена записи", "admin:cancel_policy"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("💸 Должники", "admin:debtors"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🏷 Промокоды", "admin:promos"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("👑 Роли", "admin:roles"),
+		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("🏠 Главное меню", "back:main"),
 		),
@@ -129,6 +178,9 @@ func (f *Formatter) FormatAdminLocationsMenu() (string, *InlineKeyboardMarkup) {
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("📋 Список локаций", "admin:list_locations"),
 		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🎾 Корты", "admin:courts"),
+		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
 		),
@@ -136,6 +188,137 @@ func (f *Formatter) FormatAdminLocationsMenu() (string, *InlineKeyboardMarkup) {
 	return text, keyboard
 }
 
+// FormatCourtLocations форматирует выбор локации для управления кортами
+func (f *Formatter) FormatCourtLocations(locations []location.Location) (string, *InlineKeyboardMarkup) {
+	var rows [][]InlineKeyboardButton
+	for _, loc := range locations {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(loc.Name, fmt.Sprintf("admin:courts:%s", string(loc.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:locations"),
+	))
+
+	text := "🎾 Корты\n\nВыберите локацию:"
+	if len(locations) == 0 {
+		text = "🎾 Корты\n\nСначала создайте локацию"
+	}
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatCourts форматирует список кортов локации с кнопками удаления
+func (f *Formatter) FormatCourts(loc *location.Location, courts []location.Court) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("🎾 Корты локации «%s»\n\n", html.EscapeString(loc.Name))
+	if len(courts) == 0 {
+		text += "Кортов пока нет. Без кортов события локации не бронируют площадку и не проверяются на пересечения."
+	} else {
+		for i, c := range courts {
+			text += fmt.Sprintf("%d. %s\n", i+1, html.EscapeString(c.Name))
+		}
+		text += "\nНажмите на корт, чтобы удалить его."
+	}
+
+	var rows [][]InlineKeyboardButton
+	for i, c := range courts {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🗑 "+c.Name, fmt.Sprintf("admin:court:del:%s:%d", string(loc.ID), i)),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("➕ Добавить корт", fmt.Sprintf("admin:court:add:%s", string(loc.ID))),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:courts"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// paymentModeLabels - названия способов оплаты
+var paymentModeLabels = map[event.PaymentMode]string{
+	event.PaymentModeTransfer: "📱 Перевод по номеру",
+	event.PaymentModeInvoice:  "💳 Счет в Telegram (карта)",
+	event.PaymentModeStars:    "⭐ Telegram Stars",
+}
+
+// FormatPaymentModePicker форматирует выбор способа оплаты события.
+// Кнопки отправляют {prefix}{mode}; счет картой предлагается только при настроенном провайдере
+func (f *Formatter) FormatPaymentModePicker(header, prefix, backData string, invoiceAvailable bool) (string, *InlineKeyboardMarkup) {
+	text := header + "\n\nВыберите способ оплаты:\n\n" +
+		"📱 Перевод — игрок переводит деньги по номеру, администратор подтверждает вручную\n" +
+		"⭐ Stars — игрок оплачивает звездами, регистрация подтверждается автоматически"
+	if invoiceAvailable {
+		text += "\n💳 Счет — игрок оплачивает картой в Telegram, регистрация подтверждается автоматически"
+	}
+
+	modes := []event.PaymentMode{event.PaymentModeTransfer}
+	if invoiceAvailable {
+		modes = append(modes, event.PaymentModeInvoice)
+	}
+	modes = append(modes, event.PaymentModeStars)
+
+	var rows [][]InlineKeyboardButton
+	for _, mode := range modes {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(paymentModeLabels[mode], prefix+string(mode)),
+		))
+	}
+	if backData != "" {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 Назад", backData),
+		))
+	}
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// formatAmount форматирует стоимость в валюте способа оплаты
+func formatAmount(mode event.PaymentMode, price int) string {
+	if mode == event.PaymentModeStars {
+		return fmt.Sprintf("%d ⭐", price)
+	}
+	return fmt.Sprintf("%d руб.", price)
+}
+
+// formatPrice форматирует стоимость события
+func formatPrice(evt *event.Event) string {
+	return formatAmount(evt.EffectivePaymentMode(), evt.Price)
+}
+
+// FormatCourtPicker форматирует выбор кортов на время события: free - свободные корты, selected - уже выбранные.
+// Кнопки отправляют {prefix}court:{номер} и {prefix}courts_done
+func (f *Formatter) FormatCourtPicker(header string, free []location.Court, selected []location.CourtID, prefix string) (string, *InlineKeyboardMarkup) {
+	isSelected := make(map[location.CourtID]bool, len(selected))
+	for _, id := range selected {
+		isSelected[id] = true
+	}
+
+	text := header + "\n\n"
+	if len(free) == 0 {
+		text += "🎾 Все корты локации заняты в это время. Можно продолжить без брони корта или изменить время."
+	} else {
+		text += "🎾 Выберите свободные корты (можно несколько) и нажмите «Готово»:"
+	}
+
+	var rows [][]InlineKeyboardButton
+	for i, c := range free {
+		label := "⬜ " + c.Name
+		if isSelected[c.ID] {
+			label = "✅ " + c.Name
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("%scourt:%d", prefix, i)),
+		))
+	}
+	done := "➡️ Готово"
+	if len(selected) == 0 {
+		done = "➡️ Без корта"
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData(done, prefix+"courts_done"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
 // FormatCreateLocationPrompt форматирует подсказку для создания локации
 func (f *Formatter) FormatCreateLocationPrompt() string {
 	return "📝 Создание новой локации\n\nОтправьте данные локации в формате:\nНазвание|Адрес|URL карты\n\nИли:\nНазвание|Адрес\n\nИли просто название.\n\nПример:\nСпортзал|ул. Ленина, д. 10|https://maps.google.com/..."
@@ -330,6 +513,12 @@ func (f *Formatter) FormatEventsList(events []event.Event, eventType string, loc
 
 		// Компактный формат: Название | Место | Время | 🆓N
 		buttonText := fmt.Sprintf("%s | %s | %s | 🆓%d", evt.Name, locationName, timeStr, freePlaces)
+		if evt.Status == event.StatusDraft {
+			buttonText = "📝 " + buttonText
+		}
+		if evt.Status == event.StatusPublished && evt.Phase(time.Now()) == event.PhaseInProgress {
+			buttonText = "▶️ " + buttonText
+		}
 		// Ограничиваем длину текста кнопки (Telegram рекомендует до 64 символов)
 		if len(buttonText) > 60 {
 			buttonText = buttonText[:57] + "..."
@@ -352,23 +541,167 @@ func (f *Formatter) FormatEventsList(events []event.Event, eventType string, loc
 }
 
 // FormatEventDetails форматирует детали события
-func (f *Formatter) FormatEventDetails(evt event.Event) (string, *InlineKeyboardMarkup) {
+// courtNames - названия забронированных кортов (пусто, если корты не бронировались)
+func (f *Formatter) FormatEventDetails(evt event.Event, courtNames []string) (string, *InlineKeyboardMarkup) {
 	text := fmt.Sprintf("📅 %s\n", evt.Name)
-	text += fmt.Sprintf("🗓️ Дата: %s\n", evt.Date.Format("2006-01-02 15:04"))
-	text += fmt.Sprintf("👥 Мест: %d/%d\n", evt.MaxPlayers-evt.Remaining, evt.MaxPlayers)
+	text += fmt.Sprintf("📌 Статус: %s\n", formatEventStatus(evt.Status))
+	text += fmt.Sprintf("🗓️ Дата: %s–%s\n", evt.Date.Format("2006-01-02 15:04"), formatEndTime(&evt))
+	text += fmt.Sprintf("⏱ Длительность: %s\n", formatDuration(evt.EffectiveDuration()))
+	if evt.Status == event.StatusPublished && evt.Phase(time.Now()) == event.PhaseInProgress {
+		text += "▶️ Идет сейчас\n"
+	}
+	text += fmt.Sprintf("👥 %s: %d/%d\n", capacityLabel(&evt), evt.MaxPlayers-evt.Remaining, evt.MaxPlayers)
+	if evt.Doubles {
+		text += "👥 Категория: парный разряд\n"
+	}
 	text += fmt.Sprintf("📍 Локация ID: %s\n", string(evt.LocationID))
+	if len(courtNames) > 0 {
+		text += fmt.Sprintf("🎾 Корты: %s\n", strings.Join(courtNames, ", "))
+	}
 	if evt.Trainer != "" {
 		text += fmt.Sprintf("👨‍🏫 Тренер: %s\n", evt.Trainer)
 	}
+	if evt.LevelRestricted() {
+		text += fmt.Sprintf("🎯 Уровень: %s\n", formatLevelRange(evt.MinLevel, evt.MaxLevel))
+	}
+	if evt.Price > 0 {
+		text += fmt.Sprintf("💰 Стоимость: %s\n", formatPrice(&evt))
+	}
+	text += fmt.Sprintf("💳 Оплата: %s\n", paymentModeLabels[evt.EffectivePaymentMode()])
 	if evt.Description != "" {
 		text += fmt.Sprintf("📝 %s\n", evt.Description)
 	}
 
 	var rows [][]InlineKeyboardButton
+	if evt.Status == event.StatusDraft {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📢 Опубликовать", fmt.Sprintf("admin:publish:%s", string(evt.ID))),
+		))
+	}
 	rows = append(rows, NewInlineKeyboardRow(
 		NewInlineKeyboardButtonData("✅ Модерация", fmt.Sprintf("admin:event:moderation:%s", string(evt.ID))),
 		NewInlineKeyboardButtonData("👥 Список участников", fmt.Sprintf("event:users:%s", string(evt.ID))),
 	))
+	if evt.Price > 0 {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("💰 Оплаты", fmt.Sprintf("admin:pay:%s", string(evt.ID))),
+		))
+	}
+	if len(evt.Guests) > 0 {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("👤 Гости (%d)", len(evt.Guests)), fmt.Sprintf("admin:guests:%s", string(evt.ID))),
+		))
+	}
+	if evt.SeriesID != "" && evt.Status.IsActive() {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✏️ Изменить занятие", fmt.Sprintf("admin:occ:edit:%s", string(evt.ID))),
+			NewInlineKeyboardButtonData("🚫 Отменить занятие", fmt.Sprintf("admin:occ:cancel:%s", string(evt.ID))),
+		))
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✏️ Редактировать только это занятие", fmt.Sprintf("admin:edit:%s", string(evt.ID))),
+		))
+	} else if evt.Status.IsActive() {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✏️ Редактировать", fmt.Sprintf("admin:edit:%s", string(evt.ID))),
+			NewInlineKeyboardButtonData("🚫 Отменить событие", fmt.Sprintf("admin:cancel_event:%s", string(evt.ID))),
+		))
+	}
+	if evt.AttendanceTracked() {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📋 Посещаемость", fmt.Sprintf("admin:att:%s", string(evt.ID))),
+		))
+	}
+	if evt.Type == event.EventTypeCompetition && evt.Status != event.StatusCancelled {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🏆 Турнир", fmt.Sprintf("admin:trn:%s", string(evt.ID))),
+		))
+	}
+	if evt.SeriesID != "" {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔁 Серия", fmt.Sprintf("admin:series:%s", evt.SeriesID)),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
+	))
+
+	keyboard := NewInlineKeyboardMarkup(rows...)
+	return text, keyboard
+}
+
+// formatEndTime возвращает время окончания события (с датой, если событие заканчивается на следующий день)
+func formatEndTime(evt *event.Event) string {
+	end := evt.EndsAt()
+	if end.Year() == evt.Date.Year() && end.YearDay() == evt.Date.YearDay() {
+		return end.Format("15:04")
+	}
+	return end.Format("02.01 15:04")
+}
+
+// capacityLabel возвращает подпись для количества мест (в парной категории места считаются в командах)
+func capacityLabel(evt *event.Event) string {
+	if evt.Doubles {
+		return "Команд"
+	}
+	return "Мест"
+}
+
+// formatLevelRange возвращает диапазон уровней вида "3.0–4.0", "от 3.0" или "до 4.0"
+func formatLevelRange(min, max user.Level) string {
+	switch {
+	case min.IsSet() && max.IsSet():
+		return fmt.Sprintf("%s–%s", min, max)
+	case min.IsSet():
+		return fmt.Sprintf("от %s", min)
+	case max.IsSet():
+		return fmt.Sprintf("до %s", max)
+	default:
+		return "любой"
+	}
+}
+
+// formatEventStatus возвращает название статуса события для администратора
+func formatEventStatus(status event.Status) string {
+	switch status {
+	case event.StatusDraft:
+		return "📝 Черновик"
+	case event.StatusPublished:
+		return "📢 Опубликовано"
+	case event.StatusCancelled:
+		return "🚫 Отменено"
+	case event.StatusCompleted:
+		return "🏁 Завершено"
+	default:
+		return string(status)
+	}
+}
+
+// FormatSeriesList форматирует список повторяющихся серий
+func (f *Formatter) FormatSeriesList(list []series.EventSeries) (string, *InlineKeyboardMarkup) {
+	if len(list) == 0 {
+		text := "📋 Нет серий событий"
+		keyboard := NewInlineKeyboardMarkup(
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔁 Создать серию", "admin:create_series"),
+			),
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
+			),
+		)
+		return text, keyboard
+	}
+
+	text := "🔁 Серии событий:"
+	var rows [][]InlineKeyboardButton
+	for _, s := range list {
+		buttonText := fmt.Sprintf("%s | %s %02d:%02d", s.Name, formatWeekdays(s.Recurrence.Weekdays), s.Recurrence.Time.Hour, s.Recurrence.Time.Minute)
+		if len(buttonText) > 60 {
+			buttonText = buttonText[:57] + "..."
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("admin:series:%s", string(s.ID))),
+		))
+	}
 	rows = append(rows, NewInlineKeyboardRow(
 		NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
 	))
@@ -377,15 +710,58 @@ func (f *Formatter) FormatEventDetails(evt event.Event) (string, *InlineKeyboard
 	return text, keyboard
 }
 
+// FormatSeriesDetails форматирует детали серии и список ее ближайших занятий
+func (f *Formatter) FormatSeriesDetails(s *series.EventSeries, events []event.Event) (string, *InlineKeyboardMarkup) {
+	r := s.Recurrence
+	text := fmt.Sprintf("🔁 %s\n", s.Name)
+	text += fmt.Sprintf("📆 %s в %02d:%02d\n", formatWeekdays(r.Weekdays), r.Time.Hour, r.Time.Minute)
+	text += fmt.Sprintf("🗓️ С %s", r.StartDate.Format("02.01.2006"))
+	switch {
+	case !r.Until.IsZero():
+		text += fmt.Sprintf(" по %s", r.Until.Format("02.01.2006"))
+	case r.Count > 0:
+		text += fmt.Sprintf(", %d занятий", r.Count)
+	}
+	text += "\n"
+	text += fmt.Sprintf("👥 Мест: %d\n", s.MaxPlayers)
+	if s.Trainer != "" {
+		text += fmt.Sprintf("👨‍🏫 Тренер: %s\n", s.Trainer)
+	}
+	text += fmt.Sprintf("📆 Занятия создаются на %d нед. вперед\n", s.WeeksAhead)
+
+	var rows [][]InlineKeyboardButton
+	if len(events) > 0 {
+		text += "\nЗанятия:"
+	}
+	for _, evt := range events {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s | 🆓%d", evt.Date.Format("02.01.2006 15:04"), evt.Remaining),
+				fmt.Sprintf("admin:event:%s", string(evt.ID)),
+			),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("⏹ Остановить серию", fmt.Sprintf("admin:series:stop:%s", string(s.ID))),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К сериям", "admin:series"),
+	))
+
+	keyboard := NewInlineKeyboardMarkup(rows...)
+	return text, keyboard
+}
+
 // RegistrationWithUser представляет регистрацию с данными пользователя
 type RegistrationWithUser struct {
 	Registration event.EventRegistration
 	UserName     string
 	UserSurname  string
+	PartnerName  string // Имя и фамилия напарника (для команд парной категории)
 }
 
 // FormatPendingRegistrations форматирует список ожидающих регистраций
-func (f *Formatter) FormatPendingRegistrations(eventName string, registrations []RegistrationWithUser) (string, *InlineKeyboardMarkup) {
+func (f *Formatter) FormatPendingRegistrations(eventName string, registrations []RegistrationWithUser, eventID string) (string, *InlineKeyboardMarkup) {
 	if len(registrations) == 0 {
 		text := fmt.Sprintf("✅ Нет заявок на модерацию для события:\n📅 %s", eventName)
 		keyboard := NewInlineKeyboardMarkup(
@@ -416,6 +792,9 @@ func (f *Formatter) FormatPendingRegistrations(eventName string, registrations [
 		if item.UserName != "" || item.UserSurname != "" {
 			userInfo = fmt.Sprintf("%s %s", item.UserName, item.UserSurname)
 		}
+		if item.PartnerName != "" {
+			userInfo += " + " + item.PartnerName
+		}
 
 		text += fmt.Sprintf("👤 Пользователь: %s\n⏰ %s\n\n", userInfo, timeStr)
 
@@ -429,7 +808,7 @@ func (f *Formatter) FormatPendingRegistrations(eventName string, registrations [
 		rows = append(rows, NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData(
 				buttonText,
-				fmt.Sprintf("admin:reg:%d", reg.UserID),
+				fmt.Sprintf("admin:reg:%s:%d", eventID, reg.UserID),
 			),
 		))
 	}
@@ -443,19 +822,30 @@ func (f *Formatter) FormatPendingRegistrations(eventName string, registrations [
 }
 
 // FormatRegistrationModeration форматирует модерацию конкретной регистрации
-func (f *Formatter) FormatRegistrationModeration(eventName string, userID int64, userName, userSurname string, eventID string) (string, *InlineKeyboardMarkup) {
+func (f *Formatter) FormatRegistrationModeration(eventName string, userID int64, userName, userSurname string, level user.Level, eventID string) (string, *InlineKeyboardMarkup) {
 	// Формируем текст с именем и фамилией, если они доступны
 	userInfo := fmt.Sprintf("ID: %d", userID)
 	if userName != "" || userSurname != "" {
 		userInfo = fmt.Sprintf("%s %s (ID: %d)", userName, userSurname, userID)
 	}
+	levelStr := "не определен"
+	if level.IsSet() {
+		levelStr = level.String()
+	}
 
-	text := fmt.Sprintf("🔔 Модерация регистрации\n\n📅 Событие: %s\n👤 Пользователь: %s\n\nВыберите действие:", eventName, userInfo)
+	text := fmt.Sprintf("🔔 Модерация регистрации\n\n📅 Событие: %s\n👤 Пользователь: %s\n🎯 Уровень: %s\n\nВыберите действие:", eventName, userInfo, levelStr)
 	keyboard := NewInlineKeyboardMarkup(
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("✅ Подтвердить", fmt.Sprintf("admin:reg:approve:%s:%d", eventID, userID)),
 			NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("admin:reg:reject:%s:%d", eventID, userID)),
 		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("💳 Подтвердить с оплатой", fmt.Sprintf("admin:reg:paid:%s:%d", eventID, userID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🎯 Изменить уровень", fmt.Sprintf("admin:level:%d", userID)),
+			NewInlineKeyboardButtonData("👤 Карточка игрока", fmt.Sprintf("admin:usr:%d", userID)),
+		),
 		NewInlineKeyboardRow(
 			NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("admin:event:moderation:%s", eventID)),
 		),
@@ -464,12 +854,13 @@ func (f *Formatter) FormatRegistrationModeration(eventName string, userID int64,
 }
 
 // FormatEventsListForUsers форматирует список событий для пользователей
-func (f *Formatter) FormatEventsListForUsers(events []event.Event, locationNames map[location.LocationID]string) (string, *InlineKeyboardMarkup) {
-	return f.FormatEventsListForUsersWithBack(events, locationNames, "back:main", "🏠 Главное меню")
+func (f *Formatter) FormatEventsListForUsers(events []event.Event, locationNames map[location.LocationID]string, viewerLevel user.Level) (string, *InlineKeyboardMarkup) {
+	return f.FormatEventsListForUsersWithBack(events, locationNames, viewerLevel, "back:main", "🏠 Главное меню")
 }
 
-// FormatEventsListForUsersWithBack форматирует список событий для пользователей с кастомной кнопкой "Назад"
-func (f *Formatter) FormatEventsListForUsersWithBack(events []event.Event, locationNames map[location.LocationID]string, backCallback, backText string) (string, *InlineKeyboardMarkup) {
+// FormatEventsListForUsersWithBack форматирует список событий для пользователей с кастомной кнопкой "Назад".
+// События с ограничением по уровню помечаются: 🎯 - уровень зрителя подходит, 🔒 - не подходит
+func (f *Formatter) FormatEventsListForUsersWithBack(events []event.Event, locationNames map[location.LocationID]string, viewerLevel user.Level, backCallback, backText string) (string, *InlineKeyboardMarkup) {
 	if len(events) == 0 {
 		text := "📋 Нет доступных событий"
 		keyboard := NewInlineKeyboardMarkup(
@@ -481,9 +872,10 @@ func (f *Formatter) FormatEventsListForUsersWithBack(events []event.Event, locat
 	}
 
 	text := "📅 Доступные события:"
+	restricted := false
 	var rows [][]InlineKeyboardButton
 	for _, evt := range events {
-		timeStr := evt.Date.Format("15:04")
+		timeStr := evt.Date.Format("15:04") + "–" + formatEndTime(&evt)
 		locationName := locationNames[evt.LocationID]
 		if locationName == "" {
 			locationName = string(evt.LocationID)
@@ -492,6 +884,17 @@ func (f *Formatter) FormatEventsListForUsersWithBack(events []event.Event, locat
 
 		// Компактный формат: Название | Место | Время | 🆓N
 		buttonText := fmt.Sprintf("%s | %s | %s | 🆓%d", evt.Name, locationName, timeStr, freePlaces)
+		if evt.Status == event.StatusDraft {
+			buttonText = "📝 " + buttonText
+		}
+		if evt.LevelRestricted() {
+			restricted = true
+			if evt.LevelAllows(viewerLevel) {
+				buttonText = "🎯 " + buttonText
+			} else {
+				buttonText = "🔒 " + buttonText
+			}
+		}
 		// Ограничиваем длину текста кнопки (Telegram рекомендует до 64 символов)
 		if len(buttonText) > 60 {
 			buttonText = buttonText[:57] + "..."
@@ -505,6 +908,13 @@ func (f *Formatter) FormatEventsListForUsersWithBack(events []event.Event, locat
 		))
 	}
 
+	if restricted {
+		text += "\n\n🎯 — подходит ваш уровень, 🔒 — событие для другого уровня"
+		if !viewerLevel.IsSet() {
+			text += "\nВаш уровень пока не определен — его устанавливает администратор."
+		}
+	}
+
 	rows = append(rows, NewInlineKeyboardRow(
 		NewInlineKeyboardButtonData(backText, backCallback),
 	))
@@ -514,7 +924,7 @@ func (f *Formatter) FormatEventsListForUsersWithBack(events []event.Event, locat
 }
 
 // FormatEventDetailsForUsers форматирует детали события для пользователей
-func (f *Formatter) FormatEventDetailsForUsers(evt *event.Event, userID int64) (string, *InlineKeyboardMarkup) {
+func (f *Formatter) FormatEventDetailsForUsers(evt *event.Event, userID int64, viewerLevel user.Level, cancel event.CancellationPolicy) (string, *InlineKeyboardMarkup) {
 	typeEmoji := "🏋️"
 	typeName := "Тренировка"
 	if evt.Type == event.EventTypeCompetition {
@@ -522,19 +932,57 @@ func (f *Formatter) FormatEventDetailsForUsers(evt *event.Event, userID int64) (
 		typeName = "Соревнование"
 	}
 
+	if evt.Doubles {
+		typeName += " (парный разряд)"
+	}
+
 	text := fmt.Sprintf("%s %s\n\n", typeEmoji, evt.Name)
 	text += fmt.Sprintf("📅 Тип: %s\n", typeName)
-	text += fmt.Sprintf("🗓️ Дата: %s\n", evt.Date.Format("02.01.2006 15:04"))
-	text += fmt.Sprintf("👥 Мест: %d/%d\n", evt.MaxPlayers-evt.Remaining, evt.MaxPlayers)
+	text += fmt.Sprintf("🗓️ Дата: %s–%s\n", evt.Date.Format("02.01.2006 15:04"), formatEndTime(evt))
+	text += fmt.Sprintf("⏱ Длительность: %s\n", formatDuration(evt.EffectiveDuration()))
+	text += fmt.Sprintf("👥 %s: %d/%d\n", capacityLabel(evt), evt.MaxPlayers-evt.Remaining, evt.MaxPlayers)
 	if evt.Trainer != "" {
 		text += fmt.Sprintf("👨‍🏫 Тренер: %s\n", evt.Trainer)
 	}
+	if evt.LevelRestricted() {
+		text += fmt.Sprintf("🎯 Уровень: %s\n", formatLevelRange(evt.MinLevel, evt.MaxLevel))
+	}
 	if evt.Description != "" {
 		text += fmt.Sprintf("📝 %s\n", evt.Description)
 	}
 
+	if waitlist := evt.Waitlist(); len(waitlist) > 0 {
+		text += fmt.Sprintf("🕒 В листе ожидания: %d\n", len(waitlist))
+	}
+	if evt.Doubles {
+		text += "👥 Запись парами: после записи пригласите напарника по ссылке\n"
+	}
+	if cancel.Enabled() && evt.IsOpen(time.Now()) {
+		text += formatCancellationPolicy(cancel, evt.Date) + "\n"
+	}
+
 	var rows [][]InlineKeyboardButton
 
+	// Отмененные и прошедшие события показываем без кнопок записи
+	if !evt.IsOpen(time.Now()) {
+		switch evt.Status {
+		case event.StatusCancelled:
+			text += "\n🚫 Событие отменено"
+		case event.StatusDraft:
+			text += "\n📝 Событие еще не опубликовано"
+		default:
+			if evt.Phase(time.Now()) == event.PhaseInProgress {
+				text += fmt.Sprintf("\n▶️ Событие уже идет (до %s), запись закрыта", formatEndTime(evt))
+			} else {
+				text += "\n🏁 Событие завершилось"
+			}
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 К списку событий", "events"),
+		))
+		return text, NewInlineKeyboardMarkup(rows...)
+	}
+
 	// Проверяем статус регистрации пользователя
 	reg, isRegistered := evt.Registrations[userID]
 	if isRegistered {
@@ -542,21 +990,57 @@ func (f *Formatter) FormatEventDetailsForUsers(evt *event.Event, userID int64) (
 		case event.RegistrationStatusPending:
 			text += "\n⏳ Ваша заявка ожидает подтверждения"
 			rows = append(rows, NewInlineKeyboardRow(
-				NewInlineKeyboardButtonData("❌ Отменить заявку", fmt.Sprintf("event:unregister:%s", string(evt.ID))),
+				NewInlineKeyboardButtonData("❌ Отменить заявку", fmt.Sprintf("event:unreg_ask:%s", string(evt.ID))),
 			))
 		case event.RegistrationStatusApproved:
 			text += "\n✅ Вы зарегистрированы на это событие"
 			rows = append(rows, NewInlineKeyboardRow(
-				NewInlineKeyboardButtonData("❌ Отменить регистрацию", fmt.Sprintf("event:unregister:%s", string(evt.ID))),
+				NewInlineKeyboardButtonData("❌ Отменить регистрацию", fmt.Sprintf("event:unreg_ask:%s", string(evt.ID))),
+			))
+		case event.RegistrationStatusAwaitingPartner:
+			text += "\n👥 Команда собирается: отправьте напарнику ссылку-приглашение. Место займется, когда он примет приглашение"
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔗 Ссылка для напарника", fmt.Sprintf("event:invite:%s", string(evt.ID))),
+			))
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("❌ Отменить запись", fmt.Sprintf("event:unreg_ask:%s", string(evt.ID))),
+			))
+		case event.RegistrationStatusWaitlisted:
+			text += fmt.Sprintf("\n🕒 Вы в листе ожидания, ваша позиция: %d", reg.WaitlistPosition)
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("❌ Покинуть лист ожидания", fmt.Sprintf("event:unreg_ask:%s", string(evt.ID))),
 			))
-		case event.RegistrationStatusRejected:
-			text += "\n❌ Ваша заявка была отклонена"
-			if evt.Remaining > 0 {
-				rows = append(rows, NewInlineKeyboardRow(
-					NewInlineKeyboardButtonData("🔄 Подать заявку снова", fmt.Sprintf("event:register:%s", string(evt.ID))),
-				))
+		case event.RegistrationStatusRejected, event.RegistrationStatusExpired, event.RegistrationStatusLateCancelled:
+			switch reg.Status {
+			case event.RegistrationStatusExpired:
+				text += "\n⌛ Ваша бронь была снята: оплата не подтверждена вовремя"
+			case event.RegistrationStatusLateCancelled:
+				text += "\n↩️ Вы отменили запись после срока бесплатной отмены"
+			default:
+				text += "\n❌ Ваша заявка была отклонена"
+			}
+			buttonText := "🔄 Подать заявку снова"
+			if evt.Remaining <= 0 {
+				buttonText = "🕒 Встать в лист ожидания"
 			}
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("event:register:%s", string(evt.ID))),
+			))
+		}
+		if reg.Status.HoldsSpot() && !evt.Doubles {
+			text += formatHostGuests(evt, userID)
+			rows = append(rows, hostGuestButtons(evt, userID)...)
+		}
+	} else if !evt.LevelAllows(viewerLevel) {
+		// Уровень не подходит - можно попросить администратора допустить
+		levelStr := "не определен"
+		if viewerLevel.IsSet() {
+			levelStr = viewerLevel.String()
 		}
+		text += fmt.Sprintf("\n🔒 Ваш уровень (%s) не подходит для этого события", levelStr)
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🙋 Попросить допуск", fmt.Sprintf("event:level_request:%s", string(evt.ID))),
+		))
 	} else {
 		// Пользователь не зарегистрирован
 		if evt.Remaining > 0 {
@@ -565,6 +1049,9 @@ func (f *Formatter) FormatEventDetailsForUsers(evt *event.Event, userID int64) (
 			))
 		} else {
 			text += "\n❌ Все места заняты"
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🕒 Встать в лист ожидания", fmt.Sprintf("event:register:%s", string(evt.ID))),
+			))
 		}
 	}
 
@@ -572,6 +1059,11 @@ func (f *Formatter) FormatEventDetailsForUsers(evt *event.Event, userID int64) (
 	rows = append(rows, NewInlineKeyboardRow(
 		NewInlineKeyboardButtonData("👥 Список участников", fmt.Sprintf("event:users:%s", string(evt.ID))),
 	))
+	if evt.Type == event.EventTypeCompetition {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🏆 Сетка турнира", fmt.Sprintf("event:bracket:%s", string(evt.ID))),
+		))
+	}
 
 	rows = append(rows, NewInlineKeyboardRow(
 		NewInlineKeyboardButtonData("🔙 К списку событий", "events"),
@@ -590,18 +1082,25 @@ func (f *Formatter) FormatChannelEventAnnouncement(evt *event.Event, locationNam
 		typeName = "Соревнование"
 	}
 
+	if evt.Doubles {
+		typeName += " (парный разряд)"
+	}
+
 	text := fmt.Sprintf("%s <b>%s</b>\n\n", typeEmoji, evt.Name)
 	text += fmt.Sprintf("📅 Тип: %s\n", typeName)
-	text += fmt.Sprintf("🗓️ Дата: %s\n", evt.Date.Format("02.01.2006 15:04"))
-	text += fmt.Sprintf("👥 Мест: %d\n", evt.MaxPlayers)
+	text += fmt.Sprintf("🗓️ Дата: %s–%s\n", evt.Date.Format("02.01.2006 15:04"), formatEndTime(evt))
+	text += fmt.Sprintf("👥 %s: %d\n", capacityLabel(evt), evt.MaxPlayers)
 	if locationName != "" {
 		text += fmt.Sprintf("📍 Место: %s\n", locationName)
 	}
 	if evt.Trainer != "" {
 		text += fmt.Sprintf("👨‍🏫 Тренер: %s\n", evt.Trainer)
 	}
+	if evt.LevelRestricted() {
+		text += fmt.Sprintf("🎯 Уровень: %s\n", formatLevelRange(evt.MinLevel, evt.MaxLevel))
+	}
 	if evt.Price > 0 {
-		text += fmt.Sprintf("💰 Стоимость: %d руб.\n", evt.Price)
+		text += fmt.Sprintf("💰 Стоимость: %s\n", formatPrice(evt))
 	}
 
 	deepLink := fmt.Sprintf("https://t.me/%s?start=event_%s", botUsername, string(evt.ID))
@@ -628,30 +1127,355 @@ func (f *Formatter) FormatChannelUserRegistered(evt *event.Event, userName strin
 	)
 }
 
-// FormatChannelEventCancelled форматирует уведомление об отмене события для канала
-func (f *Formatter) FormatChannelEventCancelled(evt *event.Event) string {
-	typeEmoji := "🏋️"
-	if evt.Type == event.EventTypeCompetition {
-		typeEmoji = "🏆"
-	}
-	return fmt.Sprintf("❌ <b>Событие отменено</b>\n\n%s %s\n🗓️ %s", typeEmoji, evt.Name, evt.Date.Format("02.01.2006 15:04"))
+// FormatTeamInviteLink форматирует ссылку-приглашение, которую капитан отправляет напарнику
+func (f *Formatter) FormatTeamInviteLink(evt *event.Event, captainID int64, botUsername string) (string, *InlineKeyboardMarkup) {
+	link := fmt.Sprintf("https://t.me/%s?start=team_%s_%d", botUsername, string(evt.ID), captainID)
+	text := fmt.Sprintf(
+		"👥 Приглашение в команду\n\n📅 %s\n🗓️ %s\n\nПерешлите напарнику эту ссылку:\n%s\n\nКоманда займет место, когда напарник примет приглашение.",
+		evt.Name, evt.Date.Format("02.01.2006 15:04"), link,
+	)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 Открыть событие", fmt.Sprintf("event:%s", string(evt.ID))),
+		),
+	)
+	return text, keyboard
 }
 
-// UserWithStatus представляет пользователя со статусом регистрации
-type UserWithStatus struct {
-	User   *user.User
-	Status event.RegistrationStatus
-}
+// FormatTeamInvite форматирует приглашение в команду, которое видит напарник
+func (f *Formatter) FormatTeamInvite(evt *event.Event, captainID int64, captainName string) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("👥 %s приглашает вас в команду\n\n🏆 %s\n🗓️ %s\n", captainName, evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	if evt.LevelRestricted() {
+		text += fmt.Sprintf("🎯 Уровень: %s\n", formatLevelRange(evt.MinLevel, evt.MaxLevel))
+	}
+	if evt.Price > 0 {
+		text += fmt.Sprintf("💰 Стоимость: %s с игрока\n", formatPrice(evt))
+	}
+	text += fmt.Sprintf("👥 Свободно команд: %d из %d", evt.Remaining, evt.MaxPlayers)
 
-// FormatEventUsersList форматирует список участников события
-func (f *Formatter) FormatEventUsersList(eventName string, usersWithStatus []UserWithStatus, eventID string) (string, *InlineKeyboardMarkup) {
-	text := fmt.Sprintf("👥 Участники события: %s\n\n", eventName)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✅ Принять", fmt.Sprintf("event:tm_ok:%s:%d", string(evt.ID), captainID)),
+			NewInlineKeyboardButtonData("❌ Отказаться", fmt.Sprintf("event:tm_no:%s:%d", string(evt.ID), captainID)),
+		),
+	)
+	return text, keyboard
+}
 
-	if len(usersWithStatus) == 0 {
-		text += "📭 Пока нет зарегистрированных участников"
-	} else {
+// FormatPaymentReminder форматирует напоминание об оплате перед снятием брони
+func (f *Formatter) FormatPaymentReminder(evt *event.Event, left time.Duration) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf(
+		"⏰ Напоминание об оплате\n\n📅 %s\n🗓️ %s\n\nОплата пока не подтверждена. Бронь будет снята через %s.\n\n%s",
+		evt.Name,
+		evt.Date.Format("02.01.2006 15:04"),
+		formatMinutes(left),
+		paymentReminderHint(evt),
+	)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 Открыть событие", fmt.Sprintf("event:%s", string(evt.ID))),
+		),
+	)
+	return text, keyboard
+}
+
+// paymentReminderHint подсказывает, как завершить оплату, в зависимости от способа оплаты события
+func paymentReminderHint(evt *event.Event) string {
+	if invoiceAvailable(evt) {
+		return "Оплатите счет ниже — регистрация подтвердится автоматически."
+	}
+	return "Если вы уже оплатили, дождитесь подтверждения администратора."
+}
+
+// FormatEventReminder форматирует напоминание о предстоящем событии.
+// paymentPhone передается только для неоплаченных регистраций
+func (f *Formatter) FormatEventReminder(evt *event.Event, loc *location.Location, left time.Duration, kind reminder.Kind, paymentPhone string) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("⏰ Напоминание: до начала %s\n\n📅 %s\n🗓️ %s\n",
+		formatDuration(left), evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	if loc != nil {
+		text += fmt.Sprintf("📍 %s\n", loc.Name)
+		if loc.Address != "" {
+			text += fmt.Sprintf("🏠 %s\n", loc.Address)
+		}
+	}
+	if evt.Trainer != "" {
+		text += fmt.Sprintf("👨‍🏫 Тренер: %s\n", evt.Trainer)
+	}
+	if paymentPhone != "" {
+		text += fmt.Sprintf("\n💳 Оплата еще не подтверждена.\n📱 Перевод на номер: <code>%s</code>", paymentPhone)
+		if evt.Price > 0 {
+			text += fmt.Sprintf("\n💰 Сумма: <code>%d руб.</code>", evt.Price)
+		}
+		text += "\n"
+	}
+
+	var rows [][]InlineKeyboardButton
+	if loc != nil && loc.AddressMapURL != "" {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonURL("🗺️ Открыть карту", loc.AddressMapURL),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("📅 Открыть событие", fmt.Sprintf("event:%s", string(evt.ID))),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData(fmt.Sprintf("🔕 Не напоминать за %s", formatReminderKind(kind)), fmt.Sprintf("reminders:off:%s", string(kind))),
+	))
+
+	keyboard := NewInlineKeyboardMarkup(rows...)
+	return text, keyboard
+}
+
+// FormatReminderSettings форматирует настройки напоминаний пользователя
+func (f *Formatter) FormatReminderSettings(offsets []time.Duration, optOuts map[reminder.Kind]bool) (string, *InlineKeyboardMarkup) {
+	var rows [][]InlineKeyboardButton
+	text := "🔔 Напоминания о событиях\n\n"
+	if len(offsets) == 0 {
+		text += "Напоминания сейчас отключены администратором."
+	} else {
+		text += "Бот напоминает о событиях, на которые вы записаны. Нажмите, чтобы включить или отключить:"
+		for _, offset := range offsets {
+			kind := reminder.KindFor(offset)
+			label := fmt.Sprintf("✅ За %s", formatReminderKind(kind))
+			if optOuts[kind] {
+				label = fmt.Sprintf("🔕 За %s", formatReminderKind(kind))
+			}
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData(label, fmt.Sprintf("reminders:toggle:%s", string(kind))),
+			))
+		}
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🏠 Главное меню", "back:main"),
+	))
+
+	keyboard := NewInlineKeyboardMarkup(rows...)
+	return text, keyboard
+}
+
+// formatReminderKind форматирует тип напоминания для пользователя ("24h" -> "24 ч")
+func formatReminderKind(kind reminder.Kind) string {
+	offset, err := kind.Offset()
+	if err != nil {
+		return string(kind)
+	}
+	return formatDuration(offset)
+}
+
+// formatDuration форматирует длительность в часах и минутах
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	switch {
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%d ч %d мин", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%d ч", hours)
+	default:
+		return formatMinutes(d)
+	}
+}
+
+// FormatHoldExpired форматирует уведомление о снятии неоплаченной брони
+func (f *Formatter) FormatHoldExpired(evt *event.Event) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf(
+		"⌛ Бронь снята\n\n📅 %s\n🗓️ %s\n\nОплата не была подтверждена вовремя, место освобождено. Вы можете записаться снова.",
+		evt.Name,
+		evt.Date.Format("02.01.2006 15:04"),
+	)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 Открыть событие", fmt.Sprintf("event:%s", string(evt.ID))),
+		),
+	)
+	return text, keyboard
+}
+
+// FormatChannelEventCancelled форматирует уведомление об отмене события для канала
+func (f *Formatter) FormatChannelEventCancelled(evt *event.Event) string {
+	typeEmoji := "🏋️"
+	if evt.Type == event.EventTypeCompetition {
+		typeEmoji = "🏆"
+	}
+	return fmt.Sprintf("❌ <b>Событие отменено</b>\n\n%s %s\n🗓️ %s", typeEmoji, evt.Name, evt.Date.Format("02.01.2006 15:04"))
+}
+
+// FormatEventCancelledForUser форматирует личное уведомление об отмене события
+func (f *Formatter) FormatEventCancelledForUser(evt *event.Event) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf(
+		"🚫 <b>Событие отменено</b>\n\n📅 %s\n🗓️ %s\n\nВаша запись отменена. Если вы уже оплатили участие, свяжитесь с администратором для возврата.",
+		evt.Name,
+		evt.Date.Format("02.01.2006 15:04"),
+	)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📅 Другие события", "events"),
+		),
+	)
+	return text, keyboard
+}
+
+// FormatEventChangedForUser форматирует личное уведомление об изменении даты, времени или места события
+func (f *Formatter) FormatEventChangedForUser(evt *event.Event, changes []string) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("✏️ <b>Событие изменено</b>\n\n📅 %s\n\n%s\n\nВаша запись сохранена. Если новое время или место вам не подходит, отмените запись.",
+		evt.Name, strings.Join(changes, "\n"))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📋 Подробнее", fmt.Sprintf("event:%s", string(evt.ID))),
+		),
+	)
+	return text, keyboard
+}
+
+// AttendanceEntry - строка чек-листа посещаемости
+type AttendanceEntry struct {
+	UserID      int64
+	Name        string
+	Attendance  event.Attendance
+	CheckedInAt time.Time
+}
+
+// FormatAttendanceChecklist форматирует чек-лист посещаемости подтвержденных игроков
+func (f *Formatter) FormatAttendanceChecklist(evt *event.Event, entries []AttendanceEntry) (string, *InlineKeyboardMarkup) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	present, noShow := 0, 0
+	for _, e := range entries {
+		switch e.Attendance {
+		case event.AttendancePresent:
+			present++
+		case event.AttendanceNoShow:
+			noShow++
+		}
+	}
+
+	text := fmt.Sprintf("📋 Посещаемость: %s\n🗓️ %s\n\n", evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	if len(entries) == 0 {
+		text += "Нет подтвержденных игроков"
+	} else {
+		text += fmt.Sprintf("✅ Пришли: %d  ❌ Не пришли: %d  ⬜ Не отмечены: %d\n\n", present, noShow, len(entries)-present-noShow)
+		text += "Нажмите на игрока, чтобы изменить отметку."
+	}
+
+	var rows [][]InlineKeyboardButton
+	for _, e := range entries {
+		mark := "⬜"
+		switch e.Attendance {
+		case event.AttendancePresent:
+			mark = "✅"
+		case event.AttendanceNoShow:
+			mark = "❌"
+		}
+		label := fmt.Sprintf("%s %s", mark, e.Name)
+		if !e.CheckedInAt.IsZero() {
+			label += fmt.Sprintf(" (📲 %s)", e.CheckedInAt.Format("15:04"))
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("admin:att:t:%s:%d", string(evt.ID), e.UserID)),
+		))
+	}
+	if len(entries) > present+noShow {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("❌ Остальные не пришли", fmt.Sprintf("admin:att:rest:%s", string(evt.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("📲 Ссылка для отметки на месте", fmt.Sprintf("admin:att:link:%s", string(evt.ID))),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("admin:event:%s", string(evt.ID))),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatCheckInLink форматирует ссылку для самостоятельной отметки игроков на месте
+func (f *Formatter) FormatCheckInLink(evt *event.Event, botUsername string) string {
+	link := fmt.Sprintf("https://t.me/%s?start=checkin_%s", botUsername, string(evt.ID))
+	return fmt.Sprintf("📲 Отметка на месте: <b>%s</b>\n\n%s\n\n"+
+		"Покажите ссылку игрокам или сделайте из нее QR-код. Отметиться можно за %d мин до начала и в течение %d ч после.",
+		evt.Name, link, int(event.CheckInOpensBefore/time.Minute), int(event.CheckInClosesAfter/time.Hour))
+}
+
+// FormatLevelRequest форматирует запрос игрока на допуск к событию другого уровня (для администраторов)
+func (f *Formatter) FormatLevelRequest(evt *event.Event, usr *user.User) (string, *InlineKeyboardMarkup) {
+	levelStr := "не определен"
+	if usr.Level.IsSet() {
+		levelStr = usr.Level.String()
+	}
+	text := fmt.Sprintf("🙋 Запрос на допуск\n\n👤 %s %s (ID: %d)\n🎯 Уровень игрока: %s\n\n📅 %s\n🗓️ %s\n🎯 Уровень события: %s",
+		usr.Name, usr.Surname, usr.TelegramID, levelStr,
+		evt.Name, evt.Date.Format("02.01.2006 15:04"), formatLevelRange(evt.MinLevel, evt.MaxLevel))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✅ Допустить и записать", fmt.Sprintf("admin:lvok:%s:%d", string(evt.ID), usr.TelegramID)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🎯 Изменить уровень игрока", fmt.Sprintf("admin:level:%d", usr.TelegramID)),
+		),
+	)
+	return text, keyboard
+}
+
+// FormatLevelPicker форматирует выбор уровня игрока для администратора
+func (f *Formatter) FormatLevelPicker(usr *user.User) (string, *InlineKeyboardMarkup) {
+	levelStr := "не определен"
+	if usr.Level.IsSet() {
+		levelStr = usr.Level.String()
+	}
+	text := fmt.Sprintf("🎯 Уровень игрока %s %s\n\nСейчас: %s\n\nВыберите новый уровень:", usr.Name, usr.Surname, levelStr)
+
+	var rows [][]InlineKeyboardButton
+	var row []InlineKeyboardButton
+	for _, l := range user.Levels() {
+		label := l.String()
+		if l == usr.Level {
+			label = "• " + label
+		}
+		row = append(row, NewInlineKeyboardButtonData(label, fmt.Sprintf("admin:level:set:%d:%d", usr.TelegramID, int(l))))
+		if len(row) == 4 {
+			rows = append(rows, NewInlineKeyboardRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, NewInlineKeyboardRow(row...))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("♻️ Сбросить уровень", fmt.Sprintf("admin:level:set:%d:0", usr.TelegramID)),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// UserWithStatus представляет пользователя со статусом регистрации
+type UserWithStatus struct {
+	User             *user.User
+	Status           event.RegistrationStatus
+	WaitlistPosition int
+	PartnerName      string // Напарник по команде (для парной категории)
+	Payment          string // Состояние оплаты (только для модераторов события)
+	Guests           []event.Guest
+	GuestPayments    map[event.GuestID]string // Состояние оплаты гостей (только для модераторов события)
+}
+
+// FormatEventUsersList форматирует список участников события.
+// Тем, кто управляет игроками (userCards), под списком выводятся кнопки карточек игроков
+func (f *Formatter) FormatEventUsersList(eventName string, usersWithStatus []UserWithStatus, eventID string, userCards bool) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("👥 Участники события: %s\n\n", eventName)
+
+	if len(usersWithStatus) == 0 {
+		text += "📭 Пока нет зарегистрированных участников"
+	} else {
 		// Группируем по статусам
-		var approved, pending, rejected []string
+		var approved, pending, rejected, waitlisted, lateCancelled []string
+
+		// Сортируем по позиции, чтобы лист ожидания выводился по порядку очереди
+		sort.SliceStable(usersWithStatus, func(i, j int) bool {
+			return usersWithStatus[i].WaitlistPosition < usersWithStatus[j].WaitlistPosition
+		})
 
 		for _, item := range usersWithStatus {
 			if item.User == nil {
@@ -665,14 +1489,33 @@ func (f *Formatter) FormatEventUsersList(eventName string, usersWithStatus []Use
 			if userName == "" {
 				userName = fmt.Sprintf("ID: %d", item.User.TelegramID)
 			}
+			if item.PartnerName != "" {
+				userName += " + " + item.PartnerName
+			}
+
+			// Гости выводятся сразу под игроком, который их записал
+			var guestLines []string
+			for _, guest := range item.Guests {
+				guestLines = append(guestLines, formatGuestLine(guest, item.GuestPayments[guest.ID]))
+			}
 
 			switch item.Status {
 			case event.RegistrationStatusApproved:
-				approved = append(approved, fmt.Sprintf("✅ %s", userName))
+				approved = append(approved, fmt.Sprintf("✅ %s%s", userName, paymentSuffix(item.Payment)))
+				approved = append(approved, guestLines...)
 			case event.RegistrationStatusPending:
-				pending = append(pending, fmt.Sprintf("⏳ %s", userName))
+				pending = append(pending, fmt.Sprintf("⏳ %s%s", userName, paymentSuffix(item.Payment)))
+				pending = append(pending, guestLines...)
+			case event.RegistrationStatusAwaitingPartner:
+				pending = append(pending, fmt.Sprintf("👤 %s (ждет напарника)", userName))
 			case event.RegistrationStatusRejected:
 				rejected = append(rejected, fmt.Sprintf("❌ %s", userName))
+				rejected = append(rejected, guestLines...)
+			case event.RegistrationStatusWaitlisted:
+				waitlisted = append(waitlisted, fmt.Sprintf("%d. %s", item.WaitlistPosition, userName))
+			case event.RegistrationStatusLateCancelled:
+				lateCancelled = append(lateCancelled, fmt.Sprintf("↩️ %s%s", userName, paymentSuffix(item.Payment)))
+				lateCancelled = append(lateCancelled, guestLines...)
 			}
 		}
 
@@ -694,6 +1537,24 @@ func (f *Formatter) FormatEventUsersList(eventName string, usersWithStatus []Use
 			text += "\n"
 		}
 
+		// Выводим лист ожидания в порядке очереди
+		if len(waitlisted) > 0 {
+			text += "🕒 Лист ожидания:\n"
+			for _, u := range waitlisted {
+				text += fmt.Sprintf("  %s\n", u)
+			}
+			text += "\n"
+		}
+
+		// Поздние отмены: место освободилось, но оплата не возвращается
+		if len(lateCancelled) > 0 {
+			text += "↩️ Поздние отмены:\n"
+			for _, u := range lateCancelled {
+				text += fmt.Sprintf("  %s\n", u)
+			}
+			text += "\n"
+		}
+
 		// Выводим отклоненных (обычно не показываем, но на всякий случай)
 		if len(rejected) > 0 {
 			text += "❌ Отклоненные:\n"
@@ -703,11 +1564,1656 @@ func (f *Formatter) FormatEventUsersList(eventName string, usersWithStatus []Use
 		}
 	}
 
+	var rows [][]InlineKeyboardButton
+	if userCards {
+		var row []InlineKeyboardButton
+		for _, item := range usersWithStatus {
+			if item.User == nil {
+				continue
+			}
+			row = append(row, NewInlineKeyboardButtonData("👤 "+item.User.FullName(), fmt.Sprintf("admin:usr:%d", item.User.TelegramID)))
+			if len(row) == 2 {
+				rows = append(rows, NewInlineKeyboardRow(row...))
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, NewInlineKeyboardRow(row...))
+		}
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("event:%s", eventID)),
+	))
+
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatTournament форматирует текущее состояние турнира: таблицы групп или сетку с результатами
+func (f *Formatter) FormatTournament(evt *event.Event, t *tournament.Tournament) string {
+	text := fmt.Sprintf("🏆 %s\n🗓️ %s\n📋 Формат: %s\n", html.EscapeString(evt.Name), evt.Date.Format("02.01.2006 15:04"), formatTournamentFormat(t))
+
+	if t.Format == tournament.FormatRoundRobin {
+		for pool := 1; pool <= t.Pools; pool++ {
+			if t.Pools > 1 {
+				text += fmt.Sprintf("\n<b>Группа %d</b>\n", pool)
+			} else {
+				text += "\n<b>Таблица</b>\n"
+			}
+			for i, row := range t.Standings(pool) {
+				text += fmt.Sprintf("%d. %s — %d В / %d П, очки %d:%d\n",
+					i+1, html.EscapeString(row.Participant.Name), row.Won, row.Lost, row.PointsFor, row.PointsAgainst)
+			}
+			text += "\n"
+			round := 0
+			for _, m := range t.Matches {
+				if m.Pool != pool {
+					continue
+				}
+				if m.Round != round {
+					round = m.Round
+					text += fmt.Sprintf("Тур %d:\n", round)
+				}
+				text += formatTournamentMatch(t, &m)
+			}
+		}
+	} else {
+		rounds := 0
+		for _, m := range t.Matches {
+			if m.Stage == tournament.StageWinners && m.Round > rounds {
+				rounds = m.Round
+			}
+		}
+		var stage tournament.Stage
+		round := 0
+		for _, m := range t.Matches {
+			// Проходы между двумя пустыми местами не показываем
+			if m.Winner == tournament.Bye {
+				continue
+			}
+			if m.Stage != stage || m.Round != round {
+				stage, round = m.Stage, m.Round
+				text += fmt.Sprintf("\n<b>%s</b>\n", formatTournamentRound(t.Format, stage, round, rounds))
+			}
+			text += formatTournamentMatch(t, &m)
+		}
+	}
+
+	if champion := t.Participant(t.Champion()); champion != nil {
+		text += fmt.Sprintf("\n🥇 Победитель: <b>%s</b>\n", html.EscapeString(champion.Name))
+	} else if t.Status == tournament.StatusFinished {
+		text += "\n🏁 Турнир завершен\n"
+	}
+	return text
+}
+
+// formatTournamentFormat возвращает название формата турнира
+func formatTournamentFormat(t *tournament.Tournament) string {
+	switch t.Format {
+	case tournament.FormatRoundRobin:
+		if t.Pools > 1 {
+			return fmt.Sprintf("круговая система, групп: %d", t.Pools)
+		}
+		return "круговая система"
+	case tournament.FormatSingleElimination:
+		return "олимпийская система"
+	case tournament.FormatDoubleElimination:
+		return "до двух поражений"
+	default:
+		return string(t.Format)
+	}
+}
+
+// formatTournamentRound возвращает название тура сетки
+func formatTournamentRound(format tournament.Format, stage tournament.Stage, round, winnersRounds int) string {
+	switch stage {
+	case tournament.StageLosers:
+		return fmt.Sprintf("Сетка проигравших, тур %d", round)
+	case tournament.StageFinal:
+		if round > 1 {
+			return "Суперфинал, переигровка"
+		}
+		return "Суперфинал"
+	}
+	if format == tournament.FormatDoubleElimination {
+		return fmt.Sprintf("Сетка победителей, тур %d", round)
+	}
+	switch left := winnersRounds - round; left {
+	case 0:
+		return "Финал"
+	case 1:
+		return "Полуфинал"
+	default:
+		return fmt.Sprintf("1/%d финала", 1<<left)
+	}
+}
+
+// formatTournamentMatch форматирует строку матча: участники и счет или статус
+func formatTournamentMatch(t *tournament.Tournament, m *tournament.Match) string {
+	a := html.EscapeString(tournamentSideName(t, m.A))
+	b := html.EscapeString(tournamentSideName(t, m.B))
+	switch {
+	case m.Bye:
+		winner := a
+		if m.Winner == m.B {
+			winner = b
+		}
+		return fmt.Sprintf("#%d %s — проходит без игры\n", m.ID, winner)
+	case m.Played():
+		if m.Winner == m.A {
+			a = "<b>" + a + "</b>"
+		} else {
+			b = "<b>" + b + "</b>"
+		}
+		return fmt.Sprintf("#%d %s %d:%d %s\n", m.ID, a, m.ScoreA, m.ScoreB, b)
+	default:
+		return fmt.Sprintf("#%d %s — %s\n", m.ID, a, b)
+	}
+}
+
+// tournamentSideName возвращает имя участника матча или заглушку для неопределенного места
+func tournamentSideName(t *tournament.Tournament, id int) string {
+	if p := t.Participant(id); p != nil {
+		return p.Name
+	}
+	if id == tournament.Bye {
+		return "—"
+	}
+	return "?"
+}
+
+// FormatTournamentForUsers форматирует турнир для игроков
+func (f *Formatter) FormatTournamentForUsers(evt *event.Event, t *tournament.Tournament) (string, *InlineKeyboardMarkup) {
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔄 Обновить", fmt.Sprintf("event:bracket:%s", string(evt.ID))),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("event:%s", string(evt.ID))),
+		),
+	)
+	return f.FormatTournament(evt, t), keyboard
+}
+
+// FormatTournamentForAdmin форматирует турнир для администратора: матчи, готовые к игре, - кнопками для ввода счета
+func (f *Formatter) FormatTournamentForAdmin(evt *event.Event, t *tournament.Tournament) (string, *InlineKeyboardMarkup) {
+	text := f.FormatTournament(evt, t)
+
+	var rows [][]InlineKeyboardButton
+	ready := t.ReadyMatches()
+	if len(ready) > 0 {
+		text += "\nВыберите матч, чтобы внести счет:"
+	}
+	for _, m := range ready {
+		label := fmt.Sprintf("#%d %s — %s", m.ID, tournamentSideName(t, m.A), tournamentSideName(t, m.B))
+		if len(label) > 60 {
+			label = label[:57] + "..."
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("admin:trn:m:%s:%d", string(evt.ID), m.ID)),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✏️ Исправить счет", fmt.Sprintf("admin:trn:fix:%s", string(evt.ID))),
+		NewInlineKeyboardButtonData("📢 В канал", fmt.Sprintf("admin:trn:pub:%s", string(evt.ID))),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🗑 Удалить турнир", fmt.Sprintf("admin:trn:del:%s", string(evt.ID))),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("admin:event:%s", string(evt.ID))),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatTournamentFormatChoice форматирует выбор формата нового турнира; byRating включает посев по рейтингу игроков
+func (f *Formatter) FormatTournamentFormatChoice(evt *event.Event, participants int, byRating bool) (string, *InlineKeyboardMarkup) {
+	seeding := "Сетка строится из подтвержденных участников в порядке записи."
+	seedButton := NewInlineKeyboardButtonData("📊 Посев: по порядку записи", fmt.Sprintf("admin:trn:seed:1:%s", string(evt.ID)))
+	suffix := ""
+	if byRating {
+		seeding = "Сетка строится из подтвержденных участников по убыванию рейтинга (команды - по среднему рейтингу игроков)."
+		seedButton = NewInlineKeyboardButtonData("📊 Посев: по рейтингу", fmt.Sprintf("admin:trn:seed:0:%s", string(evt.ID)))
+		suffix = ":r"
+	}
+	text := fmt.Sprintf("🏆 Турнир для «%s»\n\nПодтвержденных участников: %d\n\n%s Выберите формат:", evt.Name, participants, seeding)
+	eventID := string(evt.ID)
 	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(seedButton),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔄 Круговая система", fmt.Sprintf("admin:trn:new:rr1:%s%s", eventID, suffix)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔄 2 группы", fmt.Sprintf("admin:trn:new:rr2:%s%s", eventID, suffix)),
+			NewInlineKeyboardButtonData("🔄 4 группы", fmt.Sprintf("admin:trn:new:rr4:%s%s", eventID, suffix)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🥇 Олимпийская система", fmt.Sprintf("admin:trn:new:se:%s%s", eventID, suffix)),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("♻️ До двух поражений", fmt.Sprintf("admin:trn:new:de:%s%s", eventID, suffix)),
+		),
 		NewInlineKeyboardRow(
-			NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("event:%s", eventID)),
+			NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("admin:event:%s", eventID)),
 		),
 	)
+	return text, keyboard
+}
+
+// FormatTournamentPlayedMatches форматирует список сыгранных матчей для исправления счета
+func (f *Formatter) FormatTournamentPlayedMatches(evt *event.Event, t *tournament.Tournament) (string, *InlineKeyboardMarkup) {
+	text := "✏️ Выберите матч, счет которого нужно исправить:"
+	var rows [][]InlineKeyboardButton
+	for _, m := range t.Matches {
+		if !m.Played() {
+			continue
+		}
+		label := fmt.Sprintf("#%d %s %d:%d %s", m.ID, tournamentSideName(t, m.A), m.ScoreA, m.ScoreB, tournamentSideName(t, m.B))
+		if len(label) > 60 {
+			label = label[:57] + "..."
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("admin:trn:m:%s:%d", string(evt.ID), m.ID)),
+		))
+	}
+	if len(rows) == 0 {
+		text = "📋 Сыгранных матчей пока нет"
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К турниру", fmt.Sprintf("admin:trn:%s", string(evt.ID))),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
 
+// FormatTournamentMatchReady форматирует уведомление игроку о том, что его матч можно играть
+func (f *Formatter) FormatTournamentMatchReady(evt *event.Event, t *tournament.Tournament, m *tournament.Match) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("🏓 Ваш следующий матч на турнире «%s»\n\n#%d %s — %s", html.EscapeString(evt.Name), m.ID,
+		html.EscapeString(tournamentSideName(t, m.A)), html.EscapeString(tournamentSideName(t, m.B)))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🏆 Сетка турнира", fmt.Sprintf("event:bracket:%s", string(evt.ID))),
+		),
+	)
+	return text, keyboard
+}
+
+// FormatRating форматирует рейтинг игрока с динамикой за последние матчи
+func (f *Formatter) FormatRating(summary *rating.Summary, playerName string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("📊 <b>Рейтинг: %s</b>\n\n", html.EscapeString(playerName)))
+	b.WriteString(fmt.Sprintf("Текущий рейтинг: <b>%d</b>\n", summary.Rating))
+	if summary.Played == 0 {
+		b.WriteString("\nВы еще не сыграли ни одного матча в турнирах. Рейтинг начнет меняться после первого результата.")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("Место: %d из %d\n", summary.Rank, summary.Ranked))
+	b.WriteString(fmt.Sprintf("Матчей: %d (побед: %d, поражений: %d)\n", summary.Played, summary.Won, summary.Played-summary.Won))
+
+	const recentMatches = 10
+	recent := summary.Recent(recentMatches)
+	b.WriteString(fmt.Sprintf("\nДинамика за последние %d матч(ей): %+d\n", len(recent), summary.Trend(recentMatches)))
+	for i := len(recent) - 1; i >= 0; i-- {
+		e := recent[i]
+		mark := "▼"
+		if e.Won {
+			mark = "▲"
+		}
+		b.WriteString(fmt.Sprintf("%s %s: %d → %d (%+d)\n", mark, e.PlayedAt.Format("02.01.2006"), e.Before, e.After, e.Delta()))
+	}
+	return b.String()
+}
+
+// roleLabels - названия ролей для отображения
+var roleLabels = map[role.Role]string{
+	role.RoleOwner:     "👑 Владелец",
+	role.RoleAdmin:     "🛠 Администратор",
+	role.RoleModerator: "✅ Модератор",
+	role.RoleTrainer:   "👨‍🏫 Тренер",
+}
+
+// roleDescriptions - что может каждая роль
+var roleDescriptions = map[role.Role]string{
+	role.RoleOwner:     "все права, включая назначение ролей",
+	role.RoleAdmin:     "все права, кроме назначения ролей",
+	role.RoleModerator: "только подтверждение и отклонение заявок",
+	role.RoleTrainer:   "создание событий и управление только своими событиями",
+}
+
+// roleScope форматирует область действия роли
+func roleScope(g role.Grant, locationNames map[location.LocationID]string) string {
+	if g.LocationID == "" {
+		return "все локации"
+	}
+	if name, ok := locationNames[g.LocationID]; ok {
+		return name
+	}
+	return "удаленная локация"
+}
+
+// FormatRoles форматирует список выданных ролей с кнопками снятия
+func (f *Formatter) FormatRoles(grants []role.Grant, names map[int64]string, locationNames map[location.LocationID]string) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	b.WriteString("👑 <b>Роли</b>\n\n")
+	for _, r := range role.Roles {
+		b.WriteString(fmt.Sprintf("%s — %s\n", roleLabels[r], roleDescriptions[r]))
+	}
+	b.WriteString("\n")
+	if len(grants) == 0 {
+		b.WriteString("Роли пока никому не выданы.")
+	}
+	for i, g := range grants {
+		b.WriteString(fmt.Sprintf("%d. %s (<code>%d</code>) — %s, %s\n", i+1, html.EscapeString(names[g.TelegramID]), g.TelegramID,
+			roleLabels[g.Role], html.EscapeString(roleScope(g, locationNames))))
+	}
+	if len(grants) > 0 {
+		b.WriteString("\nНажмите на роль, чтобы снять ее.")
+	}
+
+	var rows [][]InlineKeyboardButton
+	for i, g := range grants {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("🗑 %d. %s", i+1, names[g.TelegramID]), fmt.Sprintf("admin:roles:del:%s", string(g.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("➕ Назначить роль", "admin:roles:add"),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatRolePicker форматирует выбор роли для пользователя
+func (f *Formatter) FormatRolePicker(name string, telegramID int64) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("👑 Какую роль назначить пользователю %s (<code>%d</code>)?", html.EscapeString(name), telegramID)
+
+	var rows [][]InlineKeyboardButton
+	for _, r := range role.Roles {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(roleLabels[r], fmt.Sprintf("admin:roles:role:%s", string(r))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Отмена", "admin:roles"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatRoleLocationPicker форматирует выбор локации, в которой действует роль
+func (f *Formatter) FormatRoleLocationPicker(r role.Role, locations []location.Location) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("📍 Где действует роль «%s»?", roleLabels[r])
+
+	rows := [][]InlineKeyboardButton{
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🌐 Во всех локациях", "admin:roles:loc:all")),
+	}
+	for _, loc := range locations {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(loc.Name, fmt.Sprintf("admin:roles:loc:%s", string(loc.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Отмена", "admin:roles"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatRoleGranted форматирует уведомление пользователю о выданной роли
+func (f *Formatter) FormatRoleGranted(g *role.Grant, locationName string) string {
+	scope := "во всех локациях"
+	if g.LocationID != "" {
+		scope = fmt.Sprintf("в локации «%s»", html.EscapeString(locationName))
+	}
+	return fmt.Sprintf("👑 Вам назначена роль «%s» %s: %s.\n\nПанель управления: /admin", roleLabels[g.Role], scope, roleDescriptions[g.Role])
+}
+
+// paymentMethodLabels - названия способов оплаты в журнале
+var paymentMethodLabels = map[payment.Method]string{
+	payment.MethodTransfer: "📱 перевод",
+	payment.MethodCash:     "💵 наличные",
+	payment.MethodInvoice:  "💳 счет в Telegram",
+	payment.MethodPass:     "🎟 абонемент",
+}
+
+// formatBalance форматирует состояние оплаты регистрации
+func formatBalance(evt *event.Event, b payment.Balance) string {
+	mode := evt.EffectivePaymentMode()
+	switch b.Status() {
+	case payment.StatusFree:
+		return "бесплатно"
+	case payment.StatusUnpaid:
+		return "❌ не оплачено"
+	case payment.StatusPartial:
+		return fmt.Sprintf("🌗 оплачено %s из %s", formatAmount(mode, b.Paid), formatAmount(mode, b.Due))
+	default:
+		if b.Paid > b.Due {
+			return fmt.Sprintf("💰 оплачено %s (переплата %s)", formatAmount(mode, b.Paid), formatAmount(mode, b.Paid-b.Due))
+		}
+		return "💰 оплачено"
+	}
+}
+
+// paymentSuffix добавляет состояние оплаты к строке участника
+func paymentSuffix(status string) string {
+	if status == "" {
+		return ""
+	}
+	return " — " + status
+}
+
+// PaymentRow - строка списка оплат события
+type PaymentRow struct {
+	UserID  int64
+	Name    string
+	Status  event.RegistrationStatus
+	Balance payment.Balance
+}
+
+// FormatEventPayments форматирует состояние оплат по регистрациям события
+func (f *Formatter) FormatEventPayments(evt *event.Event, rows []PaymentRow) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("💰 <b>Оплаты: %s</b>\n🗓️ %s\n", html.EscapeString(evt.Name), evt.Date.Format("02.01.2006 15:04")))
+	if evt.Price > 0 {
+		b.WriteString(fmt.Sprintf("Стоимость участия: %s\n", formatPrice(evt)))
+	}
+	b.WriteString("\n")
+
+	if len(rows) == 0 {
+		b.WriteString("📭 Оплачивать пока некому: нет подтвержденных и ожидающих заявок")
+	}
+	var paid, due int
+	for i, row := range rows {
+		mark := ""
+		if row.Status != event.RegistrationStatusApproved {
+			mark = " (ждет подтверждения)"
+		}
+		b.WriteString(fmt.Sprintf("%d. %s%s — %s\n", i+1, html.EscapeString(row.Name), mark, formatBalance(evt, row.Balance)))
+		paid += row.Balance.Paid
+		if row.Status == event.RegistrationStatusApproved {
+			due += row.Balance.Due
+		}
+	}
+	if len(rows) > 0 && evt.Price > 0 {
+		mode := evt.EffectivePaymentMode()
+		b.WriteString(fmt.Sprintf("\nСобрано: %s из %s за подтвержденных", formatAmount(mode, paid), formatAmount(mode, due)))
+	}
+
+	var buttons [][]InlineKeyboardButton
+	for i, row := range rows {
+		buttons = append(buttons, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("%d. %s", i+1, row.Name), fmt.Sprintf("admin:pay:u:%s:%d", string(evt.ID), row.UserID)),
+		))
+	}
+	buttons = append(buttons, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("admin:event:%s", string(evt.ID))),
+	))
+	return b.String(), NewInlineKeyboardMarkup(buttons...)
+}
+
+// FormatPlayerPayments форматирует журнал оплат игрока по событию с действиями администратора
+func (f *Formatter) FormatPlayerPayments(evt *event.Event, name string, balance payment.Balance, history []payment.Payment) (string, *InlineKeyboardMarkup) {
+	mode := evt.EffectivePaymentMode()
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("💰 <b>%s</b>\n📅 %s, %s\n\n", html.EscapeString(name), html.EscapeString(evt.Name), evt.Date.Format("02.01.2006 15:04")))
+	b.WriteString(fmt.Sprintf("Статус: %s\n", formatBalance(evt, balance)))
+	if owed := balance.Owed(); owed > 0 {
+		b.WriteString(fmt.Sprintf("Осталось оплатить: %s\n", formatAmount(mode, owed)))
+	}
+
+	if len(history) > 0 {
+		b.WriteString("\n📒 Журнал:\n")
+	}
+	for _, p := range history {
+		sign := "+"
+		if p.Kind == payment.KindRefund {
+			sign = "−"
+		}
+		line := fmt.Sprintf("%s %s%s, %s", p.CreatedAt.Format("02.01 15:04"), sign, formatAmount(mode, p.Amount), paymentMethodLabels[p.Method])
+		if p.ConfirmedBy == 0 {
+			line += ", автоматически"
+		}
+		if p.Comment != "" {
+			line += ", " + html.EscapeString(p.Comment)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	var rows [][]InlineKeyboardButton
+	if owed := balance.Owed(); owed > 0 {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("💵 Наличными "+formatAmount(mode, owed), "admin:pay:full:cash"),
+			NewInlineKeyboardButtonData("📱 Переводом "+formatAmount(mode, owed), "admin:pay:full:transfer"),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✏️ Часть наличными", "admin:pay:part:cash"),
+		NewInlineKeyboardButtonData("✏️ Часть переводом", "admin:pay:part:transfer"),
+	))
+	if balance.Paid > 0 {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("↩️ Возврат наличными", "admin:pay:refund:cash"),
+			NewInlineKeyboardButtonData("↩️ Возврат переводом", "admin:pay:refund:transfer"),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К оплатам", fmt.Sprintf("admin:pay:%s", string(evt.ID))),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// maxDebtorButtons - сколько должников в отчете получают кнопку перехода к карточке оплат
+const maxDebtorButtons = 20
+
+// FormatDebtors форматирует отчет о подтвержденных игроках и гостях, не оплативших участие
+func (f *Formatter) FormatDebtors(debts []payment.Debt, names map[int64]string) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	b.WriteString("💸 <b>Должники</b>\n\n")
+	if len(debts) == 0 {
+		b.WriteString("✅ Все подтвержденные игроки оплатили участие")
+	}
+
+	var lastEvent event.EventID
+	totals := make(map[event.PaymentMode]int)
+	for _, d := range debts {
+		if d.Event.ID != lastEvent {
+			lastEvent = d.Event.ID
+			b.WriteString(fmt.Sprintf("📅 %s, %s\n", html.EscapeString(d.Event.Name), d.Event.Date.Format("02.01.2006 15:04")))
+		}
+		mode := d.Event.EffectivePaymentMode()
+		b.WriteString(fmt.Sprintf("  • %s — долг %s\n", html.EscapeString(debtorName(d, names)), formatAmount(mode, d.Balance.Owed())))
+		if mode != event.PaymentModeStars {
+			mode = event.PaymentModeTransfer
+		}
+		totals[mode] += d.Balance.Owed()
+	}
+	if total := totals[event.PaymentModeTransfer]; total > 0 {
+		b.WriteString(fmt.Sprintf("\nИтого: %s", formatAmount(event.PaymentModeTransfer, total)))
+	}
+	if total := totals[event.PaymentModeStars]; total > 0 {
+		b.WriteString(fmt.Sprintf("\nИтого в звездах: %s", formatAmount(event.PaymentModeStars, total)))
+	}
+
+	var rows [][]InlineKeyboardButton
+	for i, d := range debts {
+		if i >= maxDebtorButtons {
+			break
+		}
+		// Оплата за гостя отмечается в списке гостей события
+		data := fmt.Sprintf("admin:pay:u:%s:%d", string(d.Event.ID), d.UserID)
+		if d.GuestID != "" {
+			data = fmt.Sprintf("admin:guests:%s", string(d.Event.ID))
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("%s · %s", debtorName(d, names), d.Event.Date.Format("02.01")), data),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// debtorName возвращает имя должника в отчете: для гостя - имя гостя и игрока, который его привел
+func debtorName(d payment.Debt, names map[int64]string) string {
+	if d.GuestID == "" {
+		return names[d.UserID]
+	}
+	return fmt.Sprintf("%s (гость %s)", d.Event.Guests[d.GuestID].Name, names[d.UserID])
+}
+
+// passTypeLabels - названия типов событий в условиях абонемента
+var passTypeLabels = map[event.EventType]string{
+	event.EventTypeTraining:    "тренировки",
+	event.EventTypeCompetition: "соревнования",
+}
+
+// passScope описывает, на какие события действует абонемент
+func passScope(p *pass.Pass, locationNames map[location.LocationID]string) string {
+	types := "любые события"
+	if len(p.EventTypes) > 0 {
+		labels := make([]string, 0, len(p.EventTypes))
+		for _, t := range p.EventTypes {
+			labels = append(labels, passTypeLabels[t])
+		}
+		types = strings.Join(labels, ", ")
+	}
+	locations := "все локации"
+	if len(p.LocationIDs) > 0 {
+		names := make([]string, 0, len(p.LocationIDs))
+		for _, id := range p.LocationIDs {
+			name := locationNames[id]
+			if name == "" {
+				name = string(id)
+			}
+			names = append(names, "«"+name+"»")
+		}
+		locations = strings.Join(names, ", ")
+	}
+	return types + ", " + locations
+}
+
+// formatPass форматирует абонемент: остаток, срок действия и условия
+func formatPass(p *pass.Pass, locationNames map[location.LocationID]string, now time.Time) string {
+	status := ""
+	switch {
+	case !now.Before(p.ValidUntil):
+		status = " — ⌛ истек"
+	case p.Remaining() == 0:
+		status = " — занятия закончились"
+	}
+	// ValidUntil не включительно, показываем последний день действия
+	return fmt.Sprintf("🎟 Осталось <b>%d</b> из %d занятий%s\n   📅 %s – %s\n   🎯 %s\n",
+		p.Remaining(), p.Sessions, status,
+		p.ValidFrom.Format("02.01.2006"), p.ValidUntil.AddDate(0, 0, -1).Format("02.01.2006"),
+		html.EscapeString(passScope(p, locationNames)))
+}
+
+// FormatPasses форматирует абонементы игрока для команды /pass
+func (f *Formatter) FormatPasses(passes []pass.Pass, locationNames map[location.LocationID]string, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("🎟 <b>Ваши абонементы</b>\n\n")
+
+	shown := 0
+	for i := range passes {
+		// Истекшие и израсходованные абонементы игроку не показываем
+		if !passes[i].Active(now) {
+			continue
+		}
+		b.WriteString(formatPass(&passes[i], locationNames, now))
+		shown++
+	}
+	if shown == 0 {
+		b.WriteString("Действующих абонементов нет. Чтобы купить абонемент, обратитесь к администратору.")
+		return b.String()
+	}
+
+	b.WriteString("\nЗанятие списывается при подтверждении записи и возвращается, если отменить запись до срока бесплатной отмены — он указан в карточке события.")
+	return b.String()
+}
+
+// FormatAdminPasses форматирует абонементы игрока для администратора
+func (f *Formatter) FormatAdminPasses(name string, userID int64, passes []pass.Pass, locationNames map[location.LocationID]string, now time.Time) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🎟 <b>Абонементы: %s</b> (<code>%d</code>)\n\n", html.EscapeString(name), userID))
+	if len(passes) == 0 {
+		b.WriteString("Абонементов пока нет.\n")
+	}
+	for i := range passes {
+		b.WriteString(fmt.Sprintf("%d. ", i+1))
+		b.WriteString(formatPass(&passes[i], locationNames, now))
+	}
+
+	var rows [][]InlineKeyboardButton
+	for i := range passes {
+		// Абонемент со списанными занятиями уже учтен в оплатах, аннулировать можно только неиспользованный
+		if len(passes[i].Usages) > 0 {
+			continue
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("🗑 Аннулировать %d", i+1), fmt.Sprintf("admin:pass:del:%s", string(passes[i].ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("➕ Выдать абонемент", fmt.Sprintf("admin:pass:new:%d", userID)),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatPassTypePicker форматирует выбор типа событий, на которые действует абонемент
+func (f *Formatter) FormatPassTypePicker(sessions, days int) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("🎟 Абонемент на %d занятий, %d дней\n\nНа какие события действует абонемент?", sessions, days)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🌐 Любые события", "admin:pass:type:any")),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🏃 Тренировки", fmt.Sprintf("admin:pass:type:%s", event.EventTypeTraining))),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🏆 Соревнования", fmt.Sprintf("admin:pass:type:%s", event.EventTypeCompetition))),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🔙 Отмена", "admin:pass:cancel")),
+	)
+	return text, keyboard
+}
+
+// FormatPassLocationPicker форматирует выбор локаций, в которых действует абонемент
+func (f *Formatter) FormatPassLocationPicker(selected []location.LocationID, locations []location.Location) (string, *InlineKeyboardMarkup) {
+	text := "📍 В каких локациях действует абонемент?\n\nОтметьте локации и нажмите «Готово». Если ничего не отмечено, абонемент действует во всех локациях."
+
+	chosen := make(map[location.LocationID]bool, len(selected))
+	for _, id := range selected {
+		chosen[id] = true
+	}
+	var rows [][]InlineKeyboardButton
+	for _, loc := range locations {
+		label := loc.Name
+		if chosen[loc.ID] {
+			label = "✅ " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("admin:pass:loc:%s", string(loc.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✅ Готово", "admin:pass:loc_done"),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Отмена", "admin:pass:cancel"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatPassIssued форматирует уведомление игроку о выданном абонементе
+func (f *Formatter) FormatPassIssued(p *pass.Pass, locationNames map[location.LocationID]string) string {
+	return "🎟 Вам выдан абонемент\n\n" + formatPass(p, locationNames, time.Now()) +
+		"\nЗанятия будут списываться при записи на подходящие события. Остаток: /pass"
+}
+
+// formatPromoValue форматирует размер скидки промокода
+func formatPromoValue(c *promo.Code) string {
+	if c.Kind == promo.KindPercent {
+		return fmt.Sprintf("−%d%%", c.Value)
+	}
+	return fmt.Sprintf("−%d", c.Value)
+}
+
+// promoConditions описывает условия промокода: на что действует, лимиты и срок
+func promoConditions(c *promo.Code, eventName string) string {
+	var parts []string
+	switch {
+	case c.EventID != "":
+		parts = append(parts, fmt.Sprintf("событие «%s»", eventName))
+	case len(c.EventTypes) > 0:
+		labels := make([]string, 0, len(c.EventTypes))
+		for _, t := range c.EventTypes {
+			labels = append(labels, passTypeLabels[t])
+		}
+		parts = append(parts, strings.Join(labels, ", "))
+	default:
+		parts = append(parts, "любые события")
+	}
+	if c.MaxUses > 0 {
+		parts = append(parts, fmt.Sprintf("использовано %d из %d", c.Uses, c.MaxUses))
+	} else {
+		parts = append(parts, fmt.Sprintf("использовано %d", c.Uses))
+	}
+	if c.MaxUsesPerUser > 0 {
+		parts = append(parts, fmt.Sprintf("не больше %d на игрока", c.MaxUsesPerUser))
+	}
+	if !c.ExpiresAt.IsZero() {
+		parts = append(parts, "до "+c.ExpiresAt.Format("02.01.2006 15:04"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// promoStatus возвращает отметку о том, что промокод не действует
+func promoStatus(c *promo.Code, now time.Time) string {
+	switch {
+	case !c.Active:
+		return " ⛔ отключен"
+	case c.Expired(now):
+		return " ⌛ истек"
+	case c.MaxUses > 0 && c.Uses >= c.MaxUses:
+		return " 🔚 исчерпан"
+	}
+	return ""
+}
+
+// FormatPromoCodes форматирует список промокодов для администратора
+func (f *Formatter) FormatPromoCodes(codes []promo.Code, eventNames map[event.EventID]string, now time.Time) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	b.WriteString("🏷 <b>Промокоды</b>\n\n")
+	if len(codes) == 0 {
+		b.WriteString("Промокодов пока нет.")
+	}
+	for i := range codes {
+		c := &codes[i]
+		b.WriteString(fmt.Sprintf("<code>%s</code> %s%s\n   %s\n", html.EscapeString(c.Code), formatPromoValue(c), promoStatus(c, now),
+			html.EscapeString(promoConditions(c, eventNames[c.EventID]))))
+	}
+
+	var rows [][]InlineKeyboardButton
+	for i := range codes {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("🏷 %s", codes[i].Code), fmt.Sprintf("admin:promo:%s", string(codes[i].ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("➕ Создать промокод", "admin:promo_new"),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:menu"),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// maxPromoRedemptions - сколько последних погашений показывать в карточке промокода
+const maxPromoRedemptions = 30
+
+// FormatPromoCode форматирует карточку промокода с погашениями
+func (f *Formatter) FormatPromoCode(c *promo.Code, redemptions []promo.Redemption, names map[int64]string, events map[event.EventID]*event.Event, now time.Time) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	eventName := ""
+	if evt := events[c.EventID]; evt != nil {
+		eventName = evt.Name
+	}
+	b.WriteString(fmt.Sprintf("🏷 <b>Промокод <code>%s</code></b> %s%s\n", html.EscapeString(c.Code), formatPromoValue(c), promoStatus(c, now)))
+	b.WriteString(html.EscapeString(promoConditions(c, eventName)) + "\n\n")
+
+	if len(redemptions) == 0 {
+		b.WriteString("Промокод еще не использовали.")
+	} else {
+		total := 0
+		for _, r := range redemptions {
+			total += r.Discount
+		}
+		b.WriteString(fmt.Sprintf("<b>Погашения</b> (%d, скидок на %d):\n", len(redemptions), total))
+		start := 0
+		if len(redemptions) > maxPromoRedemptions {
+			start = len(redemptions) - maxPromoRedemptions
+			b.WriteString(fmt.Sprintf("… и еще %d ранее\n", start))
+		}
+		for _, r := range redemptions[start:] {
+			what := string(r.EventID)
+			if evt := events[r.EventID]; evt != nil {
+				what = fmt.Sprintf("%s, %s", evt.Name, evt.Date.Format("02.01 15:04"))
+			}
+			b.WriteString(fmt.Sprintf("• %s — %s, −%d\n", html.EscapeString(names[r.UserID]), html.EscapeString(what), r.Discount))
+		}
+	}
+
+	var rows [][]InlineKeyboardButton
+	if c.Active {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("⛔ Отключить", fmt.Sprintf("admin:promo_off:%s", string(c.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К промокодам", "admin:promos"),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatPromoKindPicker форматирует выбор вида скидки промокода
+func (f *Formatter) FormatPromoKindPicker(code string) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("🏷 Промокод %s\n\nКакую скидку дает промокод?", code)
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("％ Процент от стоимости", fmt.Sprintf("admin:promo_kind:%s", promo.KindPercent))),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("💰 Фиксированная сумма", fmt.Sprintf("admin:promo_kind:%s", promo.KindFixed))),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🔙 Отмена", "admin:promos")),
+	)
+	return text, keyboard
+}
+
+// FormatPromoScopePicker форматирует выбор событий, на которые действует промокод
+func (f *Formatter) FormatPromoScopePicker() (string, *InlineKeyboardMarkup) {
+	text := "🎯 На какие события действует промокод?"
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🌐 Любые события", "admin:promo_scope:any")),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🏃 Тренировки", fmt.Sprintf("admin:promo_scope:%s", event.EventTypeTraining))),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🏆 Соревнования", fmt.Sprintf("admin:promo_scope:%s", event.EventTypeCompetition))),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("📅 Конкретное событие", "admin:promo_scope:event")),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🔙 Отмена", "admin:promos")),
+	)
+	return text, keyboard
+}
+
+// FormatPromoEventPicker форматирует выбор события для промокода
+func (f *Formatter) FormatPromoEventPicker(events []event.Event) (string, *InlineKeyboardMarkup) {
+	text := "📅 Выберите событие, на которое действует промокод:"
+	if len(events) == 0 {
+		text = "📅 Нет предстоящих платных событий. Выберите другой вариант."
+	}
+
+	var rows [][]InlineKeyboardButton
+	for _, evt := range events {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("%s — %s", evt.Date.Format("02.01 15:04"), evt.Name), fmt.Sprintf("admin:promo_evt:%s", string(evt.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "admin:promo_scope:back"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// formatCancellationPolicy описывает срок бесплатной отмены записи на событие, начинающееся в start
+func formatCancellationPolicy(policy event.CancellationPolicy, start time.Time) string {
+	freeUntil := policy.FreeUntil(start).Format("02.01 15:04")
+	if policy.Late == event.LateCancelBlock {
+		return fmt.Sprintf("↩️ Отменить запись можно до %s, позже — только через администратора", freeUntil)
+	}
+	return fmt.Sprintf("↩️ Бесплатная отмена до %s, позже отмена считается поздней", freeUntil)
+}
+
+// describeCancellationPolicy описывает правило отмены записи для администратора
+func describeCancellationPolicy(policy event.CancellationPolicy) string {
+	if !policy.Enabled() {
+		return "отменить запись можно в любой момент"
+	}
+	if policy.Late == event.LateCancelBlock {
+		return fmt.Sprintf("бесплатно за %s до начала, позже самостоятельная отмена запрещена", formatMinutes(policy.Deadline))
+	}
+	return fmt.Sprintf("бесплатно за %s до начала, позже — поздняя отмена (оплата не возвращается, считается как неявка)", formatMinutes(policy.Deadline))
+}
+
+// FormatUnregisterConfirm форматирует подтверждение отмены записи с предупреждением о последствиях
+func (f *Formatter) FormatUnregisterConfirm(evt *event.Event, userID int64, policy event.CancellationPolicy, now time.Time) (string, *InlineKeyboardMarkup) {
+	reg := evt.Registrations[userID]
+
+	var text string
+	switch reg.Status {
+	case event.RegistrationStatusPending:
+		text = fmt.Sprintf("❓ Отменить заявку на «%s»?", evt.Name)
+	case event.RegistrationStatusWaitlisted:
+		text = fmt.Sprintf("❓ Покинуть лист ожидания на «%s»?", evt.Name)
+	default:
+		text = fmt.Sprintf("❓ Отменить запись на «%s»?", evt.Name)
+	}
+	text += fmt.Sprintf("\n🗓️ %s", evt.Date.Format("02.01.2006 15:04"))
+	if evt.Partner(userID) != 0 {
+		text += "\n\n👥 Запись отменится для всей команды, напарник получит уведомление."
+	}
+	if guests := evt.ActiveGuestsOf(userID); len(guests) > 0 {
+		text += fmt.Sprintf("\n\n👤 Ваши гости (%d) тоже будут сняты с записи.", len(guests))
+	}
+
+	confirm := true
+	if reg.Status.HoldsSpot() && policy.Enabled() {
+		freeUntil := policy.FreeUntil(evt.Date).Format("02.01.2006 15:04")
+		switch {
+		case !policy.IsLate(evt.Date, now):
+			text += fmt.Sprintf("\n\n✅ Сейчас отмена бесплатная: срок — до %s.", freeUntil)
+		case policy.Late == event.LateCancelBlock:
+			text += fmt.Sprintf("\n\n⛔ Срок отмены прошел (до %s). Отменить запись самостоятельно уже нельзя — свяжитесь с администратором.", freeUntil)
+			confirm = false
+		default:
+			text += fmt.Sprintf("\n\n⚠️ Срок бесплатной отмены прошел (до %s). Отмена будет засчитана как поздняя:\n"+
+				"• оплата за участие не возвращается, занятие с абонемента сгорает\n"+
+				"• поздняя отмена учитывается наравне с неявкой", freeUntil)
+		}
+	}
+
+	var rows [][]InlineKeyboardButton
+	if confirm {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✅ Да, отменить", fmt.Sprintf("event:unregister:%s", string(evt.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", fmt.Sprintf("event:%s", string(evt.ID))),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// guestStatusLabel возвращает статус гостя словами
+func guestStatusLabel(status event.RegistrationStatus) string {
+	switch status {
+	case event.RegistrationStatusPending:
+		return "⏳ ждет подтверждения"
+	case event.RegistrationStatusApproved:
+		return "✅ подтвержден"
+	case event.RegistrationStatusRejected:
+		return "❌ отклонен"
+	case event.RegistrationStatusExpired:
+		return "⌛ снят"
+	case event.RegistrationStatusLateCancelled:
+		return "↩️ поздняя отмена"
+	default:
+		return string(status)
+	}
+}
+
+// formatGuestLine форматирует строку гостя под игроком в списке участников
+func formatGuestLine(guest event.Guest, payment string) string {
+	return fmt.Sprintf("   ➕ %s (гость, %s)%s", guest.Name, guestStatusLabel(guest.Status), paymentSuffix(payment))
+}
+
+// formatHostGuests форматирует блок «Ваши гости» в карточке события игрока
+func formatHostGuests(evt *event.Event, hostID int64) string {
+	guests := evt.GuestsOf(hostID)
+	if len(guests) == 0 {
+		return ""
+	}
+	text := "\n\n👤 Ваши гости:"
+	for _, guest := range guests {
+		text += fmt.Sprintf("\n  • %s — %s", guest.Name, guestStatusLabel(guest.Status))
+	}
+	return text
+}
+
+// hostGuestButtons возвращает кнопки управления гостями игрока: убрать каждого и добавить еще одного, пока есть места
+func hostGuestButtons(evt *event.Event, hostID int64) [][]InlineKeyboardButton {
+	var rows [][]InlineKeyboardButton
+	active := evt.ActiveGuestsOf(hostID)
+	for _, guest := range active {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(fmt.Sprintf("❌ Убрать гостя: %s", guest.Name), fmt.Sprintf("event:gdel:%s:%s", string(evt.ID), string(guest.ID))),
+		))
+	}
+	if len(active) < event.MaxGuestsPerHost && evt.Remaining > 0 && len(evt.Waitlist()) == 0 {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("➕ Добавить гостя", fmt.Sprintf("event:guest_add:%s", string(evt.ID))),
+		))
+	}
+	return rows
+}
+
+// FormatEventGuests форматирует гостей события для модератора: подтверждение, отклонение и отметка оплаты по каждому.
+// Состояние оплаты (balances) передается только тем, кто ведет журнал оплат события
+func (f *Formatter) FormatEventGuests(evt *event.Event, hostNames map[int64]string, balances map[event.GuestID]payment.Balance) (string, *InlineKeyboardMarkup) {
+	guests := make([]event.Guest, 0, len(evt.Guests))
+	for _, guest := range evt.Guests {
+		guests = append(guests, guest)
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].CreatedAt.Before(guests[j].CreatedAt) })
+
+	text := fmt.Sprintf("👤 Гости: %s\n", evt.Name)
+	if evt.Price > 0 {
+		text += fmt.Sprintf("💵 Стоимость за гостя: %s\n", formatPrice(evt))
+	}
+	text += "\n"
+
+	var rows [][]InlineKeyboardButton
+	if len(guests) == 0 {
+		text += "📭 Гостей нет"
+	}
+	for i, guest := range guests {
+		text += fmt.Sprintf("%d. %s — гость %s, %s", i+1, guest.Name, hostNames[guest.HostID], guestStatusLabel(guest.Status))
+		balance, canPay := balances[guest.ID]
+		if canPay && evt.Price > 0 {
+			text += ", " + formatBalance(evt, balance)
+		}
+		text += "\n"
+
+		var row []InlineKeyboardButton
+		if guest.Status == event.RegistrationStatusPending {
+			row = append(row, NewInlineKeyboardButtonData(fmt.Sprintf("✅ %d", i+1), fmt.Sprintf("admin:guest:ok:%s:%s", string(evt.ID), string(guest.ID))))
+		}
+		if guest.Status.HoldsSpot() {
+			row = append(row, NewInlineKeyboardButtonData(fmt.Sprintf("❌ %d", i+1), fmt.Sprintf("admin:guest:no:%s:%s", string(evt.ID), string(guest.ID))))
+		}
+		// Кнопка отмечает оплату остатка, а у полностью оплаченного гостя - оформляет возврат
+		if canPay && evt.Price > 0 && guest.Status != event.RegistrationStatusRejected && (balance.Owed() > 0 || balance.Paid > 0) {
+			payText := fmt.Sprintf("💰 %d", i+1)
+			if balance.Owed() == 0 {
+				payText = fmt.Sprintf("↩️ 💰 %d", i+1)
+			}
+			row = append(row, NewInlineKeyboardButtonData(payText, fmt.Sprintf("admin:guest:pay:%s:%s", string(evt.ID), string(guest.ID))))
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К событию", fmt.Sprintf("admin:event:%s", string(evt.ID))),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// handLabels - подписи игровой руки
+var handLabels = map[user.Hand]string{
+	user.HandRight: "🫱 Правая",
+	user.HandLeft:  "🫲 Левая",
+}
+
+// languageLabels - подписи языков общения
+var languageLabels = map[user.Language]string{
+	user.LanguageRU: "🇷🇺 Русский",
+	user.LanguageEN: "🇬🇧 English",
+}
+
+// FormatProfile форматирует профиль игрока с кнопками изменения полей
+func (f *Formatter) FormatProfile(usr *user.User, locationNames map[location.LocationID]string) (string, *InlineKeyboardMarkup) {
+	notSet := "не указан"
+
+	text := "👤 Ваш профиль\n\n"
+	text += fmt.Sprintf("Имя: %s\n", html.EscapeString(usr.Name))
+	surname := usr.Surname
+	if surname == "" {
+		surname = "не указана"
+	}
+	text += fmt.Sprintf("Фамилия: %s\n", html.EscapeString(surname))
+	phone := usr.Phone
+	if phone == "" {
+		phone = notSet
+	}
+	text += fmt.Sprintf("📱 Телефон: %s\n", phone)
+	level := notSet
+	if usr.Level.IsSet() {
+		level = usr.Level.String()
+	}
+	text += fmt.Sprintf("🎯 Уровень: %s\n", level)
+	hand := "не указана"
+	if label, ok := handLabels[usr.Hand]; ok {
+		hand = label
+	}
+	text += fmt.Sprintf("✋ Рука: %s\n", hand)
+	var locations []string
+	for _, id := range usr.PreferredLocations {
+		if name, ok := locationNames[id]; ok {
+			locations = append(locations, html.EscapeString(name))
+		}
+	}
+	if len(locations) == 0 {
+		text += "📍 Локации: любые\n"
+	} else {
+		text += fmt.Sprintf("📍 Локации: %s\n", strings.Join(locations, ", "))
+	}
+	text += fmt.Sprintf("🌐 Язык: %s\n", languageLabels[usr.EffectiveLanguage()])
+	if usr.DeletionRequested() {
+		text += fmt.Sprintf("\n⏳ Данные будут удалены %s (отменить: /deleteme)\n", usr.DeleteAfter.Format("02.01.2006 15:04"))
+	}
+	text += "\n📦 Выгрузить свои данные: /mydata\n🗑 Удалить данные: /deleteme"
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✏️ Имя", "profile:edit:name"),
+			NewInlineKeyboardButtonData("✏️ Фамилия", "profile:edit:surname"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📱 Телефон", "profile:phone"),
+			NewInlineKeyboardButtonData("🎯 Уровень", "profile:level"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✋ Рука", "profile:hand"),
+			NewInlineKeyboardButtonData("🌐 Язык", "profile:lang"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📍 Локации", "profile:locs"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🏠 Главное меню", "back:main"),
+		),
+	)
+	return text, keyboard
+}
+
+// FormatProfileLevelPicker форматирует самостоятельный выбор уровня (доступен, пока уровень не определен)
+func (f *Formatter) FormatProfileLevelPicker() (string, *InlineKeyboardMarkup) {
+	text := "🎯 Оцените свой уровень игры\n\nУровень можно указать самостоятельно только один раз, дальше его меняет администратор."
+
+	var rows [][]InlineKeyboardButton
+	var row []InlineKeyboardButton
+	for _, l := range user.Levels() {
+		row = append(row, NewInlineKeyboardButtonData(l.String(), fmt.Sprintf("profile:lvl:%d", int(l))))
+		if len(row) == 4 {
+			rows = append(rows, NewInlineKeyboardRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, NewInlineKeyboardRow(row...))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К профилю", "profile"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatProfileHandPicker форматирует выбор игровой руки
+func (f *Formatter) FormatProfileHandPicker(current user.Hand) (string, *InlineKeyboardMarkup) {
+	var rows [][]InlineKeyboardButton
+	for _, hand := range []user.Hand{user.HandRight, user.HandLeft} {
+		label := handLabels[hand]
+		if hand == current {
+			label = "• " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("profile:hand:%s", string(hand))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К профилю", "profile"),
+	))
+	return "✋ Какой рукой вы играете?", NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatProfileLanguagePicker форматирует выбор языка общения
+func (f *Formatter) FormatProfileLanguagePicker(current user.Language) (string, *InlineKeyboardMarkup) {
+	var rows [][]InlineKeyboardButton
+	for _, lang := range []user.Language{user.LanguageRU, user.LanguageEN} {
+		label := languageLabels[lang]
+		if lang == current {
+			label = "• " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("profile:lang:%s", string(lang))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 К профилю", "profile"),
+	))
+	return "🌐 Выберите язык общения:", NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatProfileLocationsPicker форматирует выбор предпочитаемых локаций (нажатие отмечает или снимает отметку)
+func (f *Formatter) FormatProfileLocationsPicker(selected []location.LocationID, locations []location.Location) (string, *InlineKeyboardMarkup) {
+	text := "📍 Где вы предпочитаете играть?\n\nОтметьте локации. Если ничего не отмечено, подходят любые."
+
+	chosen := make(map[location.LocationID]bool, len(selected))
+	for _, id := range selected {
+		chosen[id] = true
+	}
+	var rows [][]InlineKeyboardButton
+	for _, loc := range locations {
+		label := loc.Name
+		if chosen[loc.ID] {
+			label = "✅ " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("profile:loc:%s", string(loc.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✅ Готово", "profile"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatDeleteMeConfirm форматирует запрос подтверждения удаления данных
+func (f *Formatter) FormatDeleteMeConfirm() (string, *InlineKeyboardMarkup) {
+	text := "🗑 <b>Удаление данных</b>\n\n" +
+		"Будут стерты имя, фамилия, телефон, уровень и настройки профиля. " +
+		"История участия и оплат останется в обезличенном виде, чтобы не нарушить учет событий.\n\n" +
+		fmt.Sprintf("Удаление произойдет через %d дн. — до этого момента его можно отменить командой /deleteme.\n\n", int(user.DeletionGracePeriod.Hours()/24)) +
+		"Перед удалением можно выгрузить свои данные командой /mydata."
+
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("🗑 Да, удалить мои данные", "deleteme:confirm")),
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("❌ Отмена", "deleteme:cancel")),
+	)
+	return text, keyboard
+}
+
+// FormatDeletionScheduled форматирует сообщение о запланированном удалении с кнопкой отмены
+func (f *Formatter) FormatDeletionScheduled(deleteAfter time.Time) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("⏳ Ваши данные будут удалены %s.\n\nДо этого момента удаление можно отменить.",
+		deleteAfter.Format("02.01.2006 15:04"))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("↩️ Отменить удаление", "deleteme:undo")),
+	)
+	return text, keyboard
+}
+
+// FormatDeletionBlocked форматирует отказ в удалении, пока у игрока есть действующие записи
+func (f *Formatter) FormatDeletionBlocked(events []event.Event) string {
+	text := "⛔ Нельзя удалить данные, пока вы записаны на предстоящие события:\n"
+	for _, evt := range events {
+		text += fmt.Sprintf("\n• %s — %s", html.EscapeString(evt.Name), evt.Date.Format("02.01.2006 15:04"))
+	}
+	text += "\n\nОтмените записи и повторите /deleteme."
+	return text
+}
+
+// restrictionScope описывает словами, на что действует ограничение
+func restrictionScope(r *restriction.Restriction, locationNames map[location.LocationID]string) string {
+	switch r.Kind {
+	case restriction.KindBan:
+		return "полный бан"
+	case restriction.KindUntil:
+		return fmt.Sprintf("запрет записи до %s", r.Until.Format("02.01.2006 15:04"))
+	case restriction.KindLocations:
+		names := make([]string, 0, len(r.LocationIDs))
+		for _, id := range r.LocationIDs {
+			if name, ok := locationNames[id]; ok {
+				names = append(names, html.EscapeString(name))
+			} else {
+				names = append(names, string(id))
+			}
+		}
+		return fmt.Sprintf("запрет записи в локациях: %s", strings.Join(names, ", "))
+	default:
+		return string(r.Kind)
+	}
+}
+
+// formatRestriction форматирует ограничение для карточки игрока
+func formatRestriction(r *restriction.Restriction, adminNames map[int64]string, locationNames map[location.LocationID]string, now time.Time) string {
+	line := fmt.Sprintf("%s\n   Причина: %s\n   Установил: %s, %s\n",
+		restrictionScope(r, locationNames), html.EscapeString(r.Reason),
+		html.EscapeString(adminNames[r.CreatedBy]), r.CreatedAt.Format("02.01.2006"))
+	switch {
+	case !r.LiftedAt.IsZero():
+		line += fmt.Sprintf("   Снял: %s, %s\n", html.EscapeString(adminNames[r.LiftedBy]), r.LiftedAt.Format("02.01.2006"))
+	case !r.ActiveAt(now):
+		line += "   Истекло\n"
+	}
+	return line
+}
+
+// maxRestrictionHistory - сколько снятых и истекших ограничений показывать в карточке игрока
+const maxRestrictionHistory = 5
+
+// FormatUserCard форматирует карточку игрока для администратора с действующими ограничениями и историей.
+// Кнопки управления ограничениями показываются, только если canManage
+func (f *Formatter) FormatUserCard(usr *user.User, restrictions []restriction.Restriction, adminNames map[int64]string, locationNames map[location.LocationID]string, canManage bool, now time.Time) (string, *InlineKeyboardMarkup) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("👤 <b>%s</b> (<code>%d</code>)\n\n", html.EscapeString(usr.FullName()), usr.TelegramID))
+	if usr.Phone != "" {
+		b.WriteString(fmt.Sprintf("📱 Телефон: %s\n", usr.Phone))
+	}
+	level := "не определен"
+	if usr.Level.IsSet() {
+		level = usr.Level.String()
+	}
+	b.WriteString(fmt.Sprintf("🎯 Уровень: %s\n", level))
+	if usr.DeletionRequested() {
+		b.WriteString(fmt.Sprintf("🗑 Запросил удаление данных: %s\n", usr.DeleteAfter.Format("02.01.2006")))
+	}
+
+	var active, history []restriction.Restriction
+	for _, r := range restrictions {
+		if r.ActiveAt(now) {
+			active = append(active, r)
+		} else {
+			history = append(history, r)
+		}
+	}
+
+	if len(active) == 0 {
+		b.WriteString("\n✅ Ограничений нет\n")
+	} else {
+		b.WriteString("\n⛔ <b>Действующие ограничения</b>\n")
+		for i := range active {
+			b.WriteString(fmt.Sprintf("%d. %s", i+1, formatRestriction(&active[i], adminNames, locationNames, now)))
+		}
+	}
+	if len(history) > maxRestrictionHistory {
+		history = history[len(history)-maxRestrictionHistory:]
+	}
+	if len(history) > 0 {
+		b.WriteString("\n🗂 <b>История</b>\n")
+		for i := range history {
+			b.WriteString("• " + formatRestriction(&history[i], adminNames, locationNames, now))
+		}
+	}
+
+	var rows [][]InlineKeyboardButton
+	if canManage {
+		for i := range active {
+			rows = append(rows, NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData(fmt.Sprintf("✅ Снять ограничение %d", i+1), fmt.Sprintf("admin:ban:lift:%s", string(active[i].ID))),
+			))
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("⛔ Полный бан", fmt.Sprintf("admin:ban:full:%d", usr.TelegramID)),
+			NewInlineKeyboardButtonData("⏳ Запрет до даты", fmt.Sprintf("admin:ban:until:%d", usr.TelegramID)),
+		))
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📍 Запрет на локациях", fmt.Sprintf("admin:ban:loc:%d", usr.TelegramID)),
+		))
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🎯 Изменить уровень", fmt.Sprintf("admin:level:%d", usr.TelegramID)),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 В меню администратора", "admin:menu"),
+	))
+	return b.String(), NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatRestrictionLocationPicker форматирует выбор локаций, на которые запрещается запись
+func (f *Formatter) FormatRestrictionLocationPicker(name string, selected []location.LocationID, locations []location.Location) (string, *InlineKeyboardMarkup) {
+	text := fmt.Sprintf("📍 Запрет записи для %s\n\nОтметьте локации, на события которых игроку нельзя записываться, и нажмите «Готово».", name)
+
+	chosen := make(map[location.LocationID]bool, len(selected))
+	for _, id := range selected {
+		chosen[id] = true
+	}
+	var rows [][]InlineKeyboardButton
+	for _, loc := range locations {
+		label := loc.Name
+		if chosen[loc.ID] {
+			label = "✅ " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("admin:ban:lt:%s", string(loc.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✅ Готово", "admin:ban:ld"),
+	))
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Отмена", "admin:ban:cancel"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatRestrictionNotice форматирует уведомление игроку об установленном ограничении
+func (f *Formatter) FormatRestrictionNotice(r *restriction.Restriction, locationNames map[location.LocationID]string) string {
+	return fmt.Sprintf("⛔ Администратор ограничил вам запись на события: %s.\nПричина: %s",
+		restrictionScope(r, locationNames), html.EscapeString(r.Reason))
+}
+
+// FormatRestrictionLifted форматирует уведомление игроку о снятом ограничении
+func (f *Formatter) FormatRestrictionLifted(r *restriction.Restriction, locationNames map[location.LocationID]string) string {
+	return fmt.Sprintf("✅ Администратор снял ограничение: %s. Запись на события снова доступна.", restrictionScope(r, locationNames))
+}
+
+// FormatRestrictionBlock форматирует отказ в записи из-за ограничения администратора
+func (f *Formatter) FormatRestrictionBlock(r *restriction.Restriction) string {
+	var text string
+	switch r.Kind {
+	case restriction.KindUntil:
+		text = fmt.Sprintf("⛔ Администратор закрыл вам запись на события до %s.", r.Until.Format("02.01.2006 15:04"))
+	case restriction.KindLocations:
+		text = "⛔ Администратор закрыл вам запись на события этой локации."
+	default:
+		text = "⛔ Администратор закрыл вам запись на события."
+	}
+	return text + fmt.Sprintf("\nПричина: %s", html.EscapeString(r.Reason))
+}
+
+// subscriptionTypeLabels - названия типов событий в подписке
+var subscriptionTypeLabels = map[event.EventType]string{
+	event.EventTypeTraining:    "🏋️ Тренировки",
+	event.EventTypeCompetition: "🏆 Соревнования",
+}
+
+// FormatSubscription форматирует подписку игрока на новые события с кнопками настройки фильтров
+func (f *Formatter) FormatSubscription(sub *subscription.Subscription, locationNames map[location.LocationID]string) (string, *InlineKeyboardMarkup) {
+	text := "📬 <b>Подписка на новые события</b>\n\n"
+	if sub.Enabled {
+		text += "Статус: ✅ включена — бот пришлет сообщение, когда появится подходящее событие.\n\n"
+	} else {
+		text += "Статус: ⏸ выключена\n\n"
+	}
+
+	locations := "любые"
+	if len(sub.LocationIDs) > 0 {
+		names := make([]string, 0, len(sub.LocationIDs))
+		for _, id := range sub.LocationIDs {
+			if name, ok := locationNames[id]; ok {
+				names = append(names, html.EscapeString(name))
+			}
+		}
+		locations = strings.Join(names, ", ")
+	}
+	text += fmt.Sprintf("📍 Локации: %s\n", locations)
+
+	types := "любые"
+	if len(sub.EventTypes) > 0 {
+		labels := make([]string, 0, len(sub.EventTypes))
+		for _, t := range sub.EventTypes {
+			labels = append(labels, subscriptionTypeLabels[t])
+		}
+		types = strings.Join(labels, ", ")
+	}
+	text += fmt.Sprintf("📅 Типы: %s\n", types)
+
+	trainers := "любые"
+	if len(sub.Trainers) > 0 {
+		escaped := make([]string, 0, len(sub.Trainers))
+		for _, name := range sub.Trainers {
+			escaped = append(escaped, html.EscapeString(name))
+		}
+		trainers = strings.Join(escaped, ", ")
+	}
+	text += fmt.Sprintf("👨‍🏫 Тренеры: %s\n", trainers)
+	text += fmt.Sprintf("🎯 Уровень: %s\n", formatLevelRange(sub.MinLevel, sub.MaxLevel))
+
+	toggle := NewInlineKeyboardButtonData("✅ Включить", "subs:on")
+	if sub.Enabled {
+		toggle = NewInlineKeyboardButtonData("⏸ Выключить", "subs:off")
+	}
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(toggle),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("📍 Локации", "subs:locs"),
+			NewInlineKeyboardButtonData("📅 Типы", "subs:types"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("👨‍🏫 Тренеры", "subs:trainers"),
+			NewInlineKeyboardButtonData("🎯 Уровень", "subs:levels"),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔙 Назад", "back:main"),
+		),
+	)
+	return text, keyboard
+}
+
+// FormatSubscriptionLocationsPicker форматирует выбор локаций подписки
+func (f *Formatter) FormatSubscriptionLocationsPicker(selected []location.LocationID, locations []location.Location) (string, *InlineKeyboardMarkup) {
+	text := "📍 О событиях каких локаций сообщать?\n\nЕсли ничего не отмечено, подходят любые."
+
+	chosen := make(map[location.LocationID]bool, len(selected))
+	for _, id := range selected {
+		chosen[id] = true
+	}
+	var rows [][]InlineKeyboardButton
+	for _, loc := range locations {
+		label := loc.Name
+		if chosen[loc.ID] {
+			label = "✅ " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("subs:loc:%s", string(loc.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✅ Готово", "subs"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatSubscriptionTypesPicker форматирует выбор типов событий подписки
+func (f *Formatter) FormatSubscriptionTypesPicker(selected []event.EventType) (string, *InlineKeyboardMarkup) {
+	text := "📅 О каких событиях сообщать?\n\nЕсли ничего не отмечено, подходят любые."
+
+	chosen := make(map[event.EventType]bool, len(selected))
+	for _, t := range selected {
+		chosen[t] = true
+	}
+	var rows [][]InlineKeyboardButton
+	for _, t := range []event.EventType{event.EventTypeTraining, event.EventTypeCompetition} {
+		label := subscriptionTypeLabels[t]
+		if chosen[t] {
+			label = "✅ " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("subs:type:%s", string(t))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✅ Готово", "subs"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatSubscriptionTrainersPicker форматирует выбор тренеров подписки (кнопка ссылается на номер тренера в списке)
+func (f *Formatter) FormatSubscriptionTrainersPicker(selected []string, trainers []string) (string, *InlineKeyboardMarkup) {
+	text := "👨‍🏫 О занятиях каких тренеров сообщать?\n\nЕсли ничего не отмечено, подходят любые."
+	if len(trainers) == 0 {
+		text += "\n\nТренеры появятся в списке, когда будут указаны в событиях."
+	}
+
+	chosen := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		chosen[name] = true
+	}
+	var rows [][]InlineKeyboardButton
+	for i, name := range trainers {
+		label := name
+		if chosen[name] {
+			label = "✅ " + label
+		}
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData(label, fmt.Sprintf("subs:tr:%d", i)),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("✅ Готово", "subs"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatSubscriptionMinLevelPicker форматирует выбор нижней границы уровня событий подписки
+func (f *Formatter) FormatSubscriptionMinLevelPicker() (string, *InlineKeyboardMarkup) {
+	text := "🎯 С какого уровня показывать события?"
+
+	rows := [][]InlineKeyboardButton{
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("Любой", fmt.Sprintf("subs:min:%d", int(user.LevelUnset)))),
+	}
+	var row []InlineKeyboardButton
+	for _, l := range user.Levels() {
+		row = append(row, NewInlineKeyboardButtonData(l.String(), fmt.Sprintf("subs:min:%d", int(l))))
+		if len(row) == 4 {
+			rows = append(rows, NewInlineKeyboardRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, NewInlineKeyboardRow(row...))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "subs"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatSubscriptionMaxLevelPicker форматирует выбор верхней границы уровня (не ниже выбранной нижней)
+func (f *Formatter) FormatSubscriptionMaxLevelPicker(min user.Level) (string, *InlineKeyboardMarkup) {
+	text := "🎯 До какого уровня показывать события?"
+	if min.IsSet() {
+		text = fmt.Sprintf("🎯 Уровень от %s. До какого уровня показывать события?", min)
+	}
+
+	rows := [][]InlineKeyboardButton{
+		NewInlineKeyboardRow(NewInlineKeyboardButtonData("Любой", fmt.Sprintf("subs:lvl:%d:%d", int(min), int(user.LevelUnset)))),
+	}
+	var row []InlineKeyboardButton
+	for _, l := range user.Levels() {
+		if min.IsSet() && l < min {
+			continue
+		}
+		row = append(row, NewInlineKeyboardButtonData(l.String(), fmt.Sprintf("subs:lvl:%d:%d", int(min), int(l))))
+		if len(row) == 4 {
+			rows = append(rows, NewInlineKeyboardRow(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, NewInlineKeyboardRow(row...))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🔙 Назад", "subs:levels"),
+	))
+	return text, NewInlineKeyboardMarkup(rows...)
+}
+
+// FormatSubscriptionEventNotice форматирует личное сообщение подписчику о новом событии с кнопкой записи
+func (f *Formatter) FormatSubscriptionEventNotice(evt *event.Event, locationName, botUsername string) (string, *InlineKeyboardMarkup) {
+	text, _ := f.FormatChannelEventAnnouncement(evt, locationName, botUsername)
+	text = "📬 Новое событие по вашей подписке\n\n" + text
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("✅ Записаться", fmt.Sprintf("event:register:%s", string(evt.ID))),
+		),
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("ℹ️ Подробнее", fmt.Sprintf("event:%s", string(evt.ID))),
+			NewInlineKeyboardButtonData("📬 Подписка", "subs"),
+		),
+	)
 	return text, keyboard
 }
@@ -0,0 +1,361 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/domain/role"
+	"strings"
+	"time"
+)
+
+// maxGuestNameLength - максимальная длина имени гостя
+const maxGuestNameLength = 64
+
+// handleGuestAddStart запрашивает имя гостя, которого игрок хочет записать с собой (формат: event:guest_add:{eventID})
+func (h *Handlers) handleGuestAddStart(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "event:guest_add:"))
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for guest", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if err := guestAllowed(evt, cb.From.ID); err != nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, guestErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.addingGuests[cb.Message.ChatID] = eventID
+	text := "👤 Введите имя и фамилию гостя (у гостя может не быть Telegram):\n\nДля отмены отправьте /cancel"
+	if err := h.client.SendMessage(cb.Message.ChatID, text); err != nil {
+		h.logger.Error("failed to send guest name prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// guestAllowed проверяет, может ли игрок сейчас записать на событие еще одного гостя
+func guestAllowed(evt *event.Event, hostID int64) error {
+	if !evt.IsOpen(time.Now()) {
+		return event.ErrEventNotOpen
+	}
+	if evt.Doubles {
+		return event.ErrGuestsNotAllowed
+	}
+	if reg, ok := evt.Registrations[hostID]; !ok || !reg.Status.HoldsSpot() {
+		return event.ErrHostNotRegistered
+	}
+	if len(evt.ActiveGuestsOf(hostID)) >= event.MaxGuestsPerHost {
+		return event.ErrGuestLimit
+	}
+	if evt.Remaining <= 0 || len(evt.Waitlist()) > 0 {
+		return event.ErrEventFull
+	}
+	return nil
+}
+
+// handleGuestNameInput записывает гостя с введенным именем и сообщает модераторам события
+func (h *Handlers) handleGuestNameInput(ctx context.Context, msg *Message, eventID event.EventID) {
+	name := strings.TrimSpace(msg.Text)
+	if name == "/cancel" {
+		delete(h.addingGuests, msg.ChatID)
+		if err := h.client.SendMessage(msg.ChatID, "❌ Добавление гостя отменено"); err != nil {
+			h.logger.Error("failed to send cancel message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+	if name == "" || len([]rune(name)) > maxGuestNameLength {
+		if err := h.client.SendMessage(msg.ChatID, fmt.Sprintf("❌ Имя гостя должно быть непустым и не длиннее %d символов. Введите еще раз или /cancel:", maxGuestNameLength)); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+	delete(h.addingGuests, msg.ChatID)
+	hostID := msg.From.ID
+
+	var guest *event.Guest
+	err := retryOnConflict(func() (err error) {
+		guest, err = h.eventService.AddGuest(ctx, eventID, hostID, name)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to add guest", "event_id", string(eventID), "user_id", hostID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, guestErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	h.logger.Info("guest added", "event_id", string(eventID), "user_id", hostID, "guest_id", string(guest.ID))
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event after adding guest", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "✅ Гость добавлен и ждет подтверждения администратора"); sendErr != nil {
+			h.logger.Error("failed to send success message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := fmt.Sprintf("✅ Гость %s добавлен и ждет подтверждения администратора", html.EscapeString(guest.Name))
+	if evt.Price > 0 {
+		text += fmt.Sprintf("\n\n💵 Участие гостя оплачивается отдельно: %s", formatPrice(evt))
+		if evt.PaymentPhone != "" {
+			text += fmt.Sprintf(" по номеру %s", evt.PaymentPhone)
+		}
+		text += ". Администратор отметит оплату после перевода."
+	}
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send guest added message", "chat_id", msg.ChatID, "error", err)
+	}
+
+	h.notifyGuestAdded(ctx, evt, guest)
+
+	details, keyboard := h.formatter.FormatEventDetailsForUsers(evt, hostID, h.viewerLevel(ctx, hostID), h.cancellationPolicy(ctx, evt))
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, details, keyboard); err != nil {
+		h.logger.Error("failed to send event details", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// notifyGuestAdded сообщает модераторам события о новом госте, которого нужно подтвердить
+func (h *Handlers) notifyGuestAdded(ctx context.Context, evt *event.Event, guest *event.Guest) {
+	moderatorIDs, err := h.roleService.Holders(ctx, role.PermModerate, role.ForEvent(evt))
+	if err != nil {
+		h.logger.Error("failed to get moderators for guest", "event_id", string(evt.ID), "error", err)
+		return
+	}
+
+	text := fmt.Sprintf("👤 Новый гость на подтверждение\n\n📅 %s\n🗓️ %s\n➕ %s (гость игрока %s)",
+		evt.Name, evt.Date.Format("02.01.2006 15:04"), html.EscapeString(guest.Name), h.playerName(ctx, guest.HostID))
+	keyboard := NewInlineKeyboardMarkup(NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("👤 Гости события", fmt.Sprintf("admin:guests:%s", string(evt.ID))),
+	))
+	for _, id := range moderatorIDs {
+		if err := h.client.SendMessageWithKeyboard(id, text, keyboard); err != nil {
+			h.logger.Error("failed to notify moderator about guest", "user_id", id, "event_id", string(evt.ID), "error", err)
+		}
+	}
+}
+
+// handleGuestRemove отменяет запись гостя игроком (формат: event:gdel:{eventID}:{guestID})
+func (h *Handlers) handleGuestRemove(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid guest remove callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	eventID := event.EventID(parts[2])
+	guestID := event.GuestID(parts[3])
+	hostID := cb.From.ID
+
+	var promoted []event.EventRegistration
+	var late bool
+	err := retryOnConflict(func() (err error) {
+		promoted, late, err = h.eventService.RemoveGuest(ctx, eventID, hostID, guestID)
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to remove guest", "event_id", string(eventID), "user_id", hostID, "guest_id", string(guestID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, guestErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if late {
+		h.logger.Info("late guest cancellation", "event_id", string(eventID), "user_id", hostID, "guest_id", string(guestID))
+	}
+
+	h.notifyWaitlistPromoted(ctx, eventID, promoted)
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "✅ Запись гостя отменена"); sendErr != nil {
+			h.logger.Error("failed to send success message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, hostID, h.viewerLevel(ctx, hostID), h.cancellationPolicy(ctx, evt))
+	if late {
+		text = "↩️ Запись гостя отменена после срока бесплатной отмены: оплата за гостя не возвращается\n\n" + text
+	}
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with event details", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminGuests показывает гостей события модератору (формат: admin:guests:{eventID})
+func (h *Handlers) handleAdminGuests(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "admin:guests:"))
+	h.showEventGuests(ctx, cb.Message.ChatID, cb.Message.MessageID, cb.From.ID, eventID)
+}
+
+// showEventGuests выводит гостей события с кнопками модерации; оплаты гостей видит тот, кто ведет журнал оплат
+func (h *Handlers) showEventGuests(ctx context.Context, chatID int64, messageID int, viewerID int64, eventID event.EventID) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for guests", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	hostNames := make(map[int64]string)
+	for _, guest := range evt.Guests {
+		if _, ok := hostNames[guest.HostID]; !ok {
+			hostNames[guest.HostID] = h.playerName(ctx, guest.HostID)
+		}
+	}
+
+	var balances map[event.GuestID]payment.Balance
+	if evt.Price > 0 && h.can(ctx, viewerID, role.PermManagePayments, role.ForEvent(evt)) {
+		balances, err = h.paymentService.GuestBalances(ctx, evt)
+		if err != nil {
+			h.logger.Error("failed to get guest payment balances", "event_id", string(eventID), "chat_id", chatID, "error", err)
+		}
+	}
+
+	text, keyboard := h.formatter.FormatEventGuests(evt, hostNames, balances)
+	if err := h.client.EditMessageTextAndMarkup(chatID, messageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with event guests", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminGuestAction подтверждает, отклоняет гостя или отмечает оплату за него
+// (формат: admin:guest:{ok|no|pay}:{eventID}:{guestID})
+func (h *Handlers) handleAdminGuestAction(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid guest action callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	action := parts[2]
+	eventID := event.EventID(parts[3])
+	guestID := event.GuestID(parts[4])
+
+	if action == "pay" {
+		h.toggleGuestPayment(ctx, cb, eventID, guestID)
+		h.showEventGuests(ctx, cb.Message.ChatID, cb.Message.MessageID, cb.From.ID, eventID)
+		return
+	}
+
+	var guest *event.Guest
+	var promoted []event.EventRegistration
+	err := retryOnConflict(func() (err error) {
+		switch action {
+		case "ok":
+			guest, err = h.eventService.ApproveGuest(ctx, eventID, guestID)
+		case "no":
+			guest, promoted, err = h.eventService.RejectGuest(ctx, eventID, guestID)
+		default:
+			err = fmt.Errorf("unknown guest action %q", action)
+		}
+		return err
+	})
+	if err != nil {
+		h.logger.Error("failed to moderate guest", "event_id", string(eventID), "guest_id", string(guestID), "action", action, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, guestErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	h.logger.Info("guest moderated", "event_id", string(eventID), "guest_id", string(guestID), "action", action, "admin_id", cb.From.ID)
+
+	h.notifyWaitlistPromoted(ctx, eventID, promoted)
+	h.notifyGuestModerated(ctx, eventID, guest)
+	h.showEventGuests(ctx, cb.Message.ChatID, cb.Message.MessageID, cb.From.ID, eventID)
+}
+
+// toggleGuestPayment записывает в журнал оплат переводом остаток за гостя, а если гость уже оплачен - возврат.
+// Оплата за гостя записывается на регистрацию игрока, который его привел
+func (h *Handlers) toggleGuestPayment(ctx context.Context, cb *CallbackQuery, eventID event.EventID, guestID event.GuestID) {
+	chatID := cb.Message.ChatID
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for guest payment", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+	guest, ok := evt.Guests[guestID]
+	if !ok {
+		if sendErr := h.client.SendMessage(chatID, guestErrorMessage(event.ErrGuestNotFound)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	balances, err := h.paymentService.GuestBalances(ctx, evt)
+	if err != nil {
+		h.logger.Error("failed to get guest payment balances", "event_id", string(eventID), "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения оплат"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	state := &PaymentEntryState{EventID: eventID, UserID: guest.HostID, GuestID: guestID}
+	b := balances[guestID]
+	switch {
+	case b.Owed() > 0:
+		h.recordAdminPayment(ctx, chatID, cb.From.ID, state, payment.KindPayment, payment.MethodTransfer, b.Owed())
+	case b.Paid > 0:
+		h.recordAdminPayment(ctx, chatID, cb.From.ID, state, payment.KindRefund, payment.MethodTransfer, b.Paid)
+	}
+}
+
+// notifyGuestModerated сообщает игроку, что его гостя подтвердили или отклонили
+func (h *Handlers) notifyGuestModerated(ctx context.Context, eventID event.EventID, guest *event.Guest) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for guest notification", "event_id", string(eventID), "error", err)
+		return
+	}
+
+	text := fmt.Sprintf("✅ Ваш гость %s подтвержден", html.EscapeString(guest.Name))
+	if guest.Status == event.RegistrationStatusRejected {
+		text = fmt.Sprintf("❌ Запись вашего гостя %s отклонена", html.EscapeString(guest.Name))
+	}
+	text += fmt.Sprintf("\n\n📅 %s\n🗓️ %s", evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	if err := h.client.SendMessage(guest.HostID, text); err != nil {
+		h.logger.Error("failed to notify host about guest", "user_id", guest.HostID, "event_id", string(eventID), "error", err)
+	}
+}
+
+// guestErrorMessage переводит ошибку записи гостя в сообщение для пользователя
+func guestErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, event.ErrEventNotOpen):
+		return "❌ Запись на событие закрыта"
+	case errors.Is(err, event.ErrGuestsNotAllowed):
+		return "❌ В парном разряде гостей записывать нельзя: пригласите напарника"
+	case errors.Is(err, event.ErrHostNotRegistered):
+		return "❌ Записать гостя можно, только если вы сами записаны на событие"
+	case errors.Is(err, event.ErrGuestLimit):
+		return fmt.Sprintf("❌ Можно записать не больше %d гостей", event.MaxGuestsPerHost)
+	case errors.Is(err, event.ErrEventFull):
+		return "❌ Свободных мест для гостя нет"
+	case errors.Is(err, event.ErrGuestNameRequired):
+		return "❌ Укажите имя гостя"
+	case errors.Is(err, event.ErrGuestNotFound):
+		return "⚠️ Гость не найден или его запись уже отменена"
+	case errors.Is(err, event.ErrRegistrationAlreadyApproved):
+		return "⚠️ Гость уже подтвержден"
+	case errors.Is(err, event.ErrRegistrationAlreadyRejected):
+		return "⚠️ Гость уже отклонен"
+	case errors.Is(err, event.ErrLateCancelBlocked):
+		return "⛔ Срок отмены записи прошел. Чтобы отменить участие гостя, свяжитесь с администратором."
+	case errors.Is(err, event.ErrConflict):
+		return conflictMessage
+	default:
+		return "❌ Ошибка записи гостя"
+	}
+}
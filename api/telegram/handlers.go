@@ -2,35 +2,77 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"log/slog"
-	"os"
 	"pickletlgbot/internal/domain/event"
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/pass"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/domain/promo"
+	"pickletlgbot/internal/domain/rating"
+	"pickletlgbot/internal/domain/reminder"
+	"pickletlgbot/internal/domain/restriction"
+	"pickletlgbot/internal/domain/role"
+	"pickletlgbot/internal/domain/series"
 	"pickletlgbot/internal/domain/settings"
+	"pickletlgbot/internal/domain/subscription"
+	"pickletlgbot/internal/domain/tournament"
 	"pickletlgbot/internal/domain/user"
-	"strconv"
 	"strings"
 	"time"
 )
 
 // EventCreationState хранит состояние создания события
 type EventCreationState struct {
-	Step         string // "type", "max_players", "name", "date", "trainer", "payment_phone", "price"
-	LocationID   location.LocationID
-	EventType    event.EventType
-	MaxPlayers   int
-	EventName    string
-	EventDate    time.Time
-	Trainer      string
-	PaymentPhone string
-	Price        int
+	Step           string // "type", "doubles", "max_players", "name", "date", "duration", "courts", "trainer", "payment_mode", "payment_phone", "price", "level", "pending_timeout"
+	LocationID     location.LocationID
+	EventType      event.EventType
+	Doubles        bool // Парная категория соревнования (места считаются в командах)
+	MaxPlayers     int
+	EventName      string
+	EventDate      time.Time
+	Duration       time.Duration
+	FreeCourts     []location.Court   // Корты, свободные в выбранное время (на шаге "courts")
+	CourtIDs       []location.CourtID // Выбранные корты
+	Trainer        string
+	PaymentMode    event.PaymentMode
+	PaymentPhone   string
+	Price          int
+	MinLevel       user.Level
+	MaxLevel       user.Level
+	PendingTimeout time.Duration
+
+	// Поля для создания повторяющейся серии (вместо "date" шаги "weekdays", "time", "start_date", "series_end", "weeks_ahead")
+	Recurring  bool
+	Weekdays   []time.Weekday
+	StartTime  series.TimeOfDay
+	StartDate  time.Time
+	Until      time.Time
+	Count      int
+	WeeksAhead int
+}
+
+// OccurrenceEditState хранит состояние изменения занятия серии
+type OccurrenceEditState struct {
+	EventID event.EventID
+	Field   string // "name", "time", "max"
+	Input   series.UpdateSeriesInput
+}
+
+// EventEditState хранит состояние редактирования события
+type EventEditState struct {
+	EventID    event.EventID
+	Field      string             // "name", "date", "duration", "max", "trainer", "price", "pay", "phone", "description", "location", "type", "doubles", "courts", "cancel"
+	FreeCourts []location.Court   // Для "courts": корты, свободные во время события
+	CourtIDs   []location.CourtID // Для "courts": выбранные корты
 }
 
 // UserRegistrationState хранит состояние регистрации пользователя на событие
 type UserRegistrationState struct {
-	EventID   event.EventID
-	Step      string // "name", "surname"
-	FirstName string
+	EventID       event.EventID
+	Step          string // "name", "surname"
+	FirstName     string
+	TeamCaptainID int64 // Если задан, после регистрации игрок принимает приглашение в команду этого капитана
 }
 
 // LocationCreationState хранит состояние создания локации
@@ -41,16 +83,76 @@ type LocationCreationState struct {
 	AddressMapURL string
 }
 
+// ScoreEntryState хранит состояние ввода счета матча турнира
+type ScoreEntryState struct {
+	EventID event.EventID
+	MatchID int
+}
+
+// RoleGrantState хранит состояние назначения роли
+type RoleGrantState struct {
+	TelegramID int64 // 0 - ожидается ввод Telegram ID пользователя
+	Role       role.Role
+}
+
+// PaymentEntryState хранит состояние записи оплаты или возврата по регистрации
+type PaymentEntryState struct {
+	EventID event.EventID
+	UserID  int64
+	GuestID event.GuestID  // Гость игрока, за которого проводится операция (пусто - за самого игрока)
+	Kind    payment.Kind   // Пусто - открыта карточка оплат игрока, сумма не ожидается
+	Method  payment.Method // Способ оплаты или возврата, для которого ожидается ввод суммы
+}
+
+// PassIssueState хранит состояние выдачи абонемента
+type PassIssueState struct {
+	UserID      int64
+	Step        string // sessions, days, type, locations
+	Sessions    int
+	Days        int
+	EventTypes  []event.EventType     // Пусто - любые типы
+	LocationIDs []location.LocationID // Пусто - любые локации
+}
+
+// RestrictionState хранит состояние установки ограничения игроку
+type RestrictionState struct {
+	UserID      int64
+	Kind        restriction.Kind
+	Step        string // until, locations, reason
+	Until       time.Time
+	LocationIDs []location.LocationID
+}
+
+// PromoCreationState хранит состояние создания промокода
+type PromoCreationState struct {
+	Step           string // code, kind, value, max_uses, per_user, days, scope, event
+	Code           string
+	Kind           promo.Kind
+	Value          int
+	MaxUses        int
+	MaxUsesPerUser int
+	Days           int
+}
+
 // Handlers обрабатывает обновления от Telegram и маппит их в вызовы бизнес-сервисов
 type Handlers struct {
-	locationService location.LocationService
-	eventService    event.EventService
-	userService     user.UserService
-	settingsService settings.Service
-	client          *Client
-	formatter       *Formatter
-	adminIDs        []int64
-	logger          *slog.Logger
+	locationService     location.LocationService
+	eventService        event.EventService
+	userService         user.UserService
+	settingsService     settings.Service
+	seriesService       series.Service
+	reminderService     reminder.Service
+	tournamentService   tournament.Service
+	ratingService       rating.Service
+	roleService         role.Service
+	paymentService      payment.Service
+	passService         pass.Service
+	promoService        promo.Service
+	restrictionService  restriction.Service
+	subscriptionService subscription.Service
+	client              *Client
+	formatter           *Formatter
+	logger              *slog.Logger
 	// Временное хранилище для состояния создания событий
 	creatingEvents map[int64]*EventCreationState
 	// Временное хранилище для состояния регистрации пользователей
@@ -59,6 +161,62 @@ type Handlers struct {
 	creatingLocations map[int64]*LocationCreationState
 	// Временное хранилище для состояния настройки канала
 	settingChannel map[int64]bool
+	// Временное хранилище для состояния настройки времени брони
+	settingPendingTimeout map[int64]bool
+	// Временное хранилище для состояния изменения занятий серии
+	editingOccurrences map[int64]*OccurrenceEditState
+	// Временное хранилище для состояния редактирования событий
+	editingEvents map[int64]*EventEditState
+	// Временное хранилище для состояния настройки напоминаний о событиях
+	settingEventReminders map[int64]bool
+	// Временное хранилище для состояния настройки политики неявок
+	settingNoShowPolicy map[int64]bool
+	// Временное хранилище для состояния настройки правила отмены записи
+	settingCancelPolicy map[int64]bool
+	// Временное хранилище для состояния ввода счета матча турнира
+	enteringScores map[int64]*ScoreEntryState
+	// Временное хранилище для состояния добавления корта (локация, к которой добавляется корт)
+	addingCourts map[int64]location.LocationID
+	// Временное хранилище для состояния назначения роли
+	grantingRoles map[int64]*RoleGrantState
+	// Временное хранилище для состояния записи оплаты или возврата
+	enteringPayments map[int64]*PaymentEntryState
+	// Временное хранилище для состояния выдачи абонемента
+	issuingPasses map[int64]*PassIssueState
+	// Временное хранилище для состояния создания промокода
+	creatingPromos map[int64]*PromoCreationState
+	// Временное хранилище для ввода промокода игроком (событие, на которое он записан)
+	enteringPromos map[int64]event.EventID
+	// Временное хранилище для ввода имени гостя игроком (событие, на которое записывается гость)
+	addingGuests map[int64]event.EventID
+	// Временное хранилище для поля профиля, которое вводит игрок (name, surname, phone)
+	editingProfile map[int64]string
+	// Временное хранилище для состояния установки ограничения игроку
+	restrictingUsers map[int64]*RestrictionState
+
+	// Общий тикер, ограничивающий частоту рассылки подписчикам
+	notifyTicker *time.Ticker
+	// Очередь опубликованных событий для рассылки подписчикам (разбирает StartSubscriberNotifier)
+	subscriberNotices chan event.EventID
+}
+
+// maxConflictAttempts - сколько раз выполнять операцию с событием при конфликте параллельного изменения
+const maxConflictAttempts = 3
+
+// conflictMessage - сообщение пользователю, если событие не удалось изменить из-за параллельных изменений
+const conflictMessage = "⚠️ Событие сейчас изменяется другим запросом, попробуйте еще раз"
+
+// retryOnConflict повторяет операцию, пока событие изменяется параллельно (event.ConflictError)
+func retryOnConflict(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < maxConflictAttempts; attempt++ {
+		err = fn()
+		var conflict *event.ConflictError
+		if !errors.As(err, &conflict) {
+			return err
+		}
+	}
+	return err
 }
 
 // NewHandlers создает новый набор обработчиков
@@ -67,30 +225,74 @@ func NewHandlers(
 	eventService event.EventService,
 	userService user.UserService,
 	settingsService settings.Service,
+	seriesService series.Service,
+	reminderService reminder.Service,
+	tournamentService tournament.Service,
+	ratingService rating.Service,
+	roleService role.Service,
+	paymentService payment.Service,
+	passService pass.Service,
+	promoService promo.Service,
+	restrictionService restriction.Service,
+	subscriptionService subscription.Service,
 	client *Client,
 ) *Handlers {
-	adminIDs := parseAdminIDs()
 	logger := slog.Default()
 	return &Handlers{
-		locationService:   locationService,
-		eventService:      eventService,
-		userService:       userService,
-		settingsService:   settingsService,
-		client:            client,
-		formatter:         NewFormatter(),
-		adminIDs:          adminIDs,
-		logger:            logger,
-		creatingEvents:    make(map[int64]*EventCreationState),
-		registeringUsers:  make(map[int64]*UserRegistrationState),
-		creatingLocations: make(map[int64]*LocationCreationState),
-		settingChannel:    make(map[int64]bool),
+		locationService:       locationService,
+		eventService:          eventService,
+		userService:           userService,
+		settingsService:       settingsService,
+		seriesService:         seriesService,
+		reminderService:       reminderService,
+		tournamentService:     tournamentService,
+		ratingService:         ratingService,
+		roleService:           roleService,
+		paymentService:        paymentService,
+		passService:           passService,
+		promoService:          promoService,
+		restrictionService:    restrictionService,
+		subscriptionService:   subscriptionService,
+		notifyTicker:          time.NewTicker(subscriberNotifyInterval),
+		subscriberNotices:     make(chan event.EventID, subscriberNoticeQueueSize),
+		client:                client,
+		formatter:             NewFormatter(),
+		logger:                logger,
+		creatingEvents:        make(map[int64]*EventCreationState),
+		registeringUsers:      make(map[int64]*UserRegistrationState),
+		creatingLocations:     make(map[int64]*LocationCreationState),
+		settingChannel:        make(map[int64]bool),
+		settingPendingTimeout: make(map[int64]bool),
+		editingOccurrences:    make(map[int64]*OccurrenceEditState),
+		editingEvents:         make(map[int64]*EventEditState),
+		settingEventReminders: make(map[int64]bool),
+		settingNoShowPolicy:   make(map[int64]bool),
+		settingCancelPolicy:   make(map[int64]bool),
+		enteringScores:        make(map[int64]*ScoreEntryState),
+		addingCourts:          make(map[int64]location.LocationID),
+		grantingRoles:         make(map[int64]*RoleGrantState),
+		enteringPayments:      make(map[int64]*PaymentEntryState),
+		issuingPasses:         make(map[int64]*PassIssueState),
+		creatingPromos:        make(map[int64]*PromoCreationState),
+		enteringPromos:        make(map[int64]event.EventID),
+		addingGuests:          make(map[int64]event.EventID),
+		editingProfile:        make(map[int64]string),
+		restrictingUsers:      make(map[int64]*RestrictionState),
 	}
 }
 
 // HandleUpdate обрабатывает обновление от Telegram
 func (h *Handlers) HandleUpdate(update *Update) {
 	if update.Message != nil {
-		h.HandleMessage(update.Message)
+		if update.Message.SuccessfulPayment != nil {
+			h.handleSuccessfulPayment(update.Message)
+		} else {
+			h.HandleMessage(update.Message)
+		}
+	}
+
+	if update.PreCheckoutQuery != nil {
+		h.handlePreCheckoutQuery(update.PreCheckoutQuery)
 	}
 
 	if update.CallbackQuery != nil {
@@ -129,12 +331,99 @@ func (h *Handlers) HandleMessage(msg *Message) {
 
 	ctx := context.Background()
 
+	// Контакт приходит только по кнопке запроса телефона в профиле
+	if msg.Contact != nil {
+		h.handleContact(ctx, msg)
+		return
+	}
+
+	// Ввод, начатый в панели администратора, принимается только при праве на само действие:
+	// право проверяется заново, так как роль могли снять или сузить после начала ввода
+
 	// Перехватываем пересланные сообщения для настройки канала
-	if h.isAdmin(msg.From.ID) && h.settingChannel[msg.ChatID] {
+	if h.settingChannel[msg.ChatID] && h.can(ctx, msg.From.ID, role.PermManageSettings, role.Global) {
 		h.handleSetChannelInput(ctx, msg)
 		return
 	}
 
+	// Перехватываем ввод времени брони без оплаты
+	if h.settingPendingTimeout[msg.ChatID] && h.can(ctx, msg.From.ID, role.PermManageSettings, role.Global) {
+		h.handleSetPendingTimeoutInput(ctx, msg)
+		return
+	}
+
+	// Перехватываем ввод времени напоминаний о событиях
+	if h.settingEventReminders[msg.ChatID] && h.can(ctx, msg.From.ID, role.PermManageSettings, role.Global) {
+		h.handleSetEventRemindersInput(ctx, msg)
+		return
+	}
+
+	// Перехватываем ввод политики неявок
+	if h.settingNoShowPolicy[msg.ChatID] && h.can(ctx, msg.From.ID, role.PermManageSettings, role.Global) {
+		h.handleSetNoShowPolicyInput(ctx, msg)
+		return
+	}
+
+	// Перехватываем ввод правила отмены записи
+	if h.settingCancelPolicy[msg.ChatID] && h.can(ctx, msg.From.ID, role.PermManageSettings, role.Global) {
+		h.handleSetCancelPolicyInput(ctx, msg)
+		return
+	}
+
+	// Перехватываем ввод нового значения для занятия серии
+	if state := h.editingOccurrences[msg.ChatID]; state != nil && h.can(ctx, msg.From.ID, role.PermManageEvents, h.eventTarget(ctx, state.EventID)) {
+		h.handleAdminOccurrenceEditInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод нового значения поля события
+	if state := h.editingEvents[msg.ChatID]; state != nil && h.can(ctx, msg.From.ID, role.PermManageEvents, h.eventTarget(ctx, state.EventID)) {
+		h.handleAdminEventEditInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод счета матча турнира
+	if state := h.enteringScores[msg.ChatID]; state != nil && h.can(ctx, msg.From.ID, role.PermManageEvents, h.eventTarget(ctx, state.EventID)) {
+		h.handleAdminTournamentScoreInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод названия нового корта
+	if locationID, ok := h.addingCourts[msg.ChatID]; ok && h.can(ctx, msg.From.ID, role.PermManageLocations, role.AtLocation(locationID)) {
+		h.handleAdminAddCourtInput(ctx, msg, locationID)
+		return
+	}
+
+	// Перехватываем ввод суммы частичной оплаты или возврата
+	if state := h.enteringPayments[msg.ChatID]; state != nil && state.Kind != "" && h.can(ctx, msg.From.ID, role.PermManagePayments, h.eventTarget(ctx, state.EventID)) {
+		h.handleAdminPaymentAmountInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод количества занятий и срока действия выдаваемого абонемента
+	if state := h.issuingPasses[msg.ChatID]; state != nil && (state.Step == "sessions" || state.Step == "days") && h.can(ctx, msg.From.ID, role.PermManagePlayers, role.Global) {
+		h.handleAdminPassIssueInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод даты окончания и причины ограничения игрока
+	if state := h.restrictingUsers[msg.ChatID]; state != nil && (state.Step == "until" || state.Step == "reason") && h.can(ctx, msg.From.ID, role.PermManagePlayers, role.Global) {
+		h.handleAdminRestrictionInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод параметров создаваемого промокода
+	if state := h.creatingPromos[msg.ChatID]; state != nil && state.Step != "kind" && state.Step != "scope" && state.Step != "event" && h.can(ctx, msg.From.ID, role.PermManageSettings, role.Global) {
+		h.handleAdminPromoInput(ctx, msg, state)
+		return
+	}
+
+	// Перехватываем ввод Telegram ID пользователя, которому назначается роль
+	if state := h.grantingRoles[msg.ChatID]; state != nil && state.TelegramID == 0 && h.can(ctx, msg.From.ID, role.PermManageRoles, role.Global) {
+		h.handleAdminRoleUserInput(ctx, msg, state)
+		return
+	}
+
 	// Проверяем админ-команды
 	if strings.HasPrefix(msg.Text, "/admin") {
 		h.handleAdminCommand(msg)
@@ -146,6 +435,45 @@ func (h *Handlers) HandleMessage(msg *Message) {
 		h.handleStart(ctx, msg)
 		return
 	}
+	if msg.Text == "/rating" {
+		h.handleRating(ctx, msg)
+		return
+	}
+	if msg.Text == "/pass" {
+		h.handlePass(ctx, msg)
+		return
+	}
+	if msg.Text == "/profile" {
+		delete(h.editingProfile, msg.ChatID)
+		h.handleProfile(ctx, msg)
+		return
+	}
+	if msg.Text == "/mydata" {
+		h.handleMyData(ctx, msg)
+		return
+	}
+	if msg.Text == "/deleteme" {
+		h.handleDeleteMe(ctx, msg)
+		return
+	}
+
+	// Проверяем, не вводит ли игрок поле профиля
+	if field, ok := h.editingProfile[msg.ChatID]; ok {
+		h.handleProfileInput(ctx, msg, field)
+		return
+	}
+
+	// Проверяем, не вводит ли игрок промокод
+	if eventID, ok := h.enteringPromos[msg.ChatID]; ok {
+		h.handlePromoInput(ctx, msg, eventID)
+		return
+	}
+
+	// Проверяем, не вводит ли игрок имя гостя
+	if eventID, ok := h.addingGuests[msg.ChatID]; ok {
+		h.handleGuestNameInput(ctx, msg, eventID)
+		return
+	}
 
 	// Проверяем, не регистрируется ли пользователь (ввод имени/фамилии)
 	if state := h.getUserRegistrationState(msg.From.ID); state != nil {
@@ -154,14 +482,14 @@ func (h *Handlers) HandleMessage(msg *Message) {
 	}
 
 	// Если это не команда, проверяем, не создается ли что-то админом
-	if h.isAdmin(msg.From.ID) && !strings.HasPrefix(msg.Text, "/") {
+	if h.isStaff(msg.From.ID) && !strings.HasPrefix(msg.Text, "/") {
 		// Проверяем, не создается ли локация
-		if state := h.getCreatingLocationState(msg.ChatID); state != nil {
+		if state := h.getCreatingLocationState(msg.ChatID); state != nil && h.can(ctx, msg.From.ID, role.PermManageLocations, role.Global) {
 			h.handleAdminCreateLocationStep(ctx, msg, state)
 			return
 		}
 		// Проверяем, не создается ли событие
-		if state := h.getCreatingEventState(msg.ChatID); state != nil {
+		if state := h.getCreatingEventState(msg.ChatID); state != nil && h.can(ctx, msg.From.ID, role.PermManageEvents, role.AtLocation(state.LocationID)) {
 			h.handleAdminCreateEventStep(ctx, msg, state)
 			return
 		}
@@ -187,12 +515,19 @@ func (h *Handlers) HandleCallback(cb *CallbackQuery) {
 
 	// Проверяем админ callback'и
 	if strings.HasPrefix(cb.Data, "admin:") {
-		if !h.isAdmin(cb.From.ID) {
+		if !h.isStaff(cb.From.ID) {
 			if err := h.client.SendMessage(cb.Message.ChatID, "❌ У вас нет прав администратора"); err != nil {
 				h.logger.Error("failed to send admin access denied message", "chat_id", cb.Message.ChatID, "error", err)
 			}
 			return
 		}
+		// Каждый callback требует своего права (с учетом локации и создателя события)
+		if !h.canUseAdminCallback(ctx, cb) {
+			if err := h.client.SendMessage(cb.Message.ChatID, accessDeniedMessage); err != nil {
+				h.logger.Error("failed to send access denied message", "chat_id", cb.Message.ChatID, "error", err)
+			}
+			return
+		}
 		h.handleAdminCallback(ctx, cb)
 		return
 	}
@@ -205,9 +540,15 @@ func (h *Handlers) HandleCallback(cb *CallbackQuery) {
 		h.handleEvents(ctx, cb)
 	case "back:main":
 		h.handleBackToMain(cb)
+	case "reminders":
+		h.handleReminderSettings(ctx, cb)
+	case "profile":
+		h.handleProfileCallback(ctx, cb)
+	case "subs":
+		h.handleSubscriptionCallback(ctx, cb)
 	case "admin":
 		// Обработка кнопки "Администратор" из главного меню
-		if !h.isAdmin(cb.From.ID) {
+		if !h.isStaff(cb.From.ID) {
 			if err := h.client.SendMessage(cb.Message.ChatID, "❌ У вас нет прав администратора"); err != nil {
 				h.logger.Error("failed to send admin access denied message", "chat_id", cb.Message.ChatID, "error", err)
 			}
@@ -219,7 +560,15 @@ func (h *Handlers) HandleCallback(cb *CallbackQuery) {
 		}
 	default:
 		// Обработка динамических callback'ов
-		if strings.HasPrefix(cb.Data, "loc:events:") {
+		if strings.HasPrefix(cb.Data, "reminders:") {
+			h.handleReminderToggle(ctx, cb)
+		} else if strings.HasPrefix(cb.Data, "profile:") {
+			h.handleProfileCallback(ctx, cb)
+		} else if strings.HasPrefix(cb.Data, "deleteme:") {
+			h.handleDeleteMeCallback(ctx, cb)
+		} else if strings.HasPrefix(cb.Data, "subs:") {
+			h.handleSubscriptionCallback(ctx, cb)
+		} else if strings.HasPrefix(cb.Data, "loc:events:") {
 			h.handleLocationEvents(ctx, cb)
 		} else if strings.HasPrefix(cb.Data, "loc:") {
 			h.handleLocationSelection(ctx, cb)
@@ -227,8 +576,26 @@ func (h *Handlers) HandleCallback(cb *CallbackQuery) {
 			// Обработка callback'ов для событий
 			if strings.HasPrefix(cb.Data, "event:register:") {
 				h.handleEventRegistration(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:unreg_ask:") {
+				h.handleEventUnregisterAsk(ctx, cb)
 			} else if strings.HasPrefix(cb.Data, "event:unregister:") {
 				h.handleEventUnregister(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:bracket:") {
+				h.handleTournamentBracket(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:invite:") {
+				h.handleTeamInviteLink(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:tm_ok:") {
+				h.handleTeamInviteAccept(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:tm_no:") {
+				h.handleTeamInviteDecline(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:level_request:") {
+				h.handleLevelRequest(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:promo:") {
+				h.handlePromoStart(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:guest_add:") {
+				h.handleGuestAddStart(ctx, cb)
+			} else if strings.HasPrefix(cb.Data, "event:gdel:") {
+				h.handleGuestRemove(ctx, cb)
 			} else if strings.HasPrefix(cb.Data, "event:users:") {
 				h.handleEventUsersList(ctx, cb)
 			} else if strings.HasPrefix(cb.Data, "event:") {
@@ -239,16 +606,6 @@ func (h *Handlers) HandleCallback(cb *CallbackQuery) {
 	}
 }
 
-// isAdmin проверяет, является ли пользователь администратором
-func (h *Handlers) isAdmin(userID int64) bool {
-	for _, id := range h.adminIDs {
-		if id == userID {
-			return true
-		}
-	}
-	return false
-}
-
 // getCreatingEventState возвращает состояние создания события для чата
 func (h *Handlers) getCreatingEventState(chatID int64) *EventCreationState {
 	return h.creatingEvents[chatID]
@@ -283,24 +640,3 @@ func (h *Handlers) setUserRegistrationState(userID int64, state *UserRegistratio
 func (h *Handlers) clearUserRegistrationState(userID int64) {
 	delete(h.registeringUsers, userID)
 }
-
-// parseAdminIDs парсит список ID администраторов из переменной окружения
-func parseAdminIDs() []int64 {
-	adminIDsStr := os.Getenv("ADMIN_IDS")
-	if adminIDsStr == "" {
-		return nil
-	}
-
-	var adminIDs []int64
-	ids := strings.Split(adminIDsStr, ",")
-	for _, idStr := range ids {
-		idStr = strings.TrimSpace(idStr)
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			continue
-		}
-		adminIDs = append(adminIDs, id)
-	}
-
-	return adminIDs
-}
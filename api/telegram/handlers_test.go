@@ -0,0 +1,379 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/payment"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// botAPIStub - локальная заглушка Bot API: запоминает вызванные методы и отвечает успехом
+type botAPIStub struct {
+	mu    sync.Mutex
+	calls []botAPICall
+}
+
+type botAPICall struct {
+	Method string
+	Params url.Values
+}
+
+func (s *botAPIStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	s.mu.Lock()
+	s.calls = append(s.calls, botAPICall{Method: method, Params: r.Form})
+	s.mu.Unlock()
+
+	var result any = true
+	switch method {
+	case "getMe":
+		result = map[string]any{"id": 1, "is_bot": true, "first_name": "Bot", "username": "test_bot"}
+	case "sendMessage":
+		chatID, _ := json.Number(r.Form.Get("chat_id")).Int64()
+		result = map[string]any{"message_id": 1, "date": time.Now().Unix(), "chat": map[string]any{"id": chatID}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+func (s *botAPIStub) called(method string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var params []url.Values
+	for _, c := range s.calls {
+		if c.Method == method {
+			params = append(params, c.Params)
+		}
+	}
+	return params
+}
+
+// memoryEventRepository - хранилище событий в памяти
+type memoryEventRepository struct {
+	mu     sync.Mutex
+	events map[event.EventID]*event.Event
+}
+
+func (r *memoryEventRepository) GetByID(ctx context.Context, id event.EventID) (*event.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	evt, ok := r.events[id]
+	if !ok {
+		return nil, event.ErrEventNotFound
+	}
+	return cloneEvent(evt), nil
+}
+
+func (r *memoryEventRepository) List(ctx context.Context) ([]event.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []event.Event
+	for _, evt := range r.events {
+		events = append(events, *cloneEvent(evt))
+	}
+	return events, nil
+}
+
+func (r *memoryEventRepository) ListByLocation(ctx context.Context, locationID location.LocationID) ([]event.Event, error) {
+	return nil, nil
+}
+
+func (r *memoryEventRepository) ListByUser(ctx context.Context, userID int64) ([]event.Event, error) {
+	return nil, nil
+}
+
+func (r *memoryEventRepository) ListBySeries(ctx context.Context, seriesID string) ([]event.Event, error) {
+	return nil, nil
+}
+
+func (r *memoryEventRepository) Save(ctx context.Context, evt *event.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[evt.ID] = cloneEvent(evt)
+	return nil
+}
+
+func (r *memoryEventRepository) Update(ctx context.Context, id event.EventID, fn func(evt *event.Event) error) (*event.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.events[id]
+	if !ok {
+		return nil, event.ErrEventNotFound
+	}
+	evt := cloneEvent(stored)
+	if err := fn(evt); err != nil {
+		return nil, err
+	}
+	r.events[id] = cloneEvent(evt)
+	return evt, nil
+}
+
+func (r *memoryEventRepository) Delete(ctx context.Context, id event.EventID) error {
+	return nil
+}
+
+func (r *memoryEventRepository) ListNoShowDates(ctx context.Context, userID int64, since time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (r *memoryEventRepository) SaveAnnouncement(ctx context.Context, announcement event.Announcement) error {
+	return nil
+}
+
+func (r *memoryEventRepository) ListAnnouncements(ctx context.Context, id event.EventID) ([]event.Announcement, error) {
+	return nil, nil
+}
+
+func cloneEvent(evt *event.Event) *event.Event {
+	c := *evt
+	c.Registrations = make(map[int64]event.EventRegistration, len(evt.Registrations))
+	for id, reg := range evt.Registrations {
+		c.Registrations[id] = reg
+	}
+	c.Guests = make(map[event.GuestID]event.Guest, len(evt.Guests))
+	for id, g := range evt.Guests {
+		c.Guests[id] = g
+	}
+	return &c
+}
+
+// memoryPaymentRepository - журнал оплат в памяти
+type memoryPaymentRepository struct {
+	mu       sync.Mutex
+	payments []payment.Payment
+}
+
+func (r *memoryPaymentRepository) Save(ctx context.Context, p *payment.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.payments {
+		if p.ChargeID != "" && existing.ChargeID == p.ChargeID {
+			return payment.ErrDuplicateCharge
+		}
+	}
+	r.payments = append(r.payments, *p)
+	return nil
+}
+
+func (r *memoryPaymentRepository) ListByEvent(ctx context.Context, eventID event.EventID) ([]payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []payment.Payment
+	for _, p := range r.payments {
+		if p.EventID == eventID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepository) ListByEvents(ctx context.Context, eventIDs []event.EventID) ([]payment.Payment, error) {
+	var result []payment.Payment
+	for _, id := range eventIDs {
+		payments, _ := r.ListByEvent(ctx, id)
+		result = append(result, payments...)
+	}
+	return result, nil
+}
+
+func (r *memoryPaymentRepository) ListByUser(ctx context.Context, userID int64) ([]payment.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []payment.Payment
+	for _, p := range r.payments {
+		if p.UserID == userID || p.PayerID == userID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// newPaymentTestHandlers собирает обработчики поверх заглушки Bot API и хранилищ в памяти
+func newPaymentTestHandlers(t *testing.T, evt *event.Event) (*Handlers, *botAPIStub, *memoryEventRepository, *memoryPaymentRepository) {
+	t.Helper()
+
+	stub := &botAPIStub{}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("test-token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("failed to create bot: %v", err)
+	}
+
+	eventRepo := &memoryEventRepository{events: map[event.EventID]*event.Event{evt.ID: cloneEvent(evt)}}
+	paymentRepo := &memoryPaymentRepository{}
+	eventService := event.NewEventService(eventRepo, nil, nil, nil, nil, nil)
+
+	h := &Handlers{
+		eventService:   eventService,
+		paymentService: payment.NewService(paymentRepo, eventService),
+		client:         NewClient(bot),
+		formatter:      NewFormatter(),
+		logger:         slog.Default(),
+	}
+	return h, stub, eventRepo, paymentRepo
+}
+
+func TestInvoicePaymentApprovesRegistration(t *testing.T) {
+	const playerID = 42
+	evt := &event.Event{
+		ID:          "evt-1",
+		Name:        "Тренировка",
+		Type:        event.EventTypeTraining,
+		Status:      event.StatusPublished,
+		Date:        time.Now().Add(48 * time.Hour),
+		MaxPlayers:  8,
+		Price:       150,
+		PaymentMode: event.PaymentModeStars,
+		Registrations: map[int64]event.EventRegistration{
+			playerID: {UserID: playerID, Status: event.RegistrationStatusPending, PendingSince: time.Now()},
+		},
+	}
+	evt.RecalculateCapacity()
+
+	h, stub, eventRepo, paymentRepo := newPaymentTestHandlers(t, evt)
+	payload := invoicePayload(evt.ID, playerID)
+
+	h.HandleUpdate(&Update{PreCheckoutQuery: &PreCheckoutQuery{
+		ID:             "pcq-1",
+		From:           &User{ID: playerID},
+		Currency:       event.CurrencyStars,
+		TotalAmount:    150,
+		InvoicePayload: payload,
+	}})
+
+	answers := stub.called("answerPreCheckoutQuery")
+	if len(answers) != 1 {
+		t.Fatalf("answerPreCheckoutQuery called %d times, want 1", len(answers))
+	}
+	if got := answers[0].Get("pre_checkout_query_id"); got != "pcq-1" {
+		t.Errorf("pre_checkout_query_id = %q, want %q", got, "pcq-1")
+	}
+	if got := answers[0].Get("ok"); got != "true" {
+		t.Errorf("pre-checkout ok = %q (error %q), want true", got, answers[0].Get("error_message"))
+	}
+
+	h.HandleUpdate(&Update{Message: &Message{
+		ChatID: playerID,
+		From:   &User{ID: playerID},
+		SuccessfulPayment: &SuccessfulPayment{
+			Currency:                event.CurrencyStars,
+			TotalAmount:             150,
+			InvoicePayload:          payload,
+			TelegramPaymentChargeID: "charge-1",
+		},
+	}})
+
+	stored, err := eventRepo.GetByID(context.Background(), evt.ID)
+	if err != nil {
+		t.Fatalf("failed to get event: %v", err)
+	}
+	if got := stored.Registrations[playerID].Status; got != event.RegistrationStatusApproved {
+		t.Errorf("registration status = %q, want %q", got, event.RegistrationStatusApproved)
+	}
+
+	payments, _ := paymentRepo.ListByEvent(context.Background(), evt.ID)
+	if len(payments) != 1 || payments[0].Amount != 150 || payments[0].ChargeID != "charge-1" {
+		t.Errorf("payments = %+v, want one invoice payment of 150 with charge-1", payments)
+	}
+	if len(stub.called("sendMessage")) == 0 {
+		t.Error("player was not notified about approval")
+	}
+}
+
+func TestPreCheckoutRejectsAmountMismatch(t *testing.T) {
+	const playerID = 42
+	evt := &event.Event{
+		ID:          "evt-2",
+		Name:        "Тренировка",
+		Type:        event.EventTypeTraining,
+		Status:      event.StatusPublished,
+		Date:        time.Now().Add(48 * time.Hour),
+		MaxPlayers:  8,
+		Price:       150,
+		PaymentMode: event.PaymentModeStars,
+		Registrations: map[int64]event.EventRegistration{
+			playerID: {UserID: playerID, Status: event.RegistrationStatusPending, PendingSince: time.Now()},
+		},
+	}
+	evt.RecalculateCapacity()
+
+	h, stub, _, _ := newPaymentTestHandlers(t, evt)
+	h.HandleUpdate(&Update{PreCheckoutQuery: &PreCheckoutQuery{
+		ID:             "pcq-2",
+		From:           &User{ID: playerID},
+		Currency:       event.CurrencyStars,
+		TotalAmount:    100,
+		InvoicePayload: invoicePayload(evt.ID, playerID),
+	}})
+
+	answers := stub.called("answerPreCheckoutQuery")
+	if len(answers) != 1 {
+		t.Fatalf("answerPreCheckoutQuery called %d times, want 1", len(answers))
+	}
+	if answers[0].Get("ok") == "true" {
+		t.Error("pre-checkout accepted, want rejection")
+	}
+	if answers[0].Get("error_message") == "" {
+		t.Error("pre-checkout rejected without error message")
+	}
+}
+
+func TestSuccessfulPaymentRedeliveryIsIgnored(t *testing.T) {
+	const playerID = 42
+	evt := &event.Event{
+		ID:          "evt-3",
+		Name:        "Тренировка",
+		Type:        event.EventTypeTraining,
+		Status:      event.StatusPublished,
+		Date:        time.Now().Add(48 * time.Hour),
+		MaxPlayers:  8,
+		Price:       150,
+		PaymentMode: event.PaymentModeStars,
+		Registrations: map[int64]event.EventRegistration{
+			playerID: {UserID: playerID, Status: event.RegistrationStatusPending, PendingSince: time.Now()},
+		},
+	}
+	evt.RecalculateCapacity()
+
+	h, stub, _, paymentRepo := newPaymentTestHandlers(t, evt)
+	update := &Update{Message: &Message{
+		ChatID: playerID,
+		From:   &User{ID: playerID},
+		SuccessfulPayment: &SuccessfulPayment{
+			Currency:                event.CurrencyStars,
+			TotalAmount:             150,
+			InvoicePayload:          invoicePayload(evt.ID, playerID),
+			TelegramPaymentChargeID: "charge-3",
+		},
+	}}
+
+	h.HandleUpdate(update)
+	sent := len(stub.called("sendMessage"))
+	h.HandleUpdate(update)
+
+	payments, _ := paymentRepo.ListByEvent(context.Background(), evt.ID)
+	if len(payments) != 1 {
+		t.Errorf("payments recorded %d times, want 1", len(payments))
+	}
+	if got := len(stub.called("sendMessage")); got != sent {
+		t.Errorf("redelivered payment sent %d more messages, want none", got-sent)
+	}
+}
@@ -0,0 +1,178 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"pickletlgbot/internal/domain/location"
+	"time"
+)
+
+// ExpirePendingRegistrations напоминает об оплате и снимает неоплаченные брони.
+// Вызывается фоновым планировщиком.
+func (h *Handlers) ExpirePendingRegistrations(ctx context.Context) {
+	timeout, err := h.settingsService.GetPendingTimeout(ctx)
+	if err != nil {
+		h.logger.Error("failed to get pending timeout", "error", err)
+		return
+	}
+	lead, err := h.settingsService.GetPaymentReminderLead(ctx)
+	if err != nil {
+		h.logger.Error("failed to get payment reminder lead", "error", err)
+		return
+	}
+
+	now := time.Now()
+
+	reminders, err := h.eventService.DuePaymentReminders(ctx, now, timeout, lead)
+	if err != nil {
+		h.logger.Error("failed to collect payment reminders", "error", err)
+	}
+	for _, r := range reminders {
+		text, keyboard := h.formatter.FormatPaymentReminder(r.Event, r.Deadline.Sub(now))
+		if err := h.client.SendMessageWithKeyboard(r.Registration.UserID, text, keyboard); err != nil {
+			h.logger.Error("failed to send payment reminder", "user_id", r.Registration.UserID, "event_id", string(r.Event.ID), "error", err)
+			continue
+		}
+		if invoiceAvailable(r.Event) {
+			if err := h.sendInvoice(r.Registration.UserID, r.Registration.UserID, r.Event); err != nil {
+				h.logger.Error("failed to resend invoice", "user_id", r.Registration.UserID, "event_id", string(r.Event.ID), "error", err)
+			}
+		}
+	}
+
+	expired, err := h.eventService.ExpirePendingRegistrations(ctx, now, timeout)
+	if err != nil {
+		h.logger.Error("failed to expire pending registrations", "error", err)
+	}
+	for _, holds := range expired {
+		for _, reg := range holds.Expired {
+			h.logger.Info("pending registration expired", "event_id", string(holds.Event.ID), "user_id", reg.UserID)
+			// Брошенная бронь не должна расходовать промокод
+			h.releasePromo(ctx, holds.Event.ID, reg.UserID)
+			text, keyboard := h.formatter.FormatHoldExpired(holds.Event)
+			if err := h.client.SendMessageWithKeyboard(reg.UserID, text, keyboard); err != nil {
+				h.logger.Error("failed to notify about expired hold", "user_id", reg.UserID, "event_id", string(holds.Event.ID), "error", err)
+			}
+		}
+		h.notifyWaitlistPromoted(ctx, holds.Event.ID, holds.Promoted)
+	}
+}
+
+// SendEventReminders напоминает игрокам о предстоящих событиях.
+// Вызывается фоновым планировщиком.
+func (h *Handlers) SendEventReminders(ctx context.Context) {
+	offsets, err := h.settingsService.GetEventReminderOffsets(ctx)
+	if err != nil {
+		h.logger.Error("failed to get event reminder offsets", "error", err)
+		return
+	}
+
+	now := time.Now()
+	reminders, err := h.reminderService.Due(ctx, now, offsets)
+	if err != nil {
+		h.logger.Error("failed to collect event reminders", "error", err)
+	}
+
+	locations := make(map[location.LocationID]*location.Location)
+	for _, r := range reminders {
+		loc, ok := locations[r.Event.LocationID]
+		if !ok {
+			loc, err = h.locationService.Get(ctx, r.Event.LocationID)
+			if err != nil {
+				h.logger.Warn("failed to get location for event reminder", "location_id", string(r.Event.LocationID), "error", err)
+			}
+			locations[r.Event.LocationID] = loc
+		}
+
+		var phone string
+		if r.Unpaid && !invoiceAvailable(r.Event) {
+			phone = h.paymentPhone(r.Event)
+		}
+
+		text, keyboard := h.formatter.FormatEventReminder(r.Event, loc, r.Event.Date.Sub(now), r.Kind, phone)
+		if err := h.client.SendMessageWithKeyboard(r.UserID, text, keyboard); err != nil {
+			h.logger.Error("failed to send event reminder", "user_id", r.UserID, "event_id", string(r.Event.ID), "kind", string(r.Kind), "error", err)
+			continue
+		}
+		if r.Unpaid && invoiceAvailable(r.Event) {
+			if err := h.sendInvoice(r.UserID, r.UserID, r.Event); err != nil {
+				h.logger.Error("failed to resend invoice", "user_id", r.UserID, "event_id", string(r.Event.ID), "error", err)
+			}
+		}
+	}
+}
+
+// CompletePastEvents переводит прошедшие события в статус completed.
+// Вызывается фоновым планировщиком.
+func (h *Handlers) CompletePastEvents(ctx context.Context) {
+	completed, err := h.eventService.CompletePast(ctx, time.Now())
+	if err != nil {
+		h.logger.Error("failed to complete past events", "error", err)
+	}
+	for _, evt := range completed {
+		h.logger.Info("event completed", "event_id", string(evt.ID))
+	}
+}
+
+// GenerateSeriesEvents создает недостающие занятия повторяющихся серий и публикует их в канал.
+// Вызывается фоновым планировщиком.
+func (h *Handlers) GenerateSeriesEvents(ctx context.Context) {
+	created, err := h.seriesService.GenerateUpcoming(ctx, time.Now())
+	if err != nil {
+		h.logger.Error("failed to generate series events", "error", err)
+	}
+	for i := range created {
+		h.logger.Info("series event created", "event_id", string(created[i].ID), "series_id", created[i].SeriesID)
+		h.publishEventToChannel(ctx, &created[i])
+	}
+}
+
+// AnonymizeDeletedUsers обезличивает игроков, у которых истек срок отмены удаления.
+// Игрок, снова записавшийся на предстоящее событие, обезличивается после него.
+// Вызывается фоновым планировщиком.
+func (h *Handlers) AnonymizeDeletedUsers(ctx context.Context) {
+	now := time.Now()
+	due, err := h.userService.ListDueDeletions(ctx, now)
+	if err != nil {
+		h.logger.Error("failed to list due deletions", "error", err)
+		return
+	}
+	for i := range due {
+		usr := &due[i]
+		active, err := h.activeUpcomingEvents(ctx, usr, now)
+		if err != nil {
+			h.logger.Error("failed to check active registrations", "user_id", usr.TelegramID, "error", err)
+			continue
+		}
+		if len(active) > 0 {
+			continue
+		}
+		if _, err := h.userService.Anonymize(ctx, usr.TelegramID, now); err != nil {
+			h.logger.Error("failed to anonymize user", "user_id", usr.TelegramID, "error", err)
+			continue
+		}
+		h.logger.Info("user anonymized", "user_id", usr.TelegramID)
+		// Имя игрока сохранено и в сетках турниров, а имена его гостей - в записях событий
+		if err := h.tournamentService.AnonymizePlayer(ctx, usr.TelegramID); err != nil {
+			h.logger.Error("failed to anonymize tournament participants", "user_id", usr.TelegramID, "error", err)
+		}
+		if err := h.eventService.AnonymizeGuests(ctx, usr.TelegramID); err != nil {
+			h.logger.Error("failed to anonymize guests", "user_id", usr.TelegramID, "error", err)
+		}
+		if err := h.subscriptionService.Delete(ctx, usr.TelegramID); err != nil {
+			h.logger.Error("failed to delete subscription", "user_id", usr.TelegramID, "error", err)
+		}
+		if err := h.client.SendMessage(usr.TelegramID, "🗑 Ваши данные удалены. Спасибо, что играли с нами!"); err != nil {
+			h.logger.Error("failed to notify about deletion", "user_id", usr.TelegramID, "error", err)
+		}
+	}
+}
+
+// formatMinutes форматирует длительность в минутах для сообщений пользователю
+func formatMinutes(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("%d мин", minutes)
+}
@@ -0,0 +1,361 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/user"
+	"sort"
+	"time"
+)
+
+// personalDataExport - выгрузка персональных данных игрока (/mydata)
+type personalDataExport struct {
+	ExportedAt    time.Time               `json:"exported_at"`
+	Profile       exportProfile           `json:"profile"`
+	Registrations []exportRegistration    `json:"registrations"`
+	Payments      []exportPayment         `json:"payments"`
+	Passes        []exportPass            `json:"passes"`
+	Attendance    exportAttendanceSummary `json:"attendance"`
+}
+
+type exportProfile struct {
+	TelegramID         int64      `json:"telegram_id"`
+	Name               string     `json:"name"`
+	Surname            string     `json:"surname,omitempty"`
+	Phone              string     `json:"phone,omitempty"`
+	Level              string     `json:"level,omitempty"`
+	Hand               string     `json:"hand,omitempty"`
+	Language           string     `json:"language"`
+	PreferredLocations []string   `json:"preferred_locations,omitempty"`
+	DeleteAfter        *time.Time `json:"delete_after,omitempty"`
+}
+
+type exportRegistration struct {
+	EventID          string        `json:"event_id"`
+	EventName        string        `json:"event_name"`
+	EventDate        time.Time     `json:"event_date"`
+	EventStatus      string        `json:"event_status"`
+	Status           string        `json:"status"`
+	WaitlistPosition int           `json:"waitlist_position,omitempty"`
+	Attendance       string        `json:"attendance,omitempty"`
+	CheckedInAt      *time.Time    `json:"checked_in_at,omitempty"`
+	PartnerID        int64         `json:"partner_telegram_id,omitempty"`
+	Discount         int           `json:"discount,omitempty"`
+	Guests           []exportGuest `json:"guests,omitempty"`
+	RegisteredAt     time.Time     `json:"registered_at"`
+}
+
+type exportGuest struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type exportPayment struct {
+	EventID   string    `json:"event_id"`
+	Kind      string    `json:"kind"`
+	Method    string    `json:"method"`
+	Amount    int       `json:"amount"`
+	ForUserID int64     `json:"for_telegram_id"`
+	GuestID   string    `json:"guest_id,omitempty"`
+	PayerID   int64     `json:"payer_telegram_id"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportPass struct {
+	Sessions   int       `json:"sessions"`
+	Used       int       `json:"used"`
+	ValidFrom  time.Time `json:"valid_from"`
+	ValidUntil time.Time `json:"valid_until"`
+}
+
+type exportAttendanceSummary struct {
+	Present       int `json:"present"`
+	NoShow        int `json:"no_show"`
+	LateCancelled int `json:"late_cancelled"`
+}
+
+// handleMyData отправляет игроку его данные JSON-файлом по команде /mydata
+func (h *Handlers) handleMyData(ctx context.Context, msg *Message) {
+	usr, err := h.userService.GetByTelegramID(ctx, msg.From.ID)
+	if err != nil {
+		h.logger.Error("failed to get user for data export", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка выгрузки данных. Попробуйте позже."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if usr == nil || usr.Anonymized() {
+		if sendErr := h.client.SendMessage(msg.ChatID, "ℹ️ У нас нет ваших данных."); sendErr != nil {
+			h.logger.Error("failed to send no data message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	export, err := h.buildPersonalDataExport(ctx, usr)
+	if err != nil {
+		h.logger.Error("failed to build data export", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка выгрузки данных. Попробуйте позже."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		h.logger.Error("failed to marshal data export", "user_id", msg.From.ID, "error", err)
+		return
+	}
+
+	fileName := fmt.Sprintf("mydata_%s.json", export.ExportedAt.Format("2006-01-02"))
+	if err := h.client.SendDocument(msg.ChatID, fileName, data, "📦 Ваши данные: профиль, записи на события, оплаты и посещаемость"); err != nil {
+		h.logger.Error("failed to send data export", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// buildPersonalDataExport собирает все данные игрока для выгрузки
+func (h *Handlers) buildPersonalDataExport(ctx context.Context, usr *user.User) (*personalDataExport, error) {
+	export := &personalDataExport{
+		ExportedAt: time.Now(),
+		Profile: exportProfile{
+			TelegramID: usr.TelegramID,
+			Name:       usr.Name,
+			Surname:    usr.Surname,
+			Phone:      usr.Phone,
+			Level:      usr.Level.String(),
+			Hand:       string(usr.Hand),
+			Language:   string(usr.EffectiveLanguage()),
+		},
+		Registrations: []exportRegistration{},
+		Payments:      []exportPayment{},
+		Passes:        []exportPass{},
+	}
+	for _, id := range usr.PreferredLocations {
+		export.Profile.PreferredLocations = append(export.Profile.PreferredLocations, string(id))
+	}
+	if usr.DeletionRequested() {
+		deleteAfter := usr.DeleteAfter
+		export.Profile.DeleteAfter = &deleteAfter
+	}
+
+	// Регистрации хранятся по внутреннему ID игрока, а в событии - по Telegram ID
+	events, err := h.eventService.ListByUser(ctx, usr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	for _, evt := range events {
+		reg, ok := evt.Registrations[usr.TelegramID]
+		if !ok {
+			continue
+		}
+		item := exportRegistration{
+			EventID:          string(evt.ID),
+			EventName:        evt.Name,
+			EventDate:        evt.Date,
+			EventStatus:      string(evt.Status),
+			Status:           string(reg.Status),
+			WaitlistPosition: reg.WaitlistPosition,
+			Attendance:       string(reg.Attendance),
+			PartnerID:        reg.PartnerID,
+			Discount:         reg.Discount,
+			RegisteredAt:     reg.CreatedAt,
+		}
+		if !reg.CheckedInAt.IsZero() {
+			checkedInAt := reg.CheckedInAt
+			item.CheckedInAt = &checkedInAt
+		}
+		for _, g := range evt.GuestsOf(usr.TelegramID) {
+			item.Guests = append(item.Guests, exportGuest{Name: g.Name, Status: string(g.Status)})
+		}
+		export.Registrations = append(export.Registrations, item)
+
+		switch {
+		case reg.Attendance == event.AttendancePresent:
+			export.Attendance.Present++
+		case reg.Attendance == event.AttendanceNoShow:
+			export.Attendance.NoShow++
+		case reg.Status == event.RegistrationStatusLateCancelled:
+			export.Attendance.LateCancelled++
+		}
+	}
+
+	payments, err := h.paymentService.ListByUser(ctx, usr.TelegramID)
+	if err != nil {
+		return nil, fmt.Errorf("list payments: %w", err)
+	}
+	for _, p := range payments {
+		export.Payments = append(export.Payments, exportPayment{
+			EventID:   string(p.EventID),
+			Kind:      string(p.Kind),
+			Method:    string(p.Method),
+			Amount:    p.Amount,
+			ForUserID: p.UserID,
+			GuestID:   string(p.GuestID),
+			PayerID:   p.PayerID,
+			Comment:   p.Comment,
+			CreatedAt: p.CreatedAt,
+		})
+	}
+
+	passes, err := h.passService.ListByUser(ctx, usr.TelegramID)
+	if err != nil {
+		return nil, fmt.Errorf("list passes: %w", err)
+	}
+	for _, p := range passes {
+		export.Passes = append(export.Passes, exportPass{
+			Sessions:   p.Sessions,
+			Used:       len(p.Usages),
+			ValidFrom:  p.ValidFrom,
+			ValidUntil: p.ValidUntil,
+		})
+	}
+
+	return export, nil
+}
+
+// handleDeleteMe запрашивает подтверждение удаления данных по команде /deleteme.
+// Если удаление уже запланировано, показывает его срок и кнопку отмены
+func (h *Handlers) handleDeleteMe(ctx context.Context, msg *Message) {
+	usr, err := h.userService.GetByTelegramID(ctx, msg.From.ID)
+	if err != nil {
+		h.logger.Error("failed to get user for deletion", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка получения данных. Попробуйте позже."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if usr == nil || usr.Anonymized() {
+		if sendErr := h.client.SendMessage(msg.ChatID, "ℹ️ У нас нет ваших данных."); sendErr != nil {
+			h.logger.Error("failed to send no data message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if usr.DeletionRequested() {
+		text, keyboard := h.formatter.FormatDeletionScheduled(usr.DeleteAfter)
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send deletion status", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	blocking, err := h.activeUpcomingEvents(ctx, usr, time.Now())
+	if err != nil {
+		h.logger.Error("failed to check active registrations", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка получения данных. Попробуйте позже."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if len(blocking) > 0 {
+		if err := h.client.SendMessage(msg.ChatID, h.formatter.FormatDeletionBlocked(blocking)); err != nil {
+			h.logger.Error("failed to send deletion blocked message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatDeleteMeConfirm()
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send deletion confirmation", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleDeleteMeCallback обрабатывает кнопки удаления данных (формат: deleteme:confirm, deleteme:cancel, deleteme:undo)
+func (h *Handlers) handleDeleteMeCallback(ctx context.Context, cb *CallbackQuery) {
+	chatID := cb.Message.ChatID
+	messageID := cb.Message.MessageID
+	userID := cb.From.ID
+
+	switch cb.Data {
+	case "deleteme:cancel":
+		if err := h.client.EditMessageTextAndMarkup(chatID, messageID, "👌 Удаление отменено, ваши данные сохранены.", nil); err != nil {
+			h.logger.Error("failed to edit message", "chat_id", chatID, "error", err)
+		}
+
+	case "deleteme:confirm":
+		usr, err := h.userService.GetByTelegramID(ctx, userID)
+		if err != nil || usr == nil {
+			h.logger.Error("failed to get user for deletion", "user_id", userID, "error", err)
+			if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения данных. Попробуйте позже."); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+			}
+			return
+		}
+		// Игрок мог записаться на событие, пока висело подтверждение
+		blocking, err := h.activeUpcomingEvents(ctx, usr, time.Now())
+		if err != nil {
+			h.logger.Error("failed to check active registrations", "user_id", userID, "error", err)
+			if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения данных. Попробуйте позже."); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+			}
+			return
+		}
+		if len(blocking) > 0 {
+			if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, h.formatter.FormatDeletionBlocked(blocking), nil); err != nil {
+				h.logger.Error("failed to edit message", "chat_id", chatID, "error", err)
+			}
+			return
+		}
+
+		usr, err = h.userService.RequestDeletion(ctx, userID, time.Now())
+		if err != nil {
+			h.logger.Error("failed to request deletion", "user_id", userID, "error", err)
+			if sendErr := h.client.SendMessage(chatID, deletionErrorMessage(err)); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+			}
+			return
+		}
+		h.logger.Info("account deletion requested", "user_id", userID, "delete_after", usr.DeleteAfter)
+		text, keyboard := h.formatter.FormatDeletionScheduled(usr.DeleteAfter)
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message", "chat_id", chatID, "error", err)
+		}
+
+	case "deleteme:undo":
+		if _, err := h.userService.CancelDeletion(ctx, userID); err != nil {
+			h.logger.Error("failed to cancel deletion", "user_id", userID, "error", err)
+			if sendErr := h.client.SendMessage(chatID, deletionErrorMessage(err)); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+			}
+			return
+		}
+		h.logger.Info("account deletion cancelled", "user_id", userID)
+		if err := h.client.EditMessageTextAndMarkup(chatID, messageID, "✅ Удаление отменено, ваши данные сохранены.", nil); err != nil {
+			h.logger.Error("failed to edit message", "chat_id", chatID, "error", err)
+		}
+	}
+}
+
+// activeUpcomingEvents возвращает предстоящие события, на которые игрок записан (или стоит в очереди)
+func (h *Handlers) activeUpcomingEvents(ctx context.Context, usr *user.User, now time.Time) ([]event.Event, error) {
+	events, err := h.eventService.ListByUser(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+	var active []event.Event
+	for _, evt := range events {
+		if !evt.IsOpen(now) {
+			continue
+		}
+		if reg, ok := evt.Registrations[usr.TelegramID]; ok && reg.Status.Active() {
+			active = append(active, evt)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Date.Before(active[j].Date) })
+	return active, nil
+}
+
+// deletionErrorMessage переводит ошибку удаления данных в сообщение для игрока
+func deletionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, user.ErrDeletionNotRequested):
+		return "ℹ️ Удаление не было запрошено или уже выполнено."
+	case errors.Is(err, user.ErrAlreadyAnonymized), errors.Is(err, user.ErrUserNotFound):
+		return "ℹ️ У нас нет ваших данных."
+	default:
+		return "❌ Ошибка. Попробуйте позже."
+	}
+}
@@ -0,0 +1,424 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/pass"
+	"pickletlgbot/internal/domain/payment"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handlePass показывает игроку его абонементы и остаток занятий
+func (h *Handlers) handlePass(ctx context.Context, msg *Message) {
+	passes, err := h.passService.ListByUser(ctx, msg.From.ID)
+	if err != nil {
+		h.logger.Error("failed to list passes", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка получения абонементов"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.SendMessage(msg.ChatID, h.formatter.FormatPasses(passes, h.locationNames(ctx), time.Now())); err != nil {
+		h.logger.Error("failed to send passes", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// chargePass списывает занятие с абонемента игрока, если событие платное, еще не оплачено и абонемент подходит.
+// Списание отражается в журнале оплат; nil - абонемент не использован
+func (h *Handlers) chargePass(ctx context.Context, evt *event.Event, userID int64) *pass.Pass {
+	if evt.Price <= 0 {
+		return nil
+	}
+	balances, err := h.paymentService.Balances(ctx, evt)
+	if err != nil {
+		h.logger.Error("failed to get payment balances", "event_id", string(evt.ID), "error", err)
+		return nil
+	}
+	owed := balances[userID].Owed()
+	if owed <= 0 {
+		return nil
+	}
+
+	p, err := h.passService.Charge(ctx, evt, userID)
+	if errors.Is(err, pass.ErrNoEligiblePass) || errors.Is(err, pass.ErrAlreadyCharged) {
+		return nil
+	}
+	if err != nil {
+		h.logger.Error("failed to charge pass", "event_id", string(evt.ID), "user_id", userID, "error", err)
+		return nil
+	}
+
+	if _, err := h.paymentService.Record(ctx, payment.RecordInput{
+		EventID: evt.ID,
+		UserID:  userID,
+		Method:  payment.MethodPass,
+		Amount:  owed,
+		Comment: fmt.Sprintf("Абонемент %s", string(p.ID)),
+	}); err != nil {
+		h.logger.Error("failed to record pass payment", "event_id", string(evt.ID), "user_id", userID, "pass_id", string(p.ID), "error", err)
+	}
+
+	h.logger.Info("pass session charged", "event_id", string(evt.ID), "user_id", userID, "pass_id", string(p.ID), "remaining", p.Remaining())
+	return p
+}
+
+// returnPass возвращает занятие за событие на абонемент и сторнирует оплату абонементом в журнале;
+// nil - занятие за событие не списывалось
+func (h *Handlers) returnPass(ctx context.Context, evt *event.Event, userID int64) *pass.Pass {
+	p, err := h.passService.Return(ctx, evt.ID, userID)
+	if err != nil {
+		h.logger.Error("failed to return pass session", "event_id", string(evt.ID), "user_id", userID, "error", err)
+		return nil
+	}
+	if p == nil {
+		return nil
+	}
+
+	payments, err := h.paymentService.ListByEvent(ctx, evt.ID)
+	if err != nil {
+		h.logger.Error("failed to list payments", "event_id", string(evt.ID), "error", err)
+		return p
+	}
+	var paidByPass int
+	for _, pm := range payments {
+		if pm.UserID == userID && pm.GuestID == "" && pm.Method == payment.MethodPass {
+			paidByPass += pm.Signed()
+		}
+	}
+	if paidByPass > 0 {
+		if _, err := h.paymentService.Refund(ctx, payment.RefundInput{
+			EventID: evt.ID,
+			UserID:  userID,
+			Method:  payment.MethodPass,
+			Amount:  paidByPass,
+			Comment: fmt.Sprintf("Возврат на абонемент %s", string(p.ID)),
+		}); err != nil {
+			h.logger.Error("failed to record pass refund", "event_id", string(evt.ID), "user_id", userID, "pass_id", string(p.ID), "error", err)
+		}
+	}
+
+	h.logger.Info("pass session returned", "event_id", string(evt.ID), "user_id", userID, "pass_id", string(p.ID), "remaining", p.Remaining())
+	return p
+}
+
+// payWithPass подтверждает бронь игрока списанием занятия с абонемента вместо оплаты; nil - абонемент не подошел
+func (h *Handlers) payWithPass(ctx context.Context, evt *event.Event, userID int64) *pass.Pass {
+	if reg, ok := evt.Registrations[userID]; !ok || reg.Status != event.RegistrationStatusPending || evt.Doubles {
+		return nil
+	}
+
+	p := h.chargePass(ctx, evt, userID)
+	if p == nil {
+		return nil
+	}
+
+	err := retryOnConflict(func() error {
+		return h.eventService.ConfirmPayment(ctx, evt.ID, userID)
+	})
+	if err != nil && !errors.Is(err, event.ErrRegistrationAlreadyApproved) {
+		h.logger.Error("failed to confirm registration paid with pass", "event_id", string(evt.ID), "user_id", userID, "error", err)
+		h.returnPass(ctx, evt, userID)
+		return nil
+	}
+	return p
+}
+
+// passChargedText формирует сообщение игроку о списании занятия с абонемента
+func passChargedText(evt *event.Event, p *pass.Pass) string {
+	return fmt.Sprintf("🎟 Участие оплачено абонементом, регистрация подтверждена.\n\n📅 %s\n🗓️ %s\n\nОсталось занятий: %d",
+		evt.Name, evt.Date.Format("02.01.2006 15:04"), p.Remaining())
+}
+
+// notifyPassCharged сообщает игроку о списании занятия с абонемента при подтверждении записи администратором
+func (h *Handlers) notifyPassCharged(evt *event.Event, userID int64, p *pass.Pass) {
+	text := fmt.Sprintf("🎟 За участие в «%s» списано занятие с абонемента.\n\nОсталось занятий: %d", evt.Name, p.Remaining())
+	if err := h.client.SendMessage(userID, text); err != nil {
+		h.logger.Error("failed to notify about charged pass session", "user_id", userID, "event_id", string(evt.ID), "error", err)
+	}
+}
+
+// returnPassOnCancel возвращает занятие на абонемент, если игрок отменил запись вовремя,
+// и предупреждает, если занятие сгорело из-за поздней отмены
+func (h *Handlers) returnPassOnCancel(ctx context.Context, evt *event.Event, userID int64, late bool) {
+	if !late {
+		if p := h.returnPass(ctx, evt, userID); p != nil {
+			h.notifyPassReturned(evt, userID, p)
+		}
+		return
+	}
+
+	charged, err := h.passService.Charged(ctx, evt.ID, userID)
+	if err != nil {
+		h.logger.Error("failed to get charged pass", "event_id", string(evt.ID), "user_id", userID, "error", err)
+		return
+	}
+	if charged == nil {
+		return
+	}
+	text := fmt.Sprintf("⚠️ Запись на «%s» отменена после срока бесплатной отмены, поэтому занятие с абонемента не возвращается.\n\nОсталось занятий: %d",
+		evt.Name, charged.Remaining())
+	if err := h.client.SendMessage(userID, text); err != nil {
+		h.logger.Error("failed to notify about burned pass session", "user_id", userID, "event_id", string(evt.ID), "error", err)
+	}
+}
+
+// notifyPassReturned сообщает игроку о возврате занятия на абонемент
+func (h *Handlers) notifyPassReturned(evt *event.Event, userID int64, p *pass.Pass) {
+	text := fmt.Sprintf("🎟 Занятие за «%s» возвращено на абонемент.\n\nОсталось занятий: %d", evt.Name, p.Remaining())
+	if err := h.client.SendMessage(userID, text); err != nil {
+		h.logger.Error("failed to notify about returned pass session", "user_id", userID, "event_id", string(evt.ID), "error", err)
+	}
+}
+
+// handleAdminPassCommand показывает абонементы игрока: /admin_pass <telegram_id>
+func (h *Handlers) handleAdminPassCommand(msg *Message, args []string) {
+	if len(args) != 1 {
+		if err := h.client.SendMessage(msg.ChatID, "Использование: /admin_pass <telegram_id>\nНапример: /admin_pass 123456789"); err != nil {
+			h.logger.Error("failed to send admin pass usage", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Некорректный Telegram ID"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	ctx := context.Background()
+	usr, err := h.userService.GetByTelegramID(ctx, userID)
+	if err != nil || usr == nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Игрок не найден. Абонемент можно выдать только зарегистрированному игроку."); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	delete(h.issuingPasses, msg.ChatID)
+	h.showAdminPasses(ctx, msg.ChatID, 0, userID)
+}
+
+// showAdminPasses показывает администратору абонементы игрока; при messageID = 0 отправляет новое сообщение
+func (h *Handlers) showAdminPasses(ctx context.Context, chatID int64, messageID int, userID int64) {
+	passes, err := h.passService.ListByUser(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to list passes", "user_id", userID, "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения абонементов"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatAdminPasses(h.playerName(ctx, userID), userID, passes, h.locationNames(ctx), time.Now())
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with passes", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send passes", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminStartIssuePass начинает выдачу абонемента (формат: admin:pass:new:{userID})
+func (h *Handlers) handleAdminStartIssuePass(cb *CallbackQuery) {
+	userID, err := strconv.ParseInt(strings.TrimPrefix(cb.Data, "admin:pass:new:"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid issue pass callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	h.issuingPasses[cb.Message.ChatID] = &PassIssueState{UserID: userID, Step: "sessions"}
+	if err := h.client.SendMessage(cb.Message.ChatID, "🎟 Выдача абонемента\n\nВведите количество занятий (например, 8 или 10):\n\nДля отмены отправьте /cancel"); err != nil {
+		h.logger.Error("failed to send pass sessions prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminPassIssueInput обрабатывает ввод количества занятий и срока действия абонемента
+func (h *Handlers) handleAdminPassIssueInput(ctx context.Context, msg *Message, state *PassIssueState) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		delete(h.issuingPasses, msg.ChatID)
+		h.showAdminPasses(ctx, msg.ChatID, 0, state.UserID)
+		return
+	}
+
+	n, err := strconv.Atoi(input)
+	if err != nil || n <= 0 {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите целое положительное число или /cancel:"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if state.Step == "sessions" {
+		state.Sessions = n
+		state.Step = "days"
+		if err := h.client.SendMessage(msg.ChatID, "📅 Введите срок действия абонемента в днях (например, 30):"); err != nil {
+			h.logger.Error("failed to send pass validity prompt", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.Days = n
+	state.Step = "type"
+	text, keyboard := h.formatter.FormatPassTypePicker(state.Sessions, state.Days)
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send pass type picker", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminPassType сохраняет тип событий абонемента (формат: admin:pass:type:{any|training|competition})
+func (h *Handlers) handleAdminPassType(ctx context.Context, cb *CallbackQuery) {
+	state := h.issuingPasses[cb.Message.ChatID]
+	if state == nil || state.Step != "type" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните выдачу абонемента заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.EventTypes = nil
+	if t := event.EventType(strings.TrimPrefix(cb.Data, "admin:pass:type:")); t != "any" {
+		state.EventTypes = []event.EventType{t}
+	}
+	state.Step = "locations"
+	h.showPassLocationPicker(ctx, cb, state)
+}
+
+// handleAdminPassToggleLocation отмечает локацию абонемента (формат: admin:pass:loc:{locationID})
+func (h *Handlers) handleAdminPassToggleLocation(ctx context.Context, cb *CallbackQuery) {
+	state := h.issuingPasses[cb.Message.ChatID]
+	if state == nil || state.Step != "locations" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните выдачу абонемента заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	locationID := location.LocationID(strings.TrimPrefix(cb.Data, "admin:pass:loc:"))
+	selected := state.LocationIDs[:0]
+	found := false
+	for _, id := range state.LocationIDs {
+		if id == locationID {
+			found = true
+			continue
+		}
+		selected = append(selected, id)
+	}
+	if !found {
+		selected = append(selected, locationID)
+	}
+	state.LocationIDs = selected
+	h.showPassLocationPicker(ctx, cb, state)
+}
+
+// showPassLocationPicker показывает выбор локаций абонемента
+func (h *Handlers) showPassLocationPicker(ctx context.Context, cb *CallbackQuery, state *PassIssueState) {
+	locations, err := h.locationService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list locations", "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка локаций"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatPassLocationPicker(state.LocationIDs, locations)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with pass location picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminIssuePass выдает абонемент и уведомляет игрока (формат: admin:pass:loc_done)
+func (h *Handlers) handleAdminIssuePass(ctx context.Context, cb *CallbackQuery) {
+	state := h.issuingPasses[cb.Message.ChatID]
+	if state == nil || state.Step != "locations" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните выдачу абонемента заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	p, err := h.passService.Issue(ctx, cb.From.ID, pass.IssueInput{
+		UserID:      state.UserID,
+		Sessions:    state.Sessions,
+		ValidFrom:   time.Now(),
+		ValidDays:   state.Days,
+		EventTypes:  state.EventTypes,
+		LocationIDs: state.LocationIDs,
+	})
+	if err != nil {
+		h.logger.Error("failed to issue pass", "user_id", state.UserID, "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, passErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	delete(h.issuingPasses, cb.Message.ChatID)
+
+	h.logger.Info("pass issued", "pass_id", string(p.ID), "user_id", p.UserID, "sessions", p.Sessions, "admin_id", cb.From.ID)
+	if err := h.client.SendMessage(p.UserID, h.formatter.FormatPassIssued(p, h.locationNames(ctx))); err != nil {
+		h.logger.Error("failed to notify user about issued pass", "user_id", p.UserID, "error", err)
+	}
+
+	h.showAdminPasses(ctx, cb.Message.ChatID, cb.Message.MessageID, state.UserID)
+}
+
+// handleAdminCancelIssuePass отменяет выдачу абонемента (формат: admin:pass:cancel)
+func (h *Handlers) handleAdminCancelIssuePass(ctx context.Context, cb *CallbackQuery) {
+	state := h.issuingPasses[cb.Message.ChatID]
+	delete(h.issuingPasses, cb.Message.ChatID)
+	if state == nil {
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with admin menu", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	h.showAdminPasses(ctx, cb.Message.ChatID, cb.Message.MessageID, state.UserID)
+}
+
+// handleAdminDeletePass аннулирует неиспользованный абонемент (формат: admin:pass:del:{passID})
+func (h *Handlers) handleAdminDeletePass(ctx context.Context, cb *CallbackQuery) {
+	passID := pass.PassID(strings.TrimPrefix(cb.Data, "admin:pass:del:"))
+
+	p, err := h.passService.Delete(ctx, passID)
+	if err != nil {
+		h.logger.Error("failed to delete pass", "pass_id", string(passID), "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, passErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.logger.Info("pass deleted", "pass_id", string(p.ID), "user_id", p.UserID, "admin_id", cb.From.ID)
+	h.showAdminPasses(ctx, cb.Message.ChatID, cb.Message.MessageID, p.UserID)
+}
+
+// passErrorMessage возвращает сообщение об ошибке работы с абонементом
+func passErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, pass.ErrSessionsInvalid):
+		return "❌ Количество занятий должно быть больше нуля"
+	case errors.Is(err, pass.ErrValidityInvalid):
+		return "❌ Срок действия должен быть больше нуля"
+	case errors.Is(err, pass.ErrEventTypeInvalid):
+		return "❌ Неизвестный тип событий"
+	case errors.Is(err, pass.ErrPassNotFound):
+		return "❌ Абонемент не найден"
+	case errors.Is(err, pass.ErrPassHasUsages):
+		return "❌ С абонемента уже списывались занятия, аннулировать его нельзя"
+	default:
+		return fmt.Sprintf("❌ Ошибка: %v", err)
+	}
+}
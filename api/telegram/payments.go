@@ -0,0 +1,503 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/domain/role"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// paymentProviderToken возвращает токен платежного провайдера для счетов в рублях
+func paymentProviderToken() string {
+	return os.Getenv("PAYMENT_PROVIDER_TOKEN")
+}
+
+// invoiceAvailable проверяет, можно ли выставить счет для события:
+// звезды не требуют провайдера, для рублей нужен PAYMENT_PROVIDER_TOKEN
+func invoiceAvailable(evt *event.Event) bool {
+	if !evt.OnlinePayment() {
+		return false
+	}
+	return evt.EffectivePaymentMode() == event.PaymentModeStars || paymentProviderToken() != ""
+}
+
+// invoicePayload формирует payload счета: event:{eventID}:{userID}
+func invoicePayload(eventID event.EventID, userID int64) string {
+	return fmt.Sprintf("event:%s:%d", string(eventID), userID)
+}
+
+// parseInvoicePayload разбирает payload счета
+func parseInvoicePayload(payload string) (event.EventID, int64, bool) {
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 || parts[0] != "event" || parts[1] == "" {
+		return "", 0, false
+	}
+	userID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return event.EventID(parts[1]), userID, true
+}
+
+// sendInvoice выставляет игроку счет за участие в событии
+func (h *Handlers) sendInvoice(chatID int64, userID int64, evt *event.Event) error {
+	currency, amount := evt.Invoice(userID)
+	var token string
+	if currency != event.CurrencyStars {
+		token = paymentProviderToken()
+	}
+	description := fmt.Sprintf("Участие: %s, %s. После оплаты регистрация подтвердится автоматически.",
+		evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	return h.client.SendInvoice(chatID, evt.Name, description, invoicePayload(evt.ID, userID), token, currency, amount)
+}
+
+// handlePreCheckoutQuery проверяет перед списанием, что бронь еще действует и сумма совпадает с ценой события
+func (h *Handlers) handlePreCheckoutQuery(query *PreCheckoutQuery) {
+	ctx := context.Background()
+
+	errorMessage := h.checkPreCheckout(ctx, query)
+	if err := h.client.AnswerPreCheckoutQuery(query.ID, errorMessage == "", errorMessage); err != nil {
+		h.logger.Error("failed to answer pre-checkout query", "query_id", query.ID, "error", err)
+	}
+}
+
+// checkPreCheckout возвращает текст ошибки для игрока или пустую строку, если оплату можно принять
+func (h *Handlers) checkPreCheckout(ctx context.Context, query *PreCheckoutQuery) string {
+	eventID, userID, ok := parseInvoicePayload(query.InvoicePayload)
+	if !ok {
+		h.logger.Warn("invalid invoice payload", "payload", query.InvoicePayload)
+		return "Счет недействителен. Запишитесь на событие заново."
+	}
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for pre-checkout", "event_id", string(eventID), "error", err)
+		return "Событие не найдено."
+	}
+
+	if err := evt.CheckPayable(userID); err != nil {
+		switch {
+		case errors.Is(err, event.ErrRegistrationAlreadyApproved):
+			return "Участие уже оплачено и подтверждено."
+		case errors.Is(err, event.ErrRegistrationNotFound), errors.Is(err, event.ErrRegistrationNotPayable):
+			return "Бронь больше не действует. Запишитесь на событие заново."
+		case errors.Is(err, event.ErrTeamIncomplete):
+			return "Оплата будет доступна, когда к вам присоединится напарник."
+		case errors.Is(err, event.ErrEventNotOpen):
+			return "Запись на событие закрыта."
+		default:
+			return "Оплата этого события через Telegram недоступна."
+		}
+	}
+
+	currency, amount := evt.Invoice(userID)
+	if query.Currency != currency || query.TotalAmount != amount {
+		h.logger.Warn("pre-checkout amount mismatch", "event_id", string(eventID), "user_id", userID,
+			"currency", query.Currency, "amount", query.TotalAmount, "expected_currency", currency, "expected_amount", amount)
+		return "Стоимость события изменилась. Откройте событие и оплатите новый счет."
+	}
+
+	return ""
+}
+
+// handleSuccessfulPayment подтверждает регистрацию после успешной оплаты счета
+func (h *Handlers) handleSuccessfulPayment(msg *Message) {
+	ctx := context.Background()
+	paid := msg.SuccessfulPayment
+
+	eventID, userID, ok := parseInvoicePayload(paid.InvoicePayload)
+	if !ok {
+		h.logger.Error("successful payment with invalid payload", "payload", paid.InvoicePayload,
+			"telegram_charge_id", paid.TelegramPaymentChargeID, "provider_charge_id", paid.ProviderPaymentChargeID)
+		return
+	}
+
+	h.logger.Info("payment received", "event_id", string(eventID), "user_id", userID,
+		"currency", paid.Currency, "amount", paid.TotalAmount,
+		"telegram_charge_id", paid.TelegramPaymentChargeID, "provider_charge_id", paid.ProviderPaymentChargeID)
+
+	// Деньги уже списаны, поэтому оплата попадает в журнал, даже если регистрацию не удастся подтвердить
+	if err := h.recordInvoicePayment(ctx, eventID, userID, msg.From.ID, paid); errors.Is(err, payment.ErrDuplicateCharge) {
+		h.logger.Info("payment already processed", "event_id", string(eventID), "user_id", userID,
+			"telegram_charge_id", paid.TelegramPaymentChargeID)
+		return
+	}
+
+	err := retryOnConflict(func() error {
+		return h.eventService.ConfirmPayment(ctx, eventID, userID)
+	})
+	if errors.Is(err, event.ErrRegistrationAlreadyApproved) {
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to confirm paid registration", "event_id", string(eventID), "user_id", userID, "error", err)
+		text := "⚠️ Оплата получена, но подтвердить регистрацию автоматически не удалось. Администратор свяжется с вами."
+		if sendErr := h.client.SendMessage(msg.ChatID, text); sendErr != nil {
+			h.logger.Error("failed to send payment failure message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		h.notifyPaymentNeedsReview(ctx, eventID, userID, paid)
+		return
+	}
+
+	h.notifyTeamModerated(ctx, eventID, userID, true)
+}
+
+// notifyPaymentNeedsReview сообщает модераторам события об оплате, которую не удалось зачесть автоматически
+func (h *Handlers) notifyPaymentNeedsReview(ctx context.Context, eventID event.EventID, userID int64, payment *SuccessfulPayment) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for payment review", "event_id", string(eventID), "error", err)
+		return
+	}
+
+	moderatorIDs, err := h.roleService.Holders(ctx, role.PermModerate, role.ForEvent(evt))
+	if err != nil {
+		h.logger.Error("failed to get moderators for payment review", "event_id", string(eventID), "error", err)
+		return
+	}
+
+	text := fmt.Sprintf("⚠️ Оплата не зачтена автоматически\n\n📅 %s\n🗓️ %s\n👤 ID игрока: %d\n💳 %d %s\n🧾 %s\n\nПроверьте заявку вручную.",
+		evt.Name, evt.Date.Format("02.01.2006 15:04"), userID, payment.TotalAmount, payment.Currency, payment.TelegramPaymentChargeID)
+	for _, id := range moderatorIDs {
+		if err := h.client.SendMessage(id, text); err != nil {
+			h.logger.Error("failed to notify moderator about payment", "user_id", id, "event_id", string(eventID), "error", err)
+		}
+	}
+}
+
+// recordInvoicePayment записывает оплату счета в журнал оплат; payment.ErrDuplicateCharge - оплата уже обработана
+func (h *Handlers) recordInvoicePayment(ctx context.Context, eventID event.EventID, userID, payerID int64, p *SuccessfulPayment) error {
+	amount := p.TotalAmount
+	if p.Currency != event.CurrencyStars {
+		amount /= 100 // Telegram передает сумму в копейках
+	}
+	_, err := h.paymentService.Record(ctx, payment.RecordInput{
+		EventID:  eventID,
+		UserID:   userID,
+		PayerID:  payerID,
+		Method:   payment.MethodInvoice,
+		Amount:   amount,
+		ChargeID: p.TelegramPaymentChargeID,
+	})
+	if err != nil && !errors.Is(err, payment.ErrDuplicateCharge) {
+		h.logger.Error("failed to record invoice payment", "event_id", string(eventID), "user_id", userID,
+			"telegram_charge_id", p.TelegramPaymentChargeID, "error", err)
+	}
+	return err
+}
+
+// handleAdminEventPayments показывает состояние оплат по регистрациям события (формат: admin:pay:{eventID})
+func (h *Handlers) handleAdminEventPayments(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid event payments callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	delete(h.enteringPayments, cb.Message.ChatID)
+
+	eventID := event.EventID(parts[2])
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	balances, err := h.paymentService.Balances(ctx, evt)
+	if err != nil {
+		h.logger.Error("failed to get payment balances", "event_id", string(eventID), "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения оплат"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	var rows []PaymentRow
+	for userID, reg := range evt.Registrations {
+		if reg.Status != event.RegistrationStatusApproved && reg.Status != event.RegistrationStatusPending {
+			continue
+		}
+		rows = append(rows, PaymentRow{
+			UserID:  userID,
+			Name:    h.playerName(ctx, userID),
+			Status:  reg.Status,
+			Balance: balances[userID],
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Status != rows[j].Status {
+			return rows[i].Status == event.RegistrationStatusApproved
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	text, keyboard := h.formatter.FormatEventPayments(evt, rows)
+	if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with event payments", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminPlayerPayments открывает карточку оплат игрока по событию (формат: admin:pay:u:{eventID}:{userID})
+func (h *Handlers) handleAdminPlayerPayments(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 5 {
+		h.logger.Warn("invalid player payments callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	userID, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid user id in player payments callback", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	state := &PaymentEntryState{EventID: event.EventID(parts[3]), UserID: userID}
+	h.enteringPayments[cb.Message.ChatID] = state
+	h.showPlayerPayments(ctx, cb.Message.ChatID, cb.Message.MessageID, state)
+}
+
+// showPlayerPayments показывает карточку оплат игрока (messageID 0 - новым сообщением)
+func (h *Handlers) showPlayerPayments(ctx context.Context, chatID int64, messageID int, state *PaymentEntryState) {
+	evt, err := h.eventService.Get(ctx, state.EventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(chatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	payments, err := h.paymentService.ListByEvent(ctx, state.EventID)
+	if err != nil {
+		h.logger.Error("failed to list payments", "event_id", string(state.EventID), "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения оплат"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	// Оплаты за гостей игрока ведутся в списке гостей события
+	var history []payment.Payment
+	for _, p := range payments {
+		if p.UserID == state.UserID && p.GuestID == "" {
+			history = append(history, p)
+		}
+	}
+	balance := payment.Balances(evt, payments)[state.UserID]
+
+	text, keyboard := h.formatter.FormatPlayerPayments(evt, h.playerName(ctx, state.UserID), balance, history)
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with player payments", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send player payments", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminPaymentAction записывает оплату остатка или запрашивает сумму частичной оплаты или возврата
+// (формат: admin:pay:{full|part|refund}:{method})
+func (h *Handlers) handleAdminPaymentAction(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid payment action callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	state := h.enteringPayments[cb.Message.ChatID]
+	if state == nil {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Откройте оплаты игрока заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	action, method := parts[2], payment.Method(parts[3])
+	if method != payment.MethodCash && method != payment.MethodTransfer {
+		h.logger.Warn("invalid payment method", "method", parts[3], "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	switch action {
+	case "full":
+		evt, err := h.eventService.Get(ctx, state.EventID)
+		if err != nil || evt == nil {
+			if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+			}
+			return
+		}
+		balances, err := h.paymentService.Balances(ctx, evt)
+		if err != nil {
+			h.logger.Error("failed to get payment balances", "event_id", string(state.EventID), "chat_id", cb.Message.ChatID, "error", err)
+			return
+		}
+		owed := balances[state.UserID].Owed()
+		if owed <= 0 {
+			if err := h.client.SendMessage(cb.Message.ChatID, "✅ Участие уже оплачено полностью"); err != nil {
+				h.logger.Error("failed to send message", "chat_id", cb.Message.ChatID, "error", err)
+			}
+			return
+		}
+		if !h.recordAdminPayment(ctx, cb.Message.ChatID, cb.From.ID, state, payment.KindPayment, method, owed) {
+			return
+		}
+		h.showPlayerPayments(ctx, cb.Message.ChatID, cb.Message.MessageID, state)
+	case "part", "refund":
+		state.Kind = payment.KindPayment
+		prompt := "✏️ Введите сумму оплаты (только число)"
+		if action == "refund" {
+			state.Kind = payment.KindRefund
+			prompt = "↩️ Введите сумму возврата (только число)"
+		}
+		state.Method = method
+		text := fmt.Sprintf("%s, %s:\n\nДля отмены отправьте /cancel", prompt, paymentMethodLabels[method])
+		if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+			h.logger.Error("failed to edit message with payment amount prompt", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	default:
+		h.logger.Warn("invalid payment action", "action", action, "chat_id", cb.Message.ChatID)
+	}
+}
+
+// handleAdminPaymentAmountInput обрабатывает ввод суммы частичной оплаты или возврата
+func (h *Handlers) handleAdminPaymentAmountInput(ctx context.Context, msg *Message, state *PaymentEntryState) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		state.Kind = ""
+		h.showPlayerPayments(ctx, msg.ChatID, 0, state)
+		return
+	}
+
+	amount, err := strconv.Atoi(input)
+	if err != nil || amount <= 0 {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите сумму целым положительным числом или /cancel:"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if !h.recordAdminPayment(ctx, msg.ChatID, msg.From.ID, state, state.Kind, state.Method, amount) {
+		return
+	}
+	state.Kind = ""
+	h.showPlayerPayments(ctx, msg.ChatID, 0, state)
+}
+
+// recordAdminPayment записывает оплату или возврат, подтвержденные администратором; false - ошибка уже показана
+func (h *Handlers) recordAdminPayment(ctx context.Context, chatID, adminID int64, state *PaymentEntryState, kind payment.Kind, method payment.Method, amount int) bool {
+	var err error
+	if kind == payment.KindRefund {
+		_, err = h.paymentService.Refund(ctx, payment.RefundInput{
+			EventID:     state.EventID,
+			UserID:      state.UserID,
+			GuestID:     state.GuestID,
+			Method:      method,
+			Amount:      amount,
+			ConfirmedBy: adminID,
+		})
+	} else {
+		_, err = h.paymentService.Record(ctx, payment.RecordInput{
+			EventID:     state.EventID,
+			UserID:      state.UserID,
+			GuestID:     state.GuestID,
+			Method:      method,
+			Amount:      amount,
+			ConfirmedBy: adminID,
+		})
+	}
+	if err != nil {
+		h.logger.Error("failed to record payment", "event_id", string(state.EventID), "user_id", state.UserID, "guest_id", string(state.GuestID), "kind", string(kind), "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, paymentErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return false
+	}
+
+	h.logger.Info("payment recorded", "event_id", string(state.EventID), "user_id", state.UserID, "guest_id", string(state.GuestID),
+		"kind", string(kind), "method", string(method), "amount", amount, "admin_id", adminID)
+	return true
+}
+
+// paymentErrorMessage возвращает сообщение об ошибке записи оплаты
+func paymentErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, payment.ErrRefundExceedsPaid):
+		return "❌ Сумма возврата больше оплаченной"
+	case errors.Is(err, payment.ErrRegistrationNotFound):
+		return "❌ Регистрация игрока на событие не найдена"
+	case errors.Is(err, payment.ErrGuestNotFound):
+		return "❌ Гость игрока на событии не найден"
+	case errors.Is(err, payment.ErrAmountInvalid):
+		return "❌ Сумма должна быть больше нуля"
+	case errors.Is(err, event.ErrEventNotFound):
+		return "❌ Событие не найдено"
+	default:
+		return fmt.Sprintf("❌ Ошибка записи оплаты: %v", err)
+	}
+}
+
+// showDebtors показывает отчет о должниках по событиям, доступным пользователю (messageID 0 - новым сообщением)
+func (h *Handlers) showDebtors(ctx context.Context, chatID int64, messageID int, userID int64) {
+	debts, err := h.paymentService.Debtors(ctx)
+	if err != nil {
+		h.logger.Error("failed to get debtors", "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения списка должников"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	grants, err := h.roleService.Grants(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to get user roles", "user_id", userID, "error", err)
+		return
+	}
+	visible := debts[:0]
+	names := make(map[int64]string)
+	for _, d := range debts {
+		if !role.Allows(grants, role.PermManagePayments, role.ForEvent(d.Event)) {
+			continue
+		}
+		visible = append(visible, d)
+		if _, ok := names[d.UserID]; !ok {
+			names[d.UserID] = h.playerName(ctx, d.UserID)
+		}
+	}
+
+	text, keyboard := h.formatter.FormatDebtors(visible, names)
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with debtors", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send debtors", "chat_id", chatID, "error", err)
+	}
+}
+
+// recordModerationPayment отмечает оплату переводом при подтверждении заявки; false - оплата не записана
+func (h *Handlers) recordModerationPayment(ctx context.Context, chatID, adminID int64, evt *event.Event, userID int64) bool {
+	if evt.Price <= 0 {
+		return false
+	}
+	balances, err := h.paymentService.Balances(ctx, evt)
+	if err != nil {
+		h.logger.Error("failed to get payment balances", "event_id", string(evt.ID), "chat_id", chatID, "error", err)
+		return false
+	}
+	owed := balances[userID].Owed()
+	if owed <= 0 {
+		return false
+	}
+	state := &PaymentEntryState{EventID: evt.ID, UserID: userID}
+	return h.recordAdminPayment(ctx, chatID, adminID, state, payment.KindPayment, payment.MethodTransfer, owed)
+}
@@ -0,0 +1,285 @@
+package telegram
+
+import (
+	"context"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/role"
+	"pickletlgbot/internal/domain/series"
+	"strings"
+)
+
+// accessDeniedMessage - сообщение пользователю без нужного права
+const accessDeniedMessage = "❌ У вас нет прав на это действие"
+
+// callbackTarget - откуда брать объект, для которого проверяется право callback'а
+type callbackTarget int
+
+const (
+	targetAnywhere          callbackTarget = iota // Достаточно права в любой локации (меню, списки)
+	targetGlobal                                  // Глобальное действие (настройки, роли)
+	targetEvent                                   // ID события в сегменте Arg
+	targetLocation                                // ID локации в сегменте Arg
+	targetSeries                                  // ID серии в сегменте Arg
+	targetCreatingEvent                           // Локация создаваемого события (из состояния мастера)
+	targetEditingEvent                            // Редактируемое событие (из состояния редактирования)
+	targetEditingLocation                         // Редактируемое событие и новая локация в сегменте Arg
+	targetEditingOccurrence                       // Изменяемое занятие серии (из состояния изменения)
+	targetPaymentEntry                            // Событие открытой карточки оплат игрока (из состояния)
+)
+
+// callbackPermission - право, необходимое для callback'ов с префиксом Prefix
+type callbackPermission struct {
+	Prefix string
+	Perm   role.Permission
+	Target callbackTarget
+	Arg    int // Номер сегмента callback data (через ":") с ID объекта; -1 - последний сегмент
+}
+
+// adminCallbackPermissions - права admin: callback'ов. Проверяется первый подходящий префикс,
+// поэтому более конкретные префиксы идут раньше. Callback без правила запрещен
+var adminCallbackPermissions = []callbackPermission{
+	{Prefix: "admin:menu", Perm: role.PermView, Target: targetAnywhere},
+
+	// Роли
+	{Prefix: "admin:roles", Perm: role.PermManageRoles, Target: targetGlobal},
+
+	// Настройки
+	{Prefix: "admin:set_channel", Perm: role.PermManageSettings, Target: targetGlobal},
+	{Prefix: "admin:pending_timeout", Perm: role.PermManageSettings, Target: targetGlobal},
+	{Prefix: "admin:event_reminders", Perm: role.PermManageSettings, Target: targetGlobal},
+	{Prefix: "admin:no_show_policy", Perm: role.PermManageSettings, Target: targetGlobal},
+	{Prefix: "admin:cancel_policy", Perm: role.PermManageSettings, Target: targetGlobal},
+
+	// Локации и корты
+	{Prefix: "admin:locations", Perm: role.PermManageLocations, Target: targetAnywhere},
+	{Prefix: "admin:list_locations", Perm: role.PermManageLocations, Target: targetAnywhere},
+	{Prefix: "admin:create_location", Perm: role.PermManageLocations, Target: targetGlobal},
+	{Prefix: "admin:delete_location", Perm: role.PermManageLocations, Target: targetGlobal},
+	{Prefix: "admin:delete:", Perm: role.PermManageLocations, Target: targetGlobal},
+	{Prefix: "admin:courts:", Perm: role.PermManageLocations, Target: targetLocation, Arg: 2},
+	{Prefix: "admin:courts", Perm: role.PermManageLocations, Target: targetAnywhere},
+	{Prefix: "admin:court:", Perm: role.PermManageLocations, Target: targetLocation, Arg: 3},
+
+	// Списки событий
+	{Prefix: "admin:list_events", Perm: role.PermView, Target: targetAnywhere},
+	{Prefix: "admin:events:moderation", Perm: role.PermModerate, Target: targetAnywhere},
+	{Prefix: "admin:events", Perm: role.PermView, Target: targetAnywhere},
+
+	// Создание событий и серий
+	{Prefix: "admin:create_event:loc:", Perm: role.PermManageEvents, Target: targetLocation, Arg: 3},
+	{Prefix: "admin:create_series:loc:", Perm: role.PermManageEvents, Target: targetLocation, Arg: 3},
+	{Prefix: "admin:create_event:", Perm: role.PermManageEvents, Target: targetCreatingEvent},
+	{Prefix: "admin:create_event", Perm: role.PermManageEvents, Target: targetAnywhere},
+	{Prefix: "admin:create_series", Perm: role.PermManageEvents, Target: targetAnywhere},
+
+	// Серии и их занятия
+	{Prefix: "admin:series:stop:", Perm: role.PermManageEvents, Target: targetSeries, Arg: 3},
+	{Prefix: "admin:series:", Perm: role.PermManageEvents, Target: targetSeries, Arg: 2},
+	{Prefix: "admin:series", Perm: role.PermManageEvents, Target: targetAnywhere},
+	{Prefix: "admin:occ:apply:", Perm: role.PermManageEvents, Target: targetEditingOccurrence},
+	{Prefix: "admin:occ:", Perm: role.PermManageEvents, Target: targetEvent, Arg: -1},
+
+	// Модерация заявок
+	{Prefix: "admin:event:moderation:", Perm: role.PermModerate, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:reg:approve:", Perm: role.PermModerate, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:reg:reject:", Perm: role.PermModerate, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:reg:paid:", Perm: role.PermManagePayments, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:reg:", Perm: role.PermModerate, Target: targetEvent, Arg: 2},
+	{Prefix: "admin:guest:pay:", Perm: role.PermManagePayments, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:guest:", Perm: role.PermModerate, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:guests:", Perm: role.PermModerate, Target: targetEvent, Arg: 2},
+
+	// Оплаты: финансовые операции доступны только владельцу и администраторам
+	{Prefix: "admin:debtors", Perm: role.PermManagePayments, Target: targetAnywhere},
+	{Prefix: "admin:pay:u:", Perm: role.PermManagePayments, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:pay:full:", Perm: role.PermManagePayments, Target: targetPaymentEntry},
+	{Prefix: "admin:pay:part:", Perm: role.PermManagePayments, Target: targetPaymentEntry},
+	{Prefix: "admin:pay:refund:", Perm: role.PermManagePayments, Target: targetPaymentEntry},
+	{Prefix: "admin:pay:", Perm: role.PermManagePayments, Target: targetEvent, Arg: 2},
+
+	// Управление событием
+	{Prefix: "admin:event:", Perm: role.PermView, Target: targetEvent, Arg: 2},
+	{Prefix: "admin:publish:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 2},
+	{Prefix: "admin:lvok:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 2},
+	{Prefix: "admin:cancel_event:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 2},
+	{Prefix: "admin:delete_event:confirm:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:delete_event", Perm: role.PermManageEvents, Target: targetAnywhere},
+	{Prefix: "admin:edit:field:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 4},
+	{Prefix: "admin:edit:loc:", Perm: role.PermManageEvents, Target: targetEditingLocation, Arg: 3},
+	{Prefix: "admin:edit:type:", Perm: role.PermManageEvents, Target: targetEditingEvent},
+	{Prefix: "admin:edit:court:", Perm: role.PermManageEvents, Target: targetEditingEvent},
+	{Prefix: "admin:edit:courts_done", Perm: role.PermManageEvents, Target: targetEditingEvent},
+	{Prefix: "admin:edit:doubles:", Perm: role.PermManageEvents, Target: targetEditingEvent},
+	{Prefix: "admin:edit:pay:", Perm: role.PermManageEvents, Target: targetEditingEvent},
+	{Prefix: "admin:edit:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 2},
+
+	// Посещаемость и турниры
+	{Prefix: "admin:att:t:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:att:rest:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:att:link:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:att:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 2},
+	{Prefix: "admin:trn:new:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 4},
+	{Prefix: "admin:trn:seed:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 4},
+	{Prefix: "admin:trn:m:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:trn:fix:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:trn:pub:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:trn:delok:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:trn:del:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 3},
+	{Prefix: "admin:trn:", Perm: role.PermManageEvents, Target: targetEvent, Arg: 2},
+
+	// Игроки
+	{Prefix: "admin:level:", Perm: role.PermManagePlayers, Target: targetGlobal},
+	{Prefix: "admin:pass:", Perm: role.PermManagePlayers, Target: targetGlobal},
+	{Prefix: "admin:ban:", Perm: role.PermManagePlayers, Target: targetGlobal},
+	{Prefix: "admin:usr:", Perm: role.PermManagePlayers, Target: targetGlobal},
+
+	// Промокоды
+	{Prefix: "admin:promo", Perm: role.PermManageSettings, Target: targetGlobal},
+}
+
+// adminCommandPermissions - права админ-команд (команда без записи запрещена)
+var adminCommandPermissions = map[string]role.Permission{
+	"/admin":                 role.PermView,
+	"/admin_create_location": role.PermManageLocations,
+	"/admin_debtors":         role.PermManagePayments,
+	"/admin_delete_location": role.PermManageLocations,
+	"/admin_level":           role.PermManagePlayers,
+	"/admin_pass":            role.PermManagePlayers,
+	"/admin_rating_recalc":   role.PermManagePlayers,
+	"/admin_roles":           role.PermManageRoles,
+	"/admin_user":            role.PermManagePlayers,
+}
+
+// can проверяет право пользователя на действие над объектом
+func (h *Handlers) can(ctx context.Context, userID int64, perm role.Permission, target role.Target) bool {
+	ok, err := h.roleService.Can(ctx, userID, perm, target)
+	if err != nil {
+		h.logger.Error("failed to check permission", "user_id", userID, "permission", string(perm), "error", err)
+		return false
+	}
+	return ok
+}
+
+// isStaff проверяет, есть ли у пользователя хотя бы одна роль (владелец, администратор, модератор или тренер)
+func (h *Handlers) isStaff(userID int64) bool {
+	return h.can(context.Background(), userID, role.PermView, role.Anywhere)
+}
+
+// canUseAdminCallback проверяет право, необходимое для admin: callback'а
+func (h *Handlers) canUseAdminCallback(ctx context.Context, cb *CallbackQuery) bool {
+	for _, rule := range adminCallbackPermissions {
+		if !strings.HasPrefix(cb.Data, rule.Prefix) {
+			continue
+		}
+		targets, ok := h.callbackTargets(ctx, cb, rule)
+		if !ok {
+			return false
+		}
+		for _, target := range targets {
+			if !h.can(ctx, cb.From.ID, rule.Perm, target) {
+				return false
+			}
+		}
+		return true
+	}
+
+	h.logger.Warn("admin callback without permission rule", "callback_data", cb.Data, "user_id", cb.From.ID)
+	return false
+}
+
+// callbackTargets определяет объекты, для которых проверяется право callback'а.
+// Если объект не найден, проверяется право в любой локации: сам обработчик сообщит об ошибке
+func (h *Handlers) callbackTargets(ctx context.Context, cb *CallbackQuery, rule callbackPermission) ([]role.Target, bool) {
+	switch rule.Target {
+	case targetGlobal:
+		return []role.Target{role.Global}, true
+	case targetEvent:
+		arg, ok := callbackArg(cb.Data, rule.Arg)
+		if !ok {
+			return nil, false
+		}
+		return []role.Target{h.eventTarget(ctx, event.EventID(arg))}, true
+	case targetLocation:
+		arg, ok := callbackArg(cb.Data, rule.Arg)
+		if !ok {
+			return nil, false
+		}
+		return []role.Target{role.AtLocation(location.LocationID(arg))}, true
+	case targetSeries:
+		arg, ok := callbackArg(cb.Data, rule.Arg)
+		if !ok {
+			return nil, false
+		}
+		s, err := h.seriesService.Get(ctx, series.SeriesID(arg))
+		if err != nil || s == nil {
+			return []role.Target{role.Anywhere}, true
+		}
+		return []role.Target{role.ForSeries(s)}, true
+	case targetCreatingEvent:
+		if state := h.creatingEvents[cb.Message.ChatID]; state != nil {
+			return []role.Target{role.AtLocation(state.LocationID)}, true
+		}
+	case targetEditingEvent:
+		if state := h.editingEvents[cb.Message.ChatID]; state != nil {
+			return []role.Target{h.eventTarget(ctx, state.EventID)}, true
+		}
+	case targetEditingLocation:
+		arg, ok := callbackArg(cb.Data, rule.Arg)
+		if !ok {
+			return nil, false
+		}
+		targets := []role.Target{role.AtLocation(location.LocationID(arg))}
+		if state := h.editingEvents[cb.Message.ChatID]; state != nil {
+			targets = append(targets, h.eventTarget(ctx, state.EventID))
+		}
+		return targets, true
+	case targetEditingOccurrence:
+		if state := h.editingOccurrences[cb.Message.ChatID]; state != nil {
+			return []role.Target{h.eventTarget(ctx, state.EventID)}, true
+		}
+	case targetPaymentEntry:
+		if state := h.enteringPayments[cb.Message.ChatID]; state != nil {
+			return []role.Target{h.eventTarget(ctx, state.EventID)}, true
+		}
+	}
+	return []role.Target{role.Anywhere}, true
+}
+
+// eventTarget возвращает объект проверки прав для события
+func (h *Handlers) eventTarget(ctx context.Context, eventID event.EventID) role.Target {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		return role.Anywhere
+	}
+	return role.ForEvent(evt)
+}
+
+// callbackArg возвращает сегмент callback data по номеру (-1 - последний)
+func callbackArg(data string, index int) (string, bool) {
+	parts := strings.Split(data, ":")
+	if index < 0 {
+		index = len(parts) + index
+	}
+	if index < 0 || index >= len(parts) || parts[index] == "" {
+		return "", false
+	}
+	return parts[index], true
+}
+
+// visibleEvents оставляет события, которые пользователь может видеть в панели администратора
+func (h *Handlers) visibleEvents(ctx context.Context, userID int64, events []event.Event, perm role.Permission) []event.Event {
+	grants, err := h.roleService.Grants(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to get user roles", "user_id", userID, "error", err)
+		return nil
+	}
+
+	visible := make([]event.Event, 0, len(events))
+	for i := range events {
+		if role.Allows(grants, perm, role.ForEvent(&events[i])) {
+			visible = append(visible, events[i])
+		}
+	}
+	return visible
+}
@@ -0,0 +1,286 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/user"
+	"strconv"
+	"strings"
+)
+
+// Поля профиля, которые игрок вводит сообщением
+const (
+	profileFieldName    = "name"
+	profileFieldSurname = "surname"
+	profileFieldPhone   = "phone"
+)
+
+// profileCancelText - текст кнопки отмены под запросом контакта
+const profileCancelText = "❌ Отмена"
+
+// handleProfile показывает профиль игрока по команде /profile
+func (h *Handlers) handleProfile(ctx context.Context, msg *Message) {
+	h.showProfile(ctx, msg.ChatID, msg.From.ID, 0)
+}
+
+// showProfile выводит профиль игрока: новым сообщением (messageID == 0) или вместо сообщения messageID
+func (h *Handlers) showProfile(ctx context.Context, chatID, userID int64, messageID int) {
+	usr, err := h.userService.GetByTelegramID(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to get user profile", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения профиля"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+	if usr == nil || usr.Anonymized() {
+		if sendErr := h.client.SendMessage(chatID, "👤 Профиль появится после первой записи на событие: выберите событие в /start"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatProfile(usr, h.locationNames(ctx))
+	if messageID == 0 {
+		if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send profile", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with profile", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleProfileCallback обрабатывает кнопки профиля (формат: profile, profile:edit:{name|surname}, profile:phone,
+// profile:level, profile:lvl:{level}, profile:hand[:{hand}], profile:lang[:{language}], profile:locs, profile:loc:{locationID})
+func (h *Handlers) handleProfileCallback(ctx context.Context, cb *CallbackQuery) {
+	chatID := cb.Message.ChatID
+	userID := cb.From.ID
+
+	switch cb.Data {
+	case "profile":
+		delete(h.editingProfile, chatID)
+		h.showProfile(ctx, chatID, userID, cb.Message.MessageID)
+		return
+	case "profile:edit:name", "profile:edit:surname":
+		field := strings.TrimPrefix(cb.Data, "profile:edit:")
+		h.editingProfile[chatID] = field
+		prompt := "✏️ Введите новое имя:"
+		if field == profileFieldSurname {
+			prompt = "✏️ Введите новую фамилию:"
+		}
+		if err := h.client.SendMessage(chatID, prompt+"\n\nДля отмены отправьте /cancel"); err != nil {
+			h.logger.Error("failed to send profile prompt", "chat_id", chatID, "error", err)
+		}
+		return
+	case "profile:phone":
+		h.editingProfile[chatID] = profileFieldPhone
+		text := "📱 Нажмите кнопку ниже, чтобы поделиться номером телефона из Telegram.\n\nНомер видят только администраторы: по нему с вами свяжутся по поводу записи и оплаты."
+		if err := h.client.SendContactRequest(chatID, text, "📱 Поделиться номером", profileCancelText); err != nil {
+			h.logger.Error("failed to send contact request", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	usr, err := h.userService.GetByTelegramID(ctx, userID)
+	if err != nil || usr == nil {
+		h.logger.Error("failed to get user profile", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Профиль не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	var text string
+	var keyboard *InlineKeyboardMarkup
+	switch {
+	case cb.Data == "profile:level":
+		if usr.Level.IsSet() {
+			if sendErr := h.client.SendMessage(chatID, profileErrorMessage(user.ErrLevelLocked)); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+			}
+			return
+		}
+		text, keyboard = h.formatter.FormatProfileLevelPicker()
+	case cb.Data == "profile:hand":
+		text, keyboard = h.formatter.FormatProfileHandPicker(usr.Hand)
+	case cb.Data == "profile:lang":
+		text, keyboard = h.formatter.FormatProfileLanguagePicker(usr.EffectiveLanguage())
+	case cb.Data == "profile:locs":
+		locations, err := h.locationService.List(ctx)
+		if err != nil {
+			h.logger.Error("failed to list locations", "error", err)
+			return
+		}
+		text, keyboard = h.formatter.FormatProfileLocationsPicker(usr.PreferredLocations, locations)
+	case strings.HasPrefix(cb.Data, "profile:lvl:"):
+		value, err := strconv.Atoi(strings.TrimPrefix(cb.Data, "profile:lvl:"))
+		if err != nil {
+			h.logger.Warn("invalid profile level callback data", "callback_data", cb.Data, "chat_id", chatID)
+			return
+		}
+		level := user.Level(value)
+		h.updateProfile(ctx, chatID, userID, cb.Message.MessageID, user.UpdateProfileInput{Level: &level})
+		return
+	case strings.HasPrefix(cb.Data, "profile:hand:"):
+		hand := user.Hand(strings.TrimPrefix(cb.Data, "profile:hand:"))
+		h.updateProfile(ctx, chatID, userID, cb.Message.MessageID, user.UpdateProfileInput{Hand: &hand})
+		return
+	case strings.HasPrefix(cb.Data, "profile:lang:"):
+		lang := user.Language(strings.TrimPrefix(cb.Data, "profile:lang:"))
+		h.updateProfile(ctx, chatID, userID, cb.Message.MessageID, user.UpdateProfileInput{Language: &lang})
+		return
+	case strings.HasPrefix(cb.Data, "profile:loc:"):
+		h.toggleProfileLocation(ctx, cb, usr, location.LocationID(strings.TrimPrefix(cb.Data, "profile:loc:")))
+		return
+	default:
+		h.logger.Warn("unknown profile callback", "callback_data", cb.Data, "chat_id", chatID)
+		return
+	}
+
+	if err := h.client.EditMessageTextAndMarkup(chatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with profile picker", "chat_id", chatID, "error", err)
+	}
+}
+
+// toggleProfileLocation отмечает локацию предпочитаемой или снимает отметку и обновляет список локаций
+func (h *Handlers) toggleProfileLocation(ctx context.Context, cb *CallbackQuery, usr *user.User, id location.LocationID) {
+	var selected []location.LocationID
+	found := false
+	for _, existing := range usr.PreferredLocations {
+		if existing == id {
+			found = true
+			continue
+		}
+		selected = append(selected, existing)
+	}
+	if !found {
+		selected = append(selected, id)
+	}
+
+	updated, err := h.userService.UpdateProfile(ctx, usr.TelegramID, user.UpdateProfileInput{PreferredLocations: &selected})
+	if err != nil {
+		h.logger.Error("failed to update preferred locations", "user_id", usr.TelegramID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, profileErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	locations, err := h.locationService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list locations", "error", err)
+		return
+	}
+	text, keyboard := h.formatter.FormatProfileLocationsPicker(updated.PreferredLocations, locations)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with locations picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// updateProfile сохраняет изменение профиля и показывает обновленный профиль вместо сообщения messageID
+func (h *Handlers) updateProfile(ctx context.Context, chatID, userID int64, messageID int, in user.UpdateProfileInput) {
+	if _, err := h.userService.UpdateProfile(ctx, userID, in); err != nil {
+		h.logger.Error("failed to update profile", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, profileErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+	h.logger.Info("profile updated", "user_id", userID)
+	h.showProfile(ctx, chatID, userID, messageID)
+}
+
+// handleProfileInput обрабатывает ввод имени или фамилии, а также отмену запроса телефона
+func (h *Handlers) handleProfileInput(ctx context.Context, msg *Message, field string) {
+	text := strings.TrimSpace(msg.Text)
+	if text == "/cancel" || text == profileCancelText {
+		delete(h.editingProfile, msg.ChatID)
+		if err := h.client.SendMessageRemoveKeyboard(msg.ChatID, "❌ Изменение профиля отменено"); err != nil {
+			h.logger.Error("failed to send cancel message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	var in user.UpdateProfileInput
+	switch field {
+	case profileFieldName:
+		in.Name = &text
+	case profileFieldSurname:
+		in.Surname = &text
+	case profileFieldPhone:
+		// Телефон принимается только контактом Telegram, чтобы номер был подтвержден
+		if err := h.client.SendMessage(msg.ChatID, fmt.Sprintf("📱 Нажмите кнопку «Поделиться номером» или «%s»", profileCancelText)); err != nil {
+			h.logger.Error("failed to send contact hint", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	default:
+		delete(h.editingProfile, msg.ChatID)
+		return
+	}
+
+	if _, err := h.userService.UpdateProfile(ctx, msg.From.ID, in); err != nil {
+		h.logger.Error("failed to update profile", "user_id", msg.From.ID, "field", field, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, profileErrorMessage(err)+"\n\nВведите еще раз или /cancel:"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	delete(h.editingProfile, msg.ChatID)
+
+	h.logger.Info("profile updated", "user_id", msg.From.ID, "field", field)
+	if err := h.client.SendMessage(msg.ChatID, "✅ Сохранено"); err != nil {
+		h.logger.Error("failed to send confirmation", "chat_id", msg.ChatID, "error", err)
+	}
+	h.showProfile(ctx, msg.ChatID, msg.From.ID, 0)
+}
+
+// handleContact сохраняет телефон из контакта, которым игрок поделился по кнопке
+func (h *Handlers) handleContact(ctx context.Context, msg *Message) {
+	if msg.From == nil || msg.Contact.UserID != msg.From.ID {
+		if err := h.client.SendMessage(msg.ChatID, "❌ Поделитесь, пожалуйста, своим номером с помощью кнопки"); err != nil {
+			h.logger.Error("failed to send contact error", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	phone := msg.Contact.PhoneNumber
+	usr, err := h.userService.UpdateProfile(ctx, msg.From.ID, user.UpdateProfileInput{Phone: &phone})
+	if err != nil {
+		h.logger.Error("failed to save phone", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessageRemoveKeyboard(msg.ChatID, profileErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	delete(h.editingProfile, msg.ChatID)
+
+	h.logger.Info("phone saved", "user_id", msg.From.ID)
+	if err := h.client.SendMessageRemoveKeyboard(msg.ChatID, fmt.Sprintf("✅ Телефон сохранен: %s", usr.Phone)); err != nil {
+		h.logger.Error("failed to send confirmation", "chat_id", msg.ChatID, "error", err)
+	}
+	h.showProfile(ctx, msg.ChatID, msg.From.ID, 0)
+}
+
+// profileErrorMessage переводит ошибку изменения профиля в сообщение для пользователя
+func profileErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		return "👤 Профиль появится после первой записи на событие"
+	case errors.Is(err, user.ErrNameRequired):
+		return "❌ Имя не может быть пустым"
+	case errors.Is(err, user.ErrInvalidPhone):
+		return "❌ Не удалось распознать номер телефона"
+	case errors.Is(err, user.ErrInvalidLevel):
+		return "❌ Недопустимый уровень"
+	case errors.Is(err, user.ErrLevelLocked):
+		return "🔒 Уровень уже определен. Изменить его может администратор"
+	case errors.Is(err, user.ErrInvalidHand), errors.Is(err, user.ErrInvalidLanguage):
+		return "❌ Недопустимое значение"
+	default:
+		return "❌ Ошибка сохранения профиля"
+	}
+}
@@ -0,0 +1,519 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/promo"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPromoEvents - сколько ближайших событий предлагать при создании промокода на конкретное событие
+const maxPromoEvents = 20
+
+// handlePromoStart запрашивает у игрока промокод для его записи на событие (формат: event:promo:{eventID})
+func (h *Handlers) handlePromoStart(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "event:promo:"))
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for promo code", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if err := promoApplicable(evt, cb.From.ID); err != nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, promoErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.enteringPromos[cb.Message.ChatID] = eventID
+	if err := h.client.SendMessage(cb.Message.ChatID, "🏷 Введите промокод:\n\nДля отмены отправьте /cancel"); err != nil {
+		h.logger.Error("failed to send promo code prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// promoApplicable проверяет, что к записи игрока еще можно применить промокод
+func promoApplicable(evt *event.Event, userID int64) error {
+	reg, ok := evt.Registrations[userID]
+	if !ok {
+		return event.ErrRegistrationNotFound
+	}
+	switch reg.Status {
+	case event.RegistrationStatusPending, event.RegistrationStatusWaitlisted, event.RegistrationStatusAwaitingPartner:
+	case event.RegistrationStatusApproved:
+		return event.ErrRegistrationAlreadyApproved
+	default:
+		return event.ErrRegistrationNotPayable
+	}
+	if evt.Price <= 0 {
+		return promo.ErrCodeNotApplicable
+	}
+	if reg.Discount > 0 {
+		return event.ErrDiscountAlreadyApplied
+	}
+	return nil
+}
+
+// handlePromoInput погашает введенный игроком промокод и применяет скидку к его записи
+func (h *Handlers) handlePromoInput(ctx context.Context, msg *Message, eventID event.EventID) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		delete(h.enteringPromos, msg.ChatID)
+		if err := h.client.SendMessage(msg.ChatID, "❌ Ввод промокода отменен"); err != nil {
+			h.logger.Error("failed to send cancel message", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+	userID := msg.From.ID
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		delete(h.enteringPromos, msg.ChatID)
+		h.logger.Error("failed to get event for promo code", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if err := promoApplicable(evt, userID); err != nil {
+		delete(h.enteringPromos, msg.ChatID)
+		if sendErr := h.client.SendMessage(msg.ChatID, promoErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	redemption, code, err := h.promoService.Redeem(ctx, input, evt, userID)
+	if err != nil {
+		h.logger.Warn("failed to redeem promo code", "event_id", string(eventID), "user_id", userID, "code", promo.Normalize(input), "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, promoErrorMessage(err)+"\n\nВведите другой промокод или /cancel:"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	err = retryOnConflict(func() error {
+		evt, err = h.eventService.ApplyDiscount(ctx, eventID, userID, redemption.Discount)
+		return err
+	})
+	if err != nil {
+		delete(h.enteringPromos, msg.ChatID)
+		h.logger.Error("failed to apply promo discount", "event_id", string(eventID), "user_id", userID, "code", code.Code, "error", err)
+		// Уже примененная скидка держится на этом же погашении (другое не даст сохранить уникальный индекс)
+		if !errors.Is(err, event.ErrDiscountAlreadyApplied) {
+			if _, releaseErr := h.promoService.Release(ctx, eventID, userID); releaseErr != nil {
+				h.logger.Error("failed to release promo code", "event_id", string(eventID), "user_id", userID, "error", releaseErr)
+			}
+		}
+		if sendErr := h.client.SendMessage(msg.ChatID, promoErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+	delete(h.enteringPromos, msg.ChatID)
+
+	h.logger.Info("promo code redeemed", "event_id", string(eventID), "user_id", userID, "code", code.Code, "discount", redemption.Discount)
+	mode := evt.EffectivePaymentMode()
+	text := fmt.Sprintf("✅ Промокод %s применен: скидка %s, к оплате %s",
+		code.Code, formatAmount(mode, redemption.Discount), formatAmount(mode, evt.PriceFor(userID)))
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send promo code result", "chat_id", msg.ChatID, "error", err)
+	}
+
+	if evt.Registrations[userID].Status != event.RegistrationStatusPending {
+		return
+	}
+	if evt.PriceFor(userID) > 0 || evt.Doubles {
+		h.sendPaymentInstruction(ctx, msg.ChatID, userID, evt)
+		return
+	}
+
+	// Скидка покрыла всю стоимость: оплачивать нечего, бронь подтверждается сразу
+	err = retryOnConflict(func() error {
+		return h.eventService.ConfirmPayment(ctx, eventID, userID)
+	})
+	if err != nil && !errors.Is(err, event.ErrRegistrationAlreadyApproved) {
+		h.logger.Error("failed to confirm fully discounted registration", "event_id", string(eventID), "user_id", userID, "error", err)
+		return
+	}
+	h.notifyTeamModerated(ctx, eventID, userID, true)
+}
+
+// releasePromo отменяет погашение промокода при отмене или отклонении записи, чтобы он снова стал доступен
+func (h *Handlers) releasePromo(ctx context.Context, eventID event.EventID, userID int64) {
+	r, err := h.promoService.Release(ctx, eventID, userID)
+	if err != nil {
+		h.logger.Error("failed to release promo code", "event_id", string(eventID), "user_id", userID, "error", err)
+		return
+	}
+	if r != nil {
+		h.logger.Info("promo code released", "event_id", string(eventID), "user_id", userID, "promo_id", string(r.CodeID))
+	}
+}
+
+// showPromos показывает администратору список промокодов; при messageID = 0 отправляет новое сообщение
+func (h *Handlers) showPromos(ctx context.Context, chatID int64, messageID int) {
+	codes, err := h.promoService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list promo codes", "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения промокодов"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	eventNames := make(map[event.EventID]string)
+	for _, c := range codes {
+		if c.EventID == "" {
+			continue
+		}
+		if evt, err := h.eventService.Get(ctx, c.EventID); err == nil && evt != nil {
+			eventNames[c.EventID] = evt.Name
+		}
+	}
+
+	text, keyboard := h.formatter.FormatPromoCodes(codes, eventNames, time.Now())
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with promo codes", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send promo codes", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminPromos показывает список промокодов (формат: admin:promos)
+func (h *Handlers) handleAdminPromos(ctx context.Context, cb *CallbackQuery) {
+	delete(h.creatingPromos, cb.Message.ChatID)
+	h.showPromos(ctx, cb.Message.ChatID, cb.Message.MessageID)
+}
+
+// showPromo показывает администратору карточку промокода с погашениями
+func (h *Handlers) showPromo(ctx context.Context, chatID int64, messageID int, id promo.CodeID) {
+	c, err := h.promoService.Get(ctx, id)
+	if err != nil || c == nil {
+		h.logger.Error("failed to get promo code", "promo_id", string(id), "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Промокод не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	redemptions, err := h.promoService.Redemptions(ctx, id)
+	if err != nil {
+		h.logger.Error("failed to list promo redemptions", "promo_id", string(id), "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения погашений промокода"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	names := make(map[int64]string)
+	events := make(map[event.EventID]*event.Event)
+	eventIDs := []event.EventID{c.EventID}
+	for _, r := range redemptions {
+		if _, ok := names[r.UserID]; !ok {
+			names[r.UserID] = h.playerName(ctx, r.UserID)
+		}
+		eventIDs = append(eventIDs, r.EventID)
+	}
+	for _, eventID := range eventIDs {
+		if _, ok := events[eventID]; ok || eventID == "" {
+			continue
+		}
+		evt, err := h.eventService.Get(ctx, eventID)
+		if err != nil {
+			h.logger.Warn("failed to get event for promo code", "event_id", string(eventID), "error", err)
+		}
+		events[eventID] = evt
+	}
+
+	text, keyboard := h.formatter.FormatPromoCode(c, redemptions, names, events, time.Now())
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with promo code", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send promo code", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminPromo показывает карточку промокода (формат: admin:promo:{codeID})
+func (h *Handlers) handleAdminPromo(ctx context.Context, cb *CallbackQuery) {
+	h.showPromo(ctx, cb.Message.ChatID, cb.Message.MessageID, promo.CodeID(strings.TrimPrefix(cb.Data, "admin:promo:")))
+}
+
+// handleAdminDeactivatePromo отключает промокод (формат: admin:promo_off:{codeID})
+func (h *Handlers) handleAdminDeactivatePromo(ctx context.Context, cb *CallbackQuery) {
+	id := promo.CodeID(strings.TrimPrefix(cb.Data, "admin:promo_off:"))
+
+	c, err := h.promoService.Deactivate(ctx, id)
+	if err != nil {
+		h.logger.Error("failed to deactivate promo code", "promo_id", string(id), "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, promoErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.logger.Info("promo code deactivated", "promo_id", string(id), "code", c.Code, "admin_id", cb.From.ID)
+	h.showPromo(ctx, cb.Message.ChatID, cb.Message.MessageID, id)
+}
+
+// handleAdminStartCreatePromo начинает создание промокода (формат: admin:promo_new)
+func (h *Handlers) handleAdminStartCreatePromo(cb *CallbackQuery) {
+	h.creatingPromos[cb.Message.ChatID] = &PromoCreationState{Step: "code"}
+	text := "🏷 Создание промокода\n\nВведите текст промокода (3-32 символа: латинские буквы, цифры, «-» и «_»), например SUMMER10:\n\nДля отмены отправьте /cancel"
+	if err := h.client.SendMessage(cb.Message.ChatID, text); err != nil {
+		h.logger.Error("failed to send promo code prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminPromoInput обрабатывает текстовые шаги создания промокода: текст, размер скидки, лимиты и срок
+func (h *Handlers) handleAdminPromoInput(ctx context.Context, msg *Message, state *PromoCreationState) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		delete(h.creatingPromos, msg.ChatID)
+		h.showPromos(ctx, msg.ChatID, 0)
+		return
+	}
+
+	if state.Step == "code" {
+		code := promo.Normalize(input)
+		if err := (promo.CreateInput{Code: code, Kind: promo.KindFixed, Value: 1}).Validate(); err != nil {
+			if sendErr := h.client.SendMessage(msg.ChatID, promoErrorMessage(err)+"\n\nВведите другой промокод или /cancel:"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		codes, err := h.promoService.List(ctx)
+		if err != nil {
+			h.logger.Error("failed to list promo codes", "chat_id", msg.ChatID, "error", err)
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка получения промокодов"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		for _, c := range codes {
+			if c.Code == code {
+				if sendErr := h.client.SendMessage(msg.ChatID, promoErrorMessage(promo.ErrCodeExists)+"\n\nВведите другой промокод или /cancel:"); sendErr != nil {
+					h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+				}
+				return
+			}
+		}
+
+		state.Code = code
+		state.Step = "kind"
+		text, keyboard := h.formatter.FormatPromoKindPicker(code)
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send promo kind picker", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 0 || (state.Step == "value" && n == 0) {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите целое неотрицательное число или /cancel:"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	var prompt string
+	switch state.Step {
+	case "value":
+		if state.Kind == promo.KindPercent && n > 100 {
+			if sendErr := h.client.SendMessage(msg.ChatID, promoErrorMessage(promo.ErrPercentTooHigh)+"\n\nВведите процент скидки или /cancel:"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		state.Value = n
+		state.Step = "max_uses"
+		prompt = "🔢 Сколько раз всего можно использовать промокод? (0 - без ограничений)"
+	case "max_uses":
+		state.MaxUses = n
+		state.Step = "per_user"
+		prompt = "👤 Сколько раз может использовать промокод один игрок? (0 - без ограничений)"
+	case "per_user":
+		state.MaxUsesPerUser = n
+		state.Step = "days"
+		prompt = "📅 Сколько дней действует промокод? (0 - бессрочно)"
+	case "days":
+		state.Days = n
+		state.Step = "scope"
+		text, keyboard := h.formatter.FormatPromoScopePicker()
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send promo scope picker", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	default:
+		return
+	}
+	if err := h.client.SendMessage(msg.ChatID, prompt); err != nil {
+		h.logger.Error("failed to send promo prompt", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// handleAdminPromoKind сохраняет вид скидки промокода (формат: admin:promo_kind:{percent|fixed})
+func (h *Handlers) handleAdminPromoKind(cb *CallbackQuery) {
+	state := h.creatingPromos[cb.Message.ChatID]
+	if state == nil || state.Step != "kind" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните создание промокода заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.Kind = promo.Kind(strings.TrimPrefix(cb.Data, "admin:promo_kind:"))
+	state.Step = "value"
+	text := "💰 Введите сумму скидки в единицах цены события (рубли или звезды):"
+	if state.Kind == promo.KindPercent {
+		text = "％ Введите процент скидки (от 1 до 100):"
+	}
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, text); err != nil {
+		h.logger.Error("failed to edit message with promo value prompt", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminPromoScope сохраняет, на какие события действует промокод (формат: admin:promo_scope:{any|training|competition|event|back})
+func (h *Handlers) handleAdminPromoScope(ctx context.Context, cb *CallbackQuery) {
+	state := h.creatingPromos[cb.Message.ChatID]
+	if state == nil || (state.Step != "scope" && state.Step != "event") {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните создание промокода заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	switch scope := strings.TrimPrefix(cb.Data, "admin:promo_scope:"); scope {
+	case "back":
+		state.Step = "scope"
+		text, keyboard := h.formatter.FormatPromoScopePicker()
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with promo scope picker", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	case "event":
+		state.Step = "event"
+		h.showPromoEventPicker(ctx, cb)
+	case "any":
+		h.createPromo(ctx, cb, state, nil, "")
+	default:
+		h.createPromo(ctx, cb, state, []event.EventType{event.EventType(scope)}, "")
+	}
+}
+
+// showPromoEventPicker показывает ближайшие платные события для промокода на конкретное событие
+func (h *Handlers) showPromoEventPicker(ctx context.Context, cb *CallbackQuery) {
+	events, err := h.eventService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list events", "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка событий"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	now := time.Now()
+	paid := make([]event.Event, 0, len(events))
+	for _, evt := range events {
+		if evt.Status.IsActive() && evt.Date.After(now) && evt.Price > 0 {
+			paid = append(paid, evt)
+		}
+	}
+	sort.Slice(paid, func(i, j int) bool { return paid[i].Date.Before(paid[j].Date) })
+	if len(paid) > maxPromoEvents {
+		paid = paid[:maxPromoEvents]
+	}
+
+	text, keyboard := h.formatter.FormatPromoEventPicker(paid)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with promo event picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminPromoEvent создает промокод на выбранное событие (формат: admin:promo_evt:{eventID})
+func (h *Handlers) handleAdminPromoEvent(ctx context.Context, cb *CallbackQuery) {
+	state := h.creatingPromos[cb.Message.ChatID]
+	if state == nil || state.Step != "event" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните создание промокода заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	h.createPromo(ctx, cb, state, nil, event.EventID(strings.TrimPrefix(cb.Data, "admin:promo_evt:")))
+}
+
+// createPromo создает промокод по собранным в мастере данным и показывает его карточку
+func (h *Handlers) createPromo(ctx context.Context, cb *CallbackQuery, state *PromoCreationState, eventTypes []event.EventType, eventID event.EventID) {
+	c, err := h.promoService.Create(ctx, cb.From.ID, promo.CreateInput{
+		Code:           state.Code,
+		Kind:           state.Kind,
+		Value:          state.Value,
+		MaxUses:        state.MaxUses,
+		MaxUsesPerUser: state.MaxUsesPerUser,
+		ValidDays:      state.Days,
+		EventTypes:     eventTypes,
+		EventID:        eventID,
+	})
+	if err != nil {
+		h.logger.Error("failed to create promo code", "code", state.Code, "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, promoErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	delete(h.creatingPromos, cb.Message.ChatID)
+
+	h.logger.Info("promo code created", "promo_id", string(c.ID), "code", c.Code, "kind", string(c.Kind), "value", c.Value, "admin_id", cb.From.ID)
+	h.showPromo(ctx, cb.Message.ChatID, cb.Message.MessageID, c.ID)
+}
+
+// promoErrorMessage возвращает понятное пользователю сообщение об ошибке промокода
+func promoErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, promo.ErrCodeInvalid):
+		return "❌ Промокод может содержать от 3 до 32 символов: латинские буквы, цифры, «-» и «_»"
+	case errors.Is(err, promo.ErrCodeExists):
+		return "❌ Такой промокод уже существует"
+	case errors.Is(err, promo.ErrKindInvalid):
+		return "❌ Неизвестный вид скидки"
+	case errors.Is(err, promo.ErrValueInvalid):
+		return "❌ Размер скидки должен быть больше нуля"
+	case errors.Is(err, promo.ErrPercentTooHigh):
+		return "❌ Скидка не может быть больше 100%"
+	case errors.Is(err, promo.ErrLimitInvalid):
+		return "❌ Лимиты и срок действия не могут быть отрицательными"
+	case errors.Is(err, promo.ErrCodeNotFound):
+		return "❌ Промокод не найден"
+	case errors.Is(err, promo.ErrCodeExpired):
+		return "❌ Срок действия промокода истек"
+	case errors.Is(err, promo.ErrCodeNotApplicable):
+		return "❌ Промокод не действует на это событие"
+	case errors.Is(err, promo.ErrCodeExhausted):
+		return "❌ Промокод больше недоступен: лимит использований исчерпан"
+	case errors.Is(err, promo.ErrCodeUserLimit):
+		return "❌ Вы уже использовали этот промокод максимальное число раз"
+	case errors.Is(err, event.ErrDiscountAlreadyApplied), errors.Is(err, promo.ErrAlreadyRedeemed):
+		return "❌ К этой записи уже применен промокод"
+	case errors.Is(err, event.ErrRegistrationAlreadyApproved):
+		return "❌ Запись уже подтверждена, промокод применить нельзя"
+	case errors.Is(err, event.ErrRegistrationNotFound), errors.Is(err, event.ErrRegistrationNotPayable):
+		return "❌ У вас нет активной записи на это событие"
+	default:
+		return "❌ Не удалось применить промокод. Попробуйте позже."
+	}
+}
@@ -0,0 +1,304 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/restriction"
+	"pickletlgbot/internal/domain/role"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleAdminUserCommand открывает карточку игрока по Telegram ID (/admin_user <telegram_id>)
+func (h *Handlers) handleAdminUserCommand(msg *Message, args []string) {
+	if len(args) != 1 {
+		if err := h.client.SendMessage(msg.ChatID, "Использование: /admin_user <telegram_id>\nНапример: /admin_user 123456789"); err != nil {
+			h.logger.Error("failed to send admin user usage", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Некорректный Telegram ID"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	delete(h.restrictingUsers, msg.ChatID)
+	h.showUserCard(context.Background(), msg.ChatID, 0, userID, msg.From.ID)
+}
+
+// handleAdminUserCard показывает карточку игрока (формат: admin:usr:{userID})
+func (h *Handlers) handleAdminUserCard(ctx context.Context, cb *CallbackQuery) {
+	userID, err := strconv.ParseInt(strings.TrimPrefix(cb.Data, "admin:usr:"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid user card callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	delete(h.restrictingUsers, cb.Message.ChatID)
+	h.showUserCard(ctx, cb.Message.ChatID, cb.Message.MessageID, userID, cb.From.ID)
+}
+
+// showUserCard показывает карточку игрока с ограничениями; при messageID = 0 отправляет новое сообщение
+func (h *Handlers) showUserCard(ctx context.Context, chatID int64, messageID int, userID, viewerID int64) {
+	usr, err := h.userService.GetByTelegramID(ctx, userID)
+	if err != nil || usr == nil {
+		h.logger.Error("failed to get user for card", "user_id", userID, "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Игрок не найден"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	restrictions, err := h.restrictionService.ListByUser(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to list restrictions", "user_id", userID, "chat_id", chatID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения ограничений игрока"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	adminNames := make(map[int64]string)
+	for _, r := range restrictions {
+		for _, id := range []int64{r.CreatedBy, r.LiftedBy} {
+			if _, ok := adminNames[id]; !ok && id != 0 {
+				adminNames[id] = h.playerName(ctx, id)
+			}
+		}
+	}
+
+	canManage := h.can(ctx, viewerID, role.PermManagePlayers, role.Global)
+	text, keyboard := h.formatter.FormatUserCard(usr, restrictions, adminNames, h.locationNames(ctx), canManage, time.Now())
+	if messageID > 0 {
+		if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with user card", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+	if err := h.client.SendMessageWithKeyboard(chatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send user card", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminStartRestriction начинает установку ограничения
+// (формат: admin:ban:full:{userID}, admin:ban:until:{userID}, admin:ban:loc:{userID})
+func (h *Handlers) handleAdminStartRestriction(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 4 {
+		h.logger.Warn("invalid restriction callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	userID, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid restriction callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	state := &RestrictionState{UserID: userID}
+	h.restrictingUsers[cb.Message.ChatID] = state
+
+	switch parts[2] {
+	case "full":
+		state.Kind = restriction.KindBan
+		state.Step = "reason"
+		h.sendRestrictionReasonPrompt(cb.Message.ChatID)
+	case "until":
+		state.Kind = restriction.KindUntil
+		state.Step = "until"
+		if err := h.client.SendMessage(cb.Message.ChatID, "⏳ До какой даты запретить запись? Введите дату в формате ДД.ММ.ГГГГ или ДД.ММ.ГГГГ ЧЧ:ММ:\n\nДля отмены отправьте /cancel"); err != nil {
+			h.logger.Error("failed to send restriction date prompt", "chat_id", cb.Message.ChatID, "error", err)
+		}
+	case "loc":
+		state.Kind = restriction.KindLocations
+		state.Step = "locations"
+		h.showRestrictionLocationPicker(ctx, cb, state)
+	default:
+		delete(h.restrictingUsers, cb.Message.ChatID)
+	}
+}
+
+// sendRestrictionReasonPrompt просит ввести причину ограничения
+func (h *Handlers) sendRestrictionReasonPrompt(chatID int64) {
+	if err := h.client.SendMessage(chatID, "📝 Введите причину ограничения (ее увидит игрок):\n\nДля отмены отправьте /cancel"); err != nil {
+		h.logger.Error("failed to send restriction reason prompt", "chat_id", chatID, "error", err)
+	}
+}
+
+// handleAdminRestrictionToggleLocation отмечает локацию ограничения (формат: admin:ban:lt:{locationID})
+func (h *Handlers) handleAdminRestrictionToggleLocation(ctx context.Context, cb *CallbackQuery) {
+	state := h.restrictingUsers[cb.Message.ChatID]
+	if state == nil || state.Step != "locations" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните установку ограничения заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	locationID := location.LocationID(strings.TrimPrefix(cb.Data, "admin:ban:lt:"))
+	selected := state.LocationIDs[:0]
+	found := false
+	for _, id := range state.LocationIDs {
+		if id == locationID {
+			found = true
+			continue
+		}
+		selected = append(selected, id)
+	}
+	if !found {
+		selected = append(selected, locationID)
+	}
+	state.LocationIDs = selected
+	h.showRestrictionLocationPicker(ctx, cb, state)
+}
+
+// showRestrictionLocationPicker показывает выбор локаций ограничения
+func (h *Handlers) showRestrictionLocationPicker(ctx context.Context, cb *CallbackQuery, state *RestrictionState) {
+	locations, err := h.locationService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list locations", "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения списка локаций"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatRestrictionLocationPicker(h.playerName(ctx, state.UserID), state.LocationIDs, locations)
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with restriction location picker", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleAdminRestrictionLocationsDone завершает выбор локаций и просит причину (формат: admin:ban:ld)
+func (h *Handlers) handleAdminRestrictionLocationsDone(cb *CallbackQuery) {
+	state := h.restrictingUsers[cb.Message.ChatID]
+	if state == nil || state.Step != "locations" {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения состояния. Начните установку ограничения заново."); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	if len(state.LocationIDs) == 0 {
+		if err := h.client.SendMessage(cb.Message.ChatID, "❌ Отметьте хотя бы одну локацию"); err != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	state.Step = "reason"
+	h.sendRestrictionReasonPrompt(cb.Message.ChatID)
+}
+
+// handleAdminCancelRestriction отменяет установку ограничения (формат: admin:ban:cancel)
+func (h *Handlers) handleAdminCancelRestriction(ctx context.Context, cb *CallbackQuery) {
+	state := h.restrictingUsers[cb.Message.ChatID]
+	delete(h.restrictingUsers, cb.Message.ChatID)
+	if state == nil {
+		text, keyboard := h.formatter.FormatAdminMenu()
+		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+			h.logger.Error("failed to edit message with admin menu", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+	h.showUserCard(ctx, cb.Message.ChatID, cb.Message.MessageID, state.UserID, cb.From.ID)
+}
+
+// handleAdminRestrictionInput обрабатывает ввод даты окончания и причины ограничения
+func (h *Handlers) handleAdminRestrictionInput(ctx context.Context, msg *Message, state *RestrictionState) {
+	input := strings.TrimSpace(msg.Text)
+	if input == "/cancel" {
+		delete(h.restrictingUsers, msg.ChatID)
+		h.showUserCard(ctx, msg.ChatID, 0, state.UserID, msg.From.ID)
+		return
+	}
+
+	if state.Step == "until" {
+		until, err := time.ParseInLocation("02.01.2006 15:04", input, time.Local)
+		if err != nil {
+			until, err = time.ParseInLocation("02.01.2006", input, time.Local)
+		}
+		if err != nil || !until.After(time.Now()) {
+			if sendErr := h.client.SendMessage(msg.ChatID, "❌ Введите дату в будущем в формате ДД.ММ.ГГГГ или ДД.ММ.ГГГГ ЧЧ:ММ, либо /cancel:"); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+			}
+			return
+		}
+		state.Until = until
+		state.Step = "reason"
+		h.sendRestrictionReasonPrompt(msg.ChatID)
+		return
+	}
+
+	r, err := h.restrictionService.Restrict(ctx, msg.From.ID, restriction.CreateInput{
+		UserID:      state.UserID,
+		Kind:        state.Kind,
+		Until:       state.Until,
+		LocationIDs: state.LocationIDs,
+		Reason:      input,
+	})
+	if err != nil {
+		h.logger.Error("failed to restrict user", "user_id", state.UserID, "chat_id", msg.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, restrictionErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		// Без причины ограничение не ставится: ждем ее повторно
+		if errors.Is(err, restriction.ErrReasonRequired) {
+			return
+		}
+		delete(h.restrictingUsers, msg.ChatID)
+		return
+	}
+	delete(h.restrictingUsers, msg.ChatID)
+
+	h.logger.Info("user restricted", "restriction_id", string(r.ID), "user_id", r.UserID, "kind", string(r.Kind), "admin_id", msg.From.ID)
+	locationNames := h.locationNames(ctx)
+	if err := h.client.SendMessage(r.UserID, h.formatter.FormatRestrictionNotice(r, locationNames)); err != nil {
+		h.logger.Error("failed to notify user about restriction", "user_id", r.UserID, "error", err)
+	}
+
+	h.showUserCard(ctx, msg.ChatID, 0, r.UserID, msg.From.ID)
+}
+
+// handleAdminLiftRestriction снимает ограничение досрочно (формат: admin:ban:lift:{restrictionID})
+func (h *Handlers) handleAdminLiftRestriction(ctx context.Context, cb *CallbackQuery) {
+	id := restriction.RestrictionID(strings.TrimPrefix(cb.Data, "admin:ban:lift:"))
+
+	r, err := h.restrictionService.Lift(ctx, id, cb.From.ID)
+	if err != nil {
+		h.logger.Error("failed to lift restriction", "restriction_id", string(id), "chat_id", cb.Message.ChatID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, restrictionErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.logger.Info("restriction lifted", "restriction_id", string(r.ID), "user_id", r.UserID, "admin_id", cb.From.ID)
+	if err := h.client.SendMessage(r.UserID, h.formatter.FormatRestrictionLifted(r, h.locationNames(ctx))); err != nil {
+		h.logger.Error("failed to notify user about lifted restriction", "user_id", r.UserID, "error", err)
+	}
+
+	h.showUserCard(ctx, cb.Message.ChatID, cb.Message.MessageID, r.UserID, cb.From.ID)
+}
+
+// restrictionErrorMessage возвращает сообщение об ошибке работы с ограничением
+func restrictionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, restriction.ErrReasonRequired):
+		return "❌ Укажите причину ограничения:"
+	case errors.Is(err, restriction.ErrUntilInPast):
+		return "❌ Дата окончания запрета должна быть в будущем"
+	case errors.Is(err, restriction.ErrLocationsRequired):
+		return "❌ Отметьте хотя бы одну локацию"
+	case errors.Is(err, restriction.ErrRestrictionNotFound):
+		return "❌ Ограничение не найдено"
+	case errors.Is(err, restriction.ErrAlreadyLifted):
+		return "⚠️ Ограничение уже снято"
+	default:
+		return fmt.Sprintf("❌ Ошибка: %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/subscription"
+	"pickletlgbot/internal/domain/user"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subscriberNotifyInterval - минимальный интервал между личными сообщениями подписчикам
+// (ограничение Telegram - около 30 сообщений в секунду)
+const subscriberNotifyInterval = 50 * time.Millisecond
+
+// subscriberNoticeQueueSize - сколько опубликованных событий может ждать рассылки подписчикам
+const subscriberNoticeQueueSize = 64
+
+// handleSubscriptionCallback обрабатывает кнопки подписки (формат: subs, subs:on, subs:off, subs:locs, subs:loc:{locationID},
+// subs:types, subs:type:{type}, subs:trainers, subs:tr:{index}, subs:levels, subs:min:{level}, subs:lvl:{min}:{max})
+func (h *Handlers) handleSubscriptionCallback(ctx context.Context, cb *CallbackQuery) {
+	chatID := cb.Message.ChatID
+	userID := cb.From.ID
+
+	sub, err := h.subscriptionService.Get(ctx, userID)
+	if err != nil {
+		h.logger.Error("failed to get subscription", "user_id", userID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, "❌ Ошибка получения подписки"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+	if sub == nil {
+		sub = &subscription.Subscription{UserID: userID}
+	}
+
+	var text string
+	var keyboard *InlineKeyboardMarkup
+	switch {
+	case cb.Data == "subs":
+		h.showSubscription(ctx, chatID, cb.Message.MessageID, sub)
+		return
+	case cb.Data == "subs:on", cb.Data == "subs:off":
+		enable := cb.Data == "subs:on"
+		h.updateSubscription(ctx, cb, func(s *subscription.Subscription) error {
+			// При первом включении берем локации из профиля игрока
+			if enable && s.UpdatedAt.IsZero() && len(s.LocationIDs) == 0 {
+				if usr, err := h.userService.GetByTelegramID(ctx, userID); err == nil && usr != nil {
+					s.LocationIDs = append(s.LocationIDs, usr.PreferredLocations...)
+				}
+			}
+			s.Enabled = enable
+			return nil
+		}, "")
+		return
+	case cb.Data == "subs:locs":
+		locations, err := h.locationService.List(ctx)
+		if err != nil {
+			h.logger.Error("failed to list locations", "error", err)
+			return
+		}
+		text, keyboard = h.formatter.FormatSubscriptionLocationsPicker(sub.LocationIDs, locations)
+	case cb.Data == "subs:types":
+		text, keyboard = h.formatter.FormatSubscriptionTypesPicker(sub.EventTypes)
+	case cb.Data == "subs:trainers":
+		text, keyboard = h.formatter.FormatSubscriptionTrainersPicker(sub.Trainers, h.knownTrainers(ctx))
+	case cb.Data == "subs:levels":
+		text, keyboard = h.formatter.FormatSubscriptionMinLevelPicker()
+	case strings.HasPrefix(cb.Data, "subs:loc:"):
+		id := location.LocationID(strings.TrimPrefix(cb.Data, "subs:loc:"))
+		h.updateSubscription(ctx, cb, func(s *subscription.Subscription) error {
+			s.ToggleLocation(id)
+			return nil
+		}, "subs:locs")
+		return
+	case strings.HasPrefix(cb.Data, "subs:type:"):
+		t := event.EventType(strings.TrimPrefix(cb.Data, "subs:type:"))
+		h.updateSubscription(ctx, cb, func(s *subscription.Subscription) error {
+			s.ToggleEventType(t)
+			return nil
+		}, "subs:types")
+		return
+	case strings.HasPrefix(cb.Data, "subs:tr:"):
+		index, err := strconv.Atoi(strings.TrimPrefix(cb.Data, "subs:tr:"))
+		trainers := h.knownTrainers(ctx)
+		if err != nil || index < 0 || index >= len(trainers) {
+			h.logger.Warn("invalid subscription trainer callback data", "callback_data", cb.Data, "chat_id", chatID)
+			return
+		}
+		name := trainers[index]
+		h.updateSubscription(ctx, cb, func(s *subscription.Subscription) error {
+			s.ToggleTrainer(name)
+			return nil
+		}, "subs:trainers")
+		return
+	case strings.HasPrefix(cb.Data, "subs:min:"):
+		value, err := strconv.Atoi(strings.TrimPrefix(cb.Data, "subs:min:"))
+		if err != nil {
+			h.logger.Warn("invalid subscription level callback data", "callback_data", cb.Data, "chat_id", chatID)
+			return
+		}
+		text, keyboard = h.formatter.FormatSubscriptionMaxLevelPicker(user.Level(value))
+	case strings.HasPrefix(cb.Data, "subs:lvl:"):
+		parts := strings.Split(strings.TrimPrefix(cb.Data, "subs:lvl:"), ":")
+		if len(parts) != 2 {
+			h.logger.Warn("invalid subscription level callback data", "callback_data", cb.Data, "chat_id", chatID)
+			return
+		}
+		min, minErr := strconv.Atoi(parts[0])
+		max, maxErr := strconv.Atoi(parts[1])
+		if minErr != nil || maxErr != nil {
+			h.logger.Warn("invalid subscription level callback data", "callback_data", cb.Data, "chat_id", chatID)
+			return
+		}
+		h.updateSubscription(ctx, cb, func(s *subscription.Subscription) error {
+			return s.SetLevelRange(user.Level(min), user.Level(max))
+		}, "")
+		return
+	default:
+		h.logger.Warn("unknown subscription callback", "callback_data", cb.Data, "chat_id", chatID)
+		return
+	}
+
+	if err := h.client.EditMessageTextAndMarkup(chatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with subscription picker", "chat_id", chatID, "error", err)
+	}
+}
+
+// showSubscription выводит настройки подписки вместо сообщения messageID
+func (h *Handlers) showSubscription(ctx context.Context, chatID int64, messageID int, sub *subscription.Subscription) {
+	text, keyboard := h.formatter.FormatSubscription(sub, h.locationNames(ctx))
+	if err := h.client.EditMessageHTMLAndMarkup(chatID, messageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with subscription", "chat_id", chatID, "error", err)
+	}
+}
+
+// updateSubscription сохраняет изменение подписки и возвращает игрока к выбору picker
+// (пусто - к настройкам подписки)
+func (h *Handlers) updateSubscription(ctx context.Context, cb *CallbackQuery, fn func(s *subscription.Subscription) error, picker string) {
+	chatID := cb.Message.ChatID
+	sub, err := h.subscriptionService.Update(ctx, cb.From.ID, fn)
+	if err != nil {
+		h.logger.Error("failed to update subscription", "user_id", cb.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(chatID, subscriptionErrorMessage(err)); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+	h.logger.Info("subscription updated", "user_id", cb.From.ID, "enabled", sub.Enabled)
+
+	var text string
+	var keyboard *InlineKeyboardMarkup
+	switch picker {
+	case "subs:locs":
+		locations, err := h.locationService.List(ctx)
+		if err != nil {
+			h.logger.Error("failed to list locations", "error", err)
+			return
+		}
+		text, keyboard = h.formatter.FormatSubscriptionLocationsPicker(sub.LocationIDs, locations)
+	case "subs:types":
+		text, keyboard = h.formatter.FormatSubscriptionTypesPicker(sub.EventTypes)
+	case "subs:trainers":
+		text, keyboard = h.formatter.FormatSubscriptionTrainersPicker(sub.Trainers, h.knownTrainers(ctx))
+	default:
+		h.showSubscription(ctx, chatID, cb.Message.MessageID, sub)
+		return
+	}
+	if err := h.client.EditMessageTextAndMarkup(chatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with subscription picker", "chat_id", chatID, "error", err)
+	}
+}
+
+// knownTrainers возвращает отсортированный список тренеров, указанных в событиях
+func (h *Handlers) knownTrainers(ctx context.Context) []string {
+	events, err := h.eventService.List(ctx)
+	if err != nil {
+		h.logger.Error("failed to list events", "error", err)
+		return nil
+	}
+	seen := make(map[string]bool)
+	var trainers []string
+	for _, evt := range events {
+		name := strings.TrimSpace(evt.Trainer)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		trainers = append(trainers, name)
+	}
+	sort.Strings(trainers)
+	return trainers
+}
+
+// StartSubscriberNotifier запускает в отдельной горутине рассылку подписчикам о новых событиях из очереди.
+// Рассылка идет, пока не будет отменен ctx; wg позволяет дождаться ее завершения
+func (h *Handlers) StartSubscriberNotifier(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				h.logger.Info("subscriber notifier stopped")
+				return
+			case eventID := <-h.subscriberNotices:
+				h.notifySubscribers(ctx, eventID)
+			}
+		}
+	}()
+}
+
+// enqueueSubscriberNotice ставит событие в очередь рассылки подписчикам, не дожидаясь отправки
+func (h *Handlers) enqueueSubscriberNotice(eventID event.EventID) {
+	select {
+	case h.subscriberNotices <- eventID:
+	default:
+		h.logger.Warn("subscriber notice queue is full", "event_id", string(eventID))
+	}
+}
+
+// notifySubscribers рассылает подписчикам личные сообщения о новом событии. Событие загружается заново,
+// чтобы учесть записи, появившиеся, пока оно ждало в очереди.
+// Сообщения отправляются не чаще subscriberNotifyInterval, чтобы не упереться в лимиты Telegram
+func (h *Handlers) notifySubscribers(ctx context.Context, eventID event.EventID) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for subscribers", "event_id", string(eventID), "error", err)
+		return
+	}
+	if !evt.IsOpen(time.Now()) {
+		return
+	}
+	subs, err := h.subscriptionService.Matching(ctx, evt)
+	if err != nil {
+		h.logger.Error("failed to list matching subscriptions", "event_id", string(evt.ID), "error", err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	var locationName string
+	if loc, err := h.locationService.Get(ctx, evt.LocationID); err == nil && loc != nil {
+		locationName = loc.Name
+	}
+	text, keyboard := h.formatter.FormatSubscriptionEventNotice(evt, locationName, h.client.Username())
+
+	sent := 0
+	for _, sub := range subs {
+		if sub.UserID == evt.CreatedBy {
+			continue
+		}
+		if reg, ok := evt.Registrations[sub.UserID]; ok && reg.Status.Active() {
+			continue
+		}
+		if h.restrictionService != nil {
+			r, err := h.restrictionService.Blocking(ctx, sub.UserID, evt.LocationID, time.Now())
+			if err != nil {
+				h.logger.Error("failed to check restriction", "user_id", sub.UserID, "error", err)
+				continue
+			}
+			if r != nil {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.notifyTicker.C:
+		}
+		if err := h.client.SendMessageWithKeyboard(sub.UserID, text, keyboard); err != nil {
+			h.logger.Error("failed to notify subscriber", "user_id", sub.UserID, "event_id", string(evt.ID), "error", err)
+			continue
+		}
+		sent++
+	}
+	h.logger.Info("subscribers notified", "event_id", string(evt.ID), "sent", sent)
+}
+
+// subscriptionErrorMessage возвращает сообщение пользователю по ошибке изменения подписки
+func subscriptionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, subscription.ErrLevelRangeInvalid):
+		return "❌ Нижняя граница уровня не может быть выше верхней"
+	case errors.Is(err, subscription.ErrEventTypeInvalid):
+		return "❌ Неизвестный тип события"
+	default:
+		return "❌ Ошибка сохранения подписки"
+	}
+}
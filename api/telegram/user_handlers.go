@@ -2,22 +2,42 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"pickletlgbot/internal/domain/event"
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/domain/reminder"
+	"pickletlgbot/internal/domain/role"
 	"pickletlgbot/internal/domain/user"
 	"strings"
+	"time"
 )
 
 // handleStart обрабатывает команду /start, включая deep link /start event_<id>
 func (h *Handlers) handleStart(ctx context.Context, msg *Message) {
 	parts := strings.Fields(msg.Text)
+	if len(parts) == 2 && strings.HasPrefix(parts[1], "checkin_") {
+		h.handleCheckIn(ctx, msg, event.EventID(strings.TrimPrefix(parts[1], "checkin_")))
+		return
+	}
+	if len(parts) == 2 && strings.HasPrefix(parts[1], "team_") {
+		// Формат: team_<eventID>_<captainID> (ID события не содержит "_")
+		payload := strings.TrimPrefix(parts[1], "team_")
+		if i := strings.LastIndex(payload, "_"); i > 0 {
+			var captainID int64
+			if _, err := fmt.Sscanf(payload[i+1:], "%d", &captainID); err == nil {
+				h.handleTeamInvite(ctx, msg, event.EventID(payload[:i]), captainID)
+				return
+			}
+		}
+	}
 	if len(parts) == 2 && strings.HasPrefix(parts[1], "event_") {
 		eventIDStr := strings.TrimPrefix(parts[1], "event_")
 		evt, err := h.eventService.Get(ctx, event.EventID(eventIDStr))
-		if err == nil && evt != nil {
-			text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, msg.From.ID)
+		if err == nil && evt != nil && evt.Status != event.StatusDraft {
+			text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, msg.From.ID, h.viewerLevel(ctx, msg.From.ID), h.cancellationPolicy(ctx, evt))
 			if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
 				h.logger.Error("failed to send event details via deep link", "chat_id", msg.ChatID, "error", err)
 			}
@@ -31,6 +51,37 @@ func (h *Handlers) handleStart(ctx context.Context, msg *Message) {
 	}
 }
 
+// handleCheckIn отмечает игрока на событии по ссылке /start checkin_<id>
+func (h *Handlers) handleCheckIn(ctx context.Context, msg *Message, eventID event.EventID) {
+	var evt *event.Event
+	err := retryOnConflict(func() (err error) {
+		evt, err = h.eventService.CheckIn(ctx, eventID, msg.From.ID, time.Now())
+		return err
+	})
+	if err != nil {
+		h.logger.Warn("failed to check in", "event_id", string(eventID), "user_id", msg.From.ID, "error", err)
+		errorMsg := "❌ Не удалось отметиться"
+		if err == event.ErrEventNotFound {
+			errorMsg = "❌ Событие не найдено"
+		} else if err == event.ErrCheckInClosed {
+			errorMsg = "❌ Отметка на это событие сейчас недоступна"
+		} else if err == event.ErrRegistrationNotFound || err == event.ErrRegistrationNotApproved {
+			errorMsg = "❌ Отметиться могут только игроки с подтвержденной записью. Обратитесь к администратору."
+		} else if errors.Is(err, event.ErrConflict) {
+			errorMsg = conflictMessage
+		}
+		if sendErr := h.client.SendMessage(msg.ChatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text := fmt.Sprintf("✅ Вы отмечены на событии «%s». Хорошей игры!", evt.Name)
+	if err := h.client.SendMessage(msg.ChatID, text); err != nil {
+		h.logger.Error("failed to send check-in confirmation", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
 // handleLocations обрабатывает запрос списка локаций
 func (h *Handlers) handleLocations(ctx context.Context, cb *CallbackQuery) {
 	locations, err := h.locationService.List(ctx)
@@ -133,12 +184,15 @@ func (h *Handlers) handleLocationEvents(ctx context.Context, cb *CallbackQuery)
 		return
 	}
 
+	// Пользователям показываем только опубликованные предстоящие события
+	events = event.Upcoming(events, time.Now())
+
 	// Создаем map с названием локации
 	locationNames := make(map[location.LocationID]string)
 	locationNames[locationID] = loc.Name
 
 	// Используем кастомную кнопку "Назад" для возврата к локации
-	text, keyboard := h.formatter.FormatEventsListForUsersWithBack(events, locationNames, fmt.Sprintf("loc:%s", string(locationID)), "🔙 К локации")
+	text, keyboard := h.formatter.FormatEventsListForUsersWithBack(events, locationNames, h.viewerLevel(ctx, cb.From.ID), fmt.Sprintf("loc:%s", string(locationID)), "🔙 К локации")
 	if keyboard != nil {
 		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 			h.logger.Error("failed to edit message with events list", "chat_id", cb.Message.ChatID, "error", err)
@@ -161,6 +215,9 @@ func (h *Handlers) handleEvents(ctx context.Context, cb *CallbackQuery) {
 		return
 	}
 
+	// Пользователям показываем только опубликованные предстоящие события
+	events = event.Upcoming(events, time.Now())
+
 	// Собираем уникальные LocationID
 	locationIDs := make(map[location.LocationID]bool)
 	for _, evt := range events {
@@ -176,7 +233,7 @@ func (h *Handlers) handleEvents(ctx context.Context, cb *CallbackQuery) {
 		}
 	}
 
-	text, keyboard := h.formatter.FormatEventsListForUsers(events, locationNames)
+	text, keyboard := h.formatter.FormatEventsListForUsers(events, locationNames, h.viewerLevel(ctx, cb.From.ID))
 	if keyboard != nil {
 		if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 			h.logger.Error("failed to edit message with events list", "chat_id", cb.Message.ChatID, "error", err)
@@ -212,14 +269,15 @@ func (h *Handlers) handleEventSelection(ctx context.Context, cb *CallbackQuery)
 		return
 	}
 
-	if evt == nil {
+	// Черновики видны только сотрудникам с доступом к событию
+	if evt == nil || (evt.Status == event.StatusDraft && !h.can(ctx, cb.From.ID, role.PermView, role.ForEvent(evt))) {
 		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
 			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
 		}
 		return
 	}
 
-	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, cb.From.ID)
+	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, cb.From.ID, h.viewerLevel(ctx, cb.From.ID), h.cancellationPolicy(ctx, evt))
 	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 		h.logger.Error("failed to edit message with event details", "chat_id", cb.Message.ChatID, "error", err)
 	}
@@ -273,7 +331,9 @@ func (h *Handlers) handleEventRegistration(ctx context.Context, cb *CallbackQuer
 
 // registerUserToEvent регистрирует пользователя на событие
 func (h *Handlers) registerUserToEvent(ctx context.Context, eventID event.EventID, userID int64, chatID int64, messageID int) {
-	err := h.eventService.RegisterUserToEvent(ctx, eventID, userID)
+	err := retryOnConflict(func() error {
+		return h.eventService.RegisterUserToEvent(ctx, eventID, userID)
+	})
 	if err != nil {
 		h.logger.Error("failed to register user for event", "event_id", string(eventID), "user_id", userID, "chat_id", chatID, "error", err)
 
@@ -282,6 +342,25 @@ func (h *Handlers) registerUserToEvent(ctx context.Context, eventID event.EventI
 			errorMsg = "❌ Все места заняты"
 		} else if err == event.ErrUserAlreadyRegistered {
 			errorMsg = "⚠️ Вы уже зарегистрированы на это событие"
+		} else if err == event.ErrEventNotOpen {
+			errorMsg = "❌ Запись на это событие закрыта"
+		} else if errors.Is(err, event.ErrLevelNotAllowed) {
+			// Предлагаем попросить администратора допустить игрока вручную
+			keyboard := NewInlineKeyboardMarkup(
+				NewInlineKeyboardRow(
+					NewInlineKeyboardButtonData("🙋 Попросить допуск", fmt.Sprintf("event:level_request:%s", string(eventID))),
+				),
+			)
+			if sendErr := h.client.SendMessageWithKeyboard(chatID, "🔒 Ваш уровень не подходит для этого события. Можно попросить администратора допустить вас.", keyboard); sendErr != nil {
+				h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+			}
+			return
+		} else if blocked := (*event.NoShowBlockError)(nil); errors.As(err, &blocked) {
+			errorMsg = fmt.Sprintf("❌ Из-за неявок самостоятельная запись недоступна до %s. Обратитесь к администратору.", blocked.Until.Format("02.01.2006 15:04"))
+		} else if restricted := (*event.RestrictedError)(nil); errors.As(err, &restricted) {
+			errorMsg = h.formatter.FormatRestrictionBlock(&restricted.Restriction)
+		} else if errors.Is(err, event.ErrConflict) {
+			errorMsg = conflictMessage
 		}
 
 		if sendErr := h.client.SendMessage(chatID, errorMsg); sendErr != nil {
@@ -290,6 +369,12 @@ func (h *Handlers) registerUserToEvent(ctx context.Context, eventID event.EventI
 		return
 	}
 
+	h.sendRegistrationResult(ctx, eventID, userID, chatID, messageID)
+}
+
+// sendRegistrationResult показывает игроку результат регистрации: детали события,
+// позицию в листе ожидания или инструкцию по оплате, и уведомляет каналы
+func (h *Handlers) sendRegistrationResult(ctx context.Context, eventID event.EventID, userID int64, chatID int64, messageID int) {
 	// Получаем обновленное событие для отображения
 	evt, err := h.eventService.Get(ctx, eventID)
 	if err != nil || evt == nil {
@@ -299,7 +384,15 @@ func (h *Handlers) registerUserToEvent(ctx context.Context, eventID event.EventI
 		return
 	}
 
-	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, userID)
+	// Подходящий абонемент заменяет оплату: занятие списывается, и бронь сразу подтверждается
+	paidPass := h.payWithPass(ctx, evt, userID)
+	if paidPass != nil {
+		if updated, err := h.eventService.Get(ctx, eventID); err == nil && updated != nil {
+			evt = updated
+		}
+	}
+
+	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, userID, h.viewerLevel(ctx, userID), h.cancellationPolicy(ctx, evt))
 	if messageID > 0 {
 		// Редактируем существующее сообщение
 		if err := h.client.EditMessageTextAndMarkup(chatID, messageID, text, keyboard); err != nil {
@@ -312,13 +405,103 @@ func (h *Handlers) registerUserToEvent(ctx context.Context, eventID event.EventI
 		}
 	}
 
-	// Отправляем сообщение с инструкцией по оплате
-	h.sendPaymentInstruction(ctx, chatID, userID, evt)
+	// Капитан пары сначала приглашает напарника, оплата понадобится, когда команда соберется
+	if reg, ok := evt.Registrations[userID]; ok && reg.Status == event.RegistrationStatusAwaitingPartner {
+		inviteText, inviteKeyboard := h.formatter.FormatTeamInviteLink(evt, userID, h.client.Username())
+		if err := h.client.SendMessageWithKeyboard(chatID, inviteText, inviteKeyboard); err != nil {
+			h.logger.Error("failed to send team invite link", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	// Мест нет — пользователь встал в лист ожидания, оплата пока не нужна
+	if reg, ok := evt.Registrations[userID]; ok && reg.Status == event.RegistrationStatusWaitlisted {
+		waitlistText := fmt.Sprintf("🕒 Все места заняты, вы добавлены в лист ожидания.\n\n📍 Ваша позиция в очереди: %d\n\nКак только освободится место, мы пришлём вам сообщение.", reg.WaitlistPosition)
+		if err := h.client.SendMessage(chatID, waitlistText); err != nil {
+			h.logger.Error("failed to send waitlist message", "chat_id", chatID, "error", err)
+		}
+		return
+	}
+
+	// Отправляем сообщение с инструкцией по оплате или о списании занятия с абонемента
+	if paidPass != nil {
+		if err := h.client.SendMessage(chatID, passChargedText(evt, paidPass)); err != nil {
+			h.logger.Error("failed to send pass charged message", "chat_id", chatID, "error", err)
+		}
+	} else {
+		h.sendPaymentInstruction(ctx, chatID, userID, evt)
+	}
 
 	// Уведомляем каналы о новой регистрации
 	h.publishRegistrationToChannels(ctx, evt, userID)
 }
 
+// viewerLevel возвращает уровень игрока или LevelUnset, если игрок не найден
+func (h *Handlers) viewerLevel(ctx context.Context, userID int64) user.Level {
+	usr, err := h.userService.GetByTelegramID(ctx, userID)
+	if err != nil || usr == nil {
+		return user.LevelUnset
+	}
+	return usr.Level
+}
+
+// cancellationPolicy возвращает правило отмены записи на событие; при ошибке чтения настроек - правило самого события
+func (h *Handlers) cancellationPolicy(ctx context.Context, evt *event.Event) event.CancellationPolicy {
+	policy, err := h.eventService.CancellationPolicy(ctx, evt)
+	if err != nil {
+		h.logger.Warn("failed to get cancellation policy", "event_id", string(evt.ID), "error", err)
+	}
+	return policy
+}
+
+// handleLevelRequest отправляет администраторам запрос игрока на допуск к событию другого уровня
+func (h *Handlers) handleLevelRequest(ctx context.Context, cb *CallbackQuery) {
+	// Парсим ID из callback data (формат: event:level_request:{id})
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "event:level_request:"))
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	usr, err := h.userService.GetByTelegramID(ctx, cb.From.ID)
+	if err != nil || usr == nil {
+		// Незнакомый игрок сначала проходит обычную регистрацию
+		h.setUserRegistrationState(cb.From.ID, &UserRegistrationState{EventID: eventID, Step: "name"})
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "📝 Для регистрации на событие необходимо указать ваши данные.\n\nВведите ваше имя:"); sendErr != nil {
+			h.logger.Error("failed to send name prompt", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	// Запрос получают все, кто может допустить игрока к этому событию
+	adminIDs, err := h.roleService.Holders(ctx, role.PermManageEvents, role.ForEvent(evt))
+	if err != nil {
+		h.logger.Error("failed to list event moderators", "event_id", string(evt.ID), "error", err)
+	}
+
+	text, keyboard := h.formatter.FormatLevelRequest(evt, usr)
+	sent := 0
+	for _, adminID := range adminIDs {
+		if err := h.client.SendMessageWithKeyboard(adminID, text, keyboard); err != nil {
+			h.logger.Error("failed to send level request to admin", "admin_id", adminID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	reply := "📨 Запрос отправлен администратору. Мы сообщим, когда вас запишут."
+	if sent == 0 {
+		reply = "❌ Не удалось связаться с администратором. Попробуйте позже."
+	}
+	if err := h.client.SendMessage(cb.Message.ChatID, reply); err != nil {
+		h.logger.Error("failed to send level request confirmation", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
 // publishRegistrationToChannels отправляет уведомление о регистрации во все каналы
 func (h *Handlers) publishRegistrationToChannels(ctx context.Context, evt *event.Event, userID int64) {
 	channelIDs, err := h.settingsService.GetChannelIDs(ctx)
@@ -344,6 +527,82 @@ func (h *Handlers) publishRegistrationToChannels(ctx context.Context, evt *event
 	}
 }
 
+// notifyWaitlistPromoted уведомляет игроков, переведенных из листа ожидания, и отправляет им инструкцию по оплате
+func (h *Handlers) notifyWaitlistPromoted(ctx context.Context, eventID event.EventID, promoted []event.EventRegistration) {
+	if len(promoted) == 0 {
+		return
+	}
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		h.logger.Error("failed to get event for waitlist promotion", "event_id", string(eventID), "error", err)
+		return
+	}
+
+	for _, reg := range promoted {
+		text := fmt.Sprintf("🎉 Освободилось место!\n\n📅 %s\n🗓️ %s\n\nВы переведены из листа ожидания в список записавшихся.",
+			evt.Name, evt.Date.Format("02.01.2006 15:04"))
+		if err := h.client.SendMessage(reg.UserID, text); err != nil {
+			h.logger.Error("failed to notify promoted user", "user_id", reg.UserID, "event_id", string(eventID), "error", err)
+			continue
+		}
+		if p := h.payWithPass(ctx, evt, reg.UserID); p != nil {
+			if err := h.client.SendMessage(reg.UserID, passChargedText(evt, p)); err != nil {
+				h.logger.Error("failed to send pass charged message", "user_id", reg.UserID, "error", err)
+			}
+			continue
+		}
+		h.sendPaymentInstruction(ctx, reg.UserID, reg.UserID, evt)
+	}
+}
+
+// notifyEventCancelled лично уведомляет всех записавшихся игроков об отмене события и публикует отмену в канал
+func (h *Handlers) notifyEventCancelled(ctx context.Context, evt *event.Event) {
+	text, keyboard := h.formatter.FormatEventCancelledForUser(evt)
+	for userID, reg := range evt.Registrations {
+		if !reg.Status.Active() {
+			continue
+		}
+		if err := h.client.SendMessageWithKeyboard(userID, text, keyboard); err != nil {
+			h.logger.Error("failed to notify user about event cancellation", "user_id", userID, "event_id", string(evt.ID), "error", err)
+		}
+		// Событие отменил клуб - занятие возвращается на абонемент независимо от срока
+		if p := h.returnPass(ctx, evt, userID); p != nil {
+			h.notifyPassReturned(evt, userID, p)
+		}
+		h.releasePromo(ctx, evt.ID, userID)
+	}
+
+	h.publishEventCancelledToChannel(ctx, evt)
+}
+
+// notifyEventChanged лично уведомляет всех записавшихся игроков об изменении даты, времени или места события
+func (h *Handlers) notifyEventChanged(ctx context.Context, evt *event.Event, changes []string) {
+	text, keyboard := h.formatter.FormatEventChangedForUser(evt, changes)
+	for userID, reg := range evt.Registrations {
+		if !reg.Status.Active() {
+			continue
+		}
+		if err := h.client.SendMessageWithKeyboard(userID, text, keyboard); err != nil {
+			h.logger.Error("failed to notify user about event change", "user_id", userID, "event_id", string(evt.ID), "error", err)
+		}
+	}
+}
+
+// paymentPhone возвращает телефон для оплаты события
+func (h *Handlers) paymentPhone(evt *event.Event) string {
+	if evt.PaymentPhone != "" {
+		return evt.PaymentPhone
+	}
+	// Fallback на переменную окружения, если не указан в событии
+	phoneNumber := os.Getenv("PAYMENT_PHONE")
+	if phoneNumber == "" {
+		phoneNumber = "+79991234567" // Дефолтный номер, если не указан
+		h.logger.Warn("PAYMENT_PHONE not set in event or env, using default", "default_phone", phoneNumber)
+	}
+	return phoneNumber
+}
+
 // sendPaymentInstruction отправляет сообщение с инструкцией по оплате
 func (h *Handlers) sendPaymentInstruction(ctx context.Context, chatID int64, userID int64, evt *event.Event) {
 	// Получаем данные пользователя
@@ -358,17 +617,21 @@ func (h *Handlers) sendPaymentInstruction(ctx context.Context, chatID int64, use
 		return
 	}
 
-	// Получаем номер телефона и стоимость из события
-	phoneNumber := evt.PaymentPhone
-	if phoneNumber == "" {
-		// Fallback на переменную окружения, если не указан в событии
-		phoneNumber = os.Getenv("PAYMENT_PHONE")
-		if phoneNumber == "" {
-			phoneNumber = "+79991234567" // Дефолтный номер, если не указан
-			h.logger.Warn("PAYMENT_PHONE not set in event or env, using default", "default_phone", phoneNumber)
+	// Онлайн-оплата: вместо реквизитов выставляем счет, регистрация подтвердится после оплаты
+	if invoiceAvailable(evt) {
+		err := h.sendInvoice(chatID, userID, evt)
+		if err == nil {
+			h.sendPromoOffer(chatID, userID, evt)
+			return
 		}
+		h.logger.Error("failed to send invoice, falling back to transfer", "chat_id", chatID, "event_id", string(evt.ID), "error", err)
+	} else if evt.OnlinePayment() {
+		h.logger.Warn("PAYMENT_PROVIDER_TOKEN not set, falling back to transfer", "event_id", string(evt.ID))
 	}
 
+	// Получаем номер телефона и стоимость из события
+	phoneNumber := h.paymentPhone(evt)
+
 	// Формируем ФИО пользователя
 	userFullName := usr.Name
 	if usr.Surname != "" {
@@ -385,7 +648,23 @@ func (h *Handlers) sendPaymentInstruction(ctx context.Context, chatID int64, use
 	// Формируем сообщение с инструкцией
 	var priceText string
 	if evt.Price > 0 {
-		priceText = fmt.Sprintf("\n💰 Сумма к оплате: <code>%d руб.</code>", evt.Price)
+		priceText = fmt.Sprintf("\n💰 Сумма к оплате: <code>%s</code>", formatAmount(evt.EffectivePaymentMode(), evt.PriceFor(userID)))
+		if discount := evt.Registrations[userID].Discount; discount > 0 {
+			priceText += fmt.Sprintf(" (с учетом скидки %s)", formatAmount(evt.EffectivePaymentMode(), discount))
+		}
+	}
+
+	// Время брони: из события или глобальная настройка
+	timeout := evt.PendingTimeout
+	if timeout <= 0 {
+		timeout, err = h.settingsService.GetPendingTimeout(ctx)
+		if err != nil {
+			h.logger.Warn("failed to get pending timeout", "error", err)
+		}
+	}
+	var holdText string
+	if timeout > 0 {
+		holdText = fmt.Sprintf("⚠️ <b>Внимание!</b> Бронь будет автоматически снята через %s, если не будет подтверждения оплаты.\n\n", formatMinutes(timeout))
 	}
 
 	message := fmt.Sprintf(
@@ -395,20 +674,45 @@ func (h *Handlers) sendPaymentInstruction(ctx context.Context, chatID int64, use
 			"📝 В сообщении к переводу укажите:\n"+
 			"<code>%s</code>\n\n"+
 			"💡 Нажмите на текст выше, чтобы скопировать\n\n"+
-			"⚠️ <b>Внимание!</b> Бронь будет автоматически снята через 30 минут, если не будет подтверждения оплаты.\n\n"+
+			"%s"+
 			"⏳ После оплаты администратор подтвердит вашу регистрацию.",
 		phoneNumber,
 		priceText,
 		paymentMessage,
+		holdText,
 	)
 
+	var rows [][]InlineKeyboardButton
+	if promoOffered(evt, userID) {
+		rows = append(rows, NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🏷 Ввести промокод", fmt.Sprintf("event:promo:%s", string(evt.ID))),
+		))
+	}
+	rows = append(rows, NewInlineKeyboardRow(
+		NewInlineKeyboardButtonData("🏠 Главное меню", "back:main"),
+	))
+	if err := h.client.SendMessageWithKeyboard(chatID, message, NewInlineKeyboardMarkup(rows...)); err != nil {
+		h.logger.Error("failed to send payment instruction", "chat_id", chatID, "error", err)
+	}
+}
+
+// promoOffered сообщает, что игроку стоит предложить ввести промокод: событие платное и скидка еще не применена
+func promoOffered(evt *event.Event, userID int64) bool {
+	return evt.Price > 0 && evt.Registrations[userID].Discount == 0
+}
+
+// sendPromoOffer предлагает ввести промокод вместе со счетом на оплату: после скидки игроку придет новый счет
+func (h *Handlers) sendPromoOffer(chatID int64, userID int64, evt *event.Event) {
+	if !promoOffered(evt, userID) {
+		return
+	}
 	keyboard := NewInlineKeyboardMarkup(
 		NewInlineKeyboardRow(
-			NewInlineKeyboardButtonData("🏠 Главное меню", "back:main"),
+			NewInlineKeyboardButtonData("🏷 Ввести промокод", fmt.Sprintf("event:promo:%s", string(evt.ID))),
 		),
 	)
-	if err := h.client.SendMessageWithKeyboard(chatID, message, keyboard); err != nil {
-		h.logger.Error("failed to send payment instruction", "chat_id", chatID, "error", err)
+	if err := h.client.SendMessageWithKeyboard(chatID, "🏷 Есть промокод? Введите его до оплаты, и счет будет пересчитан со скидкой.", keyboard); err != nil {
+		h.logger.Error("failed to send promo code offer", "chat_id", chatID, "error", err)
 	}
 }
 
@@ -457,11 +761,41 @@ func (h *Handlers) handleUserRegistrationStep(ctx context.Context, msg *Message,
 			h.logger.Error("failed to send confirmation", "chat_id", msg.ChatID, "error", err)
 		}
 
+		// Игрок пришел по приглашению в команду - принимаем его, а не создаем новую команду
+		if state.TeamCaptainID != 0 {
+			h.acceptTeamInvite(ctx, state.EventID, state.TeamCaptainID, msg.From.ID, msg.ChatID, 0)
+			return
+		}
+
 		// Регистрируем на событие (messageID = 0, так как это новое сообщение)
 		h.registerUserToEvent(ctx, state.EventID, msg.From.ID, msg.ChatID, 0)
 	}
 }
 
+// handleEventUnregisterAsk показывает подтверждение отмены записи с последствиями (формат: event:unreg_ask:{id})
+func (h *Handlers) handleEventUnregisterAsk(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "event:unreg_ask:"))
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if reg, ok := evt.Registrations[cb.From.ID]; !ok || !reg.Status.Active() {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "⚠️ Вы не зарегистрированы на это событие"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatUnregisterConfirm(evt, cb.From.ID, h.cancellationPolicy(ctx, evt), time.Now())
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with unregister confirmation", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
 // handleEventUnregister обрабатывает отмену регистрации пользователя на событие
 func (h *Handlers) handleEventUnregister(ctx context.Context, cb *CallbackQuery) {
 	// Парсим ID из callback data (формат: event:unregister:{id})
@@ -478,14 +812,30 @@ func (h *Handlers) handleEventUnregister(ctx context.Context, cb *CallbackQuery)
 	eventID := event.EventID(eventIDStr)
 	userID := cb.From.ID
 
+	// Запоминаем напарника: регистрация отменяется для всей команды, его нужно предупредить
+	var partnerID int64
+	before, err := h.eventService.Get(ctx, eventID)
+	if err == nil && before != nil {
+		partnerID = before.Partner(userID)
+	}
+
 	// Отменяем регистрацию
-	err := h.eventService.UnregisterUser(ctx, eventID, userID)
+	var promoted []event.EventRegistration
+	var late bool
+	err = retryOnConflict(func() (err error) {
+		promoted, late, err = h.eventService.UnregisterUser(ctx, eventID, userID)
+		return err
+	})
 	if err != nil {
 		h.logger.Error("failed to unregister user from event", "event_id", eventIDStr, "user_id", userID, "chat_id", cb.Message.ChatID, "error", err)
 
 		errorMsg := "❌ Ошибка отмены регистрации"
 		if err == event.ErrRegistrationNotFound {
 			errorMsg = "⚠️ Вы не зарегистрированы на это событие"
+		} else if errors.Is(err, event.ErrLateCancelBlocked) {
+			errorMsg = "⛔ Срок отмены записи прошел. Чтобы отменить участие, свяжитесь с администратором."
+		} else if errors.Is(err, event.ErrConflict) {
+			errorMsg = conflictMessage
 		}
 
 		if sendErr := h.client.SendMessage(cb.Message.ChatID, errorMsg); sendErr != nil {
@@ -494,8 +844,31 @@ func (h *Handlers) handleEventUnregister(ctx context.Context, cb *CallbackQuery)
 		return
 	}
 
+	// Занятия с абонементов и промокоды возвращаются только при своевременной отмене
+	if before != nil {
+		for _, memberID := range before.TeamMembers(userID) {
+			h.returnPassOnCancel(ctx, before, memberID, late)
+			if !late {
+				h.releasePromo(ctx, eventID, memberID)
+			}
+		}
+	}
+	if late {
+		h.logger.Info("late cancellation", "event_id", eventIDStr, "user_id", userID)
+	}
+
+	// Освободившееся место получил первый из листа ожидания
+	h.notifyWaitlistPromoted(ctx, eventID, promoted)
+
 	// Получаем обновленное событие для отображения
 	evt, err := h.eventService.Get(ctx, eventID)
+	if partnerID != 0 && evt != nil {
+		text := fmt.Sprintf("❌ Напарник отменил запись команды\n\n📅 %s\n🗓️ %s\n\nВаша регистрация тоже отменена. Вы можете записаться снова с другим напарником.",
+			evt.Name, evt.Date.Format("02.01.2006 15:04"))
+		if err := h.client.SendMessage(partnerID, text); err != nil {
+			h.logger.Error("failed to notify partner about unregistration", "user_id", partnerID, "event_id", eventIDStr, "error", err)
+		}
+	}
 	if err != nil || evt == nil {
 		if sendErr := h.client.SendMessage(cb.Message.ChatID, "✅ Регистрация отменена"); sendErr != nil {
 			h.logger.Error("failed to send success message", "chat_id", cb.Message.ChatID, "error", sendErr)
@@ -503,12 +876,219 @@ func (h *Handlers) handleEventUnregister(ctx context.Context, cb *CallbackQuery)
 		return
 	}
 
-	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, userID)
+	text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, userID, h.viewerLevel(ctx, userID), h.cancellationPolicy(ctx, evt))
 	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 		h.logger.Error("failed to edit message with event details", "chat_id", cb.Message.ChatID, "error", err)
 	}
 }
 
+// handleTeamInviteLink повторно отправляет капитану ссылку-приглашение для напарника
+func (h *Handlers) handleTeamInviteLink(ctx context.Context, cb *CallbackQuery) {
+	// Парсим ID из callback data (формат: event:invite:{id})
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "event:invite:"))
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if reg, ok := evt.Registrations[cb.From.ID]; !ok || reg.Status != event.RegistrationStatusAwaitingPartner {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "⚠️ Команда уже собрана или запись отменена"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatTeamInviteLink(evt, cb.From.ID, h.client.Username())
+	if err := h.client.SendMessageWithKeyboard(cb.Message.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send team invite link", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleTeamInvite показывает приглашение в команду по ссылке /start team_<eventID>_<captainID>
+func (h *Handlers) handleTeamInvite(ctx context.Context, msg *Message, eventID event.EventID, captainID int64) {
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil || evt.Status == event.StatusDraft {
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	// Капитан открыл свою же ссылку или приглашение уже неактуально - показываем событие
+	captain, ok := evt.Registrations[captainID]
+	if captainID == msg.From.ID || !ok || captain.Status != event.RegistrationStatusAwaitingPartner {
+		text, keyboard := h.formatter.FormatEventDetailsForUsers(evt, msg.From.ID, h.viewerLevel(ctx, msg.From.ID), h.cancellationPolicy(ctx, evt))
+		if captainID != msg.From.ID {
+			text = "⚠️ Приглашение уже неактуально: команда собрана или запись отменена\n\n" + text
+		}
+		if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send event details via team link", "chat_id", msg.ChatID, "error", err)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatTeamInvite(evt, captainID, h.playerName(ctx, captainID))
+	if err := h.client.SendMessageWithKeyboard(msg.ChatID, text, keyboard); err != nil {
+		h.logger.Error("failed to send team invite", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
+// parseTeamInviteCallback разбирает callback приглашения (формат: event:tm_ok:{eventID}:{captainID})
+func parseTeamInviteCallback(data string) (event.EventID, int64, bool) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 4 {
+		return "", 0, false
+	}
+	var captainID int64
+	if _, err := fmt.Sscanf(parts[3], "%d", &captainID); err != nil {
+		return "", 0, false
+	}
+	return event.EventID(parts[2]), captainID, true
+}
+
+// handleTeamInviteAccept обрабатывает согласие напарника вступить в команду
+func (h *Handlers) handleTeamInviteAccept(ctx context.Context, cb *CallbackQuery) {
+	eventID, captainID, ok := parseTeamInviteCallback(cb.Data)
+	if !ok {
+		h.logger.Warn("invalid team invite callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	exists, err := h.userService.IsUserExists(ctx, cb.From.ID)
+	if err != nil {
+		h.logger.Error("failed to check user existence", "user_id", cb.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка проверки данных"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if !exists {
+		// Незнакомый игрок сначала указывает имя, затем приглашение принимается автоматически
+		h.setUserRegistrationState(cb.From.ID, &UserRegistrationState{EventID: eventID, Step: "name", TeamCaptainID: captainID})
+		if err := h.client.SendMessage(cb.Message.ChatID, "📝 Для регистрации на событие необходимо указать ваши данные.\n\nВведите ваше имя:"); err != nil {
+			h.logger.Error("failed to send name prompt", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	h.acceptTeamInvite(ctx, eventID, captainID, cb.From.ID, cb.Message.ChatID, cb.Message.MessageID)
+}
+
+// acceptTeamInvite добавляет игрока в команду капитана и уведомляет обоих
+func (h *Handlers) acceptTeamInvite(ctx context.Context, eventID event.EventID, captainID, partnerID int64, chatID int64, messageID int) {
+	err := retryOnConflict(func() error {
+		return h.eventService.AcceptTeamInvite(ctx, eventID, captainID, partnerID)
+	})
+	if err != nil {
+		h.logger.Error("failed to accept team invite", "event_id", string(eventID), "captain_id", captainID, "user_id", partnerID, "error", err)
+
+		errorMsg := "❌ Ошибка вступления в команду"
+		switch {
+		case errors.Is(err, event.ErrTeamInviteNotFound):
+			errorMsg = "⚠️ Приглашение уже неактуально: команда собрана или запись отменена"
+		case errors.Is(err, event.ErrCannotPartnerSelf):
+			errorMsg = "⚠️ Нельзя принять собственное приглашение — отправьте ссылку напарнику"
+		case errors.Is(err, event.ErrUserAlreadyRegistered):
+			errorMsg = "⚠️ Вы уже записаны на это событие"
+		case errors.Is(err, event.ErrEventNotOpen):
+			errorMsg = "❌ Запись на это событие закрыта"
+		case errors.Is(err, event.ErrLevelNotAllowed):
+			errorMsg = "🔒 Ваш уровень не подходит для этого события"
+		case errors.Is(err, event.ErrConflict):
+			errorMsg = conflictMessage
+		}
+		if blocked := (*event.NoShowBlockError)(nil); errors.As(err, &blocked) {
+			errorMsg = fmt.Sprintf("❌ Из-за неявок самостоятельная запись недоступна до %s. Обратитесь к администратору.", blocked.Until.Format("02.01.2006 15:04"))
+		}
+		if restricted := (*event.RestrictedError)(nil); errors.As(err, &restricted) {
+			errorMsg = h.formatter.FormatRestrictionBlock(&restricted.Restriction)
+		}
+		if sendErr := h.client.SendMessage(chatID, errorMsg); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", chatID, "error", sendErr)
+		}
+		return
+	}
+
+	h.sendRegistrationResult(ctx, eventID, partnerID, chatID, messageID)
+
+	// Капитану сообщаем, что команда собрана, и отправляем ему инструкцию по оплате
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		return
+	}
+	text := fmt.Sprintf("🤝 %s принял приглашение в команду\n\n📅 %s\n🗓️ %s", h.playerName(ctx, partnerID), evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	reg := evt.Registrations[captainID]
+	if reg.Status == event.RegistrationStatusWaitlisted {
+		text += fmt.Sprintf("\n\n🕒 Свободных мест нет, команда в листе ожидания. Позиция: %d", reg.WaitlistPosition)
+	}
+	if err := h.client.SendMessage(captainID, text); err != nil {
+		h.logger.Error("failed to notify captain about accepted invite", "user_id", captainID, "event_id", string(eventID), "error", err)
+		return
+	}
+	if reg.Status == event.RegistrationStatusPending {
+		h.sendPaymentInstruction(ctx, captainID, captainID, evt)
+	}
+}
+
+// handleTeamInviteDecline обрабатывает отказ от приглашения в команду
+func (h *Handlers) handleTeamInviteDecline(ctx context.Context, cb *CallbackQuery) {
+	eventID, captainID, ok := parseTeamInviteCallback(cb.Data)
+	if !ok {
+		h.logger.Warn("invalid team invite callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+
+	if err := h.client.EditMessageText(cb.Message.ChatID, cb.Message.MessageID, "❌ Вы отказались от приглашения в команду"); err != nil {
+		h.logger.Error("failed to edit team invite message", "chat_id", cb.Message.ChatID, "error", err)
+	}
+
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil {
+		return
+	}
+	text := fmt.Sprintf("😔 %s отказался от приглашения в команду\n\n📅 %s\n🗓️ %s\n\nПригласите другого напарника по той же ссылке.",
+		h.playerName(ctx, cb.From.ID), evt.Name, evt.Date.Format("02.01.2006 15:04"))
+	keyboard := NewInlineKeyboardMarkup(
+		NewInlineKeyboardRow(
+			NewInlineKeyboardButtonData("🔗 Ссылка для напарника", fmt.Sprintf("event:invite:%s", string(eventID))),
+		),
+	)
+	if err := h.client.SendMessageWithKeyboard(captainID, text, keyboard); err != nil {
+		h.logger.Error("failed to notify captain about declined invite", "user_id", captainID, "event_id", string(eventID), "error", err)
+	}
+}
+
+// playerName возвращает имя и фамилию игрока (или Telegram ID, если игрок не найден)
+func (h *Handlers) playerName(ctx context.Context, telegramID int64) string {
+	usr, err := h.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil || usr == nil {
+		return fmt.Sprintf("Игрок %d", telegramID)
+	}
+	if usr.Surname != "" {
+		return fmt.Sprintf("%s %s", usr.Name, usr.Surname)
+	}
+	return usr.Name
+}
+
+// handleRating показывает игроку его рейтинг и динамику за последние матчи
+func (h *Handlers) handleRating(ctx context.Context, msg *Message) {
+	summary, err := h.ratingService.Get(ctx, msg.From.ID)
+	if err != nil {
+		h.logger.Error("failed to get rating", "user_id", msg.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(msg.ChatID, "❌ Ошибка получения рейтинга"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", msg.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.SendMessage(msg.ChatID, h.formatter.FormatRating(summary, h.playerName(ctx, msg.From.ID))); err != nil {
+		h.logger.Error("failed to send rating", "chat_id", msg.ChatID, "error", err)
+	}
+}
+
 // handleEventUsersList обрабатывает запрос списка участников события
 func (h *Handlers) handleEventUsersList(ctx context.Context, cb *CallbackQuery) {
 	// Парсим ID из callback data (формат: event:users:{id})
@@ -541,9 +1121,29 @@ func (h *Handlers) handleEventUsersList(ctx context.Context, cb *CallbackQuery)
 		return
 	}
 
+	// Состояние оплат видят те, кто ведет журнал оплат события, карточки игроков - кто управляет игроками
+	canManagePayments := h.can(ctx, cb.From.ID, role.PermManagePayments, role.ForEvent(evt))
+	canManagePlayers := h.can(ctx, cb.From.ID, role.PermManagePlayers, role.Global)
+	var balances map[int64]payment.Balance
+	var guestBalances map[event.GuestID]payment.Balance
+	if evt.Price > 0 && canManagePayments {
+		balances, err = h.paymentService.Balances(ctx, evt)
+		if err != nil {
+			h.logger.Error("failed to get payment balances", "event_id", eventIDStr, "chat_id", cb.Message.ChatID, "error", err)
+		}
+		guestBalances, err = h.paymentService.GuestBalances(ctx, evt)
+		if err != nil {
+			h.logger.Error("failed to get guest payment balances", "event_id", eventIDStr, "chat_id", cb.Message.ChatID, "error", err)
+		}
+	}
+
 	// Собираем список пользователей с их статусами
 	var usersWithStatus []UserWithStatus
 	for telegramID, reg := range evt.Registrations {
+		// В парной категории команда выводится одной строкой у капитана
+		if !evt.TeamHead(reg) && evt.Partner(telegramID) != 0 {
+			continue
+		}
 		usr, err := h.userService.GetByTelegramID(ctx, telegramID)
 		if err != nil {
 			h.logger.Warn("failed to get user", "telegram_id", telegramID, "error", err)
@@ -551,16 +1151,142 @@ func (h *Handlers) handleEventUsersList(ctx context.Context, cb *CallbackQuery)
 			continue
 		}
 		if usr != nil {
-			usersWithStatus = append(usersWithStatus, UserWithStatus{
-				User:   usr,
-				Status: reg.Status,
-			})
+			var partnerName string
+			if partnerID := evt.Partner(telegramID); partnerID != 0 {
+				partnerName = h.playerName(ctx, partnerID)
+			}
+			item := UserWithStatus{
+				User:             usr,
+				Status:           reg.Status,
+				WaitlistPosition: reg.WaitlistPosition,
+				PartnerName:      partnerName,
+			}
+			if b, ok := balances[telegramID]; ok {
+				item.Payment = formatBalance(evt, b)
+			}
+			item.Guests = evt.GuestsOf(telegramID)
+			for _, guest := range item.Guests {
+				if b, ok := guestBalances[guest.ID]; ok {
+					if item.GuestPayments == nil {
+						item.GuestPayments = make(map[event.GuestID]string)
+					}
+					item.GuestPayments[guest.ID] = formatBalance(evt, b)
+				}
+			}
+			usersWithStatus = append(usersWithStatus, item)
 		}
 	}
 
 	// Форматируем и отправляем список
-	text, keyboard := h.formatter.FormatEventUsersList(evt.Name, usersWithStatus, string(eventID))
+	text, keyboard := h.formatter.FormatEventUsersList(evt.Name, usersWithStatus, string(eventID), canManagePlayers)
 	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
 		h.logger.Error("failed to edit message with users list", "chat_id", cb.Message.ChatID, "error", err)
 	}
 }
+
+// handleReminderSettings показывает настройки напоминаний пользователя
+func (h *Handlers) handleReminderSettings(ctx context.Context, cb *CallbackQuery) {
+	text, keyboard, err := h.reminderSettingsView(ctx, cb.From.ID)
+	if err != nil {
+		h.logger.Error("failed to load reminder settings", "user_id", cb.From.ID, "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения настроек напоминаний"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if err := h.client.EditMessageTextAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with reminder settings", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
+
+// handleReminderToggle включает/отключает тип напоминаний.
+// Формат: reminders:toggle:{kind} (из настроек) или reminders:off:{kind} (из самого напоминания)
+func (h *Handlers) handleReminderToggle(ctx context.Context, cb *CallbackQuery) {
+	parts := strings.Split(cb.Data, ":")
+	if len(parts) != 3 {
+		h.logger.Warn("invalid reminder callback data format", "callback_data", cb.Data, "chat_id", cb.Message.ChatID)
+		return
+	}
+	kind := reminder.Kind(parts[2])
+
+	optOut := true
+	if parts[1] == "toggle" {
+		optOuts, err := h.reminderService.OptOuts(ctx, cb.From.ID)
+		if err != nil {
+			h.logger.Error("failed to get reminder opt-outs", "user_id", cb.From.ID, "error", err)
+			return
+		}
+		optOut = !optOuts[kind]
+	}
+
+	if err := h.reminderService.SetOptOut(ctx, cb.From.ID, kind, optOut); err != nil {
+		h.logger.Error("failed to set reminder opt-out", "user_id", cb.From.ID, "kind", string(kind), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка сохранения настройки"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	if parts[1] == "off" {
+		text := fmt.Sprintf("🔕 Напоминания за %s до начала отключены.", formatReminderKind(kind))
+		keyboard := NewInlineKeyboardMarkup(
+			NewInlineKeyboardRow(
+				NewInlineKeyboardButtonData("🔔 Настроить напоминания", "reminders"),
+			),
+		)
+		if err := h.client.SendMessageWithKeyboard(cb.Message.ChatID, text, keyboard); err != nil {
+			h.logger.Error("failed to send reminder opt-out confirmation", "chat_id", cb.Message.ChatID, "error", err)
+		}
+		return
+	}
+
+	h.handleReminderSettings(ctx, cb)
+}
+
+// reminderSettingsView собирает экран настроек напоминаний для пользователя
+func (h *Handlers) reminderSettingsView(ctx context.Context, userID int64) (string, *InlineKeyboardMarkup, error) {
+	offsets, err := h.settingsService.GetEventReminderOffsets(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	optOuts, err := h.reminderService.OptOuts(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	text, keyboard := h.formatter.FormatReminderSettings(offsets, optOuts)
+	return text, keyboard, nil
+}
+
+// handleTournamentBracket показывает игроку сетку и таблицы турнира (формат: event:bracket:{id})
+func (h *Handlers) handleTournamentBracket(ctx context.Context, cb *CallbackQuery) {
+	eventID := event.EventID(strings.TrimPrefix(cb.Data, "event:bracket:"))
+	evt, err := h.eventService.Get(ctx, eventID)
+	if err != nil || evt == nil || evt.Status == event.StatusDraft {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Событие не найдено"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	t, err := h.tournamentService.GetByEvent(ctx, eventID)
+	if err != nil {
+		h.logger.Error("failed to get tournament", "event_id", string(eventID), "error", err)
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "❌ Ошибка получения турнира"); sendErr != nil {
+			h.logger.Error("failed to send error message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+	if t == nil {
+		if sendErr := h.client.SendMessage(cb.Message.ChatID, "🏆 Сетка турнира еще не сформирована"); sendErr != nil {
+			h.logger.Error("failed to send message", "chat_id", cb.Message.ChatID, "error", sendErr)
+		}
+		return
+	}
+
+	text, keyboard := h.formatter.FormatTournamentForUsers(evt, t)
+	if err := h.client.EditMessageHTMLAndMarkup(cb.Message.ChatID, cb.Message.MessageID, text, keyboard); err != nil {
+		h.logger.Error("failed to edit message with tournament", "chat_id", cb.Message.ChatID, "error", err)
+	}
+}
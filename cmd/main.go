@@ -3,15 +3,29 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"pickletlgbot/api/telegram"
 	"pickletlgbot/internal/domain/event"
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/pass"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/domain/promo"
+	"pickletlgbot/internal/domain/rating"
+	"pickletlgbot/internal/domain/reminder"
+	"pickletlgbot/internal/domain/restriction"
+	"pickletlgbot/internal/domain/role"
+	"pickletlgbot/internal/domain/series"
 	"pickletlgbot/internal/domain/settings"
+	"pickletlgbot/internal/domain/subscription"
+	"pickletlgbot/internal/domain/tournament"
 	"pickletlgbot/internal/domain/user"
 	"pickletlgbot/internal/models"
+	"pickletlgbot/internal/scheduler"
 	"pickletlgbot/repositories/postgres"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -34,7 +48,12 @@ func main() {
 	}
 
 	// Инициализация Telegram Bot API
-	tgBot, err := tgbotapi.NewBotAPI(token)
+	// TELEGRAM_API_ENDPOINT позволяет направить бота на локальный Bot API (например, тестовую заглушку для платежей)
+	endpoint := tgbotapi.APIEndpoint
+	if custom := os.Getenv("TELEGRAM_API_ENDPOINT"); custom != "" {
+		endpoint = custom
+	}
+	tgBot, err := tgbotapi.NewBotAPIWithAPIEndpoint(token, endpoint)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -55,9 +74,10 @@ func main() {
 	// Автомиграция моделей (порядок важен: сначала таблицы без foreign keys, потом с foreign keys)
 	// Этап 1: Создаем таблицы без foreign keys
 	if err := db.AutoMigrate(
-		&models.UserGORM{},     // 1. user (нет зависимостей)
-		&models.LocationGORM{}, // 2. locations (нет зависимостей)
-		&models.EventGORM{},    // 3. events (зависит от locations, но через строку - не foreign key)
+		&models.UserGORM{},        // 1. user (нет зависимостей)
+		&models.LocationGORM{},    // 2. locations (нет зависимостей)
+		&models.EventGORM{},       // 3. events (зависит от locations, но через строку - не foreign key)
+		&models.EventSeriesGORM{}, // 4. event_series (шаблоны повторяющихся событий)
 	); err != nil {
 		log.Fatalf("❌ Ошибка миграции (этап 1): %v", err)
 	}
@@ -69,8 +89,25 @@ func main() {
 	}
 
 	if err := db.AutoMigrate(
-		&models.EventRegistrationGORM{}, // 4. event_registrations (зависит от user и events)
-		&models.SettingsGORM{},          // 5. settings (нет зависимостей)
+		&models.EventRegistrationGORM{},     // 5. event_registrations (зависит от user и events)
+		&models.SettingsGORM{},              // 6. settings (нет зависимостей)
+		&models.EventReminderGORM{},         // 7. event_reminders (отправленные напоминания)
+		&models.ReminderOptOutGORM{},        // 8. reminder_opt_outs (отказы от напоминаний)
+		&models.EventAnnouncementGORM{},     // 9. event_announcements (анонсы событий в каналах)
+		&models.TournamentGORM{},            // 10. tournaments (турниры соревнований)
+		&models.TournamentParticipantGORM{}, // 11. tournament_participants (участники турниров)
+		&models.TournamentMatchGORM{},       // 12. tournament_matches (матчи турниров)
+		&models.RatingHistoryGORM{},         // 13. rating_histories (история рейтинга игроков)
+		&models.CourtGORM{},                 // 14. courts (корты локаций)
+		&models.RoleGrantGORM{},             // 15. role_grants (роли сотрудников)
+		&models.PaymentGORM{},               // 16. payments (журнал оплат, зависит от event_registrations)
+		&models.PassGORM{},                  // 17. passes (абонементы)
+		&models.PassUsageGORM{},             // 18. pass_usages (списанные занятия, зависит от passes)
+		&models.PromoCodeGORM{},             // 19. promo_codes (промокоды)
+		&models.PromoRedemptionGORM{},       // 20. promo_redemptions (погашения промокодов, зависит от promo_codes)
+		&models.EventGuestGORM{},            // 21. event_guests (гости игроков, зависит от events)
+		&models.RestrictionGORM{},           // 22. restrictions (ограничения записи игроков)
+		&models.SubscriptionGORM{},          // 23. subscriptions (подписки игроков на новые события)
 	); err != nil {
 		log.Fatalf("❌ Ошибка миграции (этап 2): %v", err)
 	}
@@ -82,16 +119,41 @@ func main() {
 	eventRepo := postgres.NewEventRepository(db)
 	userRepo := postgres.NewUserRepository(db)
 	settingsRepo := postgres.NewSettingsRepository(db)
+	seriesRepo := postgres.NewSeriesRepository(db)
+	reminderRepo := postgres.NewReminderRepository(db)
+	tournamentRepo := postgres.NewTournamentRepository(db)
+	ratingRepo := postgres.NewRatingRepository(db)
+	roleRepo := postgres.NewRoleRepository(db)
+	paymentRepo := postgres.NewPaymentRepository(db)
+	passRepo := postgres.NewPassRepository(db)
+	promoRepo := postgres.NewPromoRepository(db)
+	restrictionRepo := postgres.NewRestrictionRepository(db)
+	subscriptionRepo := postgres.NewSubscriptionRepository(db)
 
 	// Инициализация доменных сервисов (бизнес-логика)
 	locationService := location.NewService(locationRepo)
 	userService := user.NewPlayerService(userRepo)
-	eventService := event.NewEventService(eventRepo, locationService)
 	settingsService := settings.NewService(settingsRepo)
+	restrictionService := restriction.NewService(restrictionRepo)
+	eventService := event.NewEventService(eventRepo, locationService, settingsService, settingsService, userService, restrictionService)
+	seriesService := series.NewService(seriesRepo, eventService, locationService)
+	reminderService := reminder.NewService(reminderRepo, eventService)
+	ratingService := rating.NewService(ratingRepo, tournamentRepo, eventService)
+	tournamentService := tournament.NewService(tournamentRepo, eventService, userService, ratingService)
+	roleService := role.NewService(roleRepo)
+	paymentService := payment.NewService(paymentRepo, eventService)
+	passService := pass.NewService(passRepo)
+	promoService := promo.NewService(promoRepo)
+	subscriptionService := subscription.NewService(subscriptionRepo)
+
+	// ADMIN_IDS назначаются владельцами только при первом запуске, дальше роли выдаются в боте
+	if err := roleService.Bootstrap(context.Background(), parseOwnerIDs()); err != nil {
+		log.Fatalf("❌ Ошибка назначения владельцев: %v", err)
+	}
 
 	// Инициализация API слоя (Telegram)
 	tgClient := telegram.NewClient(tgBot)
-	handlers := telegram.NewHandlers(locationService, eventService, userService, settingsService, tgClient)
+	handlers := telegram.NewHandlers(locationService, eventService, userService, settingsService, seriesService, reminderService, tournamentService, ratingService, roleService, paymentService, passService, promoService, restrictionService, subscriptionService, tgClient)
 
 	// Получаем канал обновлений
 	updates := tgClient.GetUpdatesChan()
@@ -103,6 +165,18 @@ func main() {
 	// WaitGroup для отслеживания активных горутин
 	var wg sync.WaitGroup
 
+	// Фоновые задачи
+	jobs := scheduler.New(slog.Default())
+	jobs.Add("expire_pending_registrations", time.Minute, handlers.ExpirePendingRegistrations)
+	jobs.Add("generate_series_events", time.Hour, handlers.GenerateSeriesEvents)
+	jobs.Add("send_event_reminders", time.Minute, handlers.SendEventReminders)
+	jobs.Add("complete_past_events", 10*time.Minute, handlers.CompletePastEvents)
+	jobs.Add("anonymize_deleted_users", time.Hour, handlers.AnonymizeDeletedUsers)
+	jobs.Start(ctx, &wg)
+
+	// Рассылка подписчикам о новых событиях
+	handlers.StartSubscriberNotifier(ctx, &wg)
+
 	// Канал для сигналов завершения
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -150,3 +224,24 @@ func main() {
 		}
 	}
 }
+
+// parseOwnerIDs парсит список ID владельцев бота из переменной окружения ADMIN_IDS
+func parseOwnerIDs() []int64 {
+	ownerIDsStr := os.Getenv("ADMIN_IDS")
+	if ownerIDsStr == "" {
+		return nil
+	}
+
+	var ownerIDs []int64
+	ids := strings.Split(ownerIDsStr, ",")
+	for _, idStr := range ids {
+		idStr = strings.TrimSpace(idStr)
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		ownerIDs = append(ownerIDs, id)
+	}
+
+	return ownerIDs
+}
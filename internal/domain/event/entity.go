@@ -1,10 +1,15 @@
 package event
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"time"
 
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/restriction"
+	"pickletlgbot/internal/domain/user"
 )
 
 // EventID - тип для ID события (аналогично LocationID)
@@ -14,36 +19,593 @@ type EventID string
 type RegistrationStatus string
 
 const (
-	RegistrationStatusPending  RegistrationStatus = "pending"  // Ожидает подтверждения
-	RegistrationStatusApproved RegistrationStatus = "approved" // Подтвержден
-	RegistrationStatusRejected RegistrationStatus = "rejected" // Отклонен
+	RegistrationStatusPending    RegistrationStatus = "pending"    // Ожидает подтверждения
+	RegistrationStatusApproved   RegistrationStatus = "approved"   // Подтвержден
+	RegistrationStatusRejected   RegistrationStatus = "rejected"   // Отклонен
+	RegistrationStatusWaitlisted RegistrationStatus = "waitlisted" // В листе ожидания
+	RegistrationStatusExpired    RegistrationStatus = "expired"    // Бронь снята из-за отсутствия оплаты
+
+	RegistrationStatusAwaitingPartner RegistrationStatus = "awaiting_partner" // Капитан пары ждет, пока напарник примет приглашение
+	RegistrationStatusLateCancelled   RegistrationStatus = "late_cancelled"   // Игрок отменил запись после срока бесплатной отмены
+)
+
+// HoldsSpot сообщает, занимает ли регистрация с этим статусом место на событии
+func (s RegistrationStatus) HoldsSpot() bool {
+	return s == RegistrationStatusPending || s == RegistrationStatusApproved
+}
+
+// Status - статус жизненного цикла события
+type Status string
+
+const (
+	StatusDraft     Status = "draft"     // Черновик, виден только администраторам
+	StatusPublished Status = "published" // Опубликовано, открыта запись
+	StatusCancelled Status = "cancelled" // Отменено, регистрации сохраняются для истории
+	StatusCompleted Status = "completed" // Прошло (выставляется автоматически)
+)
+
+// statusTransitions - допустимые переходы между статусами события
+var statusTransitions = map[Status][]Status{
+	StatusDraft:     {StatusPublished, StatusCancelled},
+	StatusPublished: {StatusCancelled, StatusCompleted},
+}
+
+// CanTransitionTo проверяет, допустим ли переход в статус to
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive сообщает, что событие еще не отменено и не завершено
+func (s Status) IsActive() bool {
+	return s == StatusDraft || s == StatusPublished
+}
+
+// PaymentMode - способ оплаты участия в событии
+type PaymentMode string
+
+const (
+	PaymentModeTransfer PaymentMode = "transfer" // Перевод по номеру телефона, регистрацию подтверждает администратор
+	PaymentModeInvoice  PaymentMode = "invoice"  // Счет Telegram Payments в рублях, регистрация подтверждается после оплаты
+	PaymentModeStars    PaymentMode = "stars"    // Счет в Telegram Stars (Price - количество звезд), подтверждение после оплаты
+)
+
+// Valid проверяет, что способ оплаты известен
+func (m PaymentMode) Valid() bool {
+	return m == PaymentModeTransfer || m == PaymentModeInvoice || m == PaymentModeStars
+}
+
+// Online сообщает, что участие оплачивается счетом в Telegram и подтверждается автоматически
+func (m PaymentMode) Online() bool {
+	return m == PaymentModeInvoice || m == PaymentModeStars
+}
+
+// Валюты счетов Telegram Payments
+const (
+	CurrencyRUB   = "RUB"
+	CurrencyStars = "XTR"
+)
+
+// Attendance - отметка о посещении события
+type Attendance string
+
+const (
+	AttendanceUnknown Attendance = ""        // Не отмечено
+	AttendancePresent Attendance = "present" // Пришел
+	AttendanceNoShow  Attendance = "no_show" // Не пришел
+)
+
+const (
+	CheckInOpensBefore = time.Hour     // За сколько до начала можно отметиться по ссылке
+	CheckInClosesAfter = 3 * time.Hour // Сколько после начала можно отметиться по ссылке
 )
 
 // EventRegistration - регистрация пользователя на событие
 type EventRegistration struct {
-	UserID    int64
+	UserID           int64
+	Status           RegistrationStatus
+	WaitlistPosition int        // Позиция в листе ожидания (0, если не в очереди)
+	PendingSince     time.Time  // Когда регистрация перешла в pending (от этого момента считается бронь)
+	ReminderSentAt   time.Time  // Когда отправлено напоминание об оплате (нулевое, если не отправлялось)
+	Attendance       Attendance // Отметка о посещении (только для approved)
+	CheckedInAt      time.Time  // Когда игрок отметился сам по ссылке (нулевое, если не отмечался)
+	PartnerID        int64      // Telegram ID напарника по команде (0 - без пары)
+	Captain          bool       // Игрок собрал команду и пригласил напарника (от его регистрации считается место команды)
+	Discount         int        // Скидка по промокоду в единицах цены события
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// GuestID - идентификатор гостя, записанного игроком
+type GuestID string
+
+// MaxGuestsPerHost - сколько гостей может записать один игрок на событие
+const MaxGuestsPerHost = 3
+
+// AnonymizedGuestName - имя, которое остается вместо имени гостя после удаления данных игрока, который его записал
+const AnonymizedGuestName = "Гость"
+
+// Guest - гость без Telegram, которого игрок записал на событие вместе с собой (+1).
+// Гость занимает отдельное место, подтверждается и оплачивается отдельно от игрока
+type Guest struct {
+	ID        GuestID
+	HostID    int64 // Telegram ID игрока, который записал гостя
+	Name      string
 	Status    RegistrationStatus
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// Active сообщает, что регистрация еще действует (игрок записан, в очереди или собирает пару)
+func (s RegistrationStatus) Active() bool {
+	return s.HoldsSpot() || s == RegistrationStatusWaitlisted || s == RegistrationStatusAwaitingPartner
+}
+
 // Event представляет событие в доменной модели
 type Event struct {
-	ID            EventID
-	Name          string
-	Type          EventType
-	Date          time.Time
-	Remaining     int                         // Количество оставшихся мест
-	MaxPlayers    int                         // Максимальное количество игроков
-	Players       []int64                     // ID подтвержденных пользователей Telegram
-	Registrations map[int64]EventRegistration // Все регистрации (pending + approved + rejected)
-	LocationID    location.LocationID
-	Trainer       string // Тренер события
-	Description   string // Описание события (опционально)
-	PaymentPhone  string // Телефон для оплаты
-	Price         int    // Стоимость тренировки (в копейках или минимальных единицах)
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	ID             EventID
+	Name           string
+	Type           EventType
+	Status         Status
+	Date           time.Time
+	Remaining      int                         // Количество оставшихся мест (pending + approved занимают место)
+	MaxPlayers     int                         // Максимальное количество игроков
+	Players        []int64                     // ID подтвержденных пользователей Telegram
+	Registrations  map[int64]EventRegistration // Все регистрации (pending + approved + rejected + waitlisted)
+	Guests         map[GuestID]Guest           // Гости игроков (занимают места наравне с регистрациями)
+	LocationID     location.LocationID
+	Trainer        string             // Тренер события
+	Description    string             // Описание события (опционально)
+	PaymentPhone   string             // Телефон для оплаты
+	Price          int                // Стоимость участия в рублях (в звездах для PaymentModeStars)
+	PaymentMode    PaymentMode        // Способ оплаты (пусто - PaymentModeTransfer)
+	PendingTimeout time.Duration      // Время брони без оплаты (0 - использовать глобальную настройку)
+	CancelDeadline time.Duration      // За сколько до начала можно бесплатно отменить запись (0 - глобальная настройка)
+	LateCancel     LateCancelMode     // Что происходит при поздней отмене (пусто - глобальная настройка)
+	SeriesID       string             // ID повторяющейся серии, из которой создано событие (пусто для разовых)
+	MinLevel       user.Level         // Минимальный уровень игрока (0 - без ограничения)
+	MaxLevel       user.Level         // Максимальный уровень игрока (0 - без ограничения)
+	Doubles        bool               // Парная категория: запись командами по два игрока, места считаются в командах
+	Duration       time.Duration      // Длительность события (0 - DefaultDuration)
+	CourtIDs       []location.CourtID // Корты локации, забронированные на время события
+	CreatedBy      int64              // Telegram ID создателя (тренер управляет только своими событиями)
+	Version        int                // Версия для оптимистичной блокировки (увеличивается при каждом сохранении)
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// EffectivePaymentMode возвращает способ оплаты события с учетом значения по умолчанию
+func (e *Event) EffectivePaymentMode() PaymentMode {
+	if e.PaymentMode == "" {
+		return PaymentModeTransfer
+	}
+	return e.PaymentMode
+}
+
+// OnlinePayment сообщает, что участие оплачивается счетом в Telegram
+func (e *Event) OnlinePayment() bool {
+	return e.Price > 0 && e.EffectivePaymentMode().Online()
+}
+
+// PriceFor возвращает стоимость участия игрока с учетом скидки по промокоду
+func (e *Event) PriceFor(userID int64) int {
+	price := e.Price - e.Registrations[userID].Discount
+	if price < 0 {
+		return 0
+	}
+	return price
+}
+
+// Invoice возвращает валюту и сумму счета игрока в минимальных единицах (копейки или звезды)
+func (e *Event) Invoice(userID int64) (currency string, amount int) {
+	price := e.PriceFor(userID)
+	if e.EffectivePaymentMode() == PaymentModeStars {
+		return CurrencyStars, price
+	}
+	return CurrencyRUB, price * 100
+}
+
+// CheckPayable проверяет, можно ли сейчас оплатить регистрацию игрока счетом:
+// событие опубликовано и принимает онлайн-оплату, регистрация ждет оплаты, а команда в парной категории собрана
+func (e *Event) CheckPayable(userID int64) error {
+	if !e.OnlinePayment() {
+		return ErrOnlinePaymentDisabled
+	}
+	if e.Status != StatusPublished {
+		return ErrEventNotOpen
+	}
+	reg, exists := e.Registrations[userID]
+	if !exists {
+		return ErrRegistrationNotFound
+	}
+	switch reg.Status {
+	case RegistrationStatusPending:
+		return nil
+	case RegistrationStatusApproved:
+		return ErrRegistrationAlreadyApproved
+	case RegistrationStatusAwaitingPartner:
+		return ErrTeamIncomplete
+	default:
+		return ErrRegistrationNotPayable
+	}
+}
+
+// RecalculateCapacity пересчитывает Players и Remaining на основе регистраций и гостей.
+// В парной категории место занимает команда, поэтому считаются только регистрации капитанов
+func (e *Event) RecalculateCapacity() {
+	players := make([]int64, 0)
+	held := 0
+	for userID, reg := range e.Registrations {
+		if reg.Status == RegistrationStatusApproved {
+			players = append(players, userID)
+		}
+		if reg.Status.HoldsSpot() && e.TeamHead(reg) {
+			held++
+		}
+	}
+	for _, guest := range e.Guests {
+		if guest.Status.HoldsSpot() {
+			held++
+		}
+	}
+
+	e.Players = players
+	e.Remaining = e.MaxPlayers - held
+	if e.Remaining < 0 {
+		e.Remaining = 0
+	}
+}
+
+// DefaultDuration - длительность события, для которого она не указана
+const DefaultDuration = 2 * time.Hour
+
+// EffectiveDuration возвращает длительность события с учетом значения по умолчанию
+func (e *Event) EffectiveDuration() time.Duration {
+	if e.Duration <= 0 {
+		return DefaultDuration
+	}
+	return e.Duration
+}
+
+// EndsAt возвращает время окончания события
+func (e *Event) EndsAt() time.Time {
+	return e.Date.Add(e.EffectiveDuration())
+}
+
+// Overlaps сообщает, пересекается ли время события с интервалом [start, end)
+func (e *Event) Overlaps(start, end time.Time) bool {
+	return e.Date.Before(end) && start.Before(e.EndsAt())
+}
+
+// ReservesCourt сообщает, забронирован ли корт за событием
+func (e *Event) ReservesCourt(id location.CourtID) bool {
+	for _, courtID := range e.CourtIDs {
+		if courtID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// FindCourtConflict ищет среди событий локации то, что уже занимает один из кортов события evt в его время.
+// Отмененные и завершенные события корты не занимают, само событие evt не учитывается
+func FindCourtConflict(events []Event, evt *Event) *CourtConflictError {
+	if len(evt.CourtIDs) == 0 {
+		return nil
+	}
+	for i := range events {
+		other := &events[i]
+		if other.ID == evt.ID || !other.Status.IsActive() || !other.Overlaps(evt.Date, evt.EndsAt()) {
+			continue
+		}
+		for _, courtID := range evt.CourtIDs {
+			if other.ReservesCourt(courtID) {
+				return &CourtConflictError{CourtID: courtID, EventID: other.ID, EventName: other.Name, Date: other.Date, EndsAt: other.EndsAt()}
+			}
+		}
+	}
+	return nil
+}
+
+// TransitionTo переводит событие в статус to, если переход допустим
+func (e *Event) TransitionTo(to Status) error {
+	if !e.Status.CanTransitionTo(to) {
+		return ErrInvalidStatusTransition
+	}
+	e.Status = to
+	return nil
+}
+
+// Phase - этап проведения события по времени (выводится из даты начала и длительности)
+type Phase string
+
+const (
+	PhaseUpcoming   Phase = "upcoming"    // Еще не началось
+	PhaseInProgress Phase = "in_progress" // Идет сейчас
+	PhaseFinished   Phase = "finished"    // Закончилось
+)
+
+// Phase возвращает этап проведения события на момент now
+func (e *Event) Phase(now time.Time) Phase {
+	switch {
+	case now.Before(e.Date):
+		return PhaseUpcoming
+	case now.Before(e.EndsAt()):
+		return PhaseInProgress
+	default:
+		return PhaseFinished
+	}
+}
+
+// IsOpen сообщает, что событие опубликовано и еще не началось (видно пользователям, открыта запись).
+// После начала записаться уже нельзя, даже пока событие идет
+func (e *Event) IsOpen(now time.Time) bool {
+	return e.Status == StatusPublished && e.Date.After(now)
+}
+
+// Upcoming возвращает опубликованные события, которые еще не начались
+func Upcoming(events []Event, now time.Time) []Event {
+	result := make([]Event, 0, len(events))
+	for _, evt := range events {
+		if evt.IsOpen(now) {
+			result = append(result, evt)
+		}
+	}
+	return result
+}
+
+// LevelRestricted сообщает, ограничено ли событие по уровню игроков
+func (e *Event) LevelRestricted() bool {
+	return e.MinLevel.IsSet() || e.MaxLevel.IsSet()
+}
+
+// LevelAllows проверяет, подходит ли уровень игрока под ограничения события.
+// Игрок без уровня допускается только на события без ограничений
+func (e *Event) LevelAllows(level user.Level) bool {
+	if !e.LevelRestricted() {
+		return true
+	}
+	if !level.IsSet() {
+		return false
+	}
+	if e.MinLevel.IsSet() && level < e.MinLevel {
+		return false
+	}
+	if e.MaxLevel.IsSet() && level > e.MaxLevel {
+		return false
+	}
+	return true
+}
+
+// ValidateLevelRange проверяет границы уровня: каждая либо не задана, либо допустима, и min <= max
+func ValidateLevelRange(min, max user.Level) error {
+	if (min.IsSet() && !min.Valid()) || (max.IsSet() && !max.Valid()) {
+		return user.ErrInvalidLevel
+	}
+	if min.IsSet() && max.IsSet() && min > max {
+		return ErrLevelRangeInvalid
+	}
+	return nil
+}
+
+// AttendanceTracked сообщает, можно ли отмечать посещаемость события
+func (e *Event) AttendanceTracked() bool {
+	return e.Status == StatusPublished || e.Status == StatusCompleted
+}
+
+// CheckInOpen сообщает, открыта ли самостоятельная отметка по ссылке
+func (e *Event) CheckInOpen(now time.Time) bool {
+	return e.AttendanceTracked() &&
+		!now.Before(e.Date.Add(-CheckInOpensBefore)) &&
+		now.Before(e.Date.Add(CheckInClosesAfter))
+}
+
+// NoShowPolicy - правило блокировки самостоятельной записи за неявки (поздние отмены считаются неявками)
+type NoShowPolicy struct {
+	Limit    int           // Сколько неявок приводит к блокировке (0 - политика отключена)
+	Window   time.Duration // За какой период считаются неявки
+	BlockFor time.Duration // На сколько блокируется самостоятельная запись
+}
+
+// Enabled сообщает, включена ли политика
+func (p NoShowPolicy) Enabled() bool {
+	return p.Limit > 0 && p.Window > 0 && p.BlockFor > 0
+}
+
+// BlockedUntil возвращает, до какого момента заблокирована запись при данных датах неявок.
+// Блокировка начинается с неявки, на которой набралось Limit неявок за Window.
+// Нулевое время - запись не заблокирована
+func (p NoShowPolicy) BlockedUntil(noShows []time.Time, now time.Time) time.Time {
+	if !p.Enabled() || len(noShows) < p.Limit {
+		return time.Time{}
+	}
+
+	dates := append([]time.Time(nil), noShows...)
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var until time.Time
+	for i := p.Limit - 1; i < len(dates); i++ {
+		if dates[i].Sub(dates[i-p.Limit+1]) < p.Window {
+			until = dates[i].Add(p.BlockFor)
+		}
+	}
+	if !until.After(now) {
+		return time.Time{}
+	}
+	return until
+}
+
+// NoShowPolicySource отдает текущую политику неявок (реализуется сервисом настроек)
+type NoShowPolicySource interface {
+	GetNoShowPolicy(ctx context.Context) (NoShowPolicy, error)
+}
+
+// LateCancelMode - что происходит, если игрок отменяет запись после срока бесплатной отмены
+type LateCancelMode string
+
+const (
+	LateCancelNone    LateCancelMode = "none"    // Срока нет: отменить запись можно в любой момент без последствий
+	LateCancelBlock   LateCancelMode = "block"   // Самостоятельная отмена запрещена, только через администратора
+	LateCancelPenalty LateCancelMode = "penalty" // Отмена засчитывается как поздняя: учитывается наравне с неявками, оплата не возвращается
+)
+
+// Valid проверяет, что режим поздней отмены известен (пустой - глобальная настройка)
+func (m LateCancelMode) Valid() bool {
+	switch m {
+	case "", LateCancelNone, LateCancelBlock, LateCancelPenalty:
+		return true
+	}
+	return false
+}
+
+// CancellationPolicy - правило отмены записи игроком
+type CancellationPolicy struct {
+	Deadline time.Duration  // За сколько до начала можно отменить запись без последствий (0 - в любой момент)
+	Late     LateCancelMode // Что происходит при отмене после срока
+}
+
+// Enabled сообщает, ограничена ли отмена записи сроком
+func (p CancellationPolicy) Enabled() bool {
+	return p.Deadline > 0 && (p.Late == LateCancelBlock || p.Late == LateCancelPenalty)
+}
+
+// FreeUntil возвращает, до какого момента запись на событие, начинающееся в start, можно отменить без последствий
+func (p CancellationPolicy) FreeUntil(start time.Time) time.Time {
+	return start.Add(-p.Deadline)
+}
+
+// IsLate сообщает, что отмена в момент now уже поздняя
+func (p CancellationPolicy) IsLate(start, now time.Time) bool {
+	return p.Enabled() && !now.Before(p.FreeUntil(start))
+}
+
+// RestrictionSource отдает ограничение, запрещающее игроку запись на события локации (реализуется сервисом ограничений)
+type RestrictionSource interface {
+	Blocking(ctx context.Context, userID int64, locationID location.LocationID, now time.Time) (*restriction.Restriction, error)
+}
+
+// CancellationPolicySource отдает глобальное правило отмены записи (реализуется сервисом настроек)
+type CancellationPolicySource interface {
+	GetCancellationPolicy(ctx context.Context) (CancellationPolicy, error)
+}
+
+// CancellationPolicy возвращает правило отмены записи на событие: настройки события дополняются глобальными
+func (e *Event) CancellationPolicy(global CancellationPolicy) CancellationPolicy {
+	if e.LateCancel == LateCancelNone {
+		return CancellationPolicy{Late: LateCancelNone}
+	}
+	policy := global
+	if e.CancelDeadline > 0 {
+		policy.Deadline = e.CancelDeadline
+	}
+	if e.LateCancel != "" {
+		policy.Late = e.LateCancel
+	}
+	return policy
+}
+
+// TeamHead сообщает, представляет ли регистрация команду (в одиночной категории - любая регистрация)
+func (e *Event) TeamHead(reg EventRegistration) bool {
+	return !e.Doubles || reg.Captain
+}
+
+// Partner возвращает Telegram ID напарника игрока (0, если пары нет).
+// Пара считается собранной, только если регистрации ссылаются друг на друга
+func (e *Event) Partner(userID int64) int64 {
+	reg, exists := e.Registrations[userID]
+	if !exists || reg.PartnerID == 0 {
+		return 0
+	}
+	partner, exists := e.Registrations[reg.PartnerID]
+	if !exists || partner.PartnerID != userID {
+		return 0
+	}
+	return reg.PartnerID
+}
+
+// TeamMembers возвращает игрока и его напарника, если пара собрана
+func (e *Event) TeamMembers(userID int64) []int64 {
+	if partner := e.Partner(userID); partner != 0 {
+		return []int64{userID, partner}
+	}
+	return []int64{userID}
+}
+
+// GuestsOf возвращает гостей игрока в порядке записи
+func (e *Event) GuestsOf(hostID int64) []Guest {
+	var guests []Guest
+	for _, guest := range e.Guests {
+		if guest.HostID == hostID {
+			guests = append(guests, guest)
+		}
+	}
+	sort.Slice(guests, func(i, j int) bool { return guests[i].CreatedAt.Before(guests[j].CreatedAt) })
+	return guests
+}
+
+// ActiveGuestsOf возвращает гостей игрока, которые еще занимают место
+func (e *Event) ActiveGuestsOf(hostID int64) []Guest {
+	var guests []Guest
+	for _, guest := range e.GuestsOf(hostID) {
+		if guest.Status.HoldsSpot() {
+			guests = append(guests, guest)
+		}
+	}
+	return guests
+}
+
+// HasActiveRegistrations сообщает, есть ли на событии действующие регистрации
+func (e *Event) HasActiveRegistrations() bool {
+	for _, reg := range e.Registrations {
+		if reg.Status.Active() {
+			return true
+		}
+	}
+	return false
+}
+
+// Waitlist возвращает регистрации из листа ожидания, упорядоченные по позиции.
+// В парной категории очередь состоит из команд и возвращаются регистрации капитанов
+func (e *Event) Waitlist() []EventRegistration {
+	var waitlist []EventRegistration
+	for _, reg := range e.Registrations {
+		if reg.Status == RegistrationStatusWaitlisted && e.TeamHead(reg) {
+			waitlist = append(waitlist, reg)
+		}
+	}
+	sort.Slice(waitlist, func(i, j int) bool {
+		if waitlist[i].WaitlistPosition != waitlist[j].WaitlistPosition {
+			return waitlist[i].WaitlistPosition < waitlist[j].WaitlistPosition
+		}
+		return waitlist[i].CreatedAt.Before(waitlist[j].CreatedAt)
+	})
+	return waitlist
+}
+
+// HoldDeadline возвращает момент, когда истекает бронь pending-регистрации.
+// ok == false, если регистрация не в pending или бронь не ограничена по времени
+func (e *Event) HoldDeadline(reg EventRegistration, defaultTimeout time.Duration) (deadline time.Time, ok bool) {
+	if reg.Status != RegistrationStatusPending {
+		return time.Time{}, false
+	}
+
+	timeout := e.PendingTimeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if timeout <= 0 {
+		return time.Time{}, false
+	}
+
+	since := reg.PendingSince
+	if since.IsZero() {
+		since = reg.CreatedAt
+	}
+	return since.Add(timeout), true
 }
 
 type EventType string
@@ -55,25 +617,100 @@ const (
 
 // CreateEventInput - DTO для создания события
 type CreateEventInput struct {
-	Name         string
-	Type         EventType
-	Date         time.Time
-	MaxPlayers   int
-	LocationID   location.LocationID
-	Trainer      string
-	Description  string
-	PaymentPhone string
-	Price        int
+	Name           string
+	Type           EventType
+	Date           time.Time
+	MaxPlayers     int
+	LocationID     location.LocationID
+	Trainer        string
+	Description    string
+	PaymentPhone   string
+	Price          int
+	PendingTimeout time.Duration      // Время брони без оплаты (0 - глобальная настройка)
+	PaymentMode    PaymentMode        // Способ оплаты (пусто - PaymentModeTransfer)
+	SeriesID       string             // ID серии (для событий, созданных из повторяющейся серии)
+	Draft          bool               // Создать черновиком (иначе событие сразу публикуется)
+	MinLevel       user.Level         // Минимальный уровень игрока (0 - без ограничения)
+	MaxLevel       user.Level         // Максимальный уровень игрока (0 - без ограничения)
+	Doubles        bool               // Парная категория (только для соревнований)
+	Duration       time.Duration      // Длительность (0 - DefaultDuration)
+	CourtIDs       []location.CourtID // Корты, которые событие бронирует на свое время
+	CreatedBy      int64              // Telegram ID создателя события
+}
+
+// ExpiredHolds - регистрации одного события, у которых истекла бронь
+type ExpiredHolds struct {
+	Event    *Event
+	Expired  []EventRegistration // Регистрации, переведенные в expired
+	Promoted []EventRegistration // Регистрации, переведенные из листа ожидания на освободившиеся места
+}
+
+// PaymentReminder - напоминание об оплате до снятия брони
+type PaymentReminder struct {
+	Event        *Event
+	Registration EventRegistration
+	Deadline     time.Time
 }
 
 // UpdateEventInput - DTO для обновления события
 type UpdateEventInput struct {
-	Name        *string
-	Type        *EventType
-	Date        *time.Time
-	MaxPlayers  *int
-	Remaining   *int
-	Description *string
+	Name         *string
+	Type         *EventType
+	Date         *time.Time
+	MaxPlayers   *int
+	Description  *string
+	Trainer      *string
+	Price        *int
+	PaymentPhone *string
+	PaymentMode  *PaymentMode
+	LocationID   *location.LocationID
+	MinLevel     *user.Level
+	MaxLevel     *user.Level
+	Doubles      *bool
+	Duration     *time.Duration
+	CourtIDs     *[]location.CourtID // При смене локации без новых кортов бронь кортов снимается
+	// Правило отмены записи: CancelDeadline 0 и пустой LateCancel - глобальная настройка
+	CancelDeadline *time.Duration
+	LateCancel     *LateCancelMode
+}
+
+// Validate проверяет валидность входных данных для обновления события
+func (in UpdateEventInput) Validate() error {
+	if in.Name != nil && *in.Name == "" {
+		return ErrEventNameRequired
+	}
+	if in.LocationID != nil && *in.LocationID == "" {
+		return ErrLocationIDRequired
+	}
+	if in.Date != nil && in.Date.Before(time.Now()) {
+		return ErrDateInPast
+	}
+	if in.MaxPlayers != nil && *in.MaxPlayers <= 0 {
+		return ErrMaxPlayersInvalid
+	}
+	if in.Price != nil && *in.Price < 0 {
+		return ErrPriceInvalid
+	}
+	if in.Duration != nil && *in.Duration < 0 {
+		return ErrDurationInvalid
+	}
+	if in.PaymentMode != nil && !in.PaymentMode.Valid() {
+		return ErrPaymentModeInvalid
+	}
+	if in.CancelDeadline != nil && *in.CancelDeadline < 0 {
+		return ErrCancelDeadlineInvalid
+	}
+	if in.LateCancel != nil && !in.LateCancel.Valid() {
+		return ErrLateCancelModeInvalid
+	}
+	return nil
+}
+
+// Announcement - сообщение с анонсом события в канале (нужно, чтобы обновлять анонс при изменении события)
+type Announcement struct {
+	EventID   EventID
+	ChatID    int64
+	MessageID int
 }
 
 // Validate проверяет валидность входных данных для создания события
@@ -93,6 +730,32 @@ func (in CreateEventInput) Validate() error {
 	if in.MaxPlayers <= 0 {
 		return ErrMaxPlayersInvalid
 	}
+	if in.Doubles && in.Type != EventTypeCompetition {
+		return ErrDoublesOnlyCompetition
+	}
+	if in.Duration < 0 {
+		return ErrDurationInvalid
+	}
+	if err := ValidatePayment(in.PaymentMode, in.Price); err != nil {
+		return err
+	}
+	return ValidateLevelRange(in.MinLevel, in.MaxLevel)
+}
+
+// ValidatePayment проверяет способ оплаты: онлайн-оплата требует ненулевой стоимости
+func ValidatePayment(mode PaymentMode, price int) error {
+	if mode == "" {
+		mode = PaymentModeTransfer
+	}
+	if !mode.Valid() {
+		return ErrPaymentModeInvalid
+	}
+	if price < 0 {
+		return ErrPriceInvalid
+	}
+	if mode.Online() && price == 0 {
+		return ErrOnlinePaymentPriceRequired
+	}
 	return nil
 }
 
@@ -109,4 +772,100 @@ var (
 	ErrRegistrationNotFound        = errors.New("registration not found")
 	ErrRegistrationAlreadyApproved = errors.New("registration already approved")
 	ErrRegistrationAlreadyRejected = errors.New("registration already rejected")
+	ErrConflict                    = errors.New("event was modified concurrently")
+	ErrInvalidStatusTransition     = errors.New("invalid event status transition")
+	ErrEventNotOpen                = errors.New("event is not open for registration")
+	ErrEventNotEditable            = errors.New("cancelled or completed event cannot be edited")
+	ErrPriceInvalid                = errors.New("price cannot be negative")
+	ErrRegistrationNotApproved     = errors.New("registration is not approved")
+	ErrAttendanceNotTracked        = errors.New("attendance is not tracked for this event")
+	ErrCheckInClosed               = errors.New("check-in is closed")
+	ErrNoShowBlocked               = errors.New("registration is blocked due to no-shows")
+	ErrRestricted                  = errors.New("registration is restricted by an admin")
+	ErrLevelRangeInvalid           = errors.New("min level cannot be greater than max level")
+	ErrLevelNotAllowed             = errors.New("player level does not match event level range")
+	ErrDoublesOnlyCompetition      = errors.New("doubles are available only for competitions")
+	ErrTeamFormatLocked            = errors.New("team format cannot be changed while registrations exist")
+	ErrTeamInviteNotFound          = errors.New("team invite not found or already used")
+	ErrCannotPartnerSelf           = errors.New("player cannot be own partner")
+	ErrTeamIncomplete              = errors.New("partner has not accepted the team invite yet")
+	ErrDurationInvalid             = errors.New("event duration cannot be negative")
+	ErrCourtNotInLocation          = errors.New("court does not belong to event location")
+	ErrCourtBusy                   = errors.New("court is already reserved for this time")
+	ErrPaymentModeInvalid          = errors.New("unknown payment mode")
+	ErrOnlinePaymentPriceRequired  = errors.New("online payment requires a price")
+	ErrOnlinePaymentDisabled       = errors.New("event does not accept online payment")
+	ErrRegistrationNotPayable      = errors.New("registration is not awaiting payment")
+	ErrCancelDeadlineInvalid       = errors.New("cancellation deadline cannot be negative")
+	ErrLateCancelModeInvalid       = errors.New("unknown late cancellation mode")
+	ErrLateCancelBlocked           = errors.New("self-cancellation is closed after the cancellation deadline")
+	ErrRegistrationLateCancelled   = errors.New("registration was cancelled after the cancellation deadline")
+	ErrDiscountAlreadyApplied      = errors.New("discount already applied to registration")
+	ErrGuestNotFound               = errors.New("guest not found")
+	ErrGuestNameRequired           = errors.New("guest name is required")
+	ErrGuestLimit                  = errors.New("too many guests for one player")
+	ErrGuestsNotAllowed            = errors.New("guests are not allowed in doubles")
+	ErrHostNotRegistered           = errors.New("player must hold a spot to bring guests")
 )
+
+// ConflictError возвращается, если событие было изменено параллельно с момента загрузки.
+// Операцию можно безопасно повторить, загрузив событие заново
+type ConflictError struct {
+	EventID EventID
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrConflict, e.EventID)
+}
+
+// Is позволяет проверять конфликт через errors.Is(err, ErrConflict)
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// CourtConflictError возвращается, если корт уже забронирован другим событием на пересекающееся время
+type CourtConflictError struct {
+	CourtID   location.CourtID
+	CourtName string
+	EventID   EventID // Событие, которое уже заняло корт
+	EventName string
+	Date      time.Time
+	EndsAt    time.Time
+}
+
+func (e *CourtConflictError) Error() string {
+	return fmt.Sprintf("%v: court %s is reserved by event %s", ErrCourtBusy, e.CourtID, e.EventID)
+}
+
+// Is позволяет проверять конфликт через errors.Is(err, ErrCourtBusy)
+func (e *CourtConflictError) Is(target error) bool {
+	return target == ErrCourtBusy
+}
+
+// NoShowBlockError возвращается, если самостоятельная запись заблокирована из-за неявок
+type NoShowBlockError struct {
+	Until time.Time
+}
+
+func (e *NoShowBlockError) Error() string {
+	return fmt.Sprintf("%v until %s", ErrNoShowBlocked, e.Until.Format(time.RFC3339))
+}
+
+// Is позволяет проверять блокировку через errors.Is(err, ErrNoShowBlocked)
+func (e *NoShowBlockError) Is(target error) bool {
+	return target == ErrNoShowBlocked
+}
+
+// RestrictedError возвращается, если самостоятельная запись запрещена ограничением администратора
+type RestrictedError struct {
+	Restriction restriction.Restriction
+}
+
+func (e *RestrictedError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrRestricted, e.Restriction.Kind)
+}
+
+// Is позволяет проверять ограничение через errors.Is(err, ErrRestricted)
+func (e *RestrictedError) Is(target error) bool {
+	return target == ErrRestricted
+}
@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"time"
 
 	"pickletlgbot/internal/domain/location"
 )
@@ -20,9 +21,30 @@ type EventRepository interface {
 	// ListByUser возвращает события, на которые зарегистрирован пользователь
 	ListByUser(ctx context.Context, userID int64) ([]Event, error)
 
-	// Save создаёт или обновляет событие
+	// ListBySeries возвращает события, созданные из повторяющейся серии
+	ListBySeries(ctx context.Context, seriesID string) ([]Event, error)
+
+	// Save создаёт или обновляет событие вместе с регистрациями в одной транзакции.
+	// Если событие изменили после загрузки (не совпала Version), возвращает *ConflictError;
+	// если корт события уже занят другим событием на это время - *CourtConflictError
 	Save(ctx context.Context, event *Event) error
 
+	// Update загружает событие, применяет fn и сохраняет результат в одной транзакции.
+	// Строка события не блокируется: если событие успели изменить параллельно, возвращает *ConflictError.
+	// Если изменились корты или время события, а корт уже занят, возвращает *CourtConflictError.
+	// Занятость кортов проверяется в той же транзакции под блокировкой расписания локации.
+	// Ошибка fn откатывает транзакцию и возвращается как есть; если события нет - ErrEventNotFound
+	Update(ctx context.Context, id EventID, fn func(event *Event) error) (*Event, error)
+
 	// Delete удаляет событие по ID
 	Delete(ctx context.Context, id EventID) error
+
+	// ListNoShowDates возвращает даты событий начиная с since, на которые пользователь не пришел или поздно отменил запись
+	ListNoShowDates(ctx context.Context, userID int64, since time.Time) ([]time.Time, error)
+
+	// SaveAnnouncement запоминает сообщение с анонсом события в канале
+	SaveAnnouncement(ctx context.Context, announcement Announcement) error
+
+	// ListAnnouncements возвращает все сообщения с анонсами события
+	ListAnnouncements(ctx context.Context, id EventID) ([]Announcement, error)
 }
@@ -3,9 +3,11 @@ package event
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/user"
 
 	"github.com/google/uuid"
 )
@@ -16,29 +18,105 @@ type EventService interface {
 	List(ctx context.Context) ([]Event, error)
 	ListByLocation(ctx context.Context, locationID location.LocationID) ([]Event, error)
 	ListByUser(ctx context.Context, userID int64) ([]Event, error)
+	ListBySeries(ctx context.Context, seriesID string) ([]Event, error)
 	Create(ctx context.Context, input CreateEventInput) (*Event, error)
 	Update(ctx context.Context, id EventID, input UpdateEventInput) (*Event, error)
 	Delete(ctx context.Context, id EventID) error
 
+	// FreeCourts возвращает корты локации, свободные на интервале [start, start+duration).
+	// Корты события exclude считаются свободными (для редактирования уже созданного события)
+	FreeCourts(ctx context.Context, locationID location.LocationID, start time.Time, duration time.Duration, exclude EventID) ([]location.Court, error)
+
+	// Анонсы события в каналах
+	AddAnnouncement(ctx context.Context, announcement Announcement) error
+	ListAnnouncements(ctx context.Context, id EventID) ([]Announcement, error)
+
+	// Жизненный цикл события
+	// Publish публикует черновик (открывает запись)
+	Publish(ctx context.Context, id EventID) (*Event, error)
+	// Cancel отменяет событие; регистрации сохраняются, чтобы можно было уведомить игроков
+	Cancel(ctx context.Context, id EventID) (*Event, error)
+	// CompletePast переводит закончившиеся опубликованные события в completed и возвращает их
+	CompletePast(ctx context.Context, now time.Time) ([]Event, error)
+
 	// Регистрация пользователей
-	RegisterUserToEvent(ctx context.Context, eventID EventID, userID int64) error // Создает регистрацию со статусом pending
-	UnregisterUser(ctx context.Context, eventID EventID, userID int64) error
+	RegisterUserToEvent(ctx context.Context, eventID EventID, userID int64) error // Создает регистрацию со статусом pending (или waitlisted, если мест нет)
+	// RegisterUserByAdmin записывает игрока по решению администратора - без проверки уровня и блокировки за неявки
+	RegisterUserByAdmin(ctx context.Context, eventID EventID, userID int64) error
+	// AcceptTeamInvite добавляет напарника в команду капитана парной категории
+	AcceptTeamInvite(ctx context.Context, eventID EventID, captainID, partnerID int64) error
+	// UnregisterUser отменяет запись игрока (в парной категории - всей команды) и возвращает регистрации, переведенные из листа ожидания.
+	// После срока бесплатной отмены запись либо нельзя отменить (ErrLateCancelBlocked), либо она остается как поздняя отмена (late)
+	UnregisterUser(ctx context.Context, eventID EventID, userID int64) (promoted []EventRegistration, late bool, err error)
+	// CancellationPolicy возвращает правило отмены записи на событие с учетом глобальной настройки
+	CancellationPolicy(ctx context.Context, evt *Event) (CancellationPolicy, error)
+
+	// Гости игроков (+1 без Telegram)
+	// AddGuest записывает гостя игрока, который сам занимает место; гость ждет подтверждения администратора
+	AddGuest(ctx context.Context, eventID EventID, hostID int64, name string) (*Guest, error)
+	// RemoveGuest отменяет запись гостя игроком по тем же правилам срока отмены, что и запись самого игрока
+	RemoveGuest(ctx context.Context, eventID EventID, hostID int64, guestID GuestID) (promoted []EventRegistration, late bool, err error)
+	// ApproveGuest подтверждает гостя
+	ApproveGuest(ctx context.Context, eventID EventID, guestID GuestID) (*Guest, error)
+	// RejectGuest отклоняет гостя и возвращает регистрации, переведенные из листа ожидания на освободившееся место
+	RejectGuest(ctx context.Context, eventID EventID, guestID GuestID) (*Guest, []EventRegistration, error)
+	// AnonymizeGuests стирает имена гостей, которых записывал игрок hostID (при удалении его данных)
+	AnonymizeGuests(ctx context.Context, hostID int64) error
 
 	// Модерация регистраций (для админов)
 	ApproveRegistration(ctx context.Context, eventID EventID, userID int64) error
-	RejectRegistration(ctx context.Context, eventID EventID, userID int64) error
+	// RejectRegistration отклоняет регистрацию и возвращает регистрации, переведенные из листа ожидания
+	RejectRegistration(ctx context.Context, eventID EventID, userID int64) ([]EventRegistration, error)
 	ListPendingRegistrations(ctx context.Context, eventID EventID) ([]EventRegistration, error)
+
+	// ConfirmPayment подтверждает регистрацию после успешной онлайн-оплаты. Если бронь успела истечь,
+	// игрок получает место только при наличии свободных мест
+	ConfirmPayment(ctx context.Context, eventID EventID, userID int64) error
+	// ApplyDiscount сохраняет скидку по промокоду для регистрации, которая еще ждет оплаты
+	ApplyDiscount(ctx context.Context, eventID EventID, userID int64, discount int) (*Event, error)
+
+	// Посещаемость
+	// MarkAttendance отмечает, пришел ли подтвержденный игрок (AttendanceUnknown снимает отметку)
+	MarkAttendance(ctx context.Context, eventID EventID, userID int64, attendance Attendance) (*Event, error)
+	// MarkUnmarkedNoShow отмечает неявку всем подтвержденным игрокам без отметки
+	MarkUnmarkedNoShow(ctx context.Context, eventID EventID) (*Event, error)
+	// CheckIn - самостоятельная отметка игрока по ссылке на месте
+	CheckIn(ctx context.Context, eventID EventID, userID int64, now time.Time) (*Event, error)
+	// NoShowBlockedUntil возвращает, до какого момента самостоятельная запись заблокирована из-за неявок
+	NoShowBlockedUntil(ctx context.Context, userID int64, now time.Time) (time.Time, error)
+
+	// PromoteWaitlist переводит игроков из листа ожидания на свободные места
+	PromoteWaitlist(ctx context.Context, eventID EventID) ([]EventRegistration, error)
+
+	// Снятие неоплаченных броней (для фонового планировщика)
+	// ExpirePendingRegistrations переводит pending-регистрации с истекшей бронью в expired
+	ExpirePendingRegistrations(ctx context.Context, now time.Time, defaultTimeout time.Duration) ([]ExpiredHolds, error)
+	// DuePaymentReminders отмечает и возвращает регистрации, которым пора напомнить об оплате
+	DuePaymentReminders(ctx context.Context, now time.Time, defaultTimeout, lead time.Duration) ([]PaymentReminder, error)
+}
+
+// PlayerDirectory отдает данные игроков, нужные для проверки записи (реализуется сервисом пользователей)
+type PlayerDirectory interface {
+	GetByTelegramID(ctx context.Context, telegramID int64) (*user.User, error)
 }
 
 type eventService struct {
 	repo            EventRepository
 	locationService location.LocationService // Для валидации локации
+	noShowPolicies  NoShowPolicySource       // Политика блокировки записи за неявки
+	cancelPolicies  CancellationPolicySource // Глобальное правило отмены записи
+	players         PlayerDirectory          // Для проверки уровня игрока
+	restrictions    RestrictionSource        // Ограничения записи, установленные администраторами
 }
 
-func NewEventService(repo EventRepository, locationService location.LocationService) EventService {
+func NewEventService(repo EventRepository, locationService location.LocationService, noShowPolicies NoShowPolicySource, cancelPolicies CancellationPolicySource, players PlayerDirectory, restrictions RestrictionSource) EventService {
 	return &eventService{
 		repo:            repo,
 		locationService: locationService,
+		noShowPolicies:  noShowPolicies,
+		cancelPolicies:  cancelPolicies,
+		players:         players,
+		restrictions:    restrictions,
 	}
 }
 
@@ -58,6 +136,10 @@ func (s *eventService) ListByUser(ctx context.Context, userID int64) ([]Event, e
 	return s.repo.ListByUser(ctx, userID)
 }
 
+func (s *eventService) ListBySeries(ctx context.Context, seriesID string) ([]Event, error) {
+	return s.repo.ListBySeries(ctx, seriesID)
+}
+
 func (s *eventService) Create(ctx context.Context, in CreateEventInput) (*Event, error) {
 	// Валидация входных данных
 	if err := in.Validate(); err != nil {
@@ -72,229 +154,913 @@ func (s *eventService) Create(ctx context.Context, in CreateEventInput) (*Event,
 
 	// Создаем событие
 	event := &Event{
-		ID:            EventID(uuid.New().String()),
-		Name:          in.Name,
-		Type:          in.Type,
-		Date:          in.Date,
-		MaxPlayers:    in.MaxPlayers,
-		Remaining:     in.MaxPlayers, // Изначально все места свободны
-		Players:       []int64{},
-		Registrations: make(map[int64]EventRegistration),
-		LocationID:    in.LocationID,
-		Trainer:       in.Trainer,
-		Description:   in.Description,
-		PaymentPhone:  in.PaymentPhone,
-		Price:         in.Price,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:             EventID(uuid.New().String()),
+		Name:           in.Name,
+		Type:           in.Type,
+		Date:           in.Date,
+		MaxPlayers:     in.MaxPlayers,
+		Remaining:      in.MaxPlayers, // Изначально все места свободны
+		Players:        []int64{},
+		Registrations:  make(map[int64]EventRegistration),
+		Guests:         make(map[GuestID]Guest),
+		LocationID:     in.LocationID,
+		Trainer:        in.Trainer,
+		Description:    in.Description,
+		PaymentPhone:   in.PaymentPhone,
+		Price:          in.Price,
+		PendingTimeout: in.PendingTimeout,
+		PaymentMode:    in.PaymentMode,
+		SeriesID:       in.SeriesID,
+		MinLevel:       in.MinLevel,
+		MaxLevel:       in.MaxLevel,
+		Doubles:        in.Doubles,
+		Duration:       in.Duration,
+		CourtIDs:       in.CourtIDs,
+		CreatedBy:      in.CreatedBy,
+		Status:         StatusPublished,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if in.Draft {
+		event.Status = StatusDraft
+	}
+	if event.PaymentMode == "" {
+		event.PaymentMode = PaymentModeTransfer
+	}
+	courtNames, err := s.checkCourts(ctx, event)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := s.repo.Save(ctx, event); err != nil {
-		return nil, err
+		return nil, nameConflictCourt(err, courtNames)
 	}
 
 	return event, nil
 }
 
 func (s *eventService) Update(ctx context.Context, id EventID, in UpdateEventInput) (*Event, error) {
-	event, err := s.repo.GetByID(ctx, id)
-	if err != nil {
+	if err := in.Validate(); err != nil {
 		return nil, err
 	}
-	if event == nil {
-		return nil, ErrEventNotFound
-	}
 
-	// Обновляем поля
-	if in.Name != nil {
-		event.Name = *in.Name
-	}
-	if in.Type != nil {
-		event.Type = *in.Type
-	}
-	if in.Date != nil {
-		event.Date = *in.Date
-	}
-	if in.MaxPlayers != nil {
-		// При изменении MaxPlayers нужно пересчитать Remaining
-		oldMax := event.MaxPlayers
-		event.MaxPlayers = *in.MaxPlayers
-		// Если увеличили количество мест, увеличиваем и оставшиеся
-		if *in.MaxPlayers > oldMax {
-			event.Remaining += (*in.MaxPlayers - oldMax)
-		} else if *in.MaxPlayers < oldMax {
-			// Если уменьшили, уменьшаем оставшиеся (но не меньше 0)
-			diff := oldMax - *in.MaxPlayers
-			if event.Remaining > diff {
-				event.Remaining -= diff
-			} else {
-				event.Remaining = 0
+	var courtNames map[location.CourtID]string
+	evt, err := s.repo.Update(ctx, id, func(event *Event) error {
+		if !event.Status.IsActive() {
+			return ErrEventNotEditable
+		}
+
+		// Обновляем поля
+		if in.Name != nil {
+			event.Name = *in.Name
+		}
+		if in.Type != nil {
+			event.Type = *in.Type
+		}
+		if in.Date != nil {
+			event.Date = *in.Date
+		}
+		if in.MaxPlayers != nil {
+			event.MaxPlayers = *in.MaxPlayers
+		}
+		if in.Description != nil {
+			event.Description = *in.Description
+		}
+		if in.Trainer != nil {
+			event.Trainer = *in.Trainer
+		}
+		if in.Price != nil {
+			event.Price = *in.Price
+		}
+		if in.PaymentPhone != nil {
+			event.PaymentPhone = *in.PaymentPhone
+		}
+		if in.PaymentMode != nil {
+			event.PaymentMode = *in.PaymentMode
+		}
+		if err := ValidatePayment(event.PaymentMode, event.Price); err != nil {
+			return err
+		}
+		if in.CancelDeadline != nil {
+			event.CancelDeadline = *in.CancelDeadline
+		}
+		if in.LateCancel != nil {
+			event.LateCancel = *in.LateCancel
+		}
+		reschedule := in.Date != nil || in.Duration != nil || in.CourtIDs != nil
+		if in.LocationID != nil && *in.LocationID != event.LocationID {
+			event.LocationID = *in.LocationID
+			// Корты принадлежат локации: при переезде старая бронь теряет смысл
+			event.CourtIDs = nil
+			reschedule = true
+		}
+		if in.Duration != nil {
+			event.Duration = *in.Duration
+		}
+		if in.CourtIDs != nil {
+			event.CourtIDs = *in.CourtIDs
+		}
+		if reschedule {
+			var err error
+			if courtNames, err = s.checkCourts(ctx, event); err != nil {
+				return err
 			}
 		}
+		if in.MinLevel != nil {
+			event.MinLevel = *in.MinLevel
+		}
+		if in.MaxLevel != nil {
+			event.MaxLevel = *in.MaxLevel
+		}
+		if err := ValidateLevelRange(event.MinLevel, event.MaxLevel); err != nil {
+			return err
+		}
+		// Формат нельзя менять, пока есть записи: команды и одиночные регистрации не переводятся друг в друга
+		if in.Doubles != nil && *in.Doubles != event.Doubles {
+			if event.HasActiveRegistrations() {
+				return ErrTeamFormatLocked
+			}
+			event.Doubles = *in.Doubles
+		}
+		if event.Doubles && event.Type != EventTypeCompetition {
+			return ErrDoublesOnlyCompetition
+		}
+
+		// Remaining всегда считается по регистрациям
+		event.RecalculateCapacity()
+
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, nameConflictCourt(err, courtNames)
+	}
+	return evt, nil
+}
+
+// checkCourts проверяет, что корты события принадлежат его локации, и возвращает названия кортов локации.
+// Занятость кортов проверяет репозиторий при сохранении, под блокировкой расписания локации
+func (s *eventService) checkCourts(ctx context.Context, evt *Event) (map[location.CourtID]string, error) {
+	if len(evt.CourtIDs) == 0 {
+		return nil, nil
+	}
+
+	courts, err := s.locationService.ListCourts(ctx, evt.LocationID)
+	if err != nil {
+		return nil, err
 	}
-	if in.Remaining != nil {
-		event.Remaining = *in.Remaining
+	names := make(map[location.CourtID]string, len(courts))
+	for _, c := range courts {
+		names[c.ID] = c.Name
 	}
-	if in.Description != nil {
-		event.Description = *in.Description
+	for _, courtID := range evt.CourtIDs {
+		if _, ok := names[courtID]; !ok {
+			return nil, ErrCourtNotInLocation
+		}
 	}
+	return names, nil
+}
 
-	event.UpdatedAt = time.Now()
+// nameConflictCourt дописывает в ошибку занятого корта его название
+func nameConflictCourt(err error, names map[location.CourtID]string) error {
+	var conflict *CourtConflictError
+	if errors.As(err, &conflict) {
+		conflict.CourtName = names[conflict.CourtID]
+	}
+	return err
+}
 
-	if err := s.repo.Save(ctx, event); err != nil {
+func (s *eventService) FreeCourts(ctx context.Context, locationID location.LocationID, start time.Time, duration time.Duration, exclude EventID) ([]location.Court, error) {
+	courts, err := s.locationService.ListCourts(ctx, locationID)
+	if err != nil || len(courts) == 0 {
+		return nil, err
+	}
+	events, err := s.repo.ListByLocation(ctx, locationID)
+	if err != nil {
 		return nil, err
 	}
 
-	return event, nil
+	slot := &Event{ID: exclude, Date: start, Duration: duration}
+	free := make([]location.Court, 0, len(courts))
+	for _, c := range courts {
+		slot.CourtIDs = []location.CourtID{c.ID}
+		if FindCourtConflict(events, slot) == nil {
+			free = append(free, c)
+		}
+	}
+	return free, nil
 }
 
 func (s *eventService) Delete(ctx context.Context, id EventID) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *eventService) RegisterUserToEvent(ctx context.Context, eventID EventID, userID int64) error {
-	event, err := s.repo.GetByID(ctx, eventID)
+func (s *eventService) AddAnnouncement(ctx context.Context, announcement Announcement) error {
+	return s.repo.SaveAnnouncement(ctx, announcement)
+}
+
+func (s *eventService) ListAnnouncements(ctx context.Context, id EventID) ([]Announcement, error) {
+	return s.repo.ListAnnouncements(ctx, id)
+}
+
+func (s *eventService) Publish(ctx context.Context, id EventID) (*Event, error) {
+	return s.transition(ctx, id, StatusPublished)
+}
+
+func (s *eventService) Cancel(ctx context.Context, id EventID) (*Event, error) {
+	return s.transition(ctx, id, StatusCancelled)
+}
+
+func (s *eventService) CompletePast(ctx context.Context, now time.Time) ([]Event, error) {
+	events, err := s.repo.List(ctx)
 	if err != nil {
-		return err
-	}
-	if event == nil {
-		return ErrEventNotFound
+		return nil, err
 	}
 
-	// Проверяем, не зарегистрирован ли уже пользователь (в любом статусе)
-	if reg, exists := event.Registrations[userID]; exists {
-		if reg.Status == RegistrationStatusPending {
-			return ErrUserAlreadyRegistered // Уже есть pending регистрация
+	var completed []Event
+	for _, evt := range events {
+		// Событие завершается, когда закончилось, а не когда началось
+		if evt.Status != StatusPublished || evt.Phase(now) != PhaseFinished {
+			continue
 		}
-		if reg.Status == RegistrationStatusApproved {
-			return ErrUserAlreadyRegistered // Уже подтвержден
+		updated, err := s.transition(ctx, evt.ID, StatusCompleted)
+		if err != nil {
+			return completed, err
+		}
+		completed = append(completed, *updated)
+	}
+	return completed, nil
+}
+
+// transition переводит событие в новый статус с проверкой допустимости перехода
+func (s *eventService) transition(ctx context.Context, id EventID, to Status) (*Event, error) {
+	return s.repo.Update(ctx, id, func(event *Event) error {
+		if err := event.TransitionTo(to); err != nil {
+			return err
 		}
-		// Если был rejected, можно зарегистрироваться снова
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+func (s *eventService) RegisterUserToEvent(ctx context.Context, eventID EventID, userID int64) error {
+	level, err := s.selfRegistrationLevel(ctx, userID)
+	if err != nil {
+		return err
 	}
+	return s.register(ctx, eventID, userID, level, true)
+}
 
-	// Создаем регистрацию со статусом pending
-	event.Registrations[userID] = EventRegistration{
-		UserID:    userID,
-		Status:    RegistrationStatusPending,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+// selfRegistrationLevel проверяет блокировку за неявки и возвращает уровень игрока для самостоятельной записи
+func (s *eventService) selfRegistrationLevel(ctx context.Context, userID int64) (user.Level, error) {
+	// Игрок с неявками сверх лимита не может записаться сам
+	until, err := s.NoShowBlockedUntil(ctx, userID, time.Now())
+	if err != nil {
+		return user.LevelUnset, err
 	}
-	event.UpdatedAt = time.Now()
+	if !until.IsZero() {
+		return user.LevelUnset, &NoShowBlockError{Until: until}
+	}
+	return s.playerLevel(ctx, userID)
+}
 
-	return s.repo.Save(ctx, event)
+func (s *eventService) RegisterUserByAdmin(ctx context.Context, eventID EventID, userID int64) error {
+	return s.register(ctx, eventID, userID, user.LevelUnset, false)
 }
 
-func (s *eventService) UnregisterUser(ctx context.Context, eventID EventID, userID int64) error {
-	event, err := s.repo.GetByID(ctx, eventID)
+// playerLevel возвращает уровень игрока (LevelUnset, если игрок или уровень не найдены)
+func (s *eventService) playerLevel(ctx context.Context, userID int64) (user.Level, error) {
+	if s.players == nil {
+		return user.LevelUnset, nil
+	}
+	player, err := s.players.GetByTelegramID(ctx, userID)
 	if err != nil {
-		return err
+		return user.LevelUnset, err
 	}
-	if event == nil {
-		return ErrEventNotFound
+	if player == nil {
+		return user.LevelUnset, nil
 	}
+	return player.Level, nil
+}
 
-	// Проверяем, существует ли регистрация
-	reg, exists := event.Registrations[userID]
-	if !exists {
-		return ErrRegistrationNotFound
+// checkRestriction проверяет, не запретил ли администратор игроку запись на события локации
+func (s *eventService) checkRestriction(ctx context.Context, userID int64, locationID location.LocationID) error {
+	if s.restrictions == nil {
+		return nil
 	}
+	r, err := s.restrictions.Blocking(ctx, userID, locationID, time.Now())
+	if err != nil {
+		return err
+	}
+	if r != nil {
+		return &RestrictedError{Restriction: *r}
+	}
+	return nil
+}
 
-	// Если был approved, убираем из списка игроков
-	if reg.Status == RegistrationStatusApproved {
-		for i, playerID := range event.Players {
-			if playerID == userID {
-				event.Players = append(event.Players[:i], event.Players[i+1:]...)
-				event.Remaining++
-				break
+// register создает регистрацию; checkLevel == false - запись по решению администратора
+// (уровень и ограничения записи не проверяются)
+func (s *eventService) register(ctx context.Context, eventID EventID, userID int64, level user.Level, checkLevel bool) error {
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		// Записаться можно только на опубликованное и еще не начавшееся событие
+		if !event.IsOpen(time.Now()) {
+			return ErrEventNotOpen
+		}
+
+		if checkLevel {
+			if err := s.checkRestriction(ctx, userID, event.LocationID); err != nil {
+				return err
 			}
 		}
-	}
 
-	// Удаляем регистрацию
-	delete(event.Registrations, userID)
-	event.UpdatedAt = time.Now()
+		// Уровень проверяется под блокировкой, чтобы учесть параллельное изменение границ события
+		if checkLevel && !event.LevelAllows(level) {
+			return ErrLevelNotAllowed
+		}
+
+		// Проверяем, не зарегистрирован ли уже пользователь (в любом статусе)
+		if reg, exists := event.Registrations[userID]; exists {
+			if reg.Status.Active() {
+				return ErrUserAlreadyRegistered // Уже записан, в листе ожидания или собирает пару
+			}
+			// Если был rejected, можно зарегистрироваться снова
+		}
+
+		// В парной категории игрок становится капитаном команды, место займется, когда напарник примет приглашение
+		if event.Doubles {
+			event.Registrations[userID] = EventRegistration{
+				UserID:    userID,
+				Status:    RegistrationStatusAwaitingPartner,
+				Captain:   true,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			event.UpdatedAt = time.Now()
+			return nil
+		}
 
-	return s.repo.Save(ctx, event)
+		// Создаем регистрацию со статусом pending
+		reg := EventRegistration{
+			UserID:       userID,
+			Status:       RegistrationStatusPending,
+			PendingSince: time.Now(),
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		}
+
+		// Если мест нет или уже есть очередь, ставим в конец листа ожидания.
+		// Событие заблокировано на время транзакции, поэтому проверка мест атомарна
+		if event.Remaining <= 0 || len(event.Waitlist()) > 0 {
+			reg.Status = RegistrationStatusWaitlisted
+			reg.PendingSince = time.Time{}
+			reg.WaitlistPosition = len(event.Waitlist()) + 1
+		}
+
+		event.Registrations[userID] = reg
+		event.RecalculateCapacity()
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	return err
 }
 
-func (s *eventService) ApproveRegistration(ctx context.Context, eventID EventID, userID int64) error {
-	event, err := s.repo.GetByID(ctx, eventID)
+func (s *eventService) AcceptTeamInvite(ctx context.Context, eventID EventID, captainID, partnerID int64) error {
+	if captainID == partnerID {
+		return ErrCannotPartnerSelf
+	}
+	level, err := s.selfRegistrationLevel(ctx, partnerID)
 	if err != nil {
 		return err
 	}
-	if event == nil {
-		return ErrEventNotFound
+
+	_, err = s.repo.Update(ctx, eventID, func(event *Event) error {
+		if !event.IsOpen(time.Now()) {
+			return ErrEventNotOpen
+		}
+
+		captain, exists := event.Registrations[captainID]
+		if !exists || !event.Doubles || !captain.Captain || captain.Status != RegistrationStatusAwaitingPartner {
+			return ErrTeamInviteNotFound
+		}
+		if reg, exists := event.Registrations[partnerID]; exists && reg.Status.Active() {
+			return ErrUserAlreadyRegistered
+		}
+		if err := s.checkRestriction(ctx, partnerID, event.LocationID); err != nil {
+			return err
+		}
+		if !event.LevelAllows(level) {
+			return ErrLevelNotAllowed
+		}
+
+		// Команда занимает место целиком: обе регистрации получают общий статус и позицию в очереди
+		now := time.Now()
+		status := RegistrationStatusPending
+		pendingSince := now
+		position := 0
+		if event.Remaining <= 0 || len(event.Waitlist()) > 0 {
+			status = RegistrationStatusWaitlisted
+			pendingSince = time.Time{}
+			position = len(event.Waitlist()) + 1
+		}
+
+		captain.Status = status
+		captain.PendingSince = pendingSince
+		captain.WaitlistPosition = position
+		captain.PartnerID = partnerID
+		captain.UpdatedAt = now
+		event.Registrations[captainID] = captain
+
+		event.Registrations[partnerID] = EventRegistration{
+			UserID:           partnerID,
+			Status:           status,
+			PendingSince:     pendingSince,
+			WaitlistPosition: position,
+			PartnerID:        captainID,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+
+		event.RecalculateCapacity()
+		event.UpdatedAt = now
+		return nil
+	})
+	return err
+}
+
+func (s *eventService) MarkAttendance(ctx context.Context, eventID EventID, userID int64, attendance Attendance) (*Event, error) {
+	return s.repo.Update(ctx, eventID, func(event *Event) error {
+		if !event.AttendanceTracked() {
+			return ErrAttendanceNotTracked
+		}
+		reg, exists := event.Registrations[userID]
+		if !exists {
+			return ErrRegistrationNotFound
+		}
+		if reg.Status != RegistrationStatusApproved {
+			return ErrRegistrationNotApproved
+		}
+
+		reg.Attendance = attendance
+		reg.UpdatedAt = time.Now()
+		event.Registrations[userID] = reg
+		return nil
+	})
+}
+
+func (s *eventService) MarkUnmarkedNoShow(ctx context.Context, eventID EventID) (*Event, error) {
+	return s.repo.Update(ctx, eventID, func(event *Event) error {
+		if !event.AttendanceTracked() {
+			return ErrAttendanceNotTracked
+		}
+		for userID, reg := range event.Registrations {
+			if reg.Status != RegistrationStatusApproved || reg.Attendance != AttendanceUnknown {
+				continue
+			}
+			reg.Attendance = AttendanceNoShow
+			reg.UpdatedAt = time.Now()
+			event.Registrations[userID] = reg
+		}
+		return nil
+	})
+}
+
+func (s *eventService) CheckIn(ctx context.Context, eventID EventID, userID int64, now time.Time) (*Event, error) {
+	return s.repo.Update(ctx, eventID, func(event *Event) error {
+		if !event.CheckInOpen(now) {
+			return ErrCheckInClosed
+		}
+		reg, exists := event.Registrations[userID]
+		if !exists {
+			return ErrRegistrationNotFound
+		}
+		if reg.Status != RegistrationStatusApproved {
+			return ErrRegistrationNotApproved
+		}
+
+		reg.Attendance = AttendancePresent
+		if reg.CheckedInAt.IsZero() {
+			reg.CheckedInAt = now
+		}
+		reg.UpdatedAt = now
+		event.Registrations[userID] = reg
+		return nil
+	})
+}
+
+func (s *eventService) NoShowBlockedUntil(ctx context.Context, userID int64, now time.Time) (time.Time, error) {
+	if s.noShowPolicies == nil {
+		return time.Time{}, nil
+	}
+	policy, err := s.noShowPolicies.GetNoShowPolicy(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !policy.Enabled() {
+		return time.Time{}, nil
 	}
 
-	// Проверяем, существует ли регистрация
-	reg, exists := event.Registrations[userID]
-	if !exists {
-		return ErrRegistrationNotFound
+	// Блокировка могла начаться не раньше, чем Window+BlockFor назад
+	noShows, err := s.repo.ListNoShowDates(ctx, userID, now.Add(-policy.Window-policy.BlockFor))
+	if err != nil {
+		return time.Time{}, err
 	}
+	return policy.BlockedUntil(noShows, now), nil
+}
 
-	// Проверяем статус
-	if reg.Status == RegistrationStatusApproved {
-		return ErrRegistrationAlreadyApproved
+func (s *eventService) UnregisterUser(ctx context.Context, eventID EventID, userID int64) ([]EventRegistration, bool, error) {
+	var global CancellationPolicy
+	if s.cancelPolicies != nil {
+		var err error
+		if global, err = s.cancelPolicies.GetCancellationPolicy(ctx); err != nil {
+			return nil, false, err
+		}
 	}
-	if reg.Status == RegistrationStatusRejected {
-		return errors.New("cannot approve rejected registration")
+
+	var promoted []EventRegistration
+	var late bool
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		// Проверяем, существует ли регистрация
+		reg, exists := event.Registrations[userID]
+		if !exists || !reg.Status.Active() {
+			return ErrRegistrationNotFound
+		}
+
+		// Срок отмены важен только для занятого места: лист ожидания и незавершенную пару можно покинуть всегда
+		now := time.Now()
+		policy := event.CancellationPolicy(global)
+		late = reg.Status.HoldsSpot() && policy.IsLate(event.Date, now)
+		if late && policy.Late == LateCancelBlock {
+			return ErrLateCancelBlocked
+		}
+
+		// Удаляем регистрацию (вместе с напарником) и отдаем освободившееся место первому в очереди.
+		// Поздняя отмена остается в истории: она учитывается наравне с неявками
+		for _, memberID := range event.TeamMembers(userID) {
+			if !late {
+				delete(event.Registrations, memberID)
+				continue
+			}
+			member := event.Registrations[memberID]
+			member.Status = RegistrationStatusLateCancelled
+			member.WaitlistPosition = 0
+			member.UpdatedAt = now
+			event.Registrations[memberID] = member
+		}
+		// Гости приходят только вместе с игроком
+		if late {
+			releaseGuests(event, userID, RegistrationStatusLateCancelled)
+		} else {
+			releaseGuests(event, userID, "")
+		}
+		event.RecalculateCapacity()
+		promoted = promoteWaitlist(event)
+		event.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
 	}
+	return promoted, late, nil
+}
 
-	// Проверяем, есть ли свободные места
-	if event.Remaining <= 0 {
-		return ErrEventFull
+func (s *eventService) CancellationPolicy(ctx context.Context, evt *Event) (CancellationPolicy, error) {
+	var global CancellationPolicy
+	if s.cancelPolicies != nil {
+		var err error
+		if global, err = s.cancelPolicies.GetCancellationPolicy(ctx); err != nil {
+			return evt.CancellationPolicy(CancellationPolicy{}), err
+		}
 	}
+	return evt.CancellationPolicy(global), nil
+}
 
-	// Обновляем статус регистрации
-	reg.Status = RegistrationStatusApproved
-	reg.UpdatedAt = time.Now()
-	event.Registrations[userID] = reg
+func (s *eventService) AddGuest(ctx context.Context, eventID EventID, hostID int64, name string) (*Guest, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrGuestNameRequired
+	}
 
-	// Добавляем пользователя в список подтвержденных игроков
-	event.Players = append(event.Players, userID)
-	event.Remaining--
-	event.UpdatedAt = time.Now()
+	var guest Guest
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		if !event.IsOpen(time.Now()) {
+			return ErrEventNotOpen
+		}
+		// В парной категории места считаются командами, гость в них не вписывается
+		if event.Doubles {
+			return ErrGuestsNotAllowed
+		}
+		if reg, exists := event.Registrations[hostID]; !exists || !reg.Status.HoldsSpot() {
+			return ErrHostNotRegistered
+		}
+		if len(event.ActiveGuestsOf(hostID)) >= MaxGuestsPerHost {
+			return ErrGuestLimit
+		}
+		// Гость не встает в лист ожидания и не обгоняет тех, кто уже в нем
+		if event.Remaining <= 0 || len(event.Waitlist()) > 0 {
+			return ErrEventFull
+		}
 
-	return s.repo.Save(ctx, event)
+		now := time.Now()
+		guest = Guest{
+			ID:        GuestID(uuid.New().String()[:8]),
+			HostID:    hostID,
+			Name:      name,
+			Status:    RegistrationStatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if event.Guests == nil {
+			event.Guests = make(map[GuestID]Guest)
+		}
+		event.Guests[guest.ID] = guest
+		event.RecalculateCapacity()
+		event.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &guest, nil
 }
 
-func (s *eventService) RejectRegistration(ctx context.Context, eventID EventID, userID int64) error {
-	event, err := s.repo.GetByID(ctx, eventID)
-	if err != nil {
-		return err
+func (s *eventService) RemoveGuest(ctx context.Context, eventID EventID, hostID int64, guestID GuestID) ([]EventRegistration, bool, error) {
+	var global CancellationPolicy
+	if s.cancelPolicies != nil {
+		var err error
+		if global, err = s.cancelPolicies.GetCancellationPolicy(ctx); err != nil {
+			return nil, false, err
+		}
 	}
-	if event == nil {
-		return ErrEventNotFound
+
+	var promoted []EventRegistration
+	var late bool
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		guest, exists := event.Guests[guestID]
+		if !exists || guest.HostID != hostID || !guest.Status.HoldsSpot() {
+			return ErrGuestNotFound
+		}
+
+		now := time.Now()
+		policy := event.CancellationPolicy(global)
+		late = policy.IsLate(event.Date, now)
+		if late && policy.Late == LateCancelBlock {
+			return ErrLateCancelBlocked
+		}
+
+		if late {
+			guest.Status = RegistrationStatusLateCancelled
+			guest.UpdatedAt = now
+			event.Guests[guestID] = guest
+		} else {
+			delete(event.Guests, guestID)
+		}
+		event.RecalculateCapacity()
+		promoted = promoteWaitlist(event)
+		event.UpdatedAt = now
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
 	}
+	return promoted, late, nil
+}
 
-	// Проверяем, существует ли регистрация
-	reg, exists := event.Registrations[userID]
-	if !exists {
-		return ErrRegistrationNotFound
+func (s *eventService) ApproveGuest(ctx context.Context, eventID EventID, guestID GuestID) (*Guest, error) {
+	var guest Guest
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		var exists bool
+		guest, exists = event.Guests[guestID]
+		if !exists {
+			return ErrGuestNotFound
+		}
+		switch guest.Status {
+		case RegistrationStatusPending:
+		case RegistrationStatusApproved:
+			return ErrRegistrationAlreadyApproved
+		case RegistrationStatusRejected:
+			return ErrRegistrationAlreadyRejected
+		default:
+			return ErrGuestNotFound
+		}
+
+		guest.Status = RegistrationStatusApproved
+		guest.UpdatedAt = time.Now()
+		event.Guests[guestID] = guest
+		event.RecalculateCapacity()
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &guest, nil
+}
 
-	// Проверяем статус
-	if reg.Status == RegistrationStatusRejected {
-		return ErrRegistrationAlreadyRejected
+func (s *eventService) AnonymizeGuests(ctx context.Context, hostID int64) error {
+	events, err := s.repo.List(ctx)
+	if err != nil {
+		return err
 	}
-	if reg.Status == RegistrationStatusApproved {
-		// Если был подтвержден, нужно убрать из списка игроков
-		for i, playerID := range event.Players {
-			if playerID == userID {
-				event.Players = append(event.Players[:i], event.Players[i+1:]...)
-				event.Remaining++
-				break
+	for i := range events {
+		if len(events[i].GuestsOf(hostID)) == 0 {
+			continue
+		}
+		_, err := s.repo.Update(ctx, events[i].ID, func(event *Event) error {
+			for id, guest := range event.Guests {
+				if guest.HostID != hostID || guest.Name == AnonymizedGuestName {
+					continue
+				}
+				guest.Name = AnonymizedGuestName
+				guest.UpdatedAt = time.Now()
+				event.Guests[id] = guest
 			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *eventService) RejectGuest(ctx context.Context, eventID EventID, guestID GuestID) (*Guest, []EventRegistration, error) {
+	var guest Guest
+	var promoted []EventRegistration
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		var exists bool
+		guest, exists = event.Guests[guestID]
+		if !exists {
+			return ErrGuestNotFound
+		}
+		if guest.Status == RegistrationStatusRejected {
+			return ErrRegistrationAlreadyRejected
 		}
+		if !guest.Status.HoldsSpot() {
+			return ErrGuestNotFound
+		}
+
+		guest.Status = RegistrationStatusRejected
+		guest.UpdatedAt = time.Now()
+		event.Guests[guestID] = guest
+		event.RecalculateCapacity()
+		promoted = promoteWaitlist(event)
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return &guest, promoted, nil
+}
+
+func (s *eventService) ApproveRegistration(ctx context.Context, eventID EventID, userID int64) error {
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		// Проверяем, существует ли регистрация
+		reg, exists := event.Registrations[userID]
+		if !exists {
+			return ErrRegistrationNotFound
+		}
+
+		// Проверяем статус
+		if reg.Status == RegistrationStatusApproved {
+			return ErrRegistrationAlreadyApproved
+		}
+		if reg.Status == RegistrationStatusRejected {
+			return errors.New("cannot approve rejected registration")
+		}
+		if reg.Status == RegistrationStatusAwaitingPartner {
+			return ErrTeamIncomplete
+		}
+		// Поздняя отмена остается в истории как штрафная: вернуться игрок может только новой заявкой
+		if reg.Status == RegistrationStatusLateCancelled {
+			return ErrRegistrationLateCancelled
+		}
+
+		// Pending уже занимает место; из листа ожидания или после снятой брони можно подтвердить только при наличии мест
+		if !reg.Status.HoldsSpot() && event.Remaining <= 0 {
+			return ErrEventFull
+		}
+
+		// Обновляем статус регистрации (в парной категории - у обоих игроков команды)
+		setTeamStatus(event, userID, RegistrationStatusApproved)
+
+		renumberWaitlist(event)
+		event.RecalculateCapacity()
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	return err
+}
+
+func (s *eventService) ConfirmPayment(ctx context.Context, eventID EventID, userID int64) error {
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		reg, exists := event.Registrations[userID]
+		if !exists {
+			return ErrRegistrationNotFound
+		}
+
+		switch reg.Status {
+		case RegistrationStatusApproved:
+			return ErrRegistrationAlreadyApproved
+		case RegistrationStatusRejected:
+			return ErrRegistrationAlreadyRejected
+		case RegistrationStatusAwaitingPartner:
+			return ErrTeamIncomplete
+		case RegistrationStatusPending:
+			// Место уже удерживается бронью
+		default:
+			// Бронь снята или игрок в листе ожидания: оплата дает место, только если оно есть
+			if event.Remaining <= 0 {
+				return ErrEventFull
+			}
+		}
+
+		setTeamStatus(event, userID, RegistrationStatusApproved)
+
+		renumberWaitlist(event)
+		event.RecalculateCapacity()
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	return err
+}
 
-	// Обновляем статус регистрации
-	reg.Status = RegistrationStatusRejected
-	reg.UpdatedAt = time.Now()
-	event.Registrations[userID] = reg
-	event.UpdatedAt = time.Now()
+func (s *eventService) ApplyDiscount(ctx context.Context, eventID EventID, userID int64, discount int) (*Event, error) {
+	return s.repo.Update(ctx, eventID, func(event *Event) error {
+		reg, exists := event.Registrations[userID]
+		if !exists {
+			return ErrRegistrationNotFound
+		}
+
+		// Скидка меняет сумму к оплате, поэтому применяется только до подтверждения
+		switch reg.Status {
+		case RegistrationStatusApproved:
+			return ErrRegistrationAlreadyApproved
+		case RegistrationStatusPending, RegistrationStatusWaitlisted, RegistrationStatusAwaitingPartner:
+		default:
+			return ErrRegistrationNotPayable
+		}
+
+		// Проверяем под блокировкой версии: параллельно мог примениться другой промокод
+		if reg.Discount > 0 {
+			return ErrDiscountAlreadyApplied
+		}
+
+		if discount > event.Price {
+			discount = event.Price
+		}
+		reg.Discount = discount
+		reg.UpdatedAt = time.Now()
+		event.Registrations[userID] = reg
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+func (s *eventService) RejectRegistration(ctx context.Context, eventID EventID, userID int64) ([]EventRegistration, error) {
+	var promoted []EventRegistration
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		// Проверяем, существует ли регистрация
+		reg, exists := event.Registrations[userID]
+		if !exists {
+			return ErrRegistrationNotFound
+		}
 
-	return s.repo.Save(ctx, event)
+		// Проверяем статус
+		if reg.Status == RegistrationStatusRejected {
+			return ErrRegistrationAlreadyRejected
+		}
+
+		// Обновляем статус регистрации (в парной категории - у обоих игроков команды)
+		setTeamStatus(event, userID, RegistrationStatusRejected)
+		releaseGuests(event, userID, RegistrationStatusRejected)
+
+		renumberWaitlist(event)
+		event.RecalculateCapacity()
+		promoted = promoteWaitlist(event)
+		event.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+func (s *eventService) PromoteWaitlist(ctx context.Context, eventID EventID) ([]EventRegistration, error) {
+	var promoted []EventRegistration
+	_, err := s.repo.Update(ctx, eventID, func(event *Event) error {
+		event.RecalculateCapacity()
+		promoted = promoteWaitlist(event)
+		if len(promoted) > 0 {
+			event.UpdatedAt = time.Now()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promoted, nil
 }
 
 func (s *eventService) ListPendingRegistrations(ctx context.Context, eventID EventID) ([]EventRegistration, error) {
@@ -306,12 +1072,184 @@ func (s *eventService) ListPendingRegistrations(ctx context.Context, eventID Eve
 		return nil, ErrEventNotFound
 	}
 
+	// В парной категории на модерацию выводятся команды (регистрации капитанов)
 	var pending []EventRegistration
 	for _, reg := range event.Registrations {
-		if reg.Status == RegistrationStatusPending {
+		if reg.Status == RegistrationStatusPending && event.TeamHead(reg) {
 			pending = append(pending, reg)
 		}
 	}
 
 	return pending, nil
 }
+
+func (s *eventService) ExpirePendingRegistrations(ctx context.Context, now time.Time, defaultTimeout time.Duration) ([]ExpiredHolds, error) {
+	events, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ExpiredHolds
+	for i := range events {
+		if events[i].Status != StatusPublished || !hasExpiredHolds(&events[i], now, defaultTimeout) {
+			continue
+		}
+
+		// Перепроверяем брони под блокировкой: игрока могли подтвердить, пока шел обход
+		var expired, promoted []EventRegistration
+		event, err := s.repo.Update(ctx, events[i].ID, func(event *Event) error {
+			for userID, reg := range event.Registrations {
+				deadline, ok := event.HoldDeadline(reg, defaultTimeout)
+				if !ok || now.Before(deadline) {
+					continue
+				}
+				reg.Status = RegistrationStatusExpired
+				reg.UpdatedAt = now
+				event.Registrations[userID] = reg
+				releaseGuests(event, userID, RegistrationStatusExpired)
+				expired = append(expired, reg)
+			}
+			if len(expired) == 0 {
+				return nil
+			}
+
+			event.RecalculateCapacity()
+			promoted = promoteWaitlist(event)
+			event.UpdatedAt = now
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		if len(expired) == 0 {
+			continue
+		}
+		result = append(result, ExpiredHolds{Event: event, Expired: expired, Promoted: promoted})
+	}
+
+	return result, nil
+}
+
+func (s *eventService) DuePaymentReminders(ctx context.Context, now time.Time, defaultTimeout, lead time.Duration) ([]PaymentReminder, error) {
+	if lead <= 0 {
+		return nil, nil
+	}
+
+	events, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PaymentReminder
+	for i := range events {
+		if events[i].Status != StatusPublished || len(dueReminders(&events[i], now, defaultTimeout, lead)) == 0 {
+			continue
+		}
+
+		var due []PaymentReminder
+		_, err := s.repo.Update(ctx, events[i].ID, func(event *Event) error {
+			due = dueReminders(event, now, defaultTimeout, lead)
+			// Отмечаем заранее, чтобы при повторном запуске напоминание не ушло дважды
+			for _, r := range due {
+				reg := event.Registrations[r.Registration.UserID]
+				reg.ReminderSentAt = now
+				event.Registrations[reg.UserID] = reg
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		result = append(result, due...)
+	}
+
+	return result, nil
+}
+
+// hasExpiredHolds сообщает, есть ли у события pending-регистрации с истекшей бронью
+func hasExpiredHolds(event *Event, now time.Time, defaultTimeout time.Duration) bool {
+	for _, reg := range event.Registrations {
+		if deadline, ok := event.HoldDeadline(reg, defaultTimeout); ok && !now.Before(deadline) {
+			return true
+		}
+	}
+	return false
+}
+
+// dueReminders возвращает pending-регистрации, которым пора напомнить об оплате
+func dueReminders(event *Event, now time.Time, defaultTimeout, lead time.Duration) []PaymentReminder {
+	var due []PaymentReminder
+	for _, reg := range event.Registrations {
+		deadline, ok := event.HoldDeadline(reg, defaultTimeout)
+		if !ok || !reg.ReminderSentAt.IsZero() {
+			continue
+		}
+		if now.Before(deadline.Add(-lead)) || !now.Before(deadline) {
+			continue
+		}
+		due = append(due, PaymentReminder{Event: event, Registration: reg, Deadline: deadline})
+	}
+	return due
+}
+
+// promoteWaitlist переводит игроков из начала листа ожидания в pending, пока есть свободные места
+func promoteWaitlist(event *Event) []EventRegistration {
+	var promoted []EventRegistration
+	for _, reg := range event.Waitlist() {
+		if event.Remaining <= 0 {
+			break
+		}
+		// Бронь команды начинается одновременно, чтобы оба игрока снимались по одному сроку
+		now := time.Now()
+		for _, memberID := range event.TeamMembers(reg.UserID) {
+			member := event.Registrations[memberID]
+			member.Status = RegistrationStatusPending
+			member.WaitlistPosition = 0
+			member.PendingSince = now
+			member.ReminderSentAt = time.Time{}
+			member.UpdatedAt = now
+			event.Registrations[memberID] = member
+			promoted = append(promoted, member)
+		}
+		event.RecalculateCapacity()
+	}
+	if len(promoted) > 0 {
+		renumberWaitlist(event)
+	}
+	return promoted
+}
+
+// renumberWaitlist восстанавливает непрерывную нумерацию позиций в листе ожидания
+func renumberWaitlist(event *Event) {
+	for i, reg := range event.Waitlist() {
+		for _, memberID := range event.TeamMembers(reg.UserID) {
+			member := event.Registrations[memberID]
+			member.WaitlistPosition = i + 1
+			event.Registrations[memberID] = member
+		}
+	}
+}
+
+// setTeamStatus переводит регистрацию игрока и его напарника в статус status
+func setTeamStatus(event *Event, userID int64, status RegistrationStatus) {
+	for _, memberID := range event.TeamMembers(userID) {
+		member := event.Registrations[memberID]
+		member.Status = status
+		member.WaitlistPosition = 0
+		member.UpdatedAt = time.Now()
+		event.Registrations[memberID] = member
+	}
+}
+
+// releaseGuests снимает гостей игрока, которые занимают место: переводит в status или удаляет, если status пустой
+func releaseGuests(event *Event, hostID int64, status RegistrationStatus) {
+	for _, guest := range event.ActiveGuestsOf(hostID) {
+		if status == "" {
+			delete(event.Guests, guest.ID)
+			continue
+		}
+		guest.Status = status
+		guest.UpdatedAt = time.Now()
+		event.Guests[guest.ID] = guest
+	}
+}
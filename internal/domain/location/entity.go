@@ -10,3 +10,12 @@ type Location struct {
 	Description   string
 	AddressMapURL string
 }
+
+type CourtID string
+
+// Court - корт локации, который события бронируют на время проведения
+type Court struct {
+	ID         CourtID
+	LocationID LocationID
+	Name       string
+}
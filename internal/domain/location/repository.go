@@ -15,6 +15,15 @@ type LocationRepository interface {
 
 	// Delete удаляет локацию по ID (если пригодится админский функционал).
 	Delete(ctx context.Context, id LocationID) error
+
+	// ListCourts возвращает корты локации в порядке добавления.
+	ListCourts(ctx context.Context, locationID LocationID) ([]Court, error)
+
+	// SaveCourt создаёт или обновляет корт.
+	SaveCourt(ctx context.Context, court *Court) error
+
+	// DeleteCourt удаляет корт по ID.
+	DeleteCourt(ctx context.Context, id CourtID) error
 }
 
 // Scanner интерфейс для сканирования локаций
@@ -3,6 +3,7 @@ package location
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -14,8 +15,19 @@ type LocationService interface {
 	Create(ctx context.Context, input CreateLocationInput) (*Location, error)
 	Update(ctx context.Context, id LocationID, input UpdateLocationInput) (*Location, error)
 	Delete(ctx context.Context, id LocationID) error
+
+	// Корты локации
+	ListCourts(ctx context.Context, locationID LocationID) ([]Court, error)
+	AddCourt(ctx context.Context, locationID LocationID, name string) (*Court, error)
+	DeleteCourt(ctx context.Context, id CourtID) error
 }
 
+var (
+	ErrCourtNameRequired = errors.New("court name is required")
+	ErrCourtNameTaken    = errors.New("court with this name already exists at the location")
+	ErrLocationNotFound  = errors.New("location not found")
+)
+
 // DTO для создания/обновления — чтобы не таскать всю структуру.
 type CreateLocationInput struct {
 	Name          string
@@ -100,6 +112,48 @@ func (s *locationService) Delete(ctx context.Context, id LocationID) error {
 	return s.repo.Delete(ctx, id)
 }
 
+func (s *locationService) ListCourts(ctx context.Context, locationID LocationID) ([]Court, error) {
+	return s.repo.ListCourts(ctx, locationID)
+}
+
+func (s *locationService) AddCourt(ctx context.Context, locationID LocationID, name string) (*Court, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrCourtNameRequired
+	}
+	loc, err := s.repo.GetByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	courts, err := s.repo.ListCourts(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range courts {
+		if strings.EqualFold(c.Name, name) {
+			return nil, ErrCourtNameTaken
+		}
+	}
+
+	court := &Court{
+		ID:         CourtID(uuid.New().String()),
+		LocationID: locationID,
+		Name:       name,
+	}
+	if err := s.repo.SaveCourt(ctx, court); err != nil {
+		return nil, err
+	}
+	return court, nil
+}
+
+func (s *locationService) DeleteCourt(ctx context.Context, id CourtID) error {
+	return s.repo.DeleteCourt(ctx, id)
+}
+
 func generateID() LocationID {
 	return LocationID(uuid.New().String())
 }
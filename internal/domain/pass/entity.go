@@ -0,0 +1,104 @@
+package pass
+
+import (
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+)
+
+// PassID - тип для ID абонемента
+type PassID string
+
+// Pass - абонемент игрока на несколько занятий
+type Pass struct {
+	ID          PassID
+	UserID      int64
+	Sessions    int                   // Сколько занятий в абонементе
+	ValidFrom   time.Time             // Начало срока действия
+	ValidUntil  time.Time             // Конец срока действия (не включительно)
+	EventTypes  []event.EventType     // Типы событий, пусто - любые
+	LocationIDs []location.LocationID // Локации, пусто - любые
+	IssuedBy    int64                 // Кто выдал абонемент
+	CreatedAt   time.Time
+	Usages      []Usage // Списанные занятия
+}
+
+// Usage - занятие, списанное с абонемента
+type Usage struct {
+	EventID   event.EventID
+	CreatedAt time.Time
+}
+
+// Remaining возвращает количество оставшихся занятий
+func (p *Pass) Remaining() int {
+	if left := p.Sessions - len(p.Usages); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// ValidAt проверяет, действует ли абонемент в указанный момент
+func (p *Pass) ValidAt(at time.Time) bool {
+	return !at.Before(p.ValidFrom) && at.Before(p.ValidUntil)
+}
+
+// Active проверяет, что абонементом еще можно пользоваться
+func (p *Pass) Active(now time.Time) bool {
+	return p.Remaining() > 0 && now.Before(p.ValidUntil)
+}
+
+// UsedFor проверяет, списано ли с абонемента занятие за событие
+func (p *Pass) UsedFor(eventID event.EventID) bool {
+	for _, u := range p.Usages {
+		if u.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers проверяет, что абонементом можно оплатить событие: оно проходит в срок действия,
+// подходит по типу и локации
+func (p *Pass) Covers(evt *event.Event) bool {
+	if !p.ValidAt(evt.Date) {
+		return false
+	}
+	if len(p.EventTypes) > 0 && !containsType(p.EventTypes, evt.Type) {
+		return false
+	}
+	if len(p.LocationIDs) > 0 && !containsLocation(p.LocationIDs, evt.LocationID) {
+		return false
+	}
+	return true
+}
+
+func containsType(types []event.EventType, t event.EventType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLocation(ids []location.LocationID, id location.LocationID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrPassNotFound     = errors.New("pass not found")
+	ErrSessionsInvalid  = errors.New("pass sessions must be positive")
+	ErrValidityInvalid  = errors.New("pass validity must be positive")
+	ErrNoEligiblePass   = errors.New("no eligible pass")
+	ErrPassExhausted    = errors.New("pass has no sessions left")
+	ErrAlreadyCharged   = errors.New("session already charged for event")
+	ErrPassHasUsages    = errors.New("pass has charged sessions")
+	ErrEventTypeInvalid = errors.New("unknown event type")
+)
@@ -0,0 +1,29 @@
+package pass
+
+import (
+	"context"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Repository описывает хранилище абонементов
+type Repository interface {
+	// Save создает абонемент
+	Save(ctx context.Context, p *Pass) error
+
+	// Get возвращает абонемент со списанными занятиями или nil, если его нет
+	Get(ctx context.Context, id PassID) (*Pass, error)
+
+	// ListByUser возвращает абонементы игрока в порядке выдачи
+	ListByUser(ctx context.Context, userID int64) ([]Pass, error)
+
+	// FindByUsage возвращает абонемент, с которого списано занятие игрока за событие, или nil
+	FindByUsage(ctx context.Context, eventID event.EventID, userID int64) (*Pass, error)
+
+	// Update изменяет абонемент под блокировкой: fn получает актуальное состояние,
+	// изменения списанных занятий сохраняются, если fn вернула nil
+	Update(ctx context.Context, id PassID, fn func(p *Pass) error) (*Pass, error)
+
+	// Delete удаляет абонемент
+	Delete(ctx context.Context, id PassID) error
+}
@@ -0,0 +1,194 @@
+package pass
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг абонементов
+type Service interface {
+	// Issue выдает игроку абонемент; by - администратор, который его выдает
+	Issue(ctx context.Context, by int64, in IssueInput) (*Pass, error)
+	// Get возвращает абонемент или nil, если его нет
+	Get(ctx context.Context, id PassID) (*Pass, error)
+	// ListByUser возвращает абонементы игрока
+	ListByUser(ctx context.Context, userID int64) ([]Pass, error)
+	// Delete аннулирует абонемент, с которого еще не списано ни одного занятия
+	Delete(ctx context.Context, id PassID) (*Pass, error)
+
+	// Eligible возвращает абонемент, которым можно оплатить событие, или nil
+	Eligible(ctx context.Context, evt *event.Event, userID int64) (*Pass, error)
+	// Charge списывает занятие за событие с подходящего абонемента (сначала с того, что раньше истекает)
+	Charge(ctx context.Context, evt *event.Event, userID int64) (*Pass, error)
+	// Charged возвращает абонемент, с которого списано занятие за событие, или nil
+	Charged(ctx context.Context, eventID event.EventID, userID int64) (*Pass, error)
+	// Return возвращает занятие за событие на абонемент; nil - занятие не списывалось
+	Return(ctx context.Context, eventID event.EventID, userID int64) (*Pass, error)
+}
+
+// IssueInput - DTO для выдачи абонемента
+type IssueInput struct {
+	UserID      int64
+	Sessions    int
+	ValidFrom   time.Time
+	ValidDays   int
+	EventTypes  []event.EventType     // Пусто - любые
+	LocationIDs []location.LocationID // Пусто - любые
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Issue(ctx context.Context, by int64, in IssueInput) (*Pass, error) {
+	if in.Sessions <= 0 {
+		return nil, ErrSessionsInvalid
+	}
+	if in.ValidDays <= 0 {
+		return nil, ErrValidityInvalid
+	}
+	for _, t := range in.EventTypes {
+		if t != event.EventTypeTraining && t != event.EventTypeCompetition {
+			return nil, ErrEventTypeInvalid
+		}
+	}
+
+	// Абонемент действует до конца последнего дня срока
+	y, m, d := in.ValidFrom.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, in.ValidFrom.Location())
+	p := &Pass{
+		ID:          PassID(uuid.New().String()),
+		UserID:      in.UserID,
+		Sessions:    in.Sessions,
+		ValidFrom:   start,
+		ValidUntil:  start.AddDate(0, 0, in.ValidDays),
+		EventTypes:  in.EventTypes,
+		LocationIDs: in.LocationIDs,
+		IssuedBy:    by,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.Save(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) Get(ctx context.Context, id PassID) (*Pass, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) ListByUser(ctx context.Context, userID int64) ([]Pass, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Delete(ctx context.Context, id PassID) (*Pass, error) {
+	p, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPassNotFound
+	}
+	// Списанные занятия уже учтены в оплатах, такой абонемент не удаляем
+	if len(p.Usages) > 0 {
+		return nil, ErrPassHasUsages
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) Eligible(ctx context.Context, evt *event.Event, userID int64) (*Pass, error) {
+	candidates, err := s.candidates(ctx, evt, userID)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+	return &candidates[0], nil
+}
+
+func (s *service) Charge(ctx context.Context, evt *event.Event, userID int64) (*Pass, error) {
+	charged, err := s.repo.FindByUsage(ctx, evt.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if charged != nil {
+		return nil, ErrAlreadyCharged
+	}
+
+	candidates, err := s.candidates(ctx, evt, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		// Остаток проверяется под блокировкой: параллельная запись могла списать последнее занятие
+		updated, err := s.repo.Update(ctx, candidate.ID, func(p *Pass) error {
+			if p.UsedFor(evt.ID) {
+				return ErrAlreadyCharged
+			}
+			if p.Remaining() <= 0 {
+				return ErrPassExhausted
+			}
+			p.Usages = append(p.Usages, Usage{EventID: evt.ID, CreatedAt: time.Now()})
+			return nil
+		})
+		if errors.Is(err, ErrPassExhausted) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, ErrNoEligiblePass
+}
+
+func (s *service) Charged(ctx context.Context, eventID event.EventID, userID int64) (*Pass, error) {
+	return s.repo.FindByUsage(ctx, eventID, userID)
+}
+
+func (s *service) Return(ctx context.Context, eventID event.EventID, userID int64) (*Pass, error) {
+	charged, err := s.repo.FindByUsage(ctx, eventID, userID)
+	if err != nil || charged == nil {
+		return nil, err
+	}
+	return s.repo.Update(ctx, charged.ID, func(p *Pass) error {
+		usages := p.Usages[:0]
+		for _, u := range p.Usages {
+			if u.EventID != eventID {
+				usages = append(usages, u)
+			}
+		}
+		p.Usages = usages
+		return nil
+	})
+}
+
+// candidates возвращает абонементы игрока, которыми можно оплатить событие, в порядке истечения
+func (s *service) candidates(ctx context.Context, evt *event.Event, userID int64) ([]Pass, error) {
+	passes, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Pass
+	for _, p := range passes {
+		if p.Remaining() > 0 && p.Covers(evt) {
+			candidates = append(candidates, p)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ValidUntil.Before(candidates[j].ValidUntil)
+	})
+	return candidates, nil
+}
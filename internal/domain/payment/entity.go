@@ -0,0 +1,156 @@
+package payment
+
+import (
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Method - способ оплаты
+type Method string
+
+const (
+	MethodTransfer Method = "transfer" // Перевод по номеру телефона
+	MethodCash     Method = "cash"     // Наличные на площадке
+	MethodInvoice  Method = "invoice"  // Счет Telegram Payments (картой или звездами)
+	MethodPass     Method = "pass"     // Списание занятия с абонемента
+)
+
+// Valid проверяет, что способ оплаты известен
+func (m Method) Valid() bool {
+	switch m {
+	case MethodTransfer, MethodCash, MethodInvoice, MethodPass:
+		return true
+	}
+	return false
+}
+
+// Kind - вид операции в журнале оплат
+type Kind string
+
+const (
+	KindPayment Kind = "payment" // Оплата (в том числе частичная)
+	KindRefund  Kind = "refund"  // Возврат
+)
+
+// PaymentID - тип для ID записи журнала оплат
+type PaymentID string
+
+// Payment - запись журнала оплат регистрации
+type Payment struct {
+	ID          PaymentID
+	EventID     event.EventID
+	UserID      int64         // Участник, за чью регистрацию проведена операция
+	GuestID     event.GuestID // Гость участника, за которого проведена операция (пусто - за самого участника)
+	PayerID     int64         // Кто заплатил (обычно сам участник)
+	Kind        Kind
+	Method      Method
+	Amount      int    // Сумма в единицах цены события (рубли или звезды), всегда положительная
+	ConfirmedBy int64  // Кто подтвердил операцию (0 - подтверждено автоматически)
+	ChargeID    string // ID платежа в Telegram Payments (для счетов)
+	Comment     string
+	CreatedAt   time.Time
+}
+
+// Signed возвращает сумму со знаком: возвраты уменьшают оплаченное
+func (p Payment) Signed() int {
+	if p.Kind == KindRefund {
+		return -p.Amount
+	}
+	return p.Amount
+}
+
+// Status - состояние оплаты регистрации
+type Status string
+
+const (
+	StatusFree    Status = "free"    // Участие бесплатное
+	StatusUnpaid  Status = "unpaid"  // Ничего не оплачено
+	StatusPartial Status = "partial" // Оплачено частично
+	StatusPaid    Status = "paid"    // Оплачено полностью
+)
+
+// Balance - состояние оплаты регистрации по журналу
+type Balance struct {
+	Due  int // Стоимость участия
+	Paid int // Оплачено с учетом возвратов
+}
+
+// Owed возвращает, сколько осталось доплатить
+func (b Balance) Owed() int {
+	if b.Paid >= b.Due {
+		return 0
+	}
+	return b.Due - b.Paid
+}
+
+// Status возвращает состояние оплаты
+func (b Balance) Status() Status {
+	switch {
+	case b.Due <= 0 && b.Paid <= 0:
+		return StatusFree
+	case b.Paid <= 0:
+		return StatusUnpaid
+	case b.Paid < b.Due:
+		return StatusPartial
+	default:
+		return StatusPaid
+	}
+}
+
+// Balances считает состояние оплаты каждой регистрации события по журналу
+func Balances(evt *event.Event, payments []Payment) map[int64]Balance {
+	balances := make(map[int64]Balance, len(evt.Registrations))
+	for userID := range evt.Registrations {
+		balances[userID] = Balance{Due: evt.PriceFor(userID)}
+	}
+	for _, p := range payments {
+		if p.EventID != evt.ID || p.GuestID != "" {
+			continue
+		}
+		b, ok := balances[p.UserID]
+		if !ok {
+			// Оплата игрока, который уже отписался: стоимость участия с него не причитается
+			b = Balance{}
+		}
+		b.Paid += p.Signed()
+		balances[p.UserID] = b
+	}
+	return balances
+}
+
+// GuestBalances считает состояние оплаты каждого гостя события по журналу.
+// Участие гостя стоит полную цену события, оплаты за гостя записываются на регистрацию игрока, который его привел
+func GuestBalances(evt *event.Event, payments []Payment) map[event.GuestID]Balance {
+	balances := make(map[event.GuestID]Balance, len(evt.Guests))
+	for id := range evt.Guests {
+		balances[id] = Balance{Due: evt.Price}
+	}
+	for _, p := range payments {
+		if p.EventID != evt.ID || p.GuestID == "" {
+			continue
+		}
+		b := balances[p.GuestID]
+		b.Paid += p.Signed()
+		balances[p.GuestID] = b
+	}
+	return balances
+}
+
+// Debt - долг игрока за подтвержденное участие в событии (свое или гостя)
+type Debt struct {
+	Event   *event.Event
+	UserID  int64
+	GuestID event.GuestID // Пусто - долг за участие самого игрока
+	Balance Balance
+}
+
+var (
+	ErrAmountInvalid        = errors.New("payment amount must be positive")
+	ErrMethodInvalid        = errors.New("unknown payment method")
+	ErrRegistrationNotFound = errors.New("registration not found")
+	ErrGuestNotFound        = errors.New("guest not found")
+	ErrRefundExceedsPaid    = errors.New("refund exceeds paid amount")
+	ErrDuplicateCharge      = errors.New("payment with this charge id is already recorded")
+)
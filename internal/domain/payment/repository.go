@@ -0,0 +1,23 @@
+package payment
+
+import (
+	"context"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Repository описывает хранилище журнала оплат
+type Repository interface {
+	// Save добавляет запись в журнал; регистрация участника должна существовать.
+	// Если оплата с таким ChargeID уже записана, возвращает ErrDuplicateCharge
+	Save(ctx context.Context, p *Payment) error
+
+	// ListByEvent возвращает операции по регистрациям события в порядке проведения
+	ListByEvent(ctx context.Context, eventID event.EventID) ([]Payment, error)
+
+	// ListByEvents возвращает операции по регистрациям нескольких событий
+	ListByEvents(ctx context.Context, eventIDs []event.EventID) ([]Payment, error)
+
+	// ListByUser возвращает операции по регистрациям игрока (и те, где он платил за другого) в порядке проведения
+	ListByUser(ctx context.Context, userID int64) ([]Payment, error)
+}
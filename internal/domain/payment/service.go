@@ -0,0 +1,245 @@
+package payment
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг журнала оплат
+type Service interface {
+	// Record записывает оплату (в том числе частичную) регистрации на событие или гостя игрока.
+	// Повторная оплата с тем же ChargeID не записывается: возвращается ErrDuplicateCharge
+	Record(ctx context.Context, in RecordInput) (*Payment, error)
+	// Refund записывает возврат; вернуть можно не больше, чем оплачено
+	Refund(ctx context.Context, in RefundInput) (*Payment, error)
+	// ListByEvent возвращает журнал оплат события
+	ListByEvent(ctx context.Context, eventID event.EventID) ([]Payment, error)
+	// ListByUser возвращает операции игрока по всем событиям (для выгрузки персональных данных)
+	ListByUser(ctx context.Context, userID int64) ([]Payment, error)
+	// Balances возвращает состояние оплаты каждой регистрации события
+	Balances(ctx context.Context, evt *event.Event) (map[int64]Balance, error)
+	// GuestBalances возвращает состояние оплаты каждого гостя события
+	GuestBalances(ctx context.Context, evt *event.Event) (map[event.GuestID]Balance, error)
+	// Debtors возвращает подтвержденных игроков и гостей, не оплативших участие полностью, по всем неотмененным событиям
+	Debtors(ctx context.Context) ([]Debt, error)
+}
+
+// RecordInput - DTO для записи оплаты
+type RecordInput struct {
+	EventID     event.EventID
+	UserID      int64
+	GuestID     event.GuestID // Пусто - оплата за самого участника
+	PayerID     int64         // 0 - платит сам участник
+	Method      Method
+	Amount      int
+	ConfirmedBy int64 // 0 - подтверждено автоматически
+	ChargeID    string
+	Comment     string
+}
+
+// RefundInput - DTO для записи возврата
+type RefundInput struct {
+	EventID     event.EventID
+	UserID      int64
+	GuestID     event.GuestID // Пусто - возврат самому участнику
+	Method      Method
+	Amount      int
+	ConfirmedBy int64
+	Comment     string
+}
+
+type service struct {
+	repo         Repository
+	eventService event.EventService
+}
+
+func NewService(repo Repository, eventService event.EventService) Service {
+	return &service{repo: repo, eventService: eventService}
+}
+
+func (s *service) Record(ctx context.Context, in RecordInput) (*Payment, error) {
+	if in.Amount <= 0 {
+		return nil, ErrAmountInvalid
+	}
+	if !in.Method.Valid() {
+		return nil, ErrMethodInvalid
+	}
+
+	evt, err := s.eventService.Get(ctx, in.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if evt == nil {
+		return nil, event.ErrEventNotFound
+	}
+	if _, ok := evt.Registrations[in.UserID]; !ok {
+		return nil, ErrRegistrationNotFound
+	}
+	if in.GuestID != "" {
+		if guest, ok := evt.Guests[in.GuestID]; !ok || guest.HostID != in.UserID {
+			return nil, ErrGuestNotFound
+		}
+	}
+
+	payer := in.PayerID
+	if payer == 0 {
+		payer = in.UserID
+	}
+	p := &Payment{
+		ID:          PaymentID(uuid.New().String()),
+		EventID:     in.EventID,
+		UserID:      in.UserID,
+		GuestID:     in.GuestID,
+		PayerID:     payer,
+		Kind:        KindPayment,
+		Method:      in.Method,
+		Amount:      in.Amount,
+		ConfirmedBy: in.ConfirmedBy,
+		ChargeID:    in.ChargeID,
+		Comment:     in.Comment,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.Save(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) Refund(ctx context.Context, in RefundInput) (*Payment, error) {
+	if in.Amount <= 0 {
+		return nil, ErrAmountInvalid
+	}
+	if !in.Method.Valid() {
+		return nil, ErrMethodInvalid
+	}
+
+	// Вернуть можно и игроку, который уже отписался, поэтому считаем только по журналу
+	payments, err := s.repo.ListByEvent(ctx, in.EventID)
+	if err != nil {
+		return nil, err
+	}
+	var paid int
+	var found bool
+	for _, p := range payments {
+		if p.UserID == in.UserID && p.GuestID == in.GuestID {
+			paid += p.Signed()
+			found = true
+		}
+	}
+	if !found {
+		return nil, ErrRegistrationNotFound
+	}
+	if in.Amount > paid {
+		return nil, ErrRefundExceedsPaid
+	}
+
+	p := &Payment{
+		ID:          PaymentID(uuid.New().String()),
+		EventID:     in.EventID,
+		UserID:      in.UserID,
+		GuestID:     in.GuestID,
+		PayerID:     in.UserID,
+		Kind:        KindRefund,
+		Method:      in.Method,
+		Amount:      in.Amount,
+		ConfirmedBy: in.ConfirmedBy,
+		Comment:     in.Comment,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.Save(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) ListByEvent(ctx context.Context, eventID event.EventID) ([]Payment, error) {
+	return s.repo.ListByEvent(ctx, eventID)
+}
+
+func (s *service) ListByUser(ctx context.Context, userID int64) ([]Payment, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Balances(ctx context.Context, evt *event.Event) (map[int64]Balance, error) {
+	payments, err := s.repo.ListByEvent(ctx, evt.ID)
+	if err != nil {
+		return nil, err
+	}
+	return Balances(evt, payments), nil
+}
+
+func (s *service) GuestBalances(ctx context.Context, evt *event.Event) (map[event.GuestID]Balance, error) {
+	payments, err := s.repo.ListByEvent(ctx, evt.ID)
+	if err != nil {
+		return nil, err
+	}
+	return GuestBalances(evt, payments), nil
+}
+
+func (s *service) Debtors(ctx context.Context) ([]Debt, error) {
+	events, err := s.eventService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var chargeable []*event.Event
+	var ids []event.EventID
+	for i := range events {
+		evt := &events[i]
+		if evt.Price <= 0 || (evt.Status != event.StatusPublished && evt.Status != event.StatusCompleted) {
+			continue
+		}
+		chargeable = append(chargeable, evt)
+		ids = append(ids, evt.ID)
+	}
+	if len(chargeable) == 0 {
+		return nil, nil
+	}
+
+	payments, err := s.repo.ListByEvents(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var debts []Debt
+	for _, evt := range chargeable {
+		balances := Balances(evt, payments)
+		for userID, reg := range evt.Registrations {
+			if reg.Status != event.RegistrationStatusApproved {
+				continue
+			}
+			if b := balances[userID]; b.Owed() > 0 {
+				debts = append(debts, Debt{Event: evt, UserID: userID, Balance: b})
+			}
+		}
+		guestBalances := GuestBalances(evt, payments)
+		for id, guest := range evt.Guests {
+			if guest.Status != event.RegistrationStatusApproved {
+				continue
+			}
+			if b := guestBalances[id]; b.Owed() > 0 {
+				debts = append(debts, Debt{Event: evt, UserID: guest.HostID, GuestID: id, Balance: b})
+			}
+		}
+	}
+
+	// Сначала старые события, внутри события - по игроку, чтобы отчет не прыгал между открытиями
+	sort.Slice(debts, func(i, j int) bool {
+		if !debts[i].Event.Date.Equal(debts[j].Event.Date) {
+			return debts[i].Event.Date.Before(debts[j].Event.Date)
+		}
+		if debts[i].Event.ID != debts[j].Event.ID {
+			return debts[i].Event.ID < debts[j].Event.ID
+		}
+		if debts[i].UserID != debts[j].UserID {
+			return debts[i].UserID < debts[j].UserID
+		}
+		return debts[i].GuestID < debts[j].GuestID
+	})
+	return debts, nil
+}
@@ -0,0 +1,107 @@
+package promo
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Kind - вид скидки промокода
+type Kind string
+
+const (
+	KindPercent Kind = "percent" // Процент от стоимости
+	KindFixed   Kind = "fixed"   // Фиксированная сумма в единицах цены события
+)
+
+// CodeID - тип для ID промокода
+type CodeID string
+
+// Code - промокод на скидку при записи на событие
+type Code struct {
+	ID             CodeID
+	Code           string // Текст промокода в верхнем регистре
+	Kind           Kind
+	Value          int               // Процент или сумма скидки
+	MaxUses        int               // Сколько раз можно использовать всего (0 - без ограничений)
+	MaxUsesPerUser int               // Сколько раз может использовать один игрок (0 - без ограничений)
+	ExpiresAt      time.Time         // Когда перестает действовать (нулевое - бессрочно)
+	EventTypes     []event.EventType // Типы событий, пусто - любые
+	EventID        event.EventID     // Конкретное событие, пусто - любые
+	Active         bool
+	CreatedBy      int64
+	CreatedAt      time.Time
+	Uses           int // Количество действующих погашений (заполняется репозиторием)
+}
+
+// codePattern - допустимый текст промокода
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// Normalize приводит введенный промокод к виду, в котором он хранится
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Discount возвращает скидку для указанной стоимости (не больше самой стоимости)
+func (c *Code) Discount(price int) int {
+	discount := c.Value
+	if c.Kind == KindPercent {
+		discount = price * c.Value / 100
+	}
+	if discount > price {
+		return price
+	}
+	return discount
+}
+
+// Expired проверяет, истек ли срок действия промокода
+func (c *Code) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// Applies проверяет, действует ли промокод на событие
+func (c *Code) Applies(evt *event.Event) bool {
+	if c.EventID != "" && c.EventID != evt.ID {
+		return false
+	}
+	if len(c.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range c.EventTypes {
+		if t == evt.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// RedemptionID - тип для ID погашения промокода
+type RedemptionID string
+
+// Redemption - погашение промокода при записи на событие
+type Redemption struct {
+	ID        RedemptionID
+	CodeID    CodeID
+	EventID   event.EventID
+	UserID    int64
+	Discount  int // Скидка в единицах цены события
+	CreatedAt time.Time
+}
+
+var (
+	ErrCodeInvalid       = errors.New("promo code must be 3-32 latin letters, digits, '-' or '_'")
+	ErrCodeExists        = errors.New("promo code already exists")
+	ErrKindInvalid       = errors.New("unknown promo code kind")
+	ErrValueInvalid      = errors.New("promo code value must be positive")
+	ErrPercentTooHigh    = errors.New("promo code percent must not exceed 100")
+	ErrLimitInvalid      = errors.New("promo code limits must not be negative")
+	ErrCodeNotFound      = errors.New("promo code not found")
+	ErrCodeExpired       = errors.New("promo code expired")
+	ErrCodeNotApplicable = errors.New("promo code does not apply to event")
+	ErrCodeExhausted     = errors.New("promo code usage limit reached")
+	ErrCodeUserLimit     = errors.New("promo code per-user limit reached")
+	ErrAlreadyRedeemed   = errors.New("another promo code is already redeemed for this registration")
+)
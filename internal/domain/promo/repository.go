@@ -0,0 +1,33 @@
+package promo
+
+import (
+	"context"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Repository описывает хранилище промокодов и их погашений
+type Repository interface {
+	// Save сохраняет промокод
+	Save(ctx context.Context, c *Code) error
+
+	// Get возвращает промокод или nil, если его нет
+	Get(ctx context.Context, id CodeID) (*Code, error)
+
+	// GetByCode возвращает промокод по тексту или nil, если его нет
+	GetByCode(ctx context.Context, code string) (*Code, error)
+
+	// List возвращает все промокоды, новые первыми
+	List(ctx context.Context) ([]Code, error)
+
+	// Redeem погашает промокод под блокировкой: fn получает промокод и его действующие погашения
+	// и возвращает новое погашение (nil - ничего не сохранять). Если у игрока уже есть действующее погашение
+	// другого промокода за это событие, возвращает ErrAlreadyRedeemed
+	Redeem(ctx context.Context, id CodeID, fn func(c *Code, redemptions []Redemption) (*Redemption, error)) (*Redemption, error)
+
+	// ListRedemptions возвращает действующие погашения промокода в порядке погашения
+	ListRedemptions(ctx context.Context, id CodeID) ([]Redemption, error)
+
+	// Release отменяет погашение игрока за событие (запись сохраняется для отчетов); nil - погашения не было
+	Release(ctx context.Context, eventID event.EventID, userID int64) (*Redemption, error)
+}
@@ -0,0 +1,190 @@
+package promo
+
+import (
+	"context"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг промокодов
+type Service interface {
+	// Create создает промокод; by - администратор, который его создает
+	Create(ctx context.Context, by int64, in CreateInput) (*Code, error)
+	// Get возвращает промокод или nil, если его нет
+	Get(ctx context.Context, id CodeID) (*Code, error)
+	// List возвращает все промокоды
+	List(ctx context.Context) ([]Code, error)
+	// Deactivate отключает промокод; погашения сохраняются
+	Deactivate(ctx context.Context, id CodeID) (*Code, error)
+	// Redemptions возвращает действующие погашения промокода
+	Redemptions(ctx context.Context, id CodeID) ([]Redemption, error)
+
+	// Redeem проверяет условия промокода и погашает его для регистрации игрока на событие.
+	// Повторный ввод того же промокода для той же регистрации возвращает прежнее погашение
+	Redeem(ctx context.Context, code string, evt *event.Event, userID int64) (*Redemption, *Code, error)
+	// Release отменяет погашение, когда регистрация отменена или отклонена; nil - погашения не было
+	Release(ctx context.Context, eventID event.EventID, userID int64) (*Redemption, error)
+}
+
+// CreateInput - DTO для создания промокода
+type CreateInput struct {
+	Code           string
+	Kind           Kind
+	Value          int
+	MaxUses        int
+	MaxUsesPerUser int
+	ValidDays      int // 0 - бессрочно
+	EventTypes     []event.EventType
+	EventID        event.EventID
+}
+
+// Validate проверяет корректность данных промокода
+func (in CreateInput) Validate() error {
+	if !codePattern.MatchString(Normalize(in.Code)) {
+		return ErrCodeInvalid
+	}
+	if in.Kind != KindPercent && in.Kind != KindFixed {
+		return ErrKindInvalid
+	}
+	if in.Value <= 0 {
+		return ErrValueInvalid
+	}
+	if in.Kind == KindPercent && in.Value > 100 {
+		return ErrPercentTooHigh
+	}
+	if in.MaxUses < 0 || in.MaxUsesPerUser < 0 || in.ValidDays < 0 {
+		return ErrLimitInvalid
+	}
+	return nil
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Create(ctx context.Context, by int64, in CreateInput) (*Code, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+
+	text := Normalize(in.Code)
+	existing, err := s.repo.GetByCode(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrCodeExists
+	}
+
+	c := &Code{
+		ID:             CodeID(uuid.New().String()),
+		Code:           text,
+		Kind:           in.Kind,
+		Value:          in.Value,
+		MaxUses:        in.MaxUses,
+		MaxUsesPerUser: in.MaxUsesPerUser,
+		EventTypes:     in.EventTypes,
+		EventID:        in.EventID,
+		Active:         true,
+		CreatedBy:      by,
+		CreatedAt:      time.Now(),
+	}
+	if in.ValidDays > 0 {
+		c.ExpiresAt = c.CreatedAt.AddDate(0, 0, in.ValidDays)
+	}
+	if err := s.repo.Save(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) Get(ctx context.Context, id CodeID) (*Code, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *service) List(ctx context.Context) ([]Code, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) Deactivate(ctx context.Context, id CodeID) (*Code, error) {
+	c, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrCodeNotFound
+	}
+	c.Active = false
+	if err := s.repo.Save(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) Redemptions(ctx context.Context, id CodeID) ([]Redemption, error) {
+	return s.repo.ListRedemptions(ctx, id)
+}
+
+func (s *service) Redeem(ctx context.Context, code string, evt *event.Event, userID int64) (*Redemption, *Code, error) {
+	c, err := s.repo.GetByCode(ctx, Normalize(code))
+	if err != nil {
+		return nil, nil, err
+	}
+	if c == nil || !c.Active {
+		return nil, nil, ErrCodeNotFound
+	}
+	if c.Expired(time.Now()) {
+		return nil, nil, ErrCodeExpired
+	}
+	if evt.Price <= 0 || !c.Applies(evt) {
+		return nil, nil, ErrCodeNotApplicable
+	}
+
+	var existing *Redemption
+	created, err := s.repo.Redeem(ctx, c.ID, func(c *Code, redemptions []Redemption) (*Redemption, error) {
+		userUses := 0
+		for i := range redemptions {
+			if redemptions[i].UserID != userID {
+				continue
+			}
+			if redemptions[i].EventID == evt.ID {
+				existing = &redemptions[i]
+				return nil, nil
+			}
+			userUses++
+		}
+		// Лимиты проверяются под блокировкой промокода, чтобы параллельные погашения не превысили их
+		if c.MaxUses > 0 && len(redemptions) >= c.MaxUses {
+			return nil, ErrCodeExhausted
+		}
+		if c.MaxUsesPerUser > 0 && userUses >= c.MaxUsesPerUser {
+			return nil, ErrCodeUserLimit
+		}
+		return &Redemption{
+			ID:        RedemptionID(uuid.New().String()),
+			CodeID:    c.ID,
+			EventID:   evt.ID,
+			UserID:    userID,
+			Discount:  c.Discount(evt.Price),
+			CreatedAt: time.Now(),
+		}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if existing != nil {
+		return existing, c, nil
+	}
+	return created, c, nil
+}
+
+func (s *service) Release(ctx context.Context, eventID event.EventID, userID int64) (*Redemption, error) {
+	return s.repo.Release(ctx, eventID, userID)
+}
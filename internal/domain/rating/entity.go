@@ -0,0 +1,166 @@
+package rating
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/tournament"
+)
+
+const (
+	DefaultRating = 1500 // Стартовый рейтинг игрока без сыгранных матчей
+	KFactor       = 32   // Максимальное изменение рейтинга за один матч
+)
+
+// Entry - запись истории рейтинга: изменение рейтинга игрока по итогам одного матча
+type Entry struct {
+	Seq          int   // Порядковый номер матча в пересчете (одинаковый у всех игроков матча)
+	TelegramID   int64 // Игрок
+	EventID      event.EventID
+	TournamentID tournament.TournamentID
+	MatchID      int
+	Won          bool
+	Before       int // Рейтинг до матча
+	After        int // Рейтинг после матча
+	PlayedAt     time.Time
+}
+
+// Delta возвращает изменение рейтинга за матч
+func (e Entry) Delta() int {
+	return e.After - e.Before
+}
+
+// Result - итог одного сыгранного матча (в парной категории с каждой стороны по два игрока)
+type Result struct {
+	EventID      event.EventID
+	TournamentID tournament.TournamentID
+	MatchID      int
+	PlayedAt     time.Time
+	Winners      []int64
+	Losers       []int64
+}
+
+// Summary - текущий рейтинг игрока со статистикой
+type Summary struct {
+	TelegramID int64
+	Rating     int
+	Played     int
+	Won        int
+	Rank       int     // Место среди игроков с рейтингом (0 - игрок еще не играл)
+	Ranked     int     // Сколько всего игроков с рейтингом
+	History    []Entry // Изменения по матчам, от старых к новым
+}
+
+// Trend возвращает суммарное изменение рейтинга за последние n матчей
+func (s *Summary) Trend(n int) int {
+	if len(s.History) == 0 {
+		return 0
+	}
+	recent := s.Recent(n)
+	return recent[len(recent)-1].After - recent[0].Before
+}
+
+// Recent возвращает последние n записей истории
+func (s *Summary) Recent(n int) []Entry {
+	if n <= 0 || len(s.History) <= n {
+		return s.History
+	}
+	return s.History[len(s.History)-n:]
+}
+
+// ResultsFromTournament собирает итоги сыгранных матчей турнира (матчи со свободным проходом не учитываются)
+func ResultsFromTournament(t *tournament.Tournament, playedAt time.Time) []Result {
+	var results []Result
+	for _, m := range t.Matches {
+		if !m.Played() {
+			continue
+		}
+		winner, loser := t.Participant(m.Winner), t.Participant(m.Loser())
+		if winner == nil || loser == nil {
+			continue
+		}
+		results = append(results, Result{
+			EventID:      t.EventID,
+			TournamentID: t.ID,
+			MatchID:      m.ID,
+			PlayedAt:     playedAt,
+			Winners:      winner.UserIDs,
+			Losers:       loser.UserIDs,
+		})
+	}
+	return results
+}
+
+// Replay пересчитывает рейтинг с нуля по всем результатам и возвращает историю изменений.
+// Порядок матчей не зависит от порядка входных данных: по дате, затем по турниру и номеру матча
+func Replay(results []Result) []Entry {
+	ordered := append([]Result(nil), results...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if !a.PlayedAt.Equal(b.PlayedAt) {
+			return a.PlayedAt.Before(b.PlayedAt)
+		}
+		if a.TournamentID != b.TournamentID {
+			return a.TournamentID < b.TournamentID
+		}
+		return a.MatchID < b.MatchID
+	})
+
+	ratings := make(map[int64]int)
+	current := func(id int64) int {
+		if r, ok := ratings[id]; ok {
+			return r
+		}
+		return DefaultRating
+	}
+
+	var entries []Entry
+	for i, res := range ordered {
+		if len(res.Winners) == 0 || len(res.Losers) == 0 {
+			continue
+		}
+		winnersRating := teamRating(res.Winners, current)
+		losersRating := teamRating(res.Losers, current)
+		// Все игроки команды получают одинаковое изменение, рассчитанное по среднему рейтингу команд
+		delta := int(math.Round(KFactor * (1 - Expected(winnersRating, losersRating))))
+
+		for _, side := range []struct {
+			ids  []int64
+			won  bool
+			diff int
+		}{{res.Winners, true, delta}, {res.Losers, false, -delta}} {
+			for _, id := range side.ids {
+				before := current(id)
+				ratings[id] = before + side.diff
+				entries = append(entries, Entry{
+					Seq:          i + 1,
+					TelegramID:   id,
+					EventID:      res.EventID,
+					TournamentID: res.TournamentID,
+					MatchID:      res.MatchID,
+					Won:          side.won,
+					Before:       before,
+					After:        before + side.diff,
+					PlayedAt:     res.PlayedAt,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// Expected возвращает ожидаемый результат (вероятность победы) стороны с рейтингом a против рейтинга b по Эло
+func Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// teamRating возвращает средний рейтинг команды
+func teamRating(ids []int64, current func(int64) int) float64 {
+	sum := 0
+	for _, id := range ids {
+		sum += current(id)
+	}
+	return float64(sum) / float64(len(ids))
+}
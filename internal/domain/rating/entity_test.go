@@ -0,0 +1,115 @@
+package rating
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"pickletlgbot/internal/domain/tournament"
+)
+
+// finalRatings возвращает рейтинг каждого игрока после последнего матча истории
+func finalRatings(entries []Entry) map[int64]int {
+	ratings := make(map[int64]int)
+	for _, e := range entries {
+		ratings[e.TelegramID] = e.After
+	}
+	return ratings
+}
+
+func TestReplay(t *testing.T) {
+	day := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	result := func(tournamentID tournament.TournamentID, matchID int, playedAt time.Time, winners, losers []int64) Result {
+		return Result{TournamentID: tournamentID, MatchID: matchID, PlayedAt: playedAt, Winners: winners, Losers: losers}
+	}
+
+	tests := []struct {
+		name    string
+		results []Result
+		want    map[int64]int
+		entries int
+	}{
+		{
+			name:    "равные рейтинги",
+			results: []Result{result("t1", 1, day, []int64{1}, []int64{2})},
+			want:    map[int64]int{1: 1516, 2: 1484},
+			entries: 2,
+		},
+		{
+			name: "победа более слабого дает больше очков",
+			results: []Result{
+				result("t1", 1, day, []int64{1}, []int64{2}),
+				result("t1", 2, day.Add(time.Hour), []int64{2}, []int64{1}),
+			},
+			want:    map[int64]int{1: 1499, 2: 1501},
+			entries: 4,
+		},
+		{
+			name: "матчи упорядочиваются по дате, а не по входным данным",
+			results: []Result{
+				result("t1", 2, day.Add(time.Hour), []int64{2}, []int64{1}),
+				result("t1", 1, day, []int64{1}, []int64{2}),
+			},
+			want:    map[int64]int{1: 1499, 2: 1501},
+			entries: 4,
+		},
+		{
+			name: "в один момент - по турниру и номеру матча",
+			results: []Result{
+				result("t2", 1, day, []int64{2}, []int64{1}),
+				result("t1", 1, day, []int64{1}, []int64{2}),
+			},
+			want:    map[int64]int{1: 1499, 2: 1501},
+			entries: 4,
+		},
+		{
+			name:    "пара получает изменение по среднему рейтингу команды",
+			results: []Result{result("t1", 1, day, []int64{1, 2}, []int64{3, 4})},
+			want:    map[int64]int{1: 1516, 2: 1516, 3: 1484, 4: 1484},
+			entries: 4,
+		},
+		{
+			name: "результат без соперника пропускается",
+			results: []Result{
+				result("t1", 1, day, []int64{1}, nil),
+				result("t1", 2, day, []int64{1}, []int64{2}),
+			},
+			want:    map[int64]int{1: 1516, 2: 1484},
+			entries: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := Replay(tt.results)
+			if len(entries) != tt.entries {
+				t.Fatalf("entries = %d, want %d", len(entries), tt.entries)
+			}
+			if got := finalRatings(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ratings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayDeterministic(t *testing.T) {
+	day := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	results := []Result{
+		{TournamentID: "t1", MatchID: 1, PlayedAt: day, Winners: []int64{1}, Losers: []int64{2}},
+		{TournamentID: "t1", MatchID: 2, PlayedAt: day, Winners: []int64{3}, Losers: []int64{4}},
+		{TournamentID: "t1", MatchID: 3, PlayedAt: day, Winners: []int64{1}, Losers: []int64{3}},
+		{TournamentID: "t2", MatchID: 1, PlayedAt: day.AddDate(0, 0, 7), Winners: []int64{4, 2}, Losers: []int64{1, 3}},
+		{TournamentID: "t2", MatchID: 2, PlayedAt: day.AddDate(0, 0, 7), Winners: []int64{1, 4}, Losers: []int64{2, 3}},
+	}
+	want := Replay(results)
+
+	reversed := make([]Result, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		reversed = append(reversed, results[i])
+	}
+	if got := Replay(reversed); !reflect.DeepEqual(got, want) {
+		t.Fatalf("replay of reordered results differs:\ngot  %+v\nwant %+v", got, want)
+	}
+	if !reflect.DeepEqual(Replay(results), want) {
+		t.Fatal("repeated replay differs")
+	}
+}
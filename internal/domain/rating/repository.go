@@ -0,0 +1,15 @@
+package rating
+
+import "context"
+
+// Repository описывает, что нужно домену от хранилища истории рейтинга
+type Repository interface {
+	// ReplaceHistory заменяет всю историю рейтинга результатом пересчета в одной транзакции
+	ReplaceHistory(ctx context.Context, entries []Entry) error
+
+	// ListHistory возвращает историю игрока от старых матчей к новым
+	ListHistory(ctx context.Context, telegramID int64) ([]Entry, error)
+
+	// ListLatest возвращает последнюю запись истории каждого игрока (его текущий рейтинг)
+	ListLatest(ctx context.Context) ([]Entry, error)
+}
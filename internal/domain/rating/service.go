@@ -0,0 +1,118 @@
+package rating
+
+import (
+	"context"
+	"sort"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/tournament"
+)
+
+// Service описывает use-case'ы вокруг рейтинга игроков
+type Service interface {
+	// Recalculate заново проигрывает все результаты турниров и сохраняет историю; возвращает число учтенных матчей
+	Recalculate(ctx context.Context) (int, error)
+	// Get возвращает рейтинг игрока со статистикой (игрок без матчей получает стартовый рейтинг)
+	Get(ctx context.Context, telegramID int64) (*Summary, error)
+	// Ratings возвращает текущие рейтинги игроков (для посева участников турнира)
+	Ratings(ctx context.Context, telegramIDs []int64) (map[int64]int, error)
+}
+
+type ratingService struct {
+	repo         Repository
+	tournaments  tournament.Repository
+	eventService event.EventService
+}
+
+func NewService(repo Repository, tournaments tournament.Repository, eventService event.EventService) Service {
+	return &ratingService{
+		repo:         repo,
+		tournaments:  tournaments,
+		eventService: eventService,
+	}
+}
+
+func (s *ratingService) Recalculate(ctx context.Context) (int, error) {
+	tournaments, err := s.tournaments.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var results []Result
+	for i := range tournaments {
+		t := &tournaments[i]
+		evt, err := s.eventService.Get(ctx, t.EventID)
+		if err != nil {
+			return 0, err
+		}
+		// Матчи отмененных соревнований в рейтинг не идут
+		if evt == nil || evt.Status == event.StatusCancelled {
+			continue
+		}
+		results = append(results, ResultsFromTournament(t, evt.Date)...)
+	}
+
+	if err := s.repo.ReplaceHistory(ctx, Replay(results)); err != nil {
+		return 0, err
+	}
+	return len(results), nil
+}
+
+func (s *ratingService) Get(ctx context.Context, telegramID int64) (*Summary, error) {
+	history, err := s.repo.ListHistory(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{TelegramID: telegramID, Rating: DefaultRating, History: history}
+	for _, e := range history {
+		summary.Played++
+		if e.Won {
+			summary.Won++
+		}
+	}
+	if len(history) == 0 {
+		return summary, nil
+	}
+	summary.Rating = history[len(history)-1].After
+
+	latest, err := s.repo.ListLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		if latest[i].After != latest[j].After {
+			return latest[i].After > latest[j].After
+		}
+		return latest[i].TelegramID < latest[j].TelegramID
+	})
+	summary.Ranked = len(latest)
+	for i, e := range latest {
+		if e.TelegramID == telegramID {
+			summary.Rank = i + 1
+			break
+		}
+	}
+	return summary, nil
+}
+
+func (s *ratingService) Ratings(ctx context.Context, telegramIDs []int64) (map[int64]int, error) {
+	latest, err := s.repo.ListLatest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[int64]int, len(latest))
+	for _, e := range latest {
+		current[e.TelegramID] = e.After
+	}
+
+	ratings := make(map[int64]int, len(telegramIDs))
+	for _, id := range telegramIDs {
+		if r, ok := current[id]; ok {
+			ratings[id] = r
+		} else {
+			ratings[id] = DefaultRating
+		}
+	}
+	return ratings, nil
+}
@@ -0,0 +1,71 @@
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Kind - тип напоминания, определяется смещением до начала события (например, "24h", "2h")
+type Kind string
+
+// KindFor возвращает тип напоминания для смещения
+func KindFor(offset time.Duration) Kind {
+	if offset%time.Hour == 0 {
+		return Kind(fmt.Sprintf("%dh", int(offset/time.Hour)))
+	}
+	return Kind(fmt.Sprintf("%dm", int(offset/time.Minute)))
+}
+
+// Offset возвращает смещение до начала события для типа напоминания
+func (k Kind) Offset() (time.Duration, error) {
+	d, err := time.ParseDuration(string(k))
+	if err != nil || d <= 0 {
+		return 0, ErrInvalidKind
+	}
+	return d, nil
+}
+
+// Reminder - напоминание о предстоящем событии для одного игрока
+type Reminder struct {
+	Event  *event.Event
+	UserID int64
+	Kind   Kind
+	Unpaid bool // Регистрация еще не подтверждена (оплата не получена)
+}
+
+// ParseOffsets парсит список смещений вида "24h 2h 30m"
+func ParseOffsets(input string) ([]time.Duration, error) {
+	seen := make(map[time.Duration]bool)
+	var offsets []time.Duration
+	for _, part := range strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		d, err := time.ParseDuration(part)
+		if err != nil || d < time.Minute {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidOffset, part)
+		}
+		d = d.Truncate(time.Minute)
+		if !seen[d] {
+			seen[d] = true
+			offsets = append(offsets, d)
+		}
+	}
+	SortOffsets(offsets)
+	return offsets, nil
+}
+
+// SortOffsets сортирует смещения по убыванию (сначала самые ранние напоминания)
+func SortOffsets(offsets []time.Duration) {
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+}
+
+// Errors
+var (
+	ErrInvalidKind   = errors.New("invalid reminder kind")
+	ErrInvalidOffset = errors.New("invalid reminder offset")
+)
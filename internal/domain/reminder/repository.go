@@ -0,0 +1,17 @@
+package reminder
+
+import (
+	"context"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+type Repository interface {
+	// MarkSent записывает отправку напоминания. Возвращает false, если оно уже было записано ранее
+	MarkSent(ctx context.Context, eventID event.EventID, userID int64, kind Kind) (bool, error)
+
+	// ListOptOuts возвращает типы напоминаний, от которых отказался пользователь
+	ListOptOuts(ctx context.Context, userID int64) ([]Kind, error)
+	// SetOptOut включает или отключает отказ пользователя от типа напоминаний
+	SetOptOut(ctx context.Context, userID int64, kind Kind, optOut bool) error
+}
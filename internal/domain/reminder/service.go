@@ -0,0 +1,123 @@
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Service описывает use-case'ы напоминаний о предстоящих событиях
+type Service interface {
+	// Due возвращает напоминания, которые пора отправить, и записывает их как отправленные.
+	// Напоминают всем, кто занимает место (approved и pending), pending помечаются как неоплаченные
+	Due(ctx context.Context, now time.Time, offsets []time.Duration) ([]Reminder, error)
+
+	OptOuts(ctx context.Context, userID int64) (map[Kind]bool, error)
+	SetOptOut(ctx context.Context, userID int64, kind Kind, optOut bool) error
+}
+
+type reminderService struct {
+	repo         Repository
+	eventService event.EventService
+}
+
+func NewService(repo Repository, eventService event.EventService) Service {
+	return &reminderService{
+		repo:         repo,
+		eventService: eventService,
+	}
+}
+
+func (s *reminderService) Due(ctx context.Context, now time.Time, offsets []time.Duration) ([]Reminder, error) {
+	if len(offsets) == 0 {
+		return nil, nil
+	}
+	offsets = append([]time.Duration(nil), offsets...)
+	SortOffsets(offsets)
+
+	events, err := s.eventService.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	optOuts := make(map[int64]map[Kind]bool)
+	var result []Reminder
+	for i := range events {
+		evt := &events[i]
+		if !evt.IsOpen(now) {
+			continue
+		}
+
+		// Все наступившие смещения; отправляем только самое позднее из них,
+		// чтобы игрок, записавшийся за 3 часа, не получил сразу два напоминания
+		var due []Kind
+		for _, offset := range offsets {
+			if !now.Before(evt.Date.Add(-offset)) {
+				due = append(due, KindFor(offset))
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+		latest := due[len(due)-1]
+
+		for userID, reg := range evt.Registrations {
+			if !reg.Status.HoldsSpot() {
+				continue
+			}
+
+			send := false
+			for _, kind := range due {
+				created, err := s.repo.MarkSent(ctx, evt.ID, userID, kind)
+				if err != nil {
+					return result, err
+				}
+				if kind == latest && created {
+					send = true
+				}
+			}
+			if !send {
+				continue
+			}
+
+			if _, ok := optOuts[userID]; !ok {
+				userOptOuts, err := s.OptOuts(ctx, userID)
+				if err != nil {
+					return result, err
+				}
+				optOuts[userID] = userOptOuts
+			}
+			if optOuts[userID][latest] {
+				continue
+			}
+
+			result = append(result, Reminder{
+				Event:  evt,
+				UserID: userID,
+				Kind:   latest,
+				Unpaid: reg.Status == event.RegistrationStatusPending,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (s *reminderService) OptOuts(ctx context.Context, userID int64) (map[Kind]bool, error) {
+	kinds, err := s.repo.ListOptOuts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[Kind]bool, len(kinds))
+	for _, k := range kinds {
+		result[k] = true
+	}
+	return result, nil
+}
+
+func (s *reminderService) SetOptOut(ctx context.Context, userID int64, kind Kind, optOut bool) error {
+	if _, err := kind.Offset(); err != nil {
+		return err
+	}
+	return s.repo.SetOptOut(ctx, userID, kind, optOut)
+}
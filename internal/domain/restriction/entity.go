@@ -0,0 +1,98 @@
+package restriction
+
+import (
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/location"
+)
+
+// RestrictionID - тип для ID ограничения
+type RestrictionID string
+
+// Kind - вид ограничения
+type Kind string
+
+const (
+	KindBan       Kind = "ban"       // Полный бан: запись на любые события запрещена бессрочно
+	KindUntil     Kind = "until"     // Запрет записи на любые события до даты
+	KindLocations Kind = "locations" // Запрет записи на события выбранных локаций
+)
+
+// Valid проверяет, что вид ограничения известен
+func (k Kind) Valid() bool {
+	return k == KindBan || k == KindUntil || k == KindLocations
+}
+
+// Restriction - ограничение записи игрока, которое установил администратор
+type Restriction struct {
+	ID          RestrictionID
+	UserID      int64 // Telegram ID игрока
+	Kind        Kind
+	Until       time.Time             // До какого момента действует (только для KindUntil)
+	LocationIDs []location.LocationID // Локации, на которые запрещена запись (только для KindLocations)
+	Reason      string
+	CreatedBy   int64 // Telegram ID администратора, который установил ограничение
+	CreatedAt   time.Time
+	LiftedBy    int64     // Кто снял ограничение досрочно (0 - не снималось)
+	LiftedAt    time.Time // Когда ограничение снято (нулевое - не снималось)
+}
+
+// ActiveAt сообщает, действует ли ограничение в момент now
+func (r *Restriction) ActiveAt(now time.Time) bool {
+	if !r.LiftedAt.IsZero() {
+		return false
+	}
+	return r.Kind != KindUntil || now.Before(r.Until)
+}
+
+// Blocks сообщает, запрещает ли ограничение запись на событие локации locationID в момент now
+func (r *Restriction) Blocks(locationID location.LocationID, now time.Time) bool {
+	if !r.ActiveAt(now) {
+		return false
+	}
+	if r.Kind != KindLocations {
+		return true
+	}
+	for _, id := range r.LocationIDs {
+		if id == locationID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateInput - DTO для установки ограничения
+type CreateInput struct {
+	UserID      int64
+	Kind        Kind
+	Until       time.Time             // Для KindUntil
+	LocationIDs []location.LocationID // Для KindLocations
+	Reason      string
+}
+
+// Validate проверяет валидность входных данных для установки ограничения
+func (in CreateInput) Validate(now time.Time) error {
+	if !in.Kind.Valid() {
+		return ErrKindInvalid
+	}
+	if in.Reason == "" {
+		return ErrReasonRequired
+	}
+	if in.Kind == KindUntil && !in.Until.After(now) {
+		return ErrUntilInPast
+	}
+	if in.Kind == KindLocations && len(in.LocationIDs) == 0 {
+		return ErrLocationsRequired
+	}
+	return nil
+}
+
+var (
+	ErrRestrictionNotFound = errors.New("restriction not found")
+	ErrKindInvalid         = errors.New("unknown restriction kind")
+	ErrReasonRequired      = errors.New("restriction reason is required")
+	ErrUntilInPast         = errors.New("restriction end must be in the future")
+	ErrLocationsRequired   = errors.New("at least one location is required")
+	ErrAlreadyLifted       = errors.New("restriction is already lifted")
+)
@@ -0,0 +1,21 @@
+package restriction
+
+import (
+	"context"
+	"time"
+)
+
+// Repository описывает хранилище ограничений
+type Repository interface {
+	// Save создает ограничение
+	Save(ctx context.Context, r *Restriction) error
+
+	// Get возвращает ограничение или nil, если его нет
+	Get(ctx context.Context, id RestrictionID) (*Restriction, error)
+
+	// ListByUser возвращает все ограничения игрока (включая снятые и истекшие) в порядке установки
+	ListByUser(ctx context.Context, userID int64) ([]Restriction, error)
+
+	// Lift отмечает ограничение снятым
+	Lift(ctx context.Context, id RestrictionID, by int64, at time.Time) error
+}
@@ -0,0 +1,128 @@
+package restriction
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/location"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг ограничений записи
+type Service interface {
+	// Restrict устанавливает игроку ограничение; by - администратор, который его устанавливает
+	Restrict(ctx context.Context, by int64, in CreateInput) (*Restriction, error)
+	// Lift досрочно снимает ограничение
+	Lift(ctx context.Context, id RestrictionID, by int64) (*Restriction, error)
+	// ListByUser возвращает все ограничения игрока
+	ListByUser(ctx context.Context, userID int64) ([]Restriction, error)
+	// Active возвращает ограничения игрока, действующие в момент now
+	Active(ctx context.Context, userID int64, now time.Time) ([]Restriction, error)
+	// Blocking возвращает ограничение, запрещающее игроку запись на событие локации, или nil
+	Blocking(ctx context.Context, userID int64, locationID location.LocationID, now time.Time) (*Restriction, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Restrict(ctx context.Context, by int64, in CreateInput) (*Restriction, error) {
+	in.Reason = strings.TrimSpace(in.Reason)
+	now := time.Now()
+	if err := in.Validate(now); err != nil {
+		return nil, err
+	}
+
+	r := &Restriction{
+		ID:        RestrictionID(uuid.New().String()),
+		UserID:    in.UserID,
+		Kind:      in.Kind,
+		Reason:    in.Reason,
+		CreatedBy: by,
+		CreatedAt: now,
+	}
+	switch in.Kind {
+	case KindUntil:
+		r.Until = in.Until
+	case KindLocations:
+		r.LocationIDs = in.LocationIDs
+	}
+	if err := s.repo.Save(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *service) Lift(ctx context.Context, id RestrictionID, by int64) (*Restriction, error) {
+	r, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrRestrictionNotFound
+	}
+	if !r.LiftedAt.IsZero() {
+		return nil, ErrAlreadyLifted
+	}
+
+	r.LiftedBy = by
+	r.LiftedAt = time.Now()
+	if err := s.repo.Lift(ctx, id, r.LiftedBy, r.LiftedAt); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *service) ListByUser(ctx context.Context, userID int64) ([]Restriction, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Active(ctx context.Context, userID int64, now time.Time) ([]Restriction, error) {
+	all, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var active []Restriction
+	for _, r := range all {
+		if r.ActiveAt(now) {
+			active = append(active, r)
+		}
+	}
+	return active, nil
+}
+
+func (s *service) Blocking(ctx context.Context, userID int64, locationID location.LocationID, now time.Time) (*Restriction, error) {
+	active, err := s.Active(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	// Игроку сообщаем о самом строгом ограничении: полный бан, затем самый долгий запрет до даты
+	var found *Restriction
+	for i := range active {
+		r := &active[i]
+		if !r.Blocks(locationID, now) {
+			continue
+		}
+		if found == nil || stricter(r, found) {
+			found = r
+		}
+	}
+	return found, nil
+}
+
+// kindRank - строгость вида ограничения (меньше - строже)
+var kindRank = map[Kind]int{KindBan: 0, KindUntil: 1, KindLocations: 2}
+
+// stricter сообщает, что ограничение a строже ограничения b
+func stricter(a, b *Restriction) bool {
+	if kindRank[a.Kind] != kindRank[b.Kind] {
+		return kindRank[a.Kind] < kindRank[b.Kind]
+	}
+	return a.Kind == KindUntil && a.Until.After(b.Until)
+}
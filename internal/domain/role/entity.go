@@ -0,0 +1,138 @@
+package role
+
+import (
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/series"
+)
+
+// Role - роль сотрудника в боте
+type Role string
+
+const (
+	RoleOwner     Role = "owner"     // Владелец: все права, включая назначение ролей
+	RoleAdmin     Role = "admin"     // Администратор: все права, кроме назначения ролей
+	RoleModerator Role = "moderator" // Модератор: только подтверждение и отклонение заявок
+	RoleTrainer   Role = "trainer"   // Тренер: управление только своими событиями
+)
+
+// Roles - все роли в порядке убывания прав (для выбора в боте)
+var Roles = []Role{RoleOwner, RoleAdmin, RoleModerator, RoleTrainer}
+
+// Permission - право на группу действий в панели администратора
+type Permission string
+
+const (
+	PermView            Permission = "view"      // Вход в панель администратора и просмотр событий
+	PermModerate        Permission = "moderate"  // Подтверждение и отклонение заявок
+	PermManageEvents    Permission = "events"    // Создание и изменение событий, серий, посещаемость, турниры
+	PermManageLocations Permission = "locations" // Локации и корты
+	PermManagePlayers   Permission = "players"   // Уровни и рейтинг игроков
+	PermManageSettings  Permission = "settings"  // Каналы, бронь, напоминания, политика неявок
+	PermManagePayments  Permission = "payments"  // Журнал оплат: отметка оплат, возвраты, должники
+	PermManageRoles     Permission = "roles"     // Назначение и снятие ролей
+)
+
+// permissions - права каждой роли
+var permissions = map[Role][]Permission{
+	RoleOwner:     {PermView, PermModerate, PermManageEvents, PermManageLocations, PermManagePlayers, PermManageSettings, PermManagePayments, PermManageRoles},
+	RoleAdmin:     {PermView, PermModerate, PermManageEvents, PermManageLocations, PermManagePlayers, PermManageSettings, PermManagePayments},
+	RoleModerator: {PermView, PermModerate},
+	RoleTrainer:   {PermView, PermModerate, PermManageEvents},
+}
+
+// Valid проверяет, что роль известна
+func (r Role) Valid() bool {
+	_, ok := permissions[r]
+	return ok
+}
+
+// Has проверяет, входит ли право в роль
+func (r Role) Has(perm Permission) bool {
+	for _, p := range permissions[r] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantID - тип для ID выданной роли
+type GrantID string
+
+// Grant - роль, выданная пользователю
+type Grant struct {
+	ID         GrantID
+	TelegramID int64
+	Role       Role
+	LocationID location.LocationID // Пусто - роль действует во всех локациях
+	GrantedBy  int64               // Кто выдал роль (0 - владелец из ADMIN_IDS при первом запуске)
+	CreatedAt  time.Time
+}
+
+// Target - объект действия, для которого проверяется право
+type Target struct {
+	Anywhere   bool                // Достаточно права хотя бы в одной локации (входы в меню, списки)
+	LocationID location.LocationID // Локация объекта (пусто - глобальное действие, доступное только ролям без ограничения)
+	Event      bool                // Действие над конкретным событием или серией
+	CreatedBy  int64               // Создатель события или серии (тренер управляет только своими)
+}
+
+// Anywhere - действие, для которого достаточно права в любой локации
+var Anywhere = Target{Anywhere: true}
+
+// Global - глобальное действие (настройки, роли), доступное только ролям без ограничения по локации
+var Global = Target{}
+
+// AtLocation - действие в рамках локации
+func AtLocation(id location.LocationID) Target {
+	return Target{LocationID: id}
+}
+
+// ForEvent - действие над событием
+func ForEvent(evt *event.Event) Target {
+	return Target{LocationID: evt.LocationID, Event: true, CreatedBy: evt.CreatedBy}
+}
+
+// ForSeries - действие над серией событий
+func ForSeries(s *series.EventSeries) Target {
+	return Target{LocationID: s.LocationID, Event: true, CreatedBy: s.CreatedBy}
+}
+
+// Allows проверяет, дает ли роль право на действие над объектом
+func (g Grant) Allows(perm Permission, t Target) bool {
+	if !g.Role.Has(perm) {
+		return false
+	}
+	if !t.Anywhere && g.LocationID != "" && g.LocationID != t.LocationID {
+		return false
+	}
+	// Тренер управляет только событиями, которые создал сам
+	if g.Role == RoleTrainer && t.Event && t.CreatedBy != g.TelegramID {
+		return false
+	}
+	return true
+}
+
+// Allows проверяет, дает ли хотя бы одна из ролей право на действие над объектом
+func Allows(grants []Grant, perm Permission, t Target) bool {
+	for _, g := range grants {
+		if g.Allows(perm, t) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrRoleInvalid      = errors.New("unknown role")
+	ErrTelegramIDEmpty  = errors.New("telegram id is required")
+	ErrOwnerScoped      = errors.New("owner role cannot be limited to a location")
+	ErrAlreadyGranted   = errors.New("role is already granted")
+	ErrGrantNotFound    = errors.New("role grant not found")
+	ErrLastOwner        = errors.New("cannot revoke the last owner")
+	ErrPermissionDenied = errors.New("permission denied")
+)
@@ -0,0 +1,21 @@
+package role
+
+import "context"
+
+// Repository описывает хранилище выданных ролей
+type Repository interface {
+	// GetByID возвращает выданную роль или nil, если ее нет
+	GetByID(ctx context.Context, id GrantID) (*Grant, error)
+
+	// List возвращает все выданные роли в порядке выдачи
+	List(ctx context.Context) ([]Grant, error)
+
+	// ListByTelegramID возвращает роли пользователя
+	ListByTelegramID(ctx context.Context, telegramID int64) ([]Grant, error)
+
+	// Save сохраняет выданную роль
+	Save(ctx context.Context, grant *Grant) error
+
+	// Delete снимает роль
+	Delete(ctx context.Context, id GrantID) error
+}
@@ -0,0 +1,180 @@
+package role
+
+import (
+	"context"
+	"time"
+
+	"pickletlgbot/internal/domain/location"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг ролей и прав
+type Service interface {
+	// Grants возвращает роли пользователя (пусто - обычный игрок)
+	Grants(ctx context.Context, telegramID int64) ([]Grant, error)
+	// Can проверяет право пользователя на действие над объектом
+	Can(ctx context.Context, telegramID int64, perm Permission, target Target) (bool, error)
+	// List возвращает все выданные роли
+	List(ctx context.Context) ([]Grant, error)
+	// Holders возвращает Telegram ID пользователей, у которых есть право на действие над объектом
+	Holders(ctx context.Context, perm Permission, target Target) ([]int64, error)
+
+	// Grant выдает роль; by - владелец, который ее выдает
+	Grant(ctx context.Context, by int64, in GrantInput) (*Grant, error)
+	// Revoke снимает роль и возвращает снятую роль
+	Revoke(ctx context.Context, by int64, id GrantID) (*Grant, error)
+
+	// Bootstrap назначает владельцами указанных пользователей, если владельцев еще нет
+	Bootstrap(ctx context.Context, ownerIDs []int64) error
+}
+
+// GrantInput - DTO для выдачи роли
+type GrantInput struct {
+	TelegramID int64
+	Role       Role
+	LocationID location.LocationID // Пусто - во всех локациях
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Grants(ctx context.Context, telegramID int64) ([]Grant, error) {
+	return s.repo.ListByTelegramID(ctx, telegramID)
+}
+
+func (s *service) Can(ctx context.Context, telegramID int64, perm Permission, target Target) (bool, error) {
+	grants, err := s.repo.ListByTelegramID(ctx, telegramID)
+	if err != nil {
+		return false, err
+	}
+	return Allows(grants, perm, target), nil
+}
+
+func (s *service) List(ctx context.Context) ([]Grant, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *service) Holders(ctx context.Context, perm Permission, target Target) ([]int64, error) {
+	grants, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, g := range grants {
+		if seen[g.TelegramID] || !g.Allows(perm, target) {
+			continue
+		}
+		seen[g.TelegramID] = true
+		ids = append(ids, g.TelegramID)
+	}
+	return ids, nil
+}
+
+func (s *service) Grant(ctx context.Context, by int64, in GrantInput) (*Grant, error) {
+	if err := s.checkManager(ctx, by); err != nil {
+		return nil, err
+	}
+	return s.grant(ctx, by, in)
+}
+
+func (s *service) grant(ctx context.Context, by int64, in GrantInput) (*Grant, error) {
+	if in.TelegramID == 0 {
+		return nil, ErrTelegramIDEmpty
+	}
+	if !in.Role.Valid() {
+		return nil, ErrRoleInvalid
+	}
+	if in.Role == RoleOwner && in.LocationID != "" {
+		return nil, ErrOwnerScoped
+	}
+
+	existing, err := s.repo.ListByTelegramID(ctx, in.TelegramID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range existing {
+		if g.Role == in.Role && g.LocationID == in.LocationID {
+			return nil, ErrAlreadyGranted
+		}
+	}
+
+	grant := &Grant{
+		ID:         GrantID(uuid.New().String()),
+		TelegramID: in.TelegramID,
+		Role:       in.Role,
+		LocationID: in.LocationID,
+		GrantedBy:  by,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.repo.Save(ctx, grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (s *service) Revoke(ctx context.Context, by int64, id GrantID) (*Grant, error) {
+	if err := s.checkManager(ctx, by); err != nil {
+		return nil, err
+	}
+
+	grant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil {
+		return nil, ErrGrantNotFound
+	}
+
+	// Бот не должен остаться без владельца: иначе роли назначать будет некому
+	if grant.Role == RoleOwner {
+		owners, err := s.Holders(ctx, PermManageRoles, Global)
+		if err != nil {
+			return nil, err
+		}
+		if len(owners) <= 1 {
+			return nil, ErrLastOwner
+		}
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (s *service) Bootstrap(ctx context.Context, ownerIDs []int64) error {
+	owners, err := s.Holders(ctx, PermManageRoles, Global)
+	if err != nil {
+		return err
+	}
+	if len(owners) > 0 {
+		return nil
+	}
+
+	for _, id := range ownerIDs {
+		if _, err := s.grant(ctx, 0, GrantInput{TelegramID: id, Role: RoleOwner}); err != nil && err != ErrAlreadyGranted {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkManager проверяет, что пользователь может назначать роли
+func (s *service) checkManager(ctx context.Context, by int64) error {
+	ok, err := s.Can(ctx, by, PermManageRoles, Global)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPermissionDenied
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package series
+
+import (
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/user"
+)
+
+// SeriesID - тип для ID серии событий
+type SeriesID string
+
+// TimeOfDay - время начала занятия
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+}
+
+// Recurrence - правило повторения серии
+type Recurrence struct {
+	Weekdays  []time.Weekday // Дни недели, по которым проходят занятия
+	Time      TimeOfDay      // Время начала занятия
+	StartDate time.Time      // Дата первого занятия (учитывается только день)
+	Until     time.Time      // Дата последнего занятия включительно (нулевая - без ограничения)
+	Count     int            // Максимальное количество занятий (0 - без ограничения)
+}
+
+// EventSeries - повторяющаяся серия событий (например, еженедельные тренировки)
+type EventSeries struct {
+	ID             SeriesID
+	Name           string
+	Type           event.EventType
+	MaxPlayers     int
+	LocationID     location.LocationID
+	Trainer        string
+	Description    string
+	PaymentPhone   string
+	Price          int
+	PaymentMode    event.PaymentMode
+	PendingTimeout time.Duration
+	MinLevel       user.Level    // Минимальный уровень игрока (0 - без ограничения)
+	MaxLevel       user.Level    // Максимальный уровень игрока (0 - без ограничения)
+	Doubles        bool          // Парная категория (только для соревнований)
+	Duration       time.Duration // Длительность занятия (0 - длительность события по умолчанию)
+	CreatedBy      int64         // Telegram ID создателя серии (становится создателем занятий)
+	Recurrence     Recurrence
+	WeeksAhead     int      // На сколько недель вперед создавать события
+	ExcludedDates  []string // Дни (ГГГГ-ММ-ДД) отмененных занятий, которые не нужно создавать повторно
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Scope - к каким занятиям серии применяется изменение
+type Scope string
+
+const (
+	ScopeOccurrence Scope = "one"    // Только это занятие
+	ScopeFuture     Scope = "future" // Это и все последующие занятия
+)
+
+// DefaultWeeksAhead - горизонт создания событий по умолчанию
+const DefaultWeeksAhead = 2
+
+// Occurrences возвращает даты занятий серии от StartDate до upTo включительно
+// с учетом Until, Count и отмененных занятий
+func (s *EventSeries) Occurrences(upTo time.Time) []time.Time {
+	r := s.Recurrence
+	if len(r.Weekdays) == 0 || r.StartDate.IsZero() {
+		return nil
+	}
+
+	loc := r.StartDate.Location()
+	days := make(map[time.Weekday]bool, len(r.Weekdays))
+	for _, wd := range r.Weekdays {
+		days[wd] = true
+	}
+	excluded := make(map[string]bool, len(s.ExcludedDates))
+	for _, d := range s.ExcludedDates {
+		excluded[d] = true
+	}
+
+	var result []time.Time
+	n := 0
+	for day := startOfDay(r.StartDate); ; day = day.AddDate(0, 0, 1) {
+		if !r.Until.IsZero() && day.After(startOfDay(r.Until)) {
+			break
+		}
+		if day.After(upTo) {
+			break
+		}
+		if !days[day.Weekday()] {
+			continue
+		}
+
+		n++
+		if r.Count > 0 && n > r.Count {
+			break
+		}
+		if excluded[DayKey(day)] {
+			continue
+		}
+		result = append(result, time.Date(day.Year(), day.Month(), day.Day(), r.Time.Hour, r.Time.Minute, 0, 0, loc))
+	}
+	return result
+}
+
+// Exclude помечает занятие в указанный день как отмененное
+func (s *EventSeries) Exclude(day time.Time) {
+	key := DayKey(day)
+	for _, d := range s.ExcludedDates {
+		if d == key {
+			return
+		}
+	}
+	s.ExcludedDates = append(s.ExcludedDates, key)
+}
+
+// DayKey возвращает ключ дня в формате ГГГГ-ММ-ДД
+func DayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// CreateSeriesInput - DTO для создания серии
+type CreateSeriesInput struct {
+	Name           string
+	Type           event.EventType
+	MaxPlayers     int
+	LocationID     location.LocationID
+	Trainer        string
+	Description    string
+	PaymentPhone   string
+	Price          int
+	PaymentMode    event.PaymentMode
+	PendingTimeout time.Duration
+	MinLevel       user.Level
+	MaxLevel       user.Level
+	Doubles        bool
+	Duration       time.Duration
+	CreatedBy      int64
+	Recurrence     Recurrence
+	WeeksAhead     int
+}
+
+// Validate проверяет валидность входных данных для создания серии
+func (in CreateSeriesInput) Validate() error {
+	if in.Name == "" {
+		return event.ErrEventNameRequired
+	}
+	if in.LocationID == "" {
+		return event.ErrLocationIDRequired
+	}
+	if in.MaxPlayers <= 0 {
+		return event.ErrMaxPlayersInvalid
+	}
+	if len(in.Recurrence.Weekdays) == 0 {
+		return ErrWeekdaysRequired
+	}
+	if in.Recurrence.Time.Hour < 0 || in.Recurrence.Time.Hour > 23 || in.Recurrence.Time.Minute < 0 || in.Recurrence.Time.Minute > 59 {
+		return ErrInvalidTime
+	}
+	if in.Recurrence.StartDate.IsZero() {
+		return event.ErrDateRequired
+	}
+	if !in.Recurrence.Until.IsZero() && in.Recurrence.Until.Before(startOfDay(in.Recurrence.StartDate)) {
+		return ErrUntilBeforeStart
+	}
+	if in.Recurrence.Count < 0 || in.WeeksAhead < 0 {
+		return ErrInvalidLimit
+	}
+	if in.Doubles && in.Type != event.EventTypeCompetition {
+		return event.ErrDoublesOnlyCompetition
+	}
+	if in.Duration < 0 {
+		return event.ErrDurationInvalid
+	}
+	if err := event.ValidatePayment(in.PaymentMode, in.Price); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateSeriesInput - DTO для изменения занятий серии
+type UpdateSeriesInput struct {
+	Name       *string
+	Time       *TimeOfDay
+	MaxPlayers *int
+}
+
+// Errors
+var (
+	ErrSeriesNotFound   = errors.New("event series not found")
+	ErrNotSeriesEvent   = errors.New("event does not belong to a series")
+	ErrWeekdaysRequired = errors.New("at least one weekday is required")
+	ErrInvalidTime      = errors.New("invalid time of day")
+	ErrUntilBeforeStart = errors.New("series end date is before start date")
+	ErrInvalidLimit     = errors.New("series limits must not be negative")
+	ErrInvalidScope     = errors.New("invalid series scope")
+)
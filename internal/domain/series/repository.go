@@ -0,0 +1,18 @@
+package series
+
+import "context"
+
+// Repository описывает, что нужно домену от хранилища серий событий
+type Repository interface {
+	// GetByID возвращает серию по ID или nil, если не найдена
+	GetByID(ctx context.Context, id SeriesID) (*EventSeries, error)
+
+	// List возвращает все серии
+	List(ctx context.Context) ([]EventSeries, error)
+
+	// Save создаёт или обновляет серию
+	Save(ctx context.Context, s *EventSeries) error
+
+	// Delete удаляет серию по ID
+	Delete(ctx context.Context, id SeriesID) error
+}
@@ -0,0 +1,326 @@
+package series
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг повторяющихся серий событий
+type Service interface {
+	Get(ctx context.Context, id SeriesID) (*EventSeries, error)
+	List(ctx context.Context) ([]EventSeries, error)
+	// Create создает серию и сразу генерирует события на WeeksAhead недель вперед
+	Create(ctx context.Context, input CreateSeriesInput) (*EventSeries, []event.Event, error)
+	// GenerateUpcoming досоздает недостающие события всех серий и возвращает созданные
+	GenerateUpcoming(ctx context.Context, now time.Time) ([]event.Event, error)
+
+	// UpdateOccurrences меняет занятие серии (или это и все последующие)
+	UpdateOccurrences(ctx context.Context, eventID event.EventID, input UpdateSeriesInput, scope Scope) ([]event.Event, error)
+	// CancelOccurrences отменяет занятие серии (или это и все последующие) и возвращает отмененные события
+	CancelOccurrences(ctx context.Context, eventID event.EventID, scope Scope) ([]event.Event, error)
+	// Stop завершает серию: будущие занятия отменяются, новые больше не создаются
+	Stop(ctx context.Context, id SeriesID, now time.Time) ([]event.Event, error)
+}
+
+type seriesService struct {
+	repo            Repository
+	eventService    event.EventService
+	locationService location.LocationService
+}
+
+func NewService(repo Repository, eventService event.EventService, locationService location.LocationService) Service {
+	return &seriesService{
+		repo:            repo,
+		eventService:    eventService,
+		locationService: locationService,
+	}
+}
+
+func (s *seriesService) Get(ctx context.Context, id SeriesID) (*EventSeries, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *seriesService) List(ctx context.Context) ([]EventSeries, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *seriesService) Create(ctx context.Context, in CreateSeriesInput) (*EventSeries, []event.Event, error) {
+	if err := in.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// Проверяем, что локация существует
+	loc, err := s.locationService.Get(ctx, in.LocationID)
+	if err != nil || loc == nil {
+		return nil, nil, errors.New("location not found")
+	}
+
+	weeksAhead := in.WeeksAhead
+	if weeksAhead == 0 {
+		weeksAhead = DefaultWeeksAhead
+	}
+
+	series := &EventSeries{
+		ID:             SeriesID(uuid.New().String()),
+		Name:           in.Name,
+		Type:           in.Type,
+		MaxPlayers:     in.MaxPlayers,
+		LocationID:     in.LocationID,
+		Trainer:        in.Trainer,
+		Description:    in.Description,
+		PaymentPhone:   in.PaymentPhone,
+		Price:          in.Price,
+		PaymentMode:    in.PaymentMode,
+		PendingTimeout: in.PendingTimeout,
+		MinLevel:       in.MinLevel,
+		MaxLevel:       in.MaxLevel,
+		Doubles:        in.Doubles,
+		Duration:       in.Duration,
+		CreatedBy:      in.CreatedBy,
+		Recurrence:     in.Recurrence,
+		WeeksAhead:     weeksAhead,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	if err := s.repo.Save(ctx, series); err != nil {
+		return nil, nil, err
+	}
+
+	created, err := s.generate(ctx, series, time.Now())
+	if err != nil {
+		return series, created, err
+	}
+	return series, created, nil
+}
+
+func (s *seriesService) GenerateUpcoming(ctx context.Context, now time.Time) ([]event.Event, error) {
+	all, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []event.Event
+	for i := range all {
+		events, err := s.generate(ctx, &all[i], now)
+		created = append(created, events...)
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// generate создает события серии, которых еще нет, в пределах горизонта WeeksAhead
+func (s *seriesService) generate(ctx context.Context, series *EventSeries, now time.Time) ([]event.Event, error) {
+	horizon := now.AddDate(0, 0, 7*series.WeeksAhead)
+
+	existing, err := s.eventService.ListBySeries(ctx, string(series.ID))
+	if err != nil {
+		return nil, err
+	}
+	existingDays := make(map[string]bool, len(existing))
+	for _, evt := range existing {
+		existingDays[DayKey(evt.Date)] = true
+	}
+
+	var created []event.Event
+	for _, date := range series.Occurrences(horizon) {
+		if date.Before(now) || existingDays[DayKey(date)] {
+			continue
+		}
+
+		evt, err := s.eventService.Create(ctx, event.CreateEventInput{
+			Name:           series.Name,
+			Type:           series.Type,
+			Date:           date,
+			MaxPlayers:     series.MaxPlayers,
+			LocationID:     series.LocationID,
+			Trainer:        series.Trainer,
+			Description:    series.Description,
+			PaymentPhone:   series.PaymentPhone,
+			Price:          series.Price,
+			PaymentMode:    series.PaymentMode,
+			PendingTimeout: series.PendingTimeout,
+			SeriesID:       string(series.ID),
+			MinLevel:       series.MinLevel,
+			MaxLevel:       series.MaxLevel,
+			Doubles:        series.Doubles,
+			Duration:       series.Duration,
+			CreatedBy:      series.CreatedBy,
+		})
+		if err != nil {
+			return created, err
+		}
+		created = append(created, *evt)
+	}
+	return created, nil
+}
+
+func (s *seriesService) UpdateOccurrences(ctx context.Context, eventID event.EventID, in UpdateSeriesInput, scope Scope) ([]event.Event, error) {
+	evt, series, err := s.getOccurrence(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []event.Event
+	switch scope {
+	case ScopeOccurrence:
+		targets = []event.Event{*evt}
+	case ScopeFuture:
+		// Меняем шаблон серии, чтобы новые занятия создавались уже с изменениями
+		if in.Name != nil {
+			series.Name = *in.Name
+		}
+		if in.Time != nil {
+			series.Recurrence.Time = *in.Time
+		}
+		if in.MaxPlayers != nil {
+			series.MaxPlayers = *in.MaxPlayers
+		}
+		series.UpdatedAt = time.Now()
+		if err := s.repo.Save(ctx, series); err != nil {
+			return nil, err
+		}
+
+		targets, err = s.futureOccurrences(ctx, series, evt.Date)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidScope
+	}
+
+	updated := make([]event.Event, 0, len(targets))
+	for _, target := range targets {
+		input := event.UpdateEventInput{
+			Name:       in.Name,
+			MaxPlayers: in.MaxPlayers,
+		}
+		if in.Time != nil {
+			date := time.Date(target.Date.Year(), target.Date.Month(), target.Date.Day(), in.Time.Hour, in.Time.Minute, 0, 0, target.Date.Location())
+			input.Date = &date
+		}
+
+		result, err := s.eventService.Update(ctx, target.ID, input)
+		if err != nil {
+			return updated, err
+		}
+		updated = append(updated, *result)
+	}
+	return updated, nil
+}
+
+func (s *seriesService) CancelOccurrences(ctx context.Context, eventID event.EventID, scope Scope) ([]event.Event, error) {
+	evt, series, err := s.getOccurrence(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []event.Event
+	switch scope {
+	case ScopeOccurrence:
+		// Запоминаем день, чтобы занятие не было создано повторно
+		series.Exclude(evt.Date)
+		targets = []event.Event{*evt}
+	case ScopeFuture:
+		// Серия заканчивается накануне отменяемого занятия
+		series.Recurrence.Until = startOfDay(evt.Date).AddDate(0, 0, -1)
+		targets, err = s.futureOccurrences(ctx, series, evt.Date)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidScope
+	}
+
+	series.UpdatedAt = time.Now()
+	if err := s.repo.Save(ctx, series); err != nil {
+		return nil, err
+	}
+
+	return s.cancelEvents(ctx, targets)
+}
+
+func (s *seriesService) Stop(ctx context.Context, id SeriesID, now time.Time) ([]event.Event, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if series == nil {
+		return nil, ErrSeriesNotFound
+	}
+
+	series.Recurrence.Until = startOfDay(now).AddDate(0, 0, -1)
+	series.UpdatedAt = time.Now()
+	if err := s.repo.Save(ctx, series); err != nil {
+		return nil, err
+	}
+
+	targets, err := s.futureOccurrences(ctx, series, now)
+	if err != nil {
+		return nil, err
+	}
+	return s.cancelEvents(ctx, targets)
+}
+
+// getOccurrence возвращает событие и серию, к которой оно относится
+func (s *seriesService) getOccurrence(ctx context.Context, eventID event.EventID) (*event.Event, *EventSeries, error) {
+	evt, err := s.eventService.Get(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if evt == nil {
+		return nil, nil, event.ErrEventNotFound
+	}
+	if evt.SeriesID == "" {
+		return nil, nil, ErrNotSeriesEvent
+	}
+	if !evt.Status.IsActive() {
+		return nil, nil, event.ErrInvalidStatusTransition
+	}
+
+	series, err := s.repo.GetByID(ctx, SeriesID(evt.SeriesID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if series == nil {
+		return nil, nil, ErrSeriesNotFound
+	}
+	return evt, series, nil
+}
+
+// futureOccurrences возвращает события серии, начинающиеся не раньше from
+func (s *seriesService) futureOccurrences(ctx context.Context, series *EventSeries, from time.Time) ([]event.Event, error) {
+	events, err := s.eventService.ListBySeries(ctx, string(series.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	var result []event.Event
+	for _, evt := range events {
+		if evt.Status.IsActive() && !evt.Date.Before(from) {
+			result = append(result, evt)
+		}
+	}
+	return result, nil
+}
+
+// cancelEvents отменяет события (регистрации сохраняются) и возвращает их для уведомлений
+func (s *seriesService) cancelEvents(ctx context.Context, targets []event.Event) ([]event.Event, error) {
+	cancelled := make([]event.Event, 0, len(targets))
+	for _, target := range targets {
+		evt, err := s.eventService.Cancel(ctx, target.ID)
+		if err != nil {
+			return cancelled, err
+		}
+		cancelled = append(cancelled, *evt)
+	}
+	return cancelled, nil
+}
@@ -1,3 +1,31 @@
 package settings
 
-const KeyChannelIDs = "channel_ids"
+import (
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+const (
+	KeyChannelIDs             = "channel_ids"
+	KeyPendingTimeoutMinutes  = "pending_timeout_minutes"  // Время брони без оплаты
+	KeyPaymentReminderMinutes = "payment_reminder_minutes" // За сколько до снятия брони напоминать об оплате
+	KeyEventReminderMinutes   = "event_reminder_minutes"   // За сколько до начала события напоминать игрокам (список через запятую)
+	KeyNoShowLimit            = "no_show_limit"            // Сколько неявок блокирует самостоятельную запись (0 - не блокировать)
+	KeyNoShowWindowDays       = "no_show_window_days"      // За сколько дней считаются неявки
+	KeyNoShowBlockDays        = "no_show_block_days"       // На сколько дней блокируется запись
+	KeyCancelDeadlineMinutes  = "cancel_deadline_minutes"  // За сколько до начала можно бесплатно отменить запись (0 - в любой момент)
+	KeyLateCancelMode         = "late_cancel_mode"         // Что происходит при поздней отмене: block или penalty
+)
+
+const (
+	DefaultPendingTimeout      = 30 * time.Minute
+	DefaultPaymentReminderLead = 10 * time.Minute
+	DefaultNoShowWindowDays    = 30
+	DefaultNoShowBlockDays     = 14
+	DefaultCancelDeadline      = 24 * time.Hour
+	DefaultLateCancelMode      = event.LateCancelPenalty
+)
+
+// DefaultEventReminderOffsets - напоминания о событии по умолчанию: за сутки и за 2 часа
+var DefaultEventReminderOffsets = []time.Duration{24 * time.Hour, 2 * time.Hour}
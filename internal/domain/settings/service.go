@@ -4,12 +4,33 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
 )
 
 type Service interface {
 	GetChannelIDs(ctx context.Context) ([]int64, error)
 	AddChannelID(ctx context.Context, channelID int64) error
 	RemoveChannelID(ctx context.Context, channelID int64) error
+
+	// Бронь без оплаты (0 - бронь не снимается автоматически)
+	GetPendingTimeout(ctx context.Context) (time.Duration, error)
+	SetPendingTimeout(ctx context.Context, timeout time.Duration) error
+	GetPaymentReminderLead(ctx context.Context) (time.Duration, error)
+	SetPaymentReminderLead(ctx context.Context, lead time.Duration) error
+
+	// Напоминания о предстоящем событии (пустой список - напоминания отключены)
+	GetEventReminderOffsets(ctx context.Context) ([]time.Duration, error)
+	SetEventReminderOffsets(ctx context.Context, offsets []time.Duration) error
+
+	// Политика неявок (Limit == 0 - самостоятельная запись не блокируется)
+	GetNoShowPolicy(ctx context.Context) (event.NoShowPolicy, error)
+	SetNoShowPolicy(ctx context.Context, policy event.NoShowPolicy) error
+
+	// Правило отмены записи (Deadline == 0 - отменить можно в любой момент)
+	GetCancellationPolicy(ctx context.Context) (event.CancellationPolicy, error)
+	SetCancellationPolicy(ctx context.Context, policy event.CancellationPolicy) error
 }
 
 type settingsService struct {
@@ -67,6 +88,151 @@ func (s *settingsService) RemoveChannelID(ctx context.Context, channelID int64)
 	return s.repo.Set(ctx, KeyChannelIDs, joinIDs(filtered))
 }
 
+func (s *settingsService) GetPendingTimeout(ctx context.Context) (time.Duration, error) {
+	return s.getMinutes(ctx, KeyPendingTimeoutMinutes, DefaultPendingTimeout)
+}
+
+func (s *settingsService) SetPendingTimeout(ctx context.Context, timeout time.Duration) error {
+	return s.setMinutes(ctx, KeyPendingTimeoutMinutes, timeout)
+}
+
+func (s *settingsService) GetPaymentReminderLead(ctx context.Context) (time.Duration, error) {
+	return s.getMinutes(ctx, KeyPaymentReminderMinutes, DefaultPaymentReminderLead)
+}
+
+func (s *settingsService) SetPaymentReminderLead(ctx context.Context, lead time.Duration) error {
+	return s.setMinutes(ctx, KeyPaymentReminderMinutes, lead)
+}
+
+func (s *settingsService) GetEventReminderOffsets(ctx context.Context) ([]time.Duration, error) {
+	val, err := s.repo.Get(ctx, KeyEventReminderMinutes)
+	if err != nil {
+		return DefaultEventReminderOffsets, err
+	}
+	if val == "" {
+		return DefaultEventReminderOffsets, nil
+	}
+
+	var offsets []time.Duration
+	for _, part := range strings.Split(val, ",") {
+		minutes, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || minutes <= 0 {
+			continue
+		}
+		offsets = append(offsets, time.Duration(minutes)*time.Minute)
+	}
+	return offsets, nil
+}
+
+func (s *settingsService) SetEventReminderOffsets(ctx context.Context, offsets []time.Duration) error {
+	if len(offsets) == 0 {
+		// "0" отличает отключенные напоминания от незаданной настройки
+		return s.repo.Set(ctx, KeyEventReminderMinutes, "0")
+	}
+	parts := make([]string, len(offsets))
+	for i, d := range offsets {
+		parts[i] = strconv.Itoa(int(d / time.Minute))
+	}
+	return s.repo.Set(ctx, KeyEventReminderMinutes, strings.Join(parts, ","))
+}
+
+// getMinutes читает длительность, сохраненную в минутах; если значение не задано, возвращает def
+func (s *settingsService) GetNoShowPolicy(ctx context.Context) (event.NoShowPolicy, error) {
+	limit, err := s.getInt(ctx, KeyNoShowLimit, 0)
+	if err != nil {
+		return event.NoShowPolicy{}, err
+	}
+	windowDays, err := s.getInt(ctx, KeyNoShowWindowDays, DefaultNoShowWindowDays)
+	if err != nil {
+		return event.NoShowPolicy{}, err
+	}
+	blockDays, err := s.getInt(ctx, KeyNoShowBlockDays, DefaultNoShowBlockDays)
+	if err != nil {
+		return event.NoShowPolicy{}, err
+	}
+	return event.NoShowPolicy{
+		Limit:    limit,
+		Window:   time.Duration(windowDays) * 24 * time.Hour,
+		BlockFor: time.Duration(blockDays) * 24 * time.Hour,
+	}, nil
+}
+
+func (s *settingsService) SetNoShowPolicy(ctx context.Context, policy event.NoShowPolicy) error {
+	if err := s.repo.Set(ctx, KeyNoShowLimit, strconv.Itoa(policy.Limit)); err != nil {
+		return err
+	}
+	if err := s.repo.Set(ctx, KeyNoShowWindowDays, strconv.Itoa(int(policy.Window/(24*time.Hour)))); err != nil {
+		return err
+	}
+	return s.repo.Set(ctx, KeyNoShowBlockDays, strconv.Itoa(int(policy.BlockFor/(24*time.Hour))))
+}
+
+func (s *settingsService) GetCancellationPolicy(ctx context.Context) (event.CancellationPolicy, error) {
+	deadline, err := s.getMinutes(ctx, KeyCancelDeadlineMinutes, DefaultCancelDeadline)
+	if err != nil {
+		return event.CancellationPolicy{}, err
+	}
+	mode, err := s.repo.Get(ctx, KeyLateCancelMode)
+	if err != nil {
+		return event.CancellationPolicy{}, err
+	}
+	late := event.LateCancelMode(mode)
+	if late != event.LateCancelBlock && late != event.LateCancelPenalty {
+		late = DefaultLateCancelMode
+	}
+	if deadline == 0 {
+		late = event.LateCancelNone
+	}
+	return event.CancellationPolicy{Deadline: deadline, Late: late}, nil
+}
+
+func (s *settingsService) SetCancellationPolicy(ctx context.Context, policy event.CancellationPolicy) error {
+	if err := s.setMinutes(ctx, KeyCancelDeadlineMinutes, policy.Deadline); err != nil {
+		return err
+	}
+	if policy.Late != event.LateCancelBlock && policy.Late != event.LateCancelPenalty {
+		return nil
+	}
+	return s.repo.Set(ctx, KeyLateCancelMode, string(policy.Late))
+}
+
+func (s *settingsService) getInt(ctx context.Context, key string, def int) (int, error) {
+	val, err := s.repo.Get(ctx, key)
+	if err != nil {
+		return def, err
+	}
+	if val == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || n < 0 {
+		return def, nil
+	}
+	return n, nil
+}
+
+func (s *settingsService) getMinutes(ctx context.Context, key string, def time.Duration) (time.Duration, error) {
+	val, err := s.repo.Get(ctx, key)
+	if err != nil {
+		return def, err
+	}
+	if val == "" {
+		return def, nil
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || minutes < 0 {
+		return def, nil
+	}
+	return time.Duration(minutes) * time.Minute, nil
+}
+
+func (s *settingsService) setMinutes(ctx context.Context, key string, d time.Duration) error {
+	if d < 0 {
+		d = 0
+	}
+	return s.repo.Set(ctx, key, strconv.Itoa(int(d/time.Minute)))
+}
+
 func joinIDs(ids []int64) string {
 	parts := make([]string, len(ids))
 	for i, id := range ids {
@@ -0,0 +1,141 @@
+package subscription
+
+import (
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/user"
+)
+
+// Subscription - подписка игрока на новые события. Пустой фильтр означает «любые»
+type Subscription struct {
+	UserID      int64 // Telegram ID игрока
+	Enabled     bool
+	LocationIDs []location.LocationID
+	EventTypes  []event.EventType
+	Trainers    []string
+	MinLevel    user.Level // Уровень событий от (0 - без ограничения)
+	MaxLevel    user.Level // Уровень событий до (0 - без ограничения)
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Matches сообщает, подходит ли событие под фильтры подписки
+func (s *Subscription) Matches(evt *event.Event) bool {
+	if !s.Enabled {
+		return false
+	}
+	if len(s.LocationIDs) > 0 && !containsLocation(s.LocationIDs, evt.LocationID) {
+		return false
+	}
+	if len(s.EventTypes) > 0 && !containsType(s.EventTypes, evt.Type) {
+		return false
+	}
+	if len(s.Trainers) > 0 && !containsTrainer(s.Trainers, evt.Trainer) {
+		return false
+	}
+	return s.levelOverlaps(evt.MinLevel, evt.MaxLevel)
+}
+
+// levelOverlaps проверяет, что диапазон уровней события пересекается с диапазоном подписки
+func (s *Subscription) levelOverlaps(min, max user.Level) bool {
+	if s.MinLevel.IsSet() && max.IsSet() && max < s.MinLevel {
+		return false
+	}
+	if s.MaxLevel.IsSet() && min.IsSet() && min > s.MaxLevel {
+		return false
+	}
+	return true
+}
+
+// ToggleLocation добавляет локацию в фильтр или убирает ее
+func (s *Subscription) ToggleLocation(id location.LocationID) {
+	if !containsLocation(s.LocationIDs, id) {
+		s.LocationIDs = append(s.LocationIDs, id)
+		return
+	}
+	kept := make([]location.LocationID, 0, len(s.LocationIDs))
+	for _, candidate := range s.LocationIDs {
+		if candidate != id {
+			kept = append(kept, candidate)
+		}
+	}
+	s.LocationIDs = kept
+}
+
+// ToggleEventType добавляет тип события в фильтр или убирает его
+func (s *Subscription) ToggleEventType(t event.EventType) {
+	if !containsType(s.EventTypes, t) {
+		s.EventTypes = append(s.EventTypes, t)
+		return
+	}
+	kept := make([]event.EventType, 0, len(s.EventTypes))
+	for _, candidate := range s.EventTypes {
+		if candidate != t {
+			kept = append(kept, candidate)
+		}
+	}
+	s.EventTypes = kept
+}
+
+// ToggleTrainer добавляет тренера в фильтр или убирает его
+func (s *Subscription) ToggleTrainer(name string) {
+	if !containsTrainer(s.Trainers, name) {
+		s.Trainers = append(s.Trainers, name)
+		return
+	}
+	kept := make([]string, 0, len(s.Trainers))
+	for _, candidate := range s.Trainers {
+		if candidate != name {
+			kept = append(kept, candidate)
+		}
+	}
+	s.Trainers = kept
+}
+
+// SetLevelRange задает диапазон уровней событий (LevelUnset - без ограничения с этой стороны)
+func (s *Subscription) SetLevelRange(min, max user.Level) error {
+	if (min.IsSet() && !min.Valid()) || (max.IsSet() && !max.Valid()) {
+		return user.ErrInvalidLevel
+	}
+	if min.IsSet() && max.IsSet() && min > max {
+		return ErrLevelRangeInvalid
+	}
+	s.MinLevel = min
+	s.MaxLevel = max
+	return nil
+}
+
+func containsLocation(ids []location.LocationID, id location.LocationID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []event.EventType, t event.EventType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTrainer(trainers []string, name string) bool {
+	for _, candidate := range trainers {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrLevelRangeInvalid = errors.New("min level cannot be greater than max level")
+	ErrEventTypeInvalid  = errors.New("unknown event type")
+)
@@ -0,0 +1,18 @@
+package subscription
+
+import "context"
+
+// Repository описывает хранилище подписок на новые события
+type Repository interface {
+	// Get возвращает подписку игрока или nil, если он еще не подписывался
+	Get(ctx context.Context, userID int64) (*Subscription, error)
+
+	// Save создает или обновляет подписку игрока
+	Save(ctx context.Context, s *Subscription) error
+
+	// ListEnabled возвращает включенные подписки
+	ListEnabled(ctx context.Context) ([]Subscription, error)
+
+	// Delete удаляет подписку игрока
+	Delete(ctx context.Context, userID int64) error
+}
@@ -0,0 +1,78 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Service описывает use-case'ы вокруг подписок на новые события
+type Service interface {
+	// Get возвращает подписку игрока или nil, если он еще не подписывался
+	Get(ctx context.Context, userID int64) (*Subscription, error)
+	// Update изменяет подписку игрока (создает выключенную, если ее нет) и сохраняет ее, если fn вернула nil
+	Update(ctx context.Context, userID int64, fn func(s *Subscription) error) (*Subscription, error)
+	// Matching возвращает включенные подписки, под фильтры которых подходит событие
+	Matching(ctx context.Context, evt *event.Event) ([]Subscription, error)
+	// Delete удаляет подписку игрока
+	Delete(ctx context.Context, userID int64) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Get(ctx context.Context, userID int64) (*Subscription, error) {
+	return s.repo.Get(ctx, userID)
+}
+
+func (s *service) Update(ctx context.Context, userID int64, fn func(sub *Subscription) error) (*Subscription, error) {
+	sub, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		sub = &Subscription{UserID: userID, CreatedAt: time.Now()}
+	}
+
+	if err := fn(sub); err != nil {
+		return nil, err
+	}
+	for _, t := range sub.EventTypes {
+		if t != event.EventTypeTraining && t != event.EventTypeCompetition {
+			return nil, ErrEventTypeInvalid
+		}
+	}
+	if sub.MinLevel.IsSet() && sub.MaxLevel.IsSet() && sub.MinLevel > sub.MaxLevel {
+		return nil, ErrLevelRangeInvalid
+	}
+
+	sub.UpdatedAt = time.Now()
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *service) Matching(ctx context.Context, evt *event.Event) ([]Subscription, error) {
+	subs, err := s.repo.ListEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matching []Subscription
+	for i := range subs {
+		if subs[i].Matches(evt) {
+			matching = append(matching, subs[i])
+		}
+	}
+	return matching, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID int64) error {
+	return s.repo.Delete(ctx, userID)
+}
@@ -0,0 +1,669 @@
+package tournament
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// TournamentID - тип для ID турнира
+type TournamentID string
+
+// Format - формат турнира
+type Format string
+
+const (
+	FormatRoundRobin        Format = "round_robin"        // Круговая система (в одной или нескольких группах)
+	FormatSingleElimination Format = "single_elimination" // Олимпийская система (выбывание после первого поражения)
+	FormatDoubleElimination Format = "double_elimination" // Выбывание после второго поражения (сетки победителей и проигравших)
+)
+
+// Valid проверяет, что формат известен
+func (f Format) Valid() bool {
+	switch f {
+	case FormatRoundRobin, FormatSingleElimination, FormatDoubleElimination:
+		return true
+	}
+	return false
+}
+
+// Status - статус турнира
+type Status string
+
+const (
+	StatusInProgress Status = "in_progress" // Идут матчи
+	StatusFinished   Status = "finished"    // Все матчи сыграны
+)
+
+// Stage - часть турнира, к которой относится матч
+type Stage string
+
+const (
+	StagePool    Stage = "pool"    // Групповой этап круговой системы
+	StageWinners Stage = "winners" // Сетка победителей (или единственная сетка олимпийской системы)
+	StageLosers  Stage = "losers"  // Сетка проигравших (double elimination)
+	StageFinal   Stage = "final"   // Суперфинал double elimination (тур 2 - переигровка, если первый матч выиграл участник из сетки проигравших)
+)
+
+// Специальные значения участника матча
+const (
+	NoParticipant = 0  // Участник еще не определен (ждет результата предыдущего матча)
+	Bye           = -1 // Свободный проход: соперника нет, второй участник проходит дальше без игры
+)
+
+// teamNameSeparator разделяет имена игроков в имени команды
+const teamNameSeparator = " / "
+
+// Participant - участник турнира: игрок или команда
+type Participant struct {
+	ID      int     // Номер посева, начиная с 1
+	Name    string  // Отображаемое имя
+	UserIDs []int64 // Telegram ID игроков участника
+}
+
+// Link - куда переходит участник после матча
+type Link struct {
+	MatchID int // ID матча (0 - никуда)
+	Side    int // 0 - участник A, 1 - участник B
+}
+
+// Match - матч турнира
+type Match struct {
+	ID       int
+	Stage    Stage
+	Round    int // Номер тура внутри Stage, начиная с 1
+	Pool     int // Номер группы круговой системы, начиная с 1 (0 - для сеток)
+	A        int // ID участника или NoParticipant/Bye
+	B        int
+	ScoreA   int
+	ScoreB   int
+	Winner   int  // ID победителя (NoParticipant - матч не сыгран)
+	Bye      bool // Матч завершен автоматически из-за свободного прохода
+	WinnerTo Link
+	LoserTo  Link
+}
+
+// Played сообщает, что результат матча внесен администратором
+func (m *Match) Played() bool {
+	return m.Winner != NoParticipant && !m.Bye
+}
+
+// Ready сообщает, что оба участника известны и матч можно играть
+func (m *Match) Ready() bool {
+	return m.Winner == NoParticipant && m.A > 0 && m.B > 0
+}
+
+// Loser возвращает проигравшего в завершенном матче
+func (m *Match) Loser() int {
+	if m.Winner == NoParticipant {
+		return NoParticipant
+	}
+	if m.Winner == m.A {
+		return m.B
+	}
+	return m.A
+}
+
+// Tournament - турнир, проводимый в рамках соревнования
+type Tournament struct {
+	ID           TournamentID
+	EventID      event.EventID
+	Format       Format
+	Pools        int // Количество групп круговой системы
+	Status       Status
+	Participants []Participant
+	Matches      []Match
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// CreateInput - данные для создания турнира
+type CreateInput struct {
+	EventID      event.EventID
+	Format       Format
+	Pools        int  // Только для круговой системы (0 - одна группа)
+	SeedByRating bool // Посев по рейтингу игроков (иначе в порядке записи)
+}
+
+// Standing - строка турнирной таблицы группы
+type Standing struct {
+	Participant   Participant
+	Played        int
+	Won           int
+	Lost          int
+	PointsFor     int
+	PointsAgainst int
+}
+
+// MinParticipants - минимальное количество участников турнира
+const MinParticipants = 2
+
+var (
+	ErrTournamentNotFound    = errors.New("tournament not found")
+	ErrTournamentExists      = errors.New("tournament already exists for this event")
+	ErrNotCompetition        = errors.New("tournaments are available only for competitions")
+	ErrInvalidFormat         = errors.New("invalid tournament format")
+	ErrInvalidPools          = errors.New("invalid number of pools")
+	ErrNotEnoughParticipants = errors.New("not enough participants")
+	ErrMatchNotFound         = errors.New("match not found")
+	ErrMatchNotReady         = errors.New("match participants are not determined yet")
+	ErrMatchLocked           = errors.New("match result affects matches that are already played")
+	ErrInvalidScore          = errors.New("invalid score")
+)
+
+// Participant возвращает участника по ID или nil
+func (t *Tournament) Participant(id int) *Participant {
+	if id <= 0 || id > len(t.Participants) {
+		return nil
+	}
+	return &t.Participants[id-1]
+}
+
+// Match возвращает матч по ID или nil
+func (t *Tournament) Match(id int) *Match {
+	if id <= 0 || id > len(t.Matches) {
+		return nil
+	}
+	return &t.Matches[id-1]
+}
+
+// ReadyMatches возвращает матчи, которые можно играть прямо сейчас
+func (t *Tournament) ReadyMatches() []Match {
+	var result []Match
+	for _, m := range t.Matches {
+		if m.Ready() {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// ParticipantOf возвращает участника, в состав которого входит игрок, или nil
+func (t *Tournament) ParticipantOf(userID int64) *Participant {
+	for i := range t.Participants {
+		for _, id := range t.Participants[i].UserIDs {
+			if id == userID {
+				return &t.Participants[i]
+			}
+		}
+	}
+	return nil
+}
+
+// RenamePlayer заменяет имя игрока в именах участников, в которые он входит (у команды - только его часть имени).
+// Возвращает false, если игрок в турнире не участвовал
+func (t *Tournament) RenamePlayer(userID int64, name string) bool {
+	renamed := false
+	for i := range t.Participants {
+		p := &t.Participants[i]
+		for j, id := range p.UserIDs {
+			if id != userID {
+				continue
+			}
+			// Имя команды собрано из имен игроков в порядке UserIDs (см. playerName)
+			parts := strings.Split(p.Name, teamNameSeparator)
+			if len(parts) == len(p.UserIDs) {
+				parts[j] = name
+				p.Name = strings.Join(parts, teamNameSeparator)
+			} else {
+				p.Name = name
+			}
+			renamed = true
+		}
+	}
+	return renamed
+}
+
+// Champion возвращает победителя турнира (NoParticipant, пока турнир не завершен).
+// Для круговой системы с одной группой - лидер таблицы, с несколькими группами победителя нет
+func (t *Tournament) Champion() int {
+	if t.Status != StatusFinished {
+		return NoParticipant
+	}
+	switch t.Format {
+	case FormatRoundRobin:
+		if t.Pools > 1 {
+			return NoParticipant
+		}
+		standings := t.Standings(1)
+		if len(standings) == 0 {
+			return NoParticipant
+		}
+		return standings[0].Participant.ID
+	default:
+		if final, reset := t.grandFinal(); reset != nil && reset.Winner == Bye {
+			return final.Winner
+		}
+		return t.Matches[len(t.Matches)-1].Winner
+	}
+}
+
+// grandFinal возвращает суперфинал double elimination и его переигровку (nil, если переигровки в сетке нет)
+func (t *Tournament) grandFinal() (final, reset *Match) {
+	n := len(t.Matches)
+	if t.Format != FormatDoubleElimination || n < 2 {
+		return nil, nil
+	}
+	final, reset = &t.Matches[n-2], &t.Matches[n-1]
+	if final.Stage != StageFinal || reset.Stage != StageFinal {
+		return nil, nil
+	}
+	return final, reset
+}
+
+// Standings возвращает турнирную таблицу группы круговой системы, отсортированную по победам,
+// затем по разнице очков и по номеру посева
+func (t *Tournament) Standings(pool int) []Standing {
+	rows := make(map[int]*Standing)
+	var order []int
+	add := func(id int) *Standing {
+		if row, ok := rows[id]; ok {
+			return row
+		}
+		p := t.Participant(id)
+		if p == nil {
+			return nil
+		}
+		rows[id] = &Standing{Participant: *p}
+		order = append(order, id)
+		return rows[id]
+	}
+
+	for _, m := range t.Matches {
+		if m.Stage != StagePool || m.Pool != pool {
+			continue
+		}
+		a, b := add(m.A), add(m.B)
+		if !m.Played() || a == nil || b == nil {
+			continue
+		}
+		a.Played++
+		b.Played++
+		a.PointsFor += m.ScoreA
+		a.PointsAgainst += m.ScoreB
+		b.PointsFor += m.ScoreB
+		b.PointsAgainst += m.ScoreA
+		if m.Winner == m.A {
+			a.Won++
+			b.Lost++
+		} else {
+			b.Won++
+			a.Lost++
+		}
+	}
+
+	result := make([]Standing, 0, len(order))
+	for _, id := range order {
+		result = append(result, *rows[id])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Won != result[j].Won {
+			return result[i].Won > result[j].Won
+		}
+		di := result[i].PointsFor - result[i].PointsAgainst
+		dj := result[j].PointsFor - result[j].PointsAgainst
+		if di != dj {
+			return di > dj
+		}
+		return result[i].Participant.ID < result[j].Participant.ID
+	})
+	return result
+}
+
+// RecordScore вносит счет матча, продвигает победителя (и проигравшего в double elimination)
+// и завершает турнир, когда сыграны все матчи. Счет уже сыгранного матча можно исправить,
+// пока от него не зависят сыгранные матчи
+func (t *Tournament) RecordScore(matchID, scoreA, scoreB int) error {
+	m := t.Match(matchID)
+	if m == nil {
+		return ErrMatchNotFound
+	}
+	if m.A <= 0 || m.B <= 0 {
+		return ErrMatchNotReady
+	}
+	// Ничьих в пиклболе нет
+	if scoreA < 0 || scoreB < 0 || scoreA == scoreB {
+		return ErrInvalidScore
+	}
+
+	winner := m.A
+	if scoreB > scoreA {
+		winner = m.B
+	}
+
+	if m.Played() && m.Winner != winner {
+		// Победитель сменился - переигрываем все, что зависело от прежнего результата
+		if !t.resettable(m.WinnerTo.MatchID) || !t.resettable(m.LoserTo.MatchID) {
+			return ErrMatchLocked
+		}
+		// От суперфинала зависит его переигровка: исправить счет можно, только пока она не сыграна
+		if final, reset := t.grandFinal(); final == m && reset.Played() {
+			return ErrMatchLocked
+		}
+		t.clearSlot(m.WinnerTo)
+		t.clearSlot(m.LoserTo)
+	}
+
+	m.ScoreA = scoreA
+	m.ScoreB = scoreB
+	m.Winner = winner
+	m.Bye = false
+	t.advance()
+	return nil
+}
+
+// resettable проверяет, что матч и все, что следует за ним, еще не сыграны
+func (t *Tournament) resettable(matchID int) bool {
+	m := t.Match(matchID)
+	if m == nil {
+		return true
+	}
+	if m.Played() {
+		return false
+	}
+	if m.Bye {
+		return t.resettable(m.WinnerTo.MatchID) && t.resettable(m.LoserTo.MatchID)
+	}
+	return true
+}
+
+// clearSlot убирает участника из матча и отменяет автоматические проходы, сделанные с его участием
+func (t *Tournament) clearSlot(link Link) {
+	m := t.Match(link.MatchID)
+	if m == nil {
+		return
+	}
+	if link.Side == 0 {
+		m.A = NoParticipant
+	} else {
+		m.B = NoParticipant
+	}
+	if m.Bye {
+		m.Winner = NoParticipant
+		m.Bye = false
+		t.clearSlot(m.WinnerTo)
+		t.clearSlot(m.LoserTo)
+	}
+}
+
+// place ставит участника в матч, на который указывает link
+func (t *Tournament) place(link Link, participant int) {
+	m := t.Match(link.MatchID)
+	if m == nil {
+		return
+	}
+	if link.Side == 0 {
+		m.A = participant
+	} else {
+		m.B = participant
+	}
+}
+
+// advance разносит результаты завершенных матчей по следующим матчам, автоматически
+// проводит свободные проходы и обновляет статус турнира
+func (t *Tournament) advance() {
+	for changed := true; changed; {
+		changed = false
+		for i := range t.Matches {
+			m := &t.Matches[i]
+			if m.Winner == NoParticipant && m.A != NoParticipant && m.B != NoParticipant && (m.A == Bye || m.B == Bye) {
+				m.Winner = m.A
+				if m.A == Bye {
+					m.Winner = m.B
+				}
+				m.Bye = true
+				changed = true
+			}
+			if m.Winner == NoParticipant {
+				continue
+			}
+			if next := t.Match(m.WinnerTo.MatchID); next != nil && slotOf(next, m.WinnerTo.Side) != m.Winner {
+				t.place(m.WinnerTo, m.Winner)
+				changed = true
+			}
+			if next := t.Match(m.LoserTo.MatchID); next != nil && slotOf(next, m.LoserTo.Side) != m.Loser() {
+				t.place(m.LoserTo, m.Loser())
+				changed = true
+			}
+		}
+	}
+	t.settleBracketReset()
+
+	t.Status = StatusFinished
+	for _, m := range t.Matches {
+		if m.Winner == NoParticipant {
+			t.Status = StatusInProgress
+			return
+		}
+	}
+}
+
+// settleBracketReset назначает переигровку суперфинала: она нужна, только если первый матч суперфинала выиграл
+// участник из сетки проигравших (у победителя сетки победителей это первое поражение). Иначе переигровка
+// помечается несостоявшейся: оба места - свободные проходы
+func (t *Tournament) settleBracketReset() {
+	final, reset := t.grandFinal()
+	if reset == nil {
+		return
+	}
+	switch {
+	case final.Winner == NoParticipant:
+		reset.A, reset.B = NoParticipant, NoParticipant
+		reset.Winner, reset.Bye = NoParticipant, false
+	case !final.Played() || final.Winner == final.A:
+		reset.A, reset.B = Bye, Bye
+		reset.Winner, reset.Bye = Bye, true
+	case reset.A != final.A || reset.B != final.B:
+		reset.A, reset.B = final.A, final.B
+		reset.ScoreA, reset.ScoreB = 0, 0
+		reset.Winner, reset.Bye = NoParticipant, false
+	}
+}
+
+func slotOf(m *Match, side int) int {
+	if side == 0 {
+		return m.A
+	}
+	return m.B
+}
+
+// New создает турнир и генерирует сетку или группы. Участники должны быть отсортированы по посеву
+func New(id TournamentID, in CreateInput, participants []Participant, now time.Time) (*Tournament, error) {
+	if !in.Format.Valid() {
+		return nil, ErrInvalidFormat
+	}
+	if len(participants) < MinParticipants {
+		return nil, ErrNotEnoughParticipants
+	}
+
+	pools := in.Pools
+	if in.Format != FormatRoundRobin {
+		pools = 0
+	} else {
+		if pools == 0 {
+			pools = 1
+		}
+		// В каждой группе должно быть минимум два участника
+		if pools < 1 || len(participants) < pools*MinParticipants {
+			return nil, ErrInvalidPools
+		}
+	}
+
+	t := &Tournament{
+		ID:        id,
+		EventID:   in.EventID,
+		Format:    in.Format,
+		Pools:     pools,
+		Status:    StatusInProgress,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i, p := range participants {
+		p.ID = i + 1
+		t.Participants = append(t.Participants, p)
+	}
+
+	switch in.Format {
+	case FormatRoundRobin:
+		t.generatePools()
+	case FormatSingleElimination:
+		t.generateElimination(false)
+	case FormatDoubleElimination:
+		t.generateElimination(true)
+	}
+	t.advance()
+	return t, nil
+}
+
+// addMatch добавляет матч и возвращает его ID
+func (t *Tournament) addMatch(m Match) int {
+	m.ID = len(t.Matches) + 1
+	t.Matches = append(t.Matches, m)
+	return m.ID
+}
+
+// generatePools раскладывает участников по группам "змейкой" по посеву
+// и составляет расписание круговой системы методом вращения
+func (t *Tournament) generatePools() {
+	groups := make([][]int, t.Pools)
+	for i, p := range t.Participants {
+		pos := i % t.Pools
+		if (i/t.Pools)%2 == 1 {
+			pos = t.Pools - 1 - pos
+		}
+		groups[pos] = append(groups[pos], p.ID)
+	}
+
+	for poolIdx, ids := range groups {
+		if len(ids)%2 == 1 {
+			ids = append(ids, NoParticipant) // Фиктивный соперник: кто с ним в паре, отдыхает в туре
+		}
+		n := len(ids)
+		for round := 1; round < n; round++ {
+			for i := 0; i < n/2; i++ {
+				a, b := ids[i], ids[n-1-i]
+				if a == NoParticipant || b == NoParticipant {
+					continue
+				}
+				t.addMatch(Match{Stage: StagePool, Round: round, Pool: poolIdx + 1, A: a, B: b})
+			}
+			// Первый участник остается на месте, остальные сдвигаются по кругу
+			rotated := append([]int{ids[0], ids[n-1]}, ids[1:n-1]...)
+			ids = rotated
+		}
+	}
+}
+
+// seedOrder возвращает порядок посева в сетке размера size, при котором сильнейшие встречаются как можно позже
+func seedOrder(size int) []int {
+	order := []int{1}
+	for n := 1; n < size; n *= 2 {
+		next := make([]int, 0, n*2)
+		for _, s := range order {
+			next = append(next, s, 2*n+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+// generateElimination строит сетку на выбывание. Недостающие до степени двойки места
+// достаются сильнейшим посевам как свободные проходы
+func (t *Tournament) generateElimination(double bool) {
+	size := 2
+	for size < len(t.Participants) {
+		size *= 2
+	}
+	rounds := 0
+	for n := size; n > 1; n /= 2 {
+		rounds++
+	}
+
+	seed := func(s int) int {
+		if s > len(t.Participants) {
+			return Bye
+		}
+		return s
+	}
+
+	// Сетка победителей: winners[r][i] - ID матча i в туре r+1
+	winners := make([][]int, rounds)
+	order := seedOrder(size)
+	for r := 0; r < rounds; r++ {
+		count := size >> (r + 1)
+		for i := 0; i < count; i++ {
+			m := Match{Stage: StageWinners, Round: r + 1}
+			if r == 0 {
+				m.A = seed(order[2*i])
+				m.B = seed(order[2*i+1])
+			}
+			winners[r] = append(winners[r], t.addMatch(m))
+		}
+	}
+	for r := 0; r+1 < rounds; r++ {
+		for i, id := range winners[r] {
+			t.Match(id).WinnerTo = Link{MatchID: winners[r+1][i/2], Side: i % 2}
+		}
+	}
+
+	if !double {
+		return
+	}
+
+	// Сетка проигравших: нечетные туры сводят между собой победителей предыдущего тура,
+	// четные - победителей предыдущего тура с проигравшими очередного тура сетки победителей
+	var losers [][]int
+	if rounds > 1 {
+		first := make([]int, 0, size/4)
+		for i := 0; i < size/4; i++ {
+			id := t.addMatch(Match{Stage: StageLosers, Round: 1})
+			first = append(first, id)
+			t.Match(winners[0][2*i]).LoserTo = Link{MatchID: id, Side: 0}
+			t.Match(winners[0][2*i+1]).LoserTo = Link{MatchID: id, Side: 1}
+		}
+		losers = append(losers, first)
+
+		for j := 1; j < rounds; j++ {
+			// Четный тур: победители предыдущего тура против проигравших тура j+1 сетки победителей
+			prev := losers[len(losers)-1]
+			dropped := winners[j]
+			even := make([]int, 0, len(prev))
+			for i, prevID := range prev {
+				id := t.addMatch(Match{Stage: StageLosers, Round: len(losers) + 1})
+				even = append(even, id)
+				t.Match(prevID).WinnerTo = Link{MatchID: id, Side: 0}
+				// Проигравших подсаживаем в обратном порядке, чтобы отложить повторные встречи
+				t.Match(dropped[len(dropped)-1-i]).LoserTo = Link{MatchID: id, Side: 1}
+			}
+			losers = append(losers, even)
+
+			if len(even) == 1 {
+				break
+			}
+			// Нечетный тур: победители четного тура играют между собой
+			odd := make([]int, 0, len(even)/2)
+			for i := 0; i < len(even); i += 2 {
+				id := t.addMatch(Match{Stage: StageLosers, Round: len(losers) + 1})
+				odd = append(odd, id)
+				t.Match(even[i]).WinnerTo = Link{MatchID: id, Side: 0}
+				t.Match(even[i+1]).WinnerTo = Link{MatchID: id, Side: 1}
+			}
+			losers = append(losers, odd)
+		}
+	}
+
+	// Переигровка суперфинала добавляется сразу, а играется, только если ее назначит settleBracketReset
+	final := t.addMatch(Match{Stage: StageFinal, Round: 1})
+	t.addMatch(Match{Stage: StageFinal, Round: 2})
+	t.Match(winners[rounds-1][0]).WinnerTo = Link{MatchID: final, Side: 0}
+	if len(losers) > 0 {
+		last := losers[len(losers)-1]
+		t.Match(last[0]).WinnerTo = Link{MatchID: final, Side: 1}
+	} else {
+		// Два участника: проигравший в сетке победителей сразу получает второй шанс в финале
+		t.Match(winners[0][0]).LoserTo = Link{MatchID: final, Side: 1}
+	}
+}
@@ -0,0 +1,211 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTestTournament создает турнир из n участников с посевом по порядку
+func newTestTournament(t *testing.T, format Format, n int) *Tournament {
+	t.Helper()
+	participants := make([]Participant, 0, n)
+	for i := 1; i <= n; i++ {
+		participants = append(participants, Participant{Name: fmt.Sprintf("Игрок %d", i), UserIDs: []int64{int64(i)}})
+	}
+	tr, err := New("t1", CreateInput{EventID: "e1", Format: format}, participants, time.Now())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return tr
+}
+
+// recordWin вносит победу участника winner в матче matchID со счетом 11:5
+func recordWin(t *testing.T, tr *Tournament, matchID, winner int) error {
+	t.Helper()
+	m := tr.Match(matchID)
+	if m == nil {
+		t.Fatalf("match %d not found", matchID)
+	}
+	if winner == m.A {
+		return tr.RecordScore(matchID, 11, 5)
+	}
+	return tr.RecordScore(matchID, 5, 11)
+}
+
+// playFavourites доигрывает турнир: в каждом матче побеждает участник с меньшим номером посева
+func playFavourites(t *testing.T, tr *Tournament) {
+	t.Helper()
+	for ready := tr.ReadyMatches(); len(ready) > 0; ready = tr.ReadyMatches() {
+		for _, m := range ready {
+			winner := m.A
+			if m.B < m.A {
+				winner = m.B
+			}
+			if err := recordWin(t, tr, m.ID, winner); err != nil {
+				t.Fatalf("match %d: %v", m.ID, err)
+			}
+		}
+	}
+}
+
+func TestEliminationBrackets(t *testing.T) {
+	tests := []struct {
+		format  Format
+		players int
+		matches int // Матчей в сетке, включая переигровку суперфинала
+		byes    int // Свободных проходов сразу после создания
+		ready   int // Матчей, которые можно играть сразу
+		played  int // Сыгранных матчей, если всегда побеждает фаворит
+	}{
+		{FormatSingleElimination, 2, 1, 0, 1, 1},
+		{FormatSingleElimination, 3, 3, 1, 1, 2},
+		{FormatSingleElimination, 5, 7, 3, 2, 4},
+		{FormatSingleElimination, 8, 7, 0, 4, 7},
+		{FormatDoubleElimination, 2, 3, 0, 1, 2},
+		{FormatDoubleElimination, 3, 7, 1, 1, 4},
+		{FormatDoubleElimination, 5, 15, 4, 2, 8},
+		{FormatDoubleElimination, 8, 15, 0, 4, 14},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.format, tt.players), func(t *testing.T) {
+			tr := newTestTournament(t, tt.format, tt.players)
+			if len(tr.Matches) != tt.matches {
+				t.Fatalf("matches = %d, want %d", len(tr.Matches), tt.matches)
+			}
+			byes := 0
+			for _, m := range tr.Matches {
+				if m.Bye {
+					byes++
+				}
+			}
+			if byes != tt.byes {
+				t.Errorf("byes = %d, want %d", byes, tt.byes)
+			}
+			if got := len(tr.ReadyMatches()); got != tt.ready {
+				t.Errorf("ready matches = %d, want %d", got, tt.ready)
+			}
+
+			playFavourites(t, tr)
+			if tr.Status != StatusFinished {
+				t.Fatalf("status = %s, want %s", tr.Status, StatusFinished)
+			}
+			if champion := tr.Champion(); champion != 1 {
+				t.Errorf("champion = %d, want 1", champion)
+			}
+			played := 0
+			for _, m := range tr.Matches {
+				if m.Played() {
+					played++
+				}
+			}
+			if played != tt.played {
+				t.Errorf("played matches = %d, want %d", played, tt.played)
+			}
+		})
+	}
+}
+
+func TestRecordScoreCorrection(t *testing.T) {
+	// Олимпийская система на 4 участника: матчи 1 (1-4) и 2 (2-3) ведут в финал 3
+	tests := []struct {
+		name      string
+		playFinal bool
+		winner    int // Новый победитель матча 1
+		wantErr   error
+		wantSlot  int // Кто после исправления стоит в финале на месте победителя матча 1
+	}{
+		{name: "смена победителя до финала", winner: 4, wantSlot: 4},
+		{name: "смена победителя после финала", playFinal: true, winner: 4, wantErr: ErrMatchLocked, wantSlot: 1},
+		{name: "исправление счета без смены победителя", playFinal: true, winner: 1, wantSlot: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTournament(t, FormatSingleElimination, 4)
+			if err := recordWin(t, tr, 1, 1); err != nil {
+				t.Fatal(err)
+			}
+			if err := recordWin(t, tr, 2, 2); err != nil {
+				t.Fatal(err)
+			}
+			if tt.playFinal {
+				if err := recordWin(t, tr, 3, 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := recordWin(t, tr, 1, tt.winner)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RecordScore error = %v, want %v", err, tt.wantErr)
+			}
+			if got := tr.Match(3).A; got != tt.wantSlot {
+				t.Errorf("final A = %d, want %d", got, tt.wantSlot)
+			}
+			if tt.wantErr == nil && tr.Match(1).Winner != tt.winner {
+				t.Errorf("match 1 winner = %d, want %d", tr.Match(1).Winner, tt.winner)
+			}
+		})
+	}
+}
+
+func TestBracketReset(t *testing.T) {
+	// Double elimination на 2 участника: матч 1 в сетке победителей, 2 - суперфинал, 3 - переигровка
+	tests := []struct {
+		name         string
+		finalWinners []int // Победители суперфинала по очереди (последний - после исправления счета)
+		resetWinner  int   // Победитель переигровки (0 - не играем)
+		wantReady    bool  // Переигровка назначена и ждет игры
+		wantStatus   Status
+		wantChampion int
+	}{
+		{name: "суперфинал выиграл победитель сетки победителей", finalWinners: []int{1}, wantStatus: StatusFinished, wantChampion: 1},
+		{name: "суперфинал выиграл участник из сетки проигравших", finalWinners: []int{2}, wantReady: true, wantStatus: StatusInProgress, wantChampion: NoParticipant},
+		{name: "переигровка сыграна", finalWinners: []int{2}, resetWinner: 2, wantStatus: StatusFinished, wantChampion: 2},
+		{name: "исправленный суперфинал отменяет переигровку", finalWinners: []int{2, 1}, wantStatus: StatusFinished, wantChampion: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTournament(t, FormatDoubleElimination, 2)
+			if err := recordWin(t, tr, 1, 1); err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.finalWinners {
+				if err := recordWin(t, tr, 2, w); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.resetWinner != 0 {
+				if err := recordWin(t, tr, 3, tt.resetWinner); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			reset := tr.Match(3)
+			if reset.Ready() != tt.wantReady {
+				t.Errorf("reset ready = %v, want %v", reset.Ready(), tt.wantReady)
+			}
+			if tt.resetWinner == 0 && !tt.wantReady && !reset.Bye {
+				t.Errorf("reset is not skipped: %+v", *reset)
+			}
+			if tr.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", tr.Status, tt.wantStatus)
+			}
+			if champion := tr.Champion(); champion != tt.wantChampion {
+				t.Errorf("champion = %d, want %d", champion, tt.wantChampion)
+			}
+		})
+	}
+
+	t.Run("суперфинал нельзя исправить после переигровки", func(t *testing.T) {
+		tr := newTestTournament(t, FormatDoubleElimination, 2)
+		for _, step := range []struct{ match, winner int }{{1, 1}, {2, 2}, {3, 2}} {
+			if err := recordWin(t, tr, step.match, step.winner); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := recordWin(t, tr, 2, 1); !errors.Is(err, ErrMatchLocked) {
+			t.Fatalf("RecordScore error = %v, want %v", err, ErrMatchLocked)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package tournament
+
+import (
+	"context"
+
+	"pickletlgbot/internal/domain/event"
+)
+
+// Repository описывает, что нужно домену от хранилища турниров
+type Repository interface {
+	// GetByID возвращает турнир по ID или nil, если не найден
+	GetByID(ctx context.Context, id TournamentID) (*Tournament, error)
+
+	// GetByEvent возвращает турнир соревнования или nil, если турнир не создан
+	GetByEvent(ctx context.Context, eventID event.EventID) (*Tournament, error)
+
+	// List возвращает все турниры вместе с участниками и матчами
+	List(ctx context.Context) ([]Tournament, error)
+
+	// Create сохраняет новый турнир вместе с участниками и матчами
+	Create(ctx context.Context, t *Tournament) error
+
+	// Update загружает турнир с блокировкой, применяет fn и сохраняет результат в одной транзакции.
+	// Ошибка fn откатывает транзакцию и возвращается как есть; если турнира нет - ErrTournamentNotFound
+	Update(ctx context.Context, id TournamentID, fn func(t *Tournament) error) (*Tournament, error)
+
+	// Delete удаляет турнир вместе с участниками и матчами
+	Delete(ctx context.Context, id TournamentID) error
+}
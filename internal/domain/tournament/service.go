@@ -0,0 +1,223 @@
+package tournament
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+// Service описывает use-case'ы вокруг турниров
+type Service interface {
+	Get(ctx context.Context, id TournamentID) (*Tournament, error)
+	// GetByEvent возвращает турнир соревнования или nil, если турнир еще не создан
+	GetByEvent(ctx context.Context, eventID event.EventID) (*Tournament, error)
+	// Create создает турнир из подтвержденных участников соревнования
+	Create(ctx context.Context, input CreateInput) (*Tournament, error)
+	// RecordScore вносит счет матча и продвигает участников по сетке
+	RecordScore(ctx context.Context, id TournamentID, matchID, scoreA, scoreB int) (*Tournament, error)
+	// Delete удаляет турнир (например, чтобы пересоздать сетку в другом формате)
+	Delete(ctx context.Context, id TournamentID) error
+	// AnonymizePlayer заменяет имя игрока в участниках всех турниров на user.AnonymizedName (при удалении его данных)
+	AnonymizePlayer(ctx context.Context, userID int64) error
+}
+
+// PlayerDirectory отдает данные игроков для имен участников (реализуется сервисом пользователей)
+type PlayerDirectory interface {
+	GetByTelegramID(ctx context.Context, telegramID int64) (*user.User, error)
+}
+
+// RatingSource отдает текущие рейтинги игроков для посева (реализуется сервисом рейтинга)
+type RatingSource interface {
+	Ratings(ctx context.Context, telegramIDs []int64) (map[int64]int, error)
+}
+
+type tournamentService struct {
+	repo         Repository
+	eventService event.EventService
+	players      PlayerDirectory
+	ratings      RatingSource
+}
+
+func NewService(repo Repository, eventService event.EventService, players PlayerDirectory, ratings RatingSource) Service {
+	return &tournamentService{
+		repo:         repo,
+		eventService: eventService,
+		players:      players,
+		ratings:      ratings,
+	}
+}
+
+func (s *tournamentService) Get(ctx context.Context, id TournamentID) (*Tournament, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrTournamentNotFound
+	}
+	return t, nil
+}
+
+func (s *tournamentService) GetByEvent(ctx context.Context, eventID event.EventID) (*Tournament, error) {
+	return s.repo.GetByEvent(ctx, eventID)
+}
+
+func (s *tournamentService) Create(ctx context.Context, in CreateInput) (*Tournament, error) {
+	evt, err := s.eventService.Get(ctx, in.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if evt == nil {
+		return nil, event.ErrEventNotFound
+	}
+	if evt.Type != event.EventTypeCompetition {
+		return nil, ErrNotCompetition
+	}
+	if !evt.Status.IsActive() {
+		return nil, event.ErrEventNotOpen
+	}
+
+	existing, err := s.repo.GetByEvent(ctx, in.EventID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrTournamentExists
+	}
+
+	participants, err := s.participantsFromEvent(ctx, evt)
+	if err != nil {
+		return nil, err
+	}
+	if in.SeedByRating {
+		if err := s.seedByRating(ctx, participants); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err := New(TournamentID(uuid.New().String()), in, participants, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// participantsFromEvent собирает участников из подтвержденных регистраций в порядке записи.
+// В парной категории участник - команда капитана и напарника
+func (s *tournamentService) participantsFromEvent(ctx context.Context, evt *event.Event) ([]Participant, error) {
+	var regs []event.EventRegistration
+	for _, reg := range evt.Registrations {
+		if reg.Status == event.RegistrationStatusApproved && evt.TeamHead(reg) {
+			regs = append(regs, reg)
+		}
+	}
+	sort.Slice(regs, func(i, j int) bool {
+		if !regs[i].CreatedAt.Equal(regs[j].CreatedAt) {
+			return regs[i].CreatedAt.Before(regs[j].CreatedAt)
+		}
+		return regs[i].UserID < regs[j].UserID
+	})
+
+	participants := make([]Participant, 0, len(regs))
+	for _, reg := range regs {
+		members := evt.TeamMembers(reg.UserID)
+		names := make([]string, 0, len(members))
+		for _, memberID := range members {
+			player, err := s.players.GetByTelegramID(ctx, memberID)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, playerName(player, memberID))
+		}
+		participants = append(participants, Participant{
+			Name:    strings.Join(names, teamNameSeparator),
+			UserIDs: members,
+		})
+	}
+	return participants, nil
+}
+
+// seedByRating упорядочивает участников по убыванию рейтинга (команды - по среднему рейтингу игроков).
+// При равном рейтинге сохраняется порядок записи
+func (s *tournamentService) seedByRating(ctx context.Context, participants []Participant) error {
+	if s.ratings == nil {
+		return nil
+	}
+	var ids []int64
+	for _, p := range participants {
+		ids = append(ids, p.UserIDs...)
+	}
+	ratings, err := s.ratings.Ratings(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	strength := func(p Participant) float64 {
+		if len(p.UserIDs) == 0 {
+			return 0
+		}
+		sum := 0
+		for _, id := range p.UserIDs {
+			sum += ratings[id]
+		}
+		return float64(sum) / float64(len(p.UserIDs))
+	}
+	sort.SliceStable(participants, func(i, j int) bool {
+		return strength(participants[i]) > strength(participants[j])
+	})
+	return nil
+}
+
+func (s *tournamentService) AnonymizePlayer(ctx context.Context, userID int64) error {
+	tournaments, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range tournaments {
+		if tournaments[i].ParticipantOf(userID) == nil {
+			continue
+		}
+		_, err := s.repo.Update(ctx, tournaments[i].ID, func(t *Tournament) error {
+			t.RenamePlayer(userID, user.AnonymizedName)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// playerName возвращает короткое имя игрока для сетки
+func playerName(player *user.User, userID int64) string {
+	if player != nil {
+		if name := strings.TrimSpace(player.Name + " " + player.Surname); name != "" {
+			return name
+		}
+	}
+	return "Игрок " + strconv.FormatInt(userID, 10)
+}
+
+func (s *tournamentService) RecordScore(ctx context.Context, id TournamentID, matchID, scoreA, scoreB int) (*Tournament, error) {
+	return s.repo.Update(ctx, id, func(t *Tournament) error {
+		if err := t.RecordScore(matchID, scoreA, scoreB); err != nil {
+			return err
+		}
+		t.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+func (s *tournamentService) Delete(ctx context.Context, id TournamentID) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -1,8 +1,207 @@
 package user
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/location"
+)
+
 type User struct {
-	ID         int64
-	Name       string
-	Surname    string
-	TelegramID int64
+	ID                 int64
+	Name               string
+	Surname            string
+	TelegramID         int64
+	Level              Level                 // Уровень игры (0 - не определен)
+	Phone              string                // Телефон в формате +79991234567 (пусто - не указан)
+	Hand               Hand                  // Игровая рука (пусто - не указана)
+	PreferredLocations []location.LocationID // Локации, где игрок предпочитает играть
+	Language           Language              // Язык общения (пусто - DefaultLanguage)
+	DeleteAfter        time.Time             // Когда удалить данные по запросу игрока (нулевое - удаление не запрошено)
+	AnonymizedAt       time.Time             // Когда данные игрока обезличены (нулевое - не обезличены)
+}
+
+// DeletionGracePeriod - сколько времени после запроса на удаление игрок может его отменить
+const DeletionGracePeriod = 7 * 24 * time.Hour
+
+// AnonymizedName - имя, которое остается вместо имени игрока после удаления данных
+const AnonymizedName = "Удаленный пользователь"
+
+// DeletionRequested сообщает, что игрок запросил удаление данных и оно еще не выполнено
+func (u *User) DeletionRequested() bool {
+	return !u.DeleteAfter.IsZero()
+}
+
+// Anonymized сообщает, что данные игрока обезличены
+func (u *User) Anonymized() bool {
+	return !u.AnonymizedAt.IsZero()
+}
+
+// Anonymize стирает персональные данные игрока. Сама запись и Telegram ID остаются,
+// чтобы история событий, оплаты и рейтинг продолжали сходиться
+func (u *User) Anonymize(now time.Time) {
+	u.Name = AnonymizedName
+	u.Surname = ""
+	u.Level = LevelUnset
+	u.Phone = ""
+	u.Hand = HandUnset
+	u.PreferredLocations = nil
+	u.Language = ""
+	u.DeleteAfter = time.Time{}
+	u.AnonymizedAt = now
+}
+
+// Hand - игровая рука
+type Hand string
+
+const (
+	HandUnset Hand = ""
+	HandRight Hand = "right"
+	HandLeft  Hand = "left"
+)
+
+// Valid проверяет, что рука известна (пустая - не указана)
+func (h Hand) Valid() bool {
+	return h == HandUnset || h == HandRight || h == HandLeft
 }
+
+// Language - язык общения с ботом
+type Language string
+
+const (
+	LanguageRU Language = "ru"
+	LanguageEN Language = "en"
+
+	DefaultLanguage = LanguageRU
+)
+
+// Valid проверяет, что язык поддерживается (пустой - DefaultLanguage)
+func (l Language) Valid() bool {
+	return l == "" || l == LanguageRU || l == LanguageEN
+}
+
+// EffectiveLanguage возвращает язык игрока с учетом значения по умолчанию
+func (u *User) EffectiveLanguage() Language {
+	if u.Language == "" {
+		return DefaultLanguage
+	}
+	return u.Language
+}
+
+// FullName возвращает имя и фамилию игрока
+func (u *User) FullName() string {
+	return strings.TrimSpace(u.Name + " " + u.Surname)
+}
+
+// NormalizePhone приводит номер телефона к виду +79991234567.
+// Номер из контакта Telegram приходит как с плюсом, так и без него
+func NormalizePhone(s string) (string, error) {
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	if digits.Len() < 10 || digits.Len() > 15 {
+		return "", ErrInvalidPhone
+	}
+	return "+" + digits.String(), nil
+}
+
+// UpdateProfileInput - DTO для изменения профиля игроком (nil - поле не меняется)
+type UpdateProfileInput struct {
+	Name               *string
+	Surname            *string
+	Phone              *string // Пустая строка удаляет телефон
+	Level              *Level  // Игрок может указать уровень сам, только пока он не определен
+	Hand               *Hand
+	Language           *Language
+	PreferredLocations *[]location.LocationID
+}
+
+// Validate проверяет валидность входных данных для изменения профиля
+func (in UpdateProfileInput) Validate() error {
+	if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		return ErrNameRequired
+	}
+	if in.Level != nil && in.Level.IsSet() && !in.Level.Valid() {
+		return ErrInvalidLevel
+	}
+	if in.Hand != nil && !in.Hand.Valid() {
+		return ErrInvalidHand
+	}
+	if in.Language != nil && !in.Language.Valid() {
+		return ErrInvalidLanguage
+	}
+	return nil
+}
+
+// Level - уровень игры по шкале, похожей на DUPR, в десятых долях (35 = 3.5)
+type Level int
+
+const (
+	LevelUnset Level = 0
+	MinLevel   Level = 20 // 2.0
+	MaxLevel   Level = 50 // 5.0
+	LevelStep  Level = 5  // Шаг 0.5
+)
+
+// Levels возвращает все допустимые уровни от MinLevel до MaxLevel
+func Levels() []Level {
+	var levels []Level
+	for l := MinLevel; l <= MaxLevel; l += LevelStep {
+		levels = append(levels, l)
+	}
+	return levels
+}
+
+// IsSet сообщает, что уровень определен
+func (l Level) IsSet() bool {
+	return l != LevelUnset
+}
+
+// Valid проверяет, что уровень лежит в шкале и кратен шагу
+func (l Level) Valid() bool {
+	return l >= MinLevel && l <= MaxLevel && (l-MinLevel)%LevelStep == 0
+}
+
+// String возвращает уровень в виде "3.5" (пустая строка, если уровень не определен)
+func (l Level) String() string {
+	if !l.IsSet() {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d", int(l)/10, int(l)%10)
+}
+
+// ParseLevel парсит уровень вида "3.5" или "3,5"
+func ParseLevel(s string) (Level, error) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+	if err != nil {
+		return LevelUnset, ErrInvalidLevel
+	}
+	l := Level(f*10 + 0.5)
+	if !l.Valid() {
+		return LevelUnset, ErrInvalidLevel
+	}
+	return l, nil
+}
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidLevel    = errors.New("level must be between 2.0 and 5.0 in steps of 0.5")
+	ErrNameRequired    = errors.New("name is required")
+	ErrInvalidPhone    = errors.New("invalid phone number")
+	ErrInvalidHand     = errors.New("unknown hand")
+	ErrInvalidLanguage = errors.New("unsupported language")
+	ErrLevelLocked     = errors.New("level is already set and can be changed only by an admin")
+
+	ErrDeletionNotRequested = errors.New("account deletion was not requested")
+	ErrAlreadyAnonymized    = errors.New("user data is already deleted")
+)
@@ -1,6 +1,9 @@
 package user
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type UserRepository interface {
 	Save(ctx context.Context, player *User) error
@@ -9,4 +12,6 @@ type UserRepository interface {
 	GetByTelegramID(ctx context.Context, telegramID int64) (*User, error)
 	ListByEventID(ctx context.Context, eventID int64) ([]User, error)
 	ListByLocationID(ctx context.Context, locationID int64) ([]User, error)
+	// ListDeletionsDue возвращает игроков, у которых срок отмены удаления истек к моменту before
+	ListDeletionsDue(ctx context.Context, before time.Time) ([]User, error)
 }
@@ -1,12 +1,28 @@
 package user
 
-import "context"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 type UserService interface {
 	CreateUser(ctx context.Context, player *User) error
 	DeleteUser(ctx context.Context, id int64) error
 	IsUserExists(ctx context.Context, telegramID int64) (bool, error)
 	GetByTelegramID(ctx context.Context, telegramID int64) (*User, error)
+	// SetLevel устанавливает уровень игрока (LevelUnset сбрасывает уровень)
+	SetLevel(ctx context.Context, telegramID int64, level Level) (*User, error)
+	// UpdateProfile изменяет профиль игрока по его запросу
+	UpdateProfile(ctx context.Context, telegramID int64, in UpdateProfileInput) (*User, error)
+	// RequestDeletion планирует удаление данных игрока через DeletionGracePeriod
+	RequestDeletion(ctx context.Context, telegramID int64, now time.Time) (*User, error)
+	// CancelDeletion отменяет запланированное удаление, пока не истек срок отмены
+	CancelDeletion(ctx context.Context, telegramID int64) (*User, error)
+	// ListDueDeletions возвращает игроков, данные которых пора обезличить
+	ListDueDeletions(ctx context.Context, now time.Time) ([]User, error)
+	// Anonymize обезличивает данные игрока
+	Anonymize(ctx context.Context, telegramID int64, now time.Time) (*User, error)
 }
 
 type userService struct {
@@ -34,6 +50,8 @@ func (ps *userService) CreateUser(ctx context.Context, player *User) error {
 			// Обновляем существующего пользователя
 			existingUser.Name = player.Name
 			existingUser.Surname = player.Surname
+			// Игрок вернулся после удаления данных - запись снова принадлежит ему
+			existingUser.AnonymizedAt = time.Time{}
 			return ps.repository.Save(ctx, existingUser)
 		}
 	}
@@ -46,14 +64,151 @@ func (ps *userService) DeleteUser(ctx context.Context, id int64) error {
 	return ps.repository.Delete(ctx, id)
 }
 
+// IsUserExists сообщает, есть ли у игрока анкета. Обезличенный игрок считается новым:
+// при следующей записи его попросят заново ввести имя
 func (ps *userService) IsUserExists(ctx context.Context, telegramID int64) (bool, error) {
 	player, err := ps.repository.GetByTelegramID(ctx, telegramID)
 	if err != nil {
 		return false, err
 	}
-	return player != nil, nil
+	return player != nil && !player.Anonymized(), nil
 }
 
 func (ps *userService) GetByTelegramID(ctx context.Context, telegramID int64) (*User, error) {
 	return ps.repository.GetByTelegramID(ctx, telegramID)
 }
+
+func (ps *userService) SetLevel(ctx context.Context, telegramID int64, level Level) (*User, error) {
+	if level.IsSet() && !level.Valid() {
+		return nil, ErrInvalidLevel
+	}
+
+	player, err := ps.repository.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		return nil, ErrUserNotFound
+	}
+
+	player.Level = level
+	if err := ps.repository.Save(ctx, player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+func (ps *userService) UpdateProfile(ctx context.Context, telegramID int64, in UpdateProfileInput) (*User, error) {
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+
+	player, err := ps.repository.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if in.Name != nil {
+		player.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Surname != nil {
+		player.Surname = strings.TrimSpace(*in.Surname)
+	}
+	if in.Phone != nil {
+		player.Phone = ""
+		if *in.Phone != "" {
+			if player.Phone, err = NormalizePhone(*in.Phone); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if in.Level != nil {
+		// Самооценка допускается один раз: дальше уровень меняет администратор
+		if player.Level.IsSet() {
+			return nil, ErrLevelLocked
+		}
+		player.Level = *in.Level
+	}
+	if in.Hand != nil {
+		player.Hand = *in.Hand
+	}
+	if in.Language != nil {
+		player.Language = *in.Language
+	}
+	if in.PreferredLocations != nil {
+		player.PreferredLocations = *in.PreferredLocations
+	}
+
+	if err := ps.repository.Save(ctx, player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+func (ps *userService) RequestDeletion(ctx context.Context, telegramID int64, now time.Time) (*User, error) {
+	player, err := ps.repository.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		return nil, ErrUserNotFound
+	}
+	if player.Anonymized() {
+		return nil, ErrAlreadyAnonymized
+	}
+	// Повторный запрос не продлевает срок
+	if player.DeletionRequested() {
+		return player, nil
+	}
+
+	player.DeleteAfter = now.Add(DeletionGracePeriod)
+	if err := ps.repository.Save(ctx, player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+func (ps *userService) CancelDeletion(ctx context.Context, telegramID int64) (*User, error) {
+	player, err := ps.repository.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		return nil, ErrUserNotFound
+	}
+	if !player.DeletionRequested() {
+		return nil, ErrDeletionNotRequested
+	}
+
+	player.DeleteAfter = time.Time{}
+	if err := ps.repository.Save(ctx, player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+func (ps *userService) ListDueDeletions(ctx context.Context, now time.Time) ([]User, error) {
+	return ps.repository.ListDeletionsDue(ctx, now)
+}
+
+func (ps *userService) Anonymize(ctx context.Context, telegramID int64, now time.Time) (*User, error) {
+	player, err := ps.repository.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if player == nil {
+		return nil, ErrUserNotFound
+	}
+	if player.Anonymized() {
+		return nil, ErrAlreadyAnonymized
+	}
+
+	player.Anonymize(now)
+	if err := ps.repository.Save(ctx, player); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
@@ -8,32 +8,53 @@ import (
 
 // EventGORM — таблица `events` для хранения событий
 type EventGORM struct {
-	ID           uint      `gorm:"primaryKey" json:"-"`
-	EventID      string    `gorm:"uniqueIndex;size:36" json:"-"` // UUID
-	Name         string    `gorm:"size:255;not null" json:"name"`
-	Type         string    `gorm:"size:50;not null" json:"type"` // training, competition
-	Date         time.Time `gorm:"not null" json:"date"`
-	Remaining    int       `gorm:"not null;default:0" json:"remaining"`
-	MaxPlayers   int       `gorm:"not null" json:"max_players"`
-	LocationID   string    `gorm:"size:36;not null;index" json:"location_id"`
-	Trainer      string    `gorm:"size:255" json:"trainer"` // Тренер события
-	Description  string    `gorm:"type:text" json:"description"`
-	PaymentPhone string    `gorm:"size:20" json:"payment_phone"`    // Телефон для оплаты
-	Price        int       `gorm:"not null;default:0" json:"price"` // Стоимость тренировки (в копейках)
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID                    uint      `gorm:"primaryKey" json:"-"`
+	EventID               string    `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	Name                  string    `gorm:"size:255;not null" json:"name"`
+	Type                  string    `gorm:"size:50;not null" json:"type"`                             // training, competition
+	Status                string    `gorm:"size:20;not null;default:'published';index" json:"status"` // draft, published, cancelled, completed
+	Date                  time.Time `gorm:"not null" json:"date"`
+	Remaining             int       `gorm:"not null;default:0" json:"remaining"`
+	MaxPlayers            int       `gorm:"not null" json:"max_players"`
+	LocationID            string    `gorm:"size:36;not null;index" json:"location_id"`
+	Trainer               string    `gorm:"size:255" json:"trainer"` // Тренер события
+	Description           string    `gorm:"type:text" json:"description"`
+	PaymentPhone          string    `gorm:"size:20" json:"payment_phone"`                            // Телефон для оплаты
+	Price                 int       `gorm:"not null;default:0" json:"price"`                         // Стоимость тренировки (в копейках)
+	PaymentMode           string    `gorm:"size:20;not null;default:'transfer'" json:"payment_mode"` // transfer, invoice, stars
+	PendingTimeoutMinutes int       `gorm:"not null;default:0" json:"pending_timeout_minutes"`       // Время брони без оплаты (0 - глобальная настройка)
+	CancelDeadlineMinutes int       `gorm:"not null;default:0" json:"cancel_deadline_minutes"`       // Срок бесплатной отмены до начала (0 - глобальная настройка)
+	LateCancel            string    `gorm:"size:20;not null;default:''" json:"late_cancel"`          // none, block, penalty (пусто - глобальная настройка)
+	SeriesID              string    `gorm:"size:36;index" json:"series_id,omitempty"`                // ID повторяющейся серии
+	MinLevel              int       `gorm:"not null;default:0" json:"min_level"`                     // Минимальный уровень в десятых долях (0 - без ограничения)
+	MaxLevel              int       `gorm:"not null;default:0" json:"max_level"`                     // Максимальный уровень в десятых долях (0 - без ограничения)
+	Doubles               bool      `gorm:"not null;default:false" json:"doubles"`                   // Парная категория: места считаются в командах
+	DurationMinutes       int       `gorm:"not null;default:0" json:"duration_minutes"`              // Длительность события (0 - по умолчанию)
+	CourtIDs              string    `gorm:"size:500;not null;default:''" json:"court_ids"`           // ID забронированных кортов через запятую
+	CreatedBy             int64     `gorm:"not null;default:0;index" json:"created_by"`              // Telegram ID создателя события
+	Version               int       `gorm:"not null;default:0" json:"-"`                             // Версия для оптимистичной блокировки
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
 }
 
 // EventRegistrationGORM — таблица для хранения регистраций пользователей на события
 type EventRegistrationGORM struct {
-	ID        uint   `gorm:"primaryKey" json:"-"`
-	EventID   string `gorm:"size:36;not null;index;uniqueIndex:idx_event_user" json:"event_id"`
-	UserID    int64  `gorm:"not null;index;uniqueIndex:idx_event_user" json:"user_id"` // Foreign key на user.id
-	Status    string `gorm:"size:20;not null;default:'pending'" json:"status"`         // pending, approved, rejected
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uint       `gorm:"primaryKey" json:"-"`
+	EventID           string     `gorm:"size:36;not null;index;uniqueIndex:idx_event_user" json:"event_id"`
+	UserID            int64      `gorm:"not null;index;uniqueIndex:idx_event_user" json:"user_id"` // Foreign key на user.id
+	Status            string     `gorm:"size:20;not null;default:'pending'" json:"status"`         // pending, approved, rejected, waitlisted, expired
+	WaitlistPosition  int        `gorm:"not null;default:0" json:"waitlist_position,omitempty"`    // Позиция в листе ожидания
+	PendingSince      *time.Time `json:"pending_since,omitempty"`                                  // Начало брони (pending)
+	ReminderSentAt    *time.Time `json:"reminder_sent_at,omitempty"`                               // Когда отправлено напоминание об оплате
+	Attendance        string     `gorm:"size:20;not null;default:''" json:"attendance,omitempty"`  // present, no_show (пусто - не отмечено)
+	CheckedInAt       *time.Time `json:"checked_in_at,omitempty"`                                  // Когда игрок отметился сам по ссылке
+	PartnerTelegramID int64      `gorm:"not null;default:0" json:"partner_telegram_id,omitempty"`  // Telegram ID напарника по команде (0 - без пары)
+	TeamCaptain       bool       `gorm:"not null;default:false" json:"team_captain,omitempty"`     // Игрок собрал команду и пригласил напарника
+	Discount          int        `gorm:"not null;default:0" json:"discount,omitempty"`             // Скидка по промокоду в единицах цены события
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Связи (только для загрузки данных через Preload)
 	// Foreign keys создаются только в этой таблице, не в EventGORM
@@ -42,3 +63,27 @@ type EventRegistrationGORM struct {
 
 	// Уникальный индекс на пару (EventID, UserID) - один пользователь может быть зарегистрирован на событие только один раз
 }
+
+// EventGuestGORM - гость без Telegram, которого игрок записал на событие
+type EventGuestGORM struct {
+	ID             uint   `gorm:"primaryKey" json:"-"`
+	GuestID        string `gorm:"size:16;not null;uniqueIndex:idx_event_guest" json:"guest_id"`
+	EventID        string `gorm:"size:36;not null;index;uniqueIndex:idx_event_guest" json:"event_id"`
+	HostTelegramID int64  `gorm:"not null;index" json:"host_telegram_id"`           // Telegram ID игрока, который записал гостя
+	Name           string `gorm:"size:255;not null" json:"name"`                    // Имя гостя
+	Status         string `gorm:"size:20;not null;default:'pending'" json:"status"` // pending, approved, rejected, expired, late_cancelled
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Event EventGORM `gorm:"foreignKey:EventID;references:EventID;constraint:OnDelete:CASCADE" json:"event,omitempty"`
+}
+
+// EventAnnouncementGORM — таблица для хранения сообщений с анонсами событий в каналах
+type EventAnnouncementGORM struct {
+	ID        uint   `gorm:"primaryKey" json:"-"`
+	EventID   string `gorm:"size:36;not null;index;uniqueIndex:idx_event_announcement" json:"event_id"`
+	ChatID    int64  `gorm:"not null;uniqueIndex:idx_event_announcement" json:"chat_id"`
+	MessageID int    `gorm:"not null" json:"message_id"`
+	CreatedAt time.Time
+}
@@ -18,3 +18,14 @@ type LocationGORM struct {
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
 }
+
+// CourtGORM — таблица `courts` для хранения кортов локаций.
+type CourtGORM struct {
+	ID         uint   `gorm:"primaryKey" json:"-"`
+	CourtID    string `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	LocationID string `gorm:"size:36;not null;index" json:"location_id"`
+	Name       string `gorm:"size:100;not null" json:"name"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// PassGORM — таблица `passes`: абонементы игроков на несколько занятий
+type PassGORM struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	PassID      string    `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	TelegramID  int64     `gorm:"not null;index" json:"telegram_id"`
+	Sessions    int       `gorm:"not null" json:"sessions"`
+	ValidFrom   time.Time `gorm:"not null" json:"valid_from"`
+	ValidUntil  time.Time `gorm:"not null" json:"valid_until"`
+	EventTypes  string    `gorm:"size:50;not null;default:''" json:"event_types"`   // Типы событий через запятую, пусто - любые
+	LocationIDs string    `gorm:"size:500;not null;default:''" json:"location_ids"` // ID локаций через запятую, пусто - любые
+	IssuedBy    int64     `gorm:"not null;default:0" json:"issued_by"`
+	CreatedAt   time.Time
+
+	Usages []PassUsageGORM `gorm:"foreignKey:PassID;references:ID;constraint:OnDelete:CASCADE" json:"usages,omitempty"`
+}
+
+// PassUsageGORM — таблица `pass_usages`: занятия, списанные с абонементов.
+// Возвращенное занятие удаляется из таблицы; за одно событие с игрока списывается не больше одного занятия
+type PassUsageGORM struct {
+	ID         uint   `gorm:"primaryKey" json:"-"`
+	PassID     uint   `gorm:"not null;index" json:"pass_id"` // Foreign key на passes.id
+	EventID    string `gorm:"size:36;not null;uniqueIndex:idx_pass_usage_event_user" json:"event_id"`
+	TelegramID int64  `gorm:"not null;uniqueIndex:idx_pass_usage_event_user" json:"telegram_id"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PaymentGORM — таблица `payments`: журнал оплат и возвратов по регистрациям на события.
+// Записи не изменяются и не удаляются: исправление оформляется возвратом
+type PaymentGORM struct {
+	ID              uint   `gorm:"primaryKey" json:"-"`
+	PaymentID       string `gorm:"uniqueIndex;size:36" json:"-"`                                                                           // UUID
+	RegistrationID  uint   `gorm:"not null;index" json:"registration_id"`                                                                  // Foreign key на event_registrations.id
+	EventID         string `gorm:"size:36;not null;index" json:"event_id"`                                                                 // Дублирует событие регистрации для отчетов
+	TelegramID      int64  `gorm:"not null;index" json:"telegram_id"`                                                                      // Участник, за чью регистрацию проведена операция
+	GuestID         string `gorm:"size:16;not null;default:''" json:"guest_id,omitempty"`                                                  // Гость участника, за которого проведена операция (пусто - за самого участника)
+	PayerTelegramID int64  `gorm:"not null" json:"payer_telegram_id"`                                                                      // Кто заплатил
+	Kind            string `gorm:"size:20;not null" json:"kind"`                                                                           // payment, refund
+	Method          string `gorm:"size:20;not null" json:"method"`                                                                         // transfer, cash, invoice, pass
+	Amount          int    `gorm:"not null" json:"amount"`                                                                                 // Сумма в единицах цены события, всегда положительная
+	ConfirmedBy     int64  `gorm:"not null;default:0" json:"confirmed_by"`                                                                 // Кто подтвердил (0 - автоматически)
+	ChargeID        string `gorm:"size:255;not null;default:'';uniqueIndex:idx_payments_charge_id,where:charge_id <> ''" json:"charge_id"` // ID платежа в Telegram Payments (уникален, если указан)
+	Comment         string `gorm:"size:255;not null;default:''" json:"comment"`
+	CreatedAt       time.Time
+
+	Registration EventRegistrationGORM `gorm:"foreignKey:RegistrationID;references:ID" json:"-"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PromoCodeGORM — таблица `promo_codes`: промокоды на скидку при записи на события
+type PromoCodeGORM struct {
+	ID             uint       `gorm:"primaryKey" json:"-"`
+	CodeID         string     `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	Code           string     `gorm:"uniqueIndex;size:32;not null" json:"code"`
+	Kind           string     `gorm:"size:20;not null" json:"kind"`                   // percent, fixed
+	Value          int        `gorm:"not null" json:"value"`                          // Процент или сумма скидки
+	MaxUses        int        `gorm:"not null;default:0" json:"max_uses"`             // 0 - без ограничений
+	MaxUsesPerUser int        `gorm:"not null;default:0" json:"max_uses_per_user"`    // 0 - без ограничений
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`                           // NULL - бессрочно
+	EventTypes     string     `gorm:"size:50;not null;default:''" json:"event_types"` // Типы событий через запятую, пусто - любые
+	EventID        string     `gorm:"size:36;not null;default:''" json:"event_id"`    // Конкретное событие, пусто - любые
+	Active         bool       `gorm:"not null;default:true" json:"active"`
+	CreatedBy      int64      `gorm:"not null;default:0" json:"created_by"`
+	CreatedAt      time.Time
+}
+
+// PromoRedemptionGORM — таблица `promo_redemptions`: погашения промокодов.
+// Погашение отмененной регистрации помечается удаленным и остается для отчетов
+type PromoRedemptionGORM struct {
+	ID           uint   `gorm:"primaryKey" json:"-"`
+	RedemptionID string `gorm:"uniqueIndex;size:36" json:"-"`        // UUID
+	PromoCodeID  uint   `gorm:"not null;index" json:"promo_code_id"` // Foreign key на promo_codes.id
+	EventID      string `gorm:"size:36;not null;index;uniqueIndex:idx_promo_redemption_active,where:deleted_at IS NULL" json:"event_id"`
+	TelegramID   int64  `gorm:"not null;index;uniqueIndex:idx_promo_redemption_active,where:deleted_at IS NULL" json:"telegram_id"` // На одну запись действует не больше одного промокода
+	Discount     int    `gorm:"not null" json:"discount"`
+	CreatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	PromoCode PromoCodeGORM `gorm:"foreignKey:PromoCodeID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
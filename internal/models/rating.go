@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RatingHistoryGORM — таблица `rating_histories`: изменение рейтинга игрока по итогам матча.
+// Таблица целиком пересобирается при пересчете рейтинга
+type RatingHistoryGORM struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	TelegramID   int64     `gorm:"not null;index:idx_rating_player_seq" json:"telegram_id"`
+	Seq          int       `gorm:"not null;index:idx_rating_player_seq" json:"seq"` // Порядковый номер матча в пересчете
+	EventID      string    `gorm:"size:36;not null;index" json:"event_id"`
+	TournamentID string    `gorm:"size:36;not null" json:"tournament_id"`
+	MatchNo      int       `gorm:"not null" json:"match_no"`
+	Won          bool      `gorm:"not null" json:"won"`
+	RatingBefore int       `gorm:"not null" json:"rating_before"`
+	RatingAfter  int       `gorm:"not null" json:"rating_after"`
+	PlayedAt     time.Time `gorm:"not null" json:"played_at"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EventReminderGORM — таблица `event_reminders` с отправленными напоминаниями о событиях
+type EventReminderGORM struct {
+	ID      uint      `gorm:"primaryKey" json:"-"`
+	EventID string    `gorm:"size:36;not null;uniqueIndex:idx_event_reminder" json:"event_id"`
+	UserID  int64     `gorm:"not null;uniqueIndex:idx_event_reminder" json:"user_id"` // Telegram ID пользователя
+	Kind    string    `gorm:"size:10;not null;uniqueIndex:idx_event_reminder" json:"kind"`
+	SentAt  time.Time `gorm:"not null" json:"sent_at"`
+}
+
+// ReminderOptOutGORM — таблица `reminder_opt_outs` с отказами пользователей от типов напоминаний
+type ReminderOptOutGORM struct {
+	ID        uint   `gorm:"primaryKey" json:"-"`
+	UserID    int64  `gorm:"not null;uniqueIndex:idx_reminder_opt_out" json:"user_id"` // Telegram ID пользователя
+	Kind      string `gorm:"size:10;not null;uniqueIndex:idx_reminder_opt_out" json:"kind"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RestrictionGORM — таблица `restrictions`: ограничения записи игроков, установленные администраторами.
+// Снятое ограничение остается в таблице для истории
+type RestrictionGORM struct {
+	ID            uint       `gorm:"primaryKey" json:"-"`
+	RestrictionID string     `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	TelegramID    int64      `gorm:"not null;index" json:"telegram_id"`
+	Kind          string     `gorm:"size:20;not null" json:"kind"`                     // ban, until, locations
+	Until         *time.Time `json:"until,omitempty"`                                  // Конец запрета (для until)
+	LocationIDs   string     `gorm:"size:500;not null;default:''" json:"location_ids"` // ID локаций через запятую (для locations)
+	Reason        string     `gorm:"size:500;not null" json:"reason"`
+	CreatedBy     int64      `gorm:"not null" json:"created_by"`
+	CreatedAt     time.Time
+	LiftedBy      int64      `gorm:"not null;default:0" json:"lifted_by,omitempty"`
+	LiftedAt      *time.Time `json:"lifted_at,omitempty"`
+}
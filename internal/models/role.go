@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RoleGrantGORM — таблица `role_grants`: роли сотрудников (владелец, администратор, модератор, тренер).
+// Снятая роль удаляется из таблицы
+type RoleGrantGORM struct {
+	ID         uint   `gorm:"primaryKey" json:"-"`
+	GrantID    string `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	TelegramID int64  `gorm:"not null;index" json:"telegram_id"`
+	Role       string `gorm:"size:20;not null" json:"role"`                   // owner, admin, moderator, trainer
+	LocationID string `gorm:"size:36;not null;default:''" json:"location_id"` // Пусто - во всех локациях
+	GrantedBy  int64  `gorm:"not null;default:0" json:"granted_by"`           // Кто выдал роль (0 - из ADMIN_IDS)
+	CreatedAt  time.Time
+}
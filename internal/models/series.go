@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EventSeriesGORM — таблица `event_series` для хранения повторяющихся серий событий
+type EventSeriesGORM struct {
+	ID                    uint       `gorm:"primaryKey" json:"-"`
+	SeriesID              string     `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	Name                  string     `gorm:"size:255;not null" json:"name"`
+	Type                  string     `gorm:"size:50;not null" json:"type"` // training, competition
+	MaxPlayers            int        `gorm:"not null" json:"max_players"`
+	LocationID            string     `gorm:"size:36;not null;index" json:"location_id"`
+	Trainer               string     `gorm:"size:255" json:"trainer"`
+	Description           string     `gorm:"type:text" json:"description"`
+	PaymentPhone          string     `gorm:"size:20" json:"payment_phone"`
+	Price                 int        `gorm:"not null;default:0" json:"price"` // В копейках
+	PaymentMode           string     `gorm:"size:20;not null;default:'transfer'" json:"payment_mode"`
+	PendingTimeoutMinutes int        `gorm:"not null;default:0" json:"pending_timeout_minutes"`
+	MinLevel              int        `gorm:"not null;default:0" json:"min_level"` // Уровень в десятых долях (0 - без ограничения)
+	MaxLevel              int        `gorm:"not null;default:0" json:"max_level"`
+	Doubles               bool       `gorm:"not null;default:false" json:"doubles"`      // Парная категория
+	DurationMinutes       int        `gorm:"not null;default:0" json:"duration_minutes"` // Длительность занятия (0 - по умолчанию)
+	CreatedBy             int64      `gorm:"not null;default:0" json:"created_by"`       // Telegram ID создателя серии
+	Weekdays              string     `gorm:"size:20;not null" json:"weekdays"`           // Дни недели через запятую (0 - воскресенье), например "2,4"
+	StartTime             string     `gorm:"size:5;not null" json:"start_time"`          // Время начала "ЧЧ:ММ"
+	StartDate             time.Time  `gorm:"not null" json:"start_date"`
+	Until                 *time.Time `json:"until,omitempty"`                       // Дата последнего занятия
+	Count                 int        `gorm:"not null;default:0" json:"count"`       // Количество занятий (0 - без ограничения)
+	WeeksAhead            int        `gorm:"not null;default:2" json:"weeks_ahead"` // Горизонт создания событий
+	ExcludedDates         string     `gorm:"type:text" json:"excluded_dates"`       // Отмененные дни через запятую
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SubscriptionGORM — таблица `subscriptions`: подписки игроков на новые события (одна на игрока)
+type SubscriptionGORM struct {
+	ID          uint   `gorm:"primaryKey" json:"-"`
+	TelegramID  int64  `gorm:"uniqueIndex;not null" json:"telegram_id"`
+	Enabled     bool   `gorm:"not null;default:false;index" json:"enabled"`
+	LocationIDs string `gorm:"size:500;not null;default:''" json:"location_ids"` // ID локаций через запятую, пусто - любые
+	EventTypes  string `gorm:"size:50;not null;default:''" json:"event_types"`   // Типы событий через запятую, пусто - любые
+	Trainers    string `gorm:"size:1000;not null;default:''" json:"trainers"`    // Тренеры по одному на строку, пусто - любые
+	MinLevel    int    `gorm:"not null;default:0" json:"min_level"`              // Уровень событий от (0 - без ограничения)
+	MaxLevel    int    `gorm:"not null;default:0" json:"max_level"`              // Уровень событий до (0 - без ограничения)
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TournamentGORM — таблица `tournaments` для хранения турниров соревнований
+type TournamentGORM struct {
+	ID           uint   `gorm:"primaryKey" json:"-"`
+	TournamentID string `gorm:"uniqueIndex;size:36" json:"-"` // UUID
+	EventID      string `gorm:"size:36;not null;index" json:"event_id"`
+	Format       string `gorm:"size:30;not null" json:"format"` // round_robin, single_elimination, double_elimination
+	Pools        int    `gorm:"not null;default:0" json:"pools"`
+	Status       string `gorm:"size:20;not null" json:"status"` // in_progress, finished
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+// TournamentParticipantGORM — таблица участников турнира (игроков или команд)
+type TournamentParticipantGORM struct {
+	ID           uint   `gorm:"primaryKey" json:"-"`
+	TournamentID string `gorm:"size:36;not null;uniqueIndex:idx_tournament_participant" json:"tournament_id"`
+	Seed         int    `gorm:"not null;uniqueIndex:idx_tournament_participant" json:"seed"` // Номер посева, он же ID участника в турнире
+	Name         string `gorm:"size:255;not null" json:"name"`
+	UserIDs      string `gorm:"size:255;not null" json:"user_ids"` // Telegram ID игроков через запятую
+}
+
+// TournamentMatchGORM — таблица матчей турнира
+type TournamentMatchGORM struct {
+	ID            uint   `gorm:"primaryKey" json:"-"`
+	TournamentID  string `gorm:"size:36;not null;uniqueIndex:idx_tournament_match" json:"tournament_id"`
+	MatchNo       int    `gorm:"not null;uniqueIndex:idx_tournament_match" json:"match_no"` // ID матча внутри турнира
+	Stage         string `gorm:"size:20;not null" json:"stage"`                             // pool, winners, losers, final
+	Round         int    `gorm:"not null" json:"round"`
+	Pool          int    `gorm:"not null;default:0" json:"pool"`
+	SideA         int    `gorm:"not null;default:0" json:"side_a"` // Посев участника (0 - не определен, -1 - свободный проход)
+	SideB         int    `gorm:"not null;default:0" json:"side_b"`
+	ScoreA        int    `gorm:"not null;default:0" json:"score_a"`
+	ScoreB        int    `gorm:"not null;default:0" json:"score_b"`
+	Winner        int    `gorm:"not null;default:0" json:"winner"`
+	Bye           bool   `gorm:"not null;default:false" json:"bye"`
+	WinnerToMatch int    `gorm:"not null;default:0" json:"winner_to_match"`
+	WinnerToSide  int    `gorm:"not null;default:0" json:"winner_to_side"`
+	LoserToMatch  int    `gorm:"not null;default:0" json:"loser_to_match"`
+	LoserToSide   int    `gorm:"not null;default:0" json:"loser_to_side"`
+	UpdatedAt     time.Time
+}
@@ -8,11 +8,18 @@ import (
 
 // UserGORM — таблица `user` для хранения пользователей
 type UserGORM struct {
-	ID         int64          `gorm:"primaryKey;autoIncrement" json:"id"` // Автоинкрементный первичный ключ (BIGSERIAL)
-	Name       string         `gorm:"size:255;not null" json:"name"`
-	Surname    string         `gorm:"size:255" json:"surname"`
-	TelegramID int64          `gorm:"uniqueIndex;not null" json:"telegram_id"` // Уникальный идентификатор Telegram
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           int64          `gorm:"primaryKey;autoIncrement" json:"id"` // Автоинкрементный первичный ключ (BIGSERIAL)
+	Name         string         `gorm:"size:255;not null" json:"name"`
+	Surname      string         `gorm:"size:255" json:"surname"`
+	TelegramID   int64          `gorm:"uniqueIndex;not null" json:"telegram_id"` // Уникальный идентификатор Telegram
+	Level        int            `gorm:"not null;default:0" json:"level"`         // Уровень игры в десятых долях (35 = 3.5, 0 - не определен)
+	Phone        string         `gorm:"size:32" json:"phone"`                    // Телефон в формате +79991234567
+	Hand         string         `gorm:"size:10" json:"hand"`                     // Игровая рука: right, left (пусто - не указана)
+	Locations    string         `gorm:"size:1024" json:"locations"`              // ID предпочитаемых локаций через запятую
+	Language     string         `gorm:"size:8" json:"language"`                  // Язык общения: ru, en (пусто - по умолчанию)
+	DeleteAfter  *time.Time     `gorm:"index" json:"delete_after,omitempty"`     // Когда обезличить данные по запросу игрока
+	AnonymizedAt *time.Time     `json:"anonymized_at,omitempty"`                 // Когда данные игрока обезличены
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 }
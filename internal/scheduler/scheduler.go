@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job - периодическая фоновая задача
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context)
+}
+
+// Scheduler запускает фоновые задачи с заданным интервалом
+type Scheduler struct {
+	jobs   []Job
+	logger *slog.Logger
+}
+
+// New создает новый планировщик
+func New(logger *slog.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Add регистрирует задачу; задачи нужно добавлять до вызова Start
+func (s *Scheduler) Add(name string, interval time.Duration, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start запускает каждую задачу в отдельной горутине. Задача выполняется сразу
+// и затем по таймеру, пока не будет отменен ctx. wg позволяет дождаться завершения.
+func (s *Scheduler) Start(ctx context.Context, wg *sync.WaitGroup) {
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("scheduler job stopped", "job", job.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce выполняет задачу, не давая панике остановить планировщик
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("scheduler job panicked", "job", job.Name, "panic", r)
+		}
+	}()
+	job.Run(ctx)
+}
@@ -3,12 +3,16 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"pickletlgbot/internal/domain/event"
 	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/user"
 	"pickletlgbot/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type eventRepository struct {
@@ -36,11 +40,15 @@ func (r *eventRepository) GetByID(ctx context.Context, id event.EventID) (*event
 	}
 
 	// Загружаем регистрации из отдельной таблицы
-	registrations, err := r.loadRegistrations(ctx, id)
+	registrations, err := r.loadRegistrations(ctx, r.db, id)
 	if err != nil {
 		return nil, err
 	}
 	evt.Registrations = registrations
+	evt.Guests, err = r.loadGuests(ctx, r.db, evt.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Пересчитываем Players и Remaining на основе approved регистраций
 	r.recalculatePlayersAndRemaining(evt)
@@ -62,11 +70,15 @@ func (r *eventRepository) List(ctx context.Context) ([]event.Event, error) {
 		if err != nil {
 			return nil, err
 		}
-		registrations, err := r.loadRegistrations(ctx, event.EventID(m.EventID))
+		registrations, err := r.loadRegistrations(ctx, r.db, event.EventID(m.EventID))
 		if err != nil {
 			return nil, err
 		}
 		evt.Registrations = registrations
+		evt.Guests, err = r.loadGuests(ctx, r.db, evt.ID)
+		if err != nil {
+			return nil, err
+		}
 		r.recalculatePlayersAndRemaining(evt)
 		events[i] = *evt
 	}
@@ -87,11 +99,15 @@ func (r *eventRepository) ListByLocation(ctx context.Context, locationID locatio
 		if err != nil {
 			return nil, err
 		}
-		registrations, err := r.loadRegistrations(ctx, event.EventID(m.EventID))
+		registrations, err := r.loadRegistrations(ctx, r.db, event.EventID(m.EventID))
 		if err != nil {
 			return nil, err
 		}
 		evt.Registrations = registrations
+		evt.Guests, err = r.loadGuests(ctx, r.db, evt.ID)
+		if err != nil {
+			return nil, err
+		}
 		r.recalculatePlayersAndRemaining(evt)
 		events = append(events, *evt)
 	}
@@ -133,33 +149,189 @@ func (r *eventRepository) ListByUser(ctx context.Context, userID int64) ([]event
 		if err != nil {
 			continue
 		}
-		registrations, err := r.loadRegistrations(ctx, event.EventID(m.EventID))
+		registrations, err := r.loadRegistrations(ctx, r.db, event.EventID(m.EventID))
 		if err != nil {
 			continue
 		}
 		evt.Registrations = registrations
+		evt.Guests, err = r.loadGuests(ctx, r.db, evt.ID)
+		if err != nil {
+			continue
+		}
 		r.recalculatePlayersAndRemaining(evt)
 		userEvents = append(userEvents, *evt)
 	}
 	return userEvents, nil
 }
 
+func (r *eventRepository) ListBySeries(ctx context.Context, seriesID string) ([]event.Event, error) {
+	var models []models.EventGORM
+	if err := r.db.WithContext(ctx).
+		Where("series_id = ? AND deleted_at IS NULL", seriesID).
+		Order("date").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]event.Event, 0, len(models))
+	for _, m := range models {
+		evt, err := r.modelToDomain(&m)
+		if err != nil {
+			return nil, err
+		}
+		registrations, err := r.loadRegistrations(ctx, r.db, event.EventID(m.EventID))
+		if err != nil {
+			return nil, err
+		}
+		evt.Registrations = registrations
+		evt.Guests, err = r.loadGuests(ctx, r.db, evt.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.recalculatePlayersAndRemaining(evt)
+		events = append(events, *evt)
+	}
+	return events, nil
+}
+
 func (r *eventRepository) Save(ctx context.Context, evt *event.Event) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Событие могли загрузить вне транзакции, поэтому сравниваем с тем, что сейчас в базе
+		registrations, err := r.loadRegistrations(ctx, tx, evt.ID)
+		if err != nil {
+			return err
+		}
+		guests, err := r.loadGuests(ctx, tx, evt.ID)
+		if err != nil {
+			return err
+		}
+		return r.save(ctx, tx, evt, registrations, guests, true)
+	})
+}
+
+// Update не блокирует строку события: от параллельных изменений защищает версия события.
+// Если другая транзакция успела сохранить событие, запись в save не найдет загруженную версию
+// и вернет *event.ConflictError, а вызывающий код повторит операцию (см. retryOnConflict)
+func (r *eventRepository) Update(ctx context.Context, id event.EventID, fn func(evt *event.Event) error) (*event.Event, error) {
+	var evt *event.Event
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model models.EventGORM
+		if err := tx.Where("event_id = ?", string(id)).
+			First(&model).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return event.ErrEventNotFound
+			}
+			return err
+		}
+
+		loaded, err := r.modelToDomain(&model)
+		if err != nil {
+			return err
+		}
+		registrations, err := r.loadRegistrations(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		guests, err := r.loadGuests(ctx, tx, loaded.ID)
+		if err != nil {
+			return err
+		}
+		// fn изменяет карты события, поэтому для сравнения сохраняем их копии
+		loaded.Registrations = copyRegistrations(registrations)
+		loaded.Guests = copyGuests(guests)
+		r.recalculatePlayersAndRemaining(loaded)
+		before := *loaded
+		before.CourtIDs = append([]location.CourtID(nil), loaded.CourtIDs...)
+
+		if err := fn(loaded); err != nil {
+			return err
+		}
+
+		evt = loaded
+		return r.save(ctx, tx, evt, registrations, guests, bookingChanged(&before, evt))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+// save сохраняет событие в рамках транзакции tx. Событие обновляется, только если его версия
+// не изменилась с момента загрузки; из регистраций и гостей записываются только отличающиеся
+// от сохраненных (prevRegistrations, prevGuests). checkCourts - проверить, что корты события не заняты
+func (r *eventRepository) save(ctx context.Context, tx *gorm.DB, evt *event.Event, prevRegistrations map[int64]event.EventRegistration, prevGuests map[event.GuestID]event.Guest, checkCourts bool) error {
+	if checkCourts {
+		if err := r.checkCourts(ctx, tx, evt); err != nil {
+			return err
+		}
+	}
+
 	model, err := r.domainToModel(evt)
 	if err != nil {
 		return err
 	}
 
-	err = r.db.WithContext(ctx).
-		Where("event_id = ?", string(evt.ID)).
-		Assign(model).
-		FirstOrCreate(model).Error
+	// Обновляем через map, чтобы нулевые значения (например, Remaining = 0) тоже сохранялись
+	result := tx.WithContext(ctx).
+		Model(&models.EventGORM{}).
+		Where("event_id = ? AND version = ?", model.EventID, evt.Version).
+		Updates(map[string]interface{}{
+			"name":                    model.Name,
+			"type":                    model.Type,
+			"status":                  model.Status,
+			"date":                    model.Date,
+			"remaining":               model.Remaining,
+			"max_players":             model.MaxPlayers,
+			"location_id":             model.LocationID,
+			"trainer":                 model.Trainer,
+			"description":             model.Description,
+			"payment_phone":           model.PaymentPhone,
+			"price":                   model.Price,
+			"payment_mode":            model.PaymentMode,
+			"pending_timeout_minutes": model.PendingTimeoutMinutes,
+			"cancel_deadline_minutes": model.CancelDeadlineMinutes,
+			"late_cancel":             model.LateCancel,
+			"series_id":               model.SeriesID,
+			"min_level":               model.MinLevel,
+			"max_level":               model.MaxLevel,
+			"doubles":                 model.Doubles,
+			"duration_minutes":        model.DurationMinutes,
+			"court_ids":               model.CourtIDs,
+			"updated_at":              model.UpdatedAt,
+			"version":                 evt.Version + 1,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
 
-	if err != nil {
+	if result.RowsAffected == 0 {
+		// Либо событие новое, либо его успели изменить параллельно
+		var count int64
+		if err := tx.WithContext(ctx).
+			Model(&models.EventGORM{}).
+			Where("event_id = ?", model.EventID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return &event.ConflictError{EventID: evt.ID}
+		}
+
+		model.Version = evt.Version + 1
+		if err := tx.WithContext(ctx).Create(model).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := r.saveRegistrations(ctx, tx, evt.ID, prevRegistrations, evt.Registrations); err != nil {
+		return err
+	}
+	if err := r.saveGuests(ctx, tx, evt.ID, prevGuests, evt.Guests); err != nil {
 		return err
 	}
 
-	return r.saveRegistrations(ctx, evt.ID, evt.Registrations)
+	evt.Version++
+	return nil
 }
 
 func (r *eventRepository) Delete(ctx context.Context, id event.EventID) error {
@@ -168,27 +340,113 @@ func (r *eventRepository) Delete(ctx context.Context, id event.EventID) error {
 		Delete(&models.EventRegistrationGORM{}).Error; err != nil {
 		return err
 	}
+	if err := r.db.WithContext(ctx).
+		Where("event_id = ?", string(id)).
+		Delete(&models.EventGuestGORM{}).Error; err != nil {
+		return err
+	}
 
 	return r.db.WithContext(ctx).
 		Where("event_id = ?", string(id)).
 		Delete(&models.EventGORM{}).Error
 }
 
+func (r *eventRepository) ListNoShowDates(ctx context.Context, userID int64, since time.Time) ([]time.Time, error) {
+	// В регистрациях хранится внутренний ID пользователя, а не Telegram ID
+	var user models.UserGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ?", userID).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var eventIDs []string
+	if err := r.db.WithContext(ctx).
+		Model(&models.EventRegistrationGORM{}).
+		Where("user_id = ? AND (attendance = ? OR status = ?) AND deleted_at IS NULL",
+			user.ID, string(event.AttendanceNoShow), string(event.RegistrationStatusLateCancelled)).
+		Pluck("event_id", &eventIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+
+	var dates []time.Time
+	if err := r.db.WithContext(ctx).
+		Model(&models.EventGORM{}).
+		Where("event_id IN ? AND date >= ? AND deleted_at IS NULL", eventIDs, since).
+		Pluck("date", &dates).Error; err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
+
+func (r *eventRepository) SaveAnnouncement(ctx context.Context, announcement event.Announcement) error {
+	model := models.EventAnnouncementGORM{
+		EventID:   string(announcement.EventID),
+		ChatID:    announcement.ChatID,
+		MessageID: announcement.MessageID,
+	}
+	// Повторная публикация в тот же канал заменяет сохраненное сообщение
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "event_id"}, {Name: "chat_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"message_id"}),
+		}).
+		Create(&model).Error
+}
+
+func (r *eventRepository) ListAnnouncements(ctx context.Context, id event.EventID) ([]event.Announcement, error) {
+	var rows []models.EventAnnouncementGORM
+	if err := r.db.WithContext(ctx).
+		Where("event_id = ?", string(id)).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	announcements := make([]event.Announcement, 0, len(rows))
+	for _, row := range rows {
+		announcements = append(announcements, event.Announcement{
+			EventID:   event.EventID(row.EventID),
+			ChatID:    row.ChatID,
+			MessageID: row.MessageID,
+		})
+	}
+	return announcements, nil
+}
+
 func (r *eventRepository) modelToDomain(model *models.EventGORM) (*event.Event, error) {
 	evt := &event.Event{
-		ID:           event.EventID(model.EventID),
-		Name:         model.Name,
-		Type:         event.EventType(model.Type),
-		Date:         model.Date,
-		Remaining:    model.Remaining,
-		MaxPlayers:   model.MaxPlayers,
-		LocationID:   location.LocationID(model.LocationID),
-		Trainer:      model.Trainer,
-		Description:  model.Description,
-		PaymentPhone: model.PaymentPhone,
-		Price:        model.Price,
-		CreatedAt:    model.CreatedAt,
-		UpdatedAt:    model.UpdatedAt,
+		ID:             event.EventID(model.EventID),
+		Name:           model.Name,
+		Type:           event.EventType(model.Type),
+		Status:         event.Status(model.Status),
+		Date:           model.Date,
+		Remaining:      model.Remaining,
+		MaxPlayers:     model.MaxPlayers,
+		LocationID:     location.LocationID(model.LocationID),
+		Trainer:        model.Trainer,
+		Description:    model.Description,
+		PaymentPhone:   model.PaymentPhone,
+		Price:          model.Price,
+		PaymentMode:    event.PaymentMode(model.PaymentMode),
+		PendingTimeout: time.Duration(model.PendingTimeoutMinutes) * time.Minute,
+		CancelDeadline: time.Duration(model.CancelDeadlineMinutes) * time.Minute,
+		LateCancel:     event.LateCancelMode(model.LateCancel),
+		SeriesID:       model.SeriesID,
+		MinLevel:       user.Level(model.MinLevel),
+		MaxLevel:       user.Level(model.MaxLevel),
+		Doubles:        model.Doubles,
+		Duration:       time.Duration(model.DurationMinutes) * time.Minute,
+		CourtIDs:       splitCourtIDs(model.CourtIDs),
+		CreatedBy:      model.CreatedBy,
+		Version:        model.Version,
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
 	}
 
 	evt.Players = []int64{}
@@ -199,27 +457,60 @@ func (r *eventRepository) modelToDomain(model *models.EventGORM) (*event.Event,
 
 func (r *eventRepository) domainToModel(evt *event.Event) (*models.EventGORM, error) {
 	model := &models.EventGORM{
-		EventID:      string(evt.ID),
-		Name:         evt.Name,
-		Type:         string(evt.Type),
-		Date:         evt.Date,
-		Remaining:    evt.Remaining,
-		MaxPlayers:   evt.MaxPlayers,
-		LocationID:   string(evt.LocationID),
-		Trainer:      evt.Trainer,
-		Description:  evt.Description,
-		PaymentPhone: evt.PaymentPhone,
-		Price:        evt.Price,
-		CreatedAt:    evt.CreatedAt,
-		UpdatedAt:    evt.UpdatedAt,
+		EventID:               string(evt.ID),
+		Name:                  evt.Name,
+		Type:                  string(evt.Type),
+		Status:                string(evt.Status),
+		Date:                  evt.Date,
+		Remaining:             evt.Remaining,
+		MaxPlayers:            evt.MaxPlayers,
+		LocationID:            string(evt.LocationID),
+		Trainer:               evt.Trainer,
+		Description:           evt.Description,
+		PaymentPhone:          evt.PaymentPhone,
+		Price:                 evt.Price,
+		PaymentMode:           string(evt.PaymentMode),
+		PendingTimeoutMinutes: int(evt.PendingTimeout / time.Minute),
+		CancelDeadlineMinutes: int(evt.CancelDeadline / time.Minute),
+		LateCancel:            string(evt.LateCancel),
+		SeriesID:              evt.SeriesID,
+		MinLevel:              int(evt.MinLevel),
+		MaxLevel:              int(evt.MaxLevel),
+		Doubles:               evt.Doubles,
+		DurationMinutes:       int(evt.Duration / time.Minute),
+		CourtIDs:              joinCourtIDs(evt.CourtIDs),
+		CreatedBy:             evt.CreatedBy,
+		CreatedAt:             evt.CreatedAt,
+		UpdatedAt:             evt.UpdatedAt,
 	}
 
 	return model, nil
 }
 
-func (r *eventRepository) loadRegistrations(ctx context.Context, eventID event.EventID) (map[int64]event.EventRegistration, error) {
+// joinCourtIDs упаковывает ID кортов в строку через запятую
+func joinCourtIDs(ids []location.CourtID) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, string(id))
+	}
+	return strings.Join(parts, ",")
+}
+
+// splitCourtIDs распаковывает ID кортов из строки через запятую
+func splitCourtIDs(s string) []location.CourtID {
+	if s == "" {
+		return nil
+	}
+	var ids []location.CourtID
+	for _, part := range strings.Split(s, ",") {
+		ids = append(ids, location.CourtID(part))
+	}
+	return ids
+}
+
+func (r *eventRepository) loadRegistrations(ctx context.Context, db *gorm.DB, eventID event.EventID) (map[int64]event.EventRegistration, error) {
 	var regModels []models.EventRegistrationGORM
-	if err := r.db.WithContext(ctx).
+	if err := db.WithContext(ctx).
 		Preload("User").
 		Where("event_id = ? AND deleted_at IS NULL", string(eventID)).
 		Find(&regModels).Error; err != nil {
@@ -230,130 +521,242 @@ func (r *eventRepository) loadRegistrations(ctx context.Context, eventID event.E
 	for _, regModel := range regModels {
 		telegramID := regModel.User.TelegramID
 		registrations[telegramID] = event.EventRegistration{
-			UserID:    telegramID,
-			Status:    event.RegistrationStatus(regModel.Status),
-			CreatedAt: regModel.CreatedAt,
-			UpdatedAt: regModel.UpdatedAt,
+			UserID:           telegramID,
+			Status:           event.RegistrationStatus(regModel.Status),
+			WaitlistPosition: regModel.WaitlistPosition,
+			PendingSince:     timeValue(regModel.PendingSince),
+			ReminderSentAt:   timeValue(regModel.ReminderSentAt),
+			Attendance:       event.Attendance(regModel.Attendance),
+			CheckedInAt:      timeValue(regModel.CheckedInAt),
+			PartnerID:        regModel.PartnerTelegramID,
+			Captain:          regModel.TeamCaptain,
+			Discount:         regModel.Discount,
+			CreatedAt:        regModel.CreatedAt,
+			UpdatedAt:        regModel.UpdatedAt,
 		}
 	}
 
 	return registrations, nil
 }
 
-func (r *eventRepository) recalculatePlayersAndRemaining(evt *event.Event) {
-	approvedPlayers := make([]int64, 0)
-	for userID, reg := range evt.Registrations {
-		if reg.Status == event.RegistrationStatusApproved {
-			approvedPlayers = append(approvedPlayers, userID)
+func (r *eventRepository) loadGuests(ctx context.Context, db *gorm.DB, eventID event.EventID) (map[event.GuestID]event.Guest, error) {
+	var guestModels []models.EventGuestGORM
+	if err := db.WithContext(ctx).
+		Where("event_id = ? AND deleted_at IS NULL", string(eventID)).
+		Find(&guestModels).Error; err != nil {
+		return nil, err
+	}
+
+	guests := make(map[event.GuestID]event.Guest, len(guestModels))
+	for _, m := range guestModels {
+		guests[event.GuestID(m.GuestID)] = event.Guest{
+			ID:        event.GuestID(m.GuestID),
+			HostID:    m.HostTelegramID,
+			Name:      m.Name,
+			Status:    event.RegistrationStatus(m.Status),
+			CreatedAt: m.CreatedAt,
+			UpdatedAt: m.UpdatedAt,
+		}
+	}
+	return guests, nil
+}
+
+// saveGuests записывает изменившихся и новых гостей события и удаляет (soft delete) тех, кого больше нет
+func (r *eventRepository) saveGuests(ctx context.Context, db *gorm.DB, eventID event.EventID, prev, guests map[event.GuestID]event.Guest) error {
+	for id, guest := range guests {
+		if old, ok := prev[id]; ok && old == guest {
+			continue
+		}
+		if err := db.WithContext(ctx).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "guest_id"}, {Name: "event_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"name", "status", "updated_at", "deleted_at"}),
+			}).
+			Create(&models.EventGuestGORM{
+				GuestID:        string(id),
+				EventID:        string(eventID),
+				HostTelegramID: guest.HostID,
+				Name:           guest.Name,
+				Status:         string(guest.Status),
+				CreatedAt:      guest.CreatedAt,
+				UpdatedAt:      guest.UpdatedAt,
+			}).Error; err != nil {
+			return err
 		}
 	}
 
-	evt.Players = approvedPlayers
-	evt.Remaining = evt.MaxPlayers - len(approvedPlayers)
-	if evt.Remaining < 0 {
-		evt.Remaining = 0
+	var removed []string
+	for id := range prev {
+		if _, ok := guests[id]; !ok {
+			removed = append(removed, string(id))
+		}
+	}
+	if len(removed) == 0 {
+		return nil
 	}
+	return db.WithContext(ctx).
+		Where("event_id = ? AND guest_id IN ?", string(eventID), removed).
+		Delete(&models.EventGuestGORM{}).Error
 }
 
-func (r *eventRepository) saveRegistrations(ctx context.Context, eventID event.EventID, registrations map[int64]event.EventRegistration) error {
-	// Используем Unscoped() чтобы найти и soft-deleted записи
-	var existingRegs []models.EventRegistrationGORM
-	if err := r.db.WithContext(ctx).Unscoped().
-		Preload("User").
-		Where("event_id = ?", string(eventID)).
-		Find(&existingRegs).Error; err != nil {
+// checkCourts не дает двум событиям занять один корт в одно время. Расписание локации блокируется
+// (pg_advisory_xact_lock) до конца транзакции, и только потом события локации перечитываются:
+// параллельная транзакция с кортами той же локации дождется блокировки и увидит уже сохраненное событие
+func (r *eventRepository) checkCourts(ctx context.Context, tx *gorm.DB, evt *event.Event) error {
+	if len(evt.CourtIDs) == 0 || !evt.Status.IsActive() {
+		return nil
+	}
+	if err := tx.WithContext(ctx).
+		Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "event_courts:"+string(evt.LocationID)).Error; err != nil {
 		return err
 	}
 
-	existingMap := make(map[int64]int64) // telegramID -> userID
-	deletedMap := make(map[int64]bool)   // telegramID -> isDeleted
-	for _, reg := range existingRegs {
-		existingMap[reg.User.TelegramID] = reg.UserID
-		// Проверяем, является ли запись soft-deleted
-		if reg.DeletedAt.Valid {
-			deletedMap[reg.User.TelegramID] = true
+	var rows []models.EventGORM
+	if err := tx.WithContext(ctx).
+		Where("location_id = ? AND event_id <> ? AND deleted_at IS NULL", string(evt.LocationID), string(evt.ID)).
+		Find(&rows).Error; err != nil {
+		return err
+	}
+	events := make([]event.Event, 0, len(rows))
+	for i := range rows {
+		other, err := r.modelToDomain(&rows[i])
+		if err != nil {
+			return err
 		}
+		events = append(events, *other)
+	}
+	if conflict := event.FindCourtConflict(events, evt); conflict != nil {
+		return conflict
 	}
+	return nil
+}
 
-	for telegramID, reg := range registrations {
-		var user models.UserGORM
-		if err := r.db.WithContext(ctx).
-			Where("telegram_id = ?", telegramID).
-			First(&user).Error; err != nil {
-			continue
+// bookingChanged сообщает, что изменилось то, от чего зависит занятость кортов: локация, время, корты или статус
+func bookingChanged(before, after *event.Event) bool {
+	if before.LocationID != after.LocationID || !before.Date.Equal(after.Date) ||
+		before.Duration != after.Duration || before.Status != after.Status ||
+		len(before.CourtIDs) != len(after.CourtIDs) {
+		return true
+	}
+	for i := range before.CourtIDs {
+		if before.CourtIDs[i] != after.CourtIDs[i] {
+			return true
 		}
+	}
+	return false
+}
 
-		regModel := &models.EventRegistrationGORM{
-			EventID:   string(eventID),
-			UserID:    user.ID,
-			Status:    string(reg.Status),
-			CreatedAt: reg.CreatedAt,
-			UpdatedAt: reg.UpdatedAt,
-		}
-
-		if userID, exists := existingMap[telegramID]; exists {
-			// Запись существует (возможно, soft-deleted)
-			if deletedMap[telegramID] {
-				// Восстанавливаем soft-deleted запись
-				if err := r.db.WithContext(ctx).Unscoped().
-					Model(&models.EventRegistrationGORM{}).
-					Where("event_id = ? AND user_id = ?", string(eventID), userID).
-					Updates(map[string]interface{}{
-						"status":     regModel.Status,
-						"updated_at": regModel.UpdatedAt,
-						"deleted_at": nil, // Восстанавливаем запись
-					}).Error; err != nil {
-					return err
-				}
-			} else {
-				// Обновляем существующую запись
-				if err := r.db.WithContext(ctx).
-					Model(&models.EventRegistrationGORM{}).
-					Where("event_id = ? AND user_id = ?", string(eventID), userID).
-					Updates(regModel).Error; err != nil {
-					return err
-				}
-			}
-		} else {
-			// Создаем новую запись
-			if err := r.db.WithContext(ctx).Create(regModel).Error; err != nil {
-				return err
-			}
+func (r *eventRepository) recalculatePlayersAndRemaining(evt *event.Event) {
+	evt.RecalculateCapacity()
+}
+
+// saveRegistrations записывает только изменившиеся и новые регистрации (upsert по паре event_id, user_id,
+// который заодно восстанавливает soft-deleted запись) и удаляет (soft delete) регистрации, которых больше нет
+func (r *eventRepository) saveRegistrations(ctx context.Context, db *gorm.DB, eventID event.EventID, prev, registrations map[int64]event.EventRegistration) error {
+	var changed, removed []int64
+	for telegramID, reg := range registrations {
+		if old, ok := prev[telegramID]; !ok || old != reg {
+			changed = append(changed, telegramID)
+		}
+	}
+	for telegramID := range prev {
+		if _, ok := registrations[telegramID]; !ok {
+			removed = append(removed, telegramID)
 		}
 	}
+	if len(changed) == 0 && len(removed) == 0 {
+		return nil
+	}
 
-	var telegramIDsToKeep []int64
-	for telegramID := range registrations {
-		telegramIDsToKeep = append(telegramIDsToKeep, telegramID)
+	// В регистрациях хранится внутренний ID пользователя: получаем все нужные одним запросом
+	var users []models.UserGORM
+	if err := db.WithContext(ctx).
+		Where("telegram_id IN ?", append(changed, removed...)).
+		Find(&users).Error; err != nil {
+		return err
+	}
+	userIDs := make(map[int64]int64, len(users)) // telegramID -> userID
+	for _, u := range users {
+		userIDs[u.TelegramID] = u.ID
 	}
 
-	if len(telegramIDsToKeep) > 0 {
-		var users []models.UserGORM
-		if err := r.db.WithContext(ctx).
-			Where("telegram_id IN ?", telegramIDsToKeep).
-			Find(&users).Error; err != nil {
+	for _, telegramID := range changed {
+		userID, ok := userIDs[telegramID]
+		if !ok {
+			continue
+		}
+		reg := registrations[telegramID]
+		if err := db.WithContext(ctx).
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "event_id"}, {Name: "user_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"status", "waitlist_position", "pending_since", "reminder_sent_at", "attendance", "checked_in_at",
+					"partner_telegram_id", "team_captain", "discount", "created_at", "updated_at", "deleted_at",
+				}),
+			}).
+			Create(&models.EventRegistrationGORM{
+				EventID:           string(eventID),
+				UserID:            userID,
+				Status:            string(reg.Status),
+				WaitlistPosition:  reg.WaitlistPosition,
+				PendingSince:      timePtr(reg.PendingSince),
+				ReminderSentAt:    timePtr(reg.ReminderSentAt),
+				Attendance:        string(reg.Attendance),
+				CheckedInAt:       timePtr(reg.CheckedInAt),
+				PartnerTelegramID: reg.PartnerID,
+				TeamCaptain:       reg.Captain,
+				Discount:          reg.Discount,
+				CreatedAt:         reg.CreatedAt,
+				UpdatedAt:         reg.UpdatedAt,
+			}).Error; err != nil {
 			return err
 		}
+	}
 
-		var userIDsToKeep []int64
-		for _, user := range users {
-			userIDsToKeep = append(userIDsToKeep, user.ID)
+	var removedUserIDs []int64
+	for _, telegramID := range removed {
+		if userID, ok := userIDs[telegramID]; ok {
+			removedUserIDs = append(removedUserIDs, userID)
 		}
+	}
+	if len(removedUserIDs) == 0 {
+		return nil
+	}
+	return db.WithContext(ctx).
+		Where("event_id = ? AND user_id IN ?", string(eventID), removedUserIDs).
+		Delete(&models.EventRegistrationGORM{}).Error
+}
 
-		if len(userIDsToKeep) > 0 {
-			if err := r.db.WithContext(ctx).
-				Where("event_id = ? AND user_id NOT IN ?", string(eventID), userIDsToKeep).
-				Delete(&models.EventRegistrationGORM{}).Error; err != nil {
-				return err
-			}
-		}
-	} else {
-		if err := r.db.WithContext(ctx).
-			Where("event_id = ?", string(eventID)).
-			Delete(&models.EventRegistrationGORM{}).Error; err != nil {
-			return err
-		}
+// copyRegistrations копирует регистрации, чтобы сравнить их с измененными
+func copyRegistrations(registrations map[int64]event.EventRegistration) map[int64]event.EventRegistration {
+	c := make(map[int64]event.EventRegistration, len(registrations))
+	for id, reg := range registrations {
+		c[id] = reg
 	}
+	return c
+}
 
-	return nil
+// copyGuests копирует гостей, чтобы сравнить их с измененными
+func copyGuests(guests map[event.GuestID]event.Guest) map[event.GuestID]event.Guest {
+	c := make(map[event.GuestID]event.Guest, len(guests))
+	for id, g := range guests {
+		c[id] = g
+	}
+	return c
+}
+
+// timePtr конвертирует нулевое время в NULL для nullable-колонок
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// timeValue конвертирует nullable-колонку в time.Time (NULL -> нулевое время)
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
 }
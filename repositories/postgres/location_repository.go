@@ -79,3 +79,42 @@ func (r *locationRepository) Delete(ctx context.Context, id location.LocationID)
 		Where("location_id = ?", id).
 		Delete(&models.LocationGORM{}).Error
 }
+
+func (r *locationRepository) ListCourts(ctx context.Context, locationID location.LocationID) ([]location.Court, error) {
+	var rows []models.CourtGORM
+	if err := r.db.WithContext(ctx).
+		Where("location_id = ?", string(locationID)).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	courts := make([]location.Court, 0, len(rows))
+	for _, m := range rows {
+		courts = append(courts, location.Court{
+			ID:         location.CourtID(m.CourtID),
+			LocationID: location.LocationID(m.LocationID),
+			Name:       m.Name,
+		})
+	}
+	return courts, nil
+}
+
+func (r *locationRepository) SaveCourt(ctx context.Context, court *location.Court) error {
+	model := &models.CourtGORM{
+		CourtID:    string(court.ID),
+		LocationID: string(court.LocationID),
+		Name:       court.Name,
+	}
+
+	return r.db.WithContext(ctx).
+		Where("court_id = ?", string(court.ID)).
+		Assign(model).
+		FirstOrCreate(model).Error
+}
+
+func (r *locationRepository) DeleteCourt(ctx context.Context, id location.CourtID) error {
+	return r.db.WithContext(ctx).
+		Where("court_id = ?", string(id)).
+		Delete(&models.CourtGORM{}).Error
+}
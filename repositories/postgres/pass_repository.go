@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/pass"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type passRepository struct {
+	db *gorm.DB
+}
+
+func NewPassRepository(db *gorm.DB) pass.Repository {
+	return &passRepository{db: db}
+}
+
+func (r *passRepository) Save(ctx context.Context, p *pass.Pass) error {
+	types := make([]string, 0, len(p.EventTypes))
+	for _, t := range p.EventTypes {
+		types = append(types, string(t))
+	}
+	locations := make([]string, 0, len(p.LocationIDs))
+	for _, id := range p.LocationIDs {
+		locations = append(locations, string(id))
+	}
+
+	model := &models.PassGORM{
+		PassID:      string(p.ID),
+		TelegramID:  p.UserID,
+		Sessions:    p.Sessions,
+		ValidFrom:   p.ValidFrom,
+		ValidUntil:  p.ValidUntil,
+		EventTypes:  strings.Join(types, ","),
+		LocationIDs: strings.Join(locations, ","),
+		IssuedBy:    p.IssuedBy,
+		CreatedAt:   p.CreatedAt,
+	}
+	return r.db.WithContext(ctx).Create(model).Error
+}
+
+func (r *passRepository) Get(ctx context.Context, id pass.PassID) (*pass.Pass, error) {
+	var model models.PassGORM
+	if err := r.db.WithContext(ctx).
+		Preload("Usages", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("pass_id = ?", string(id)).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	p := toPass(&model)
+	return &p, nil
+}
+
+func (r *passRepository) ListByUser(ctx context.Context, userID int64) ([]pass.Pass, error) {
+	var rows []models.PassGORM
+	if err := r.db.WithContext(ctx).
+		Preload("Usages", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("telegram_id = ?", userID).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	passes := make([]pass.Pass, 0, len(rows))
+	for i := range rows {
+		passes = append(passes, toPass(&rows[i]))
+	}
+	return passes, nil
+}
+
+func (r *passRepository) FindByUsage(ctx context.Context, eventID event.EventID, userID int64) (*pass.Pass, error) {
+	var usage models.PassUsageGORM
+	if err := r.db.WithContext(ctx).
+		Where("event_id = ? AND telegram_id = ?", string(eventID), userID).
+		First(&usage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var model models.PassGORM
+	if err := r.db.WithContext(ctx).
+		Preload("Usages", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&model, usage.PassID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	p := toPass(&model)
+	return &p, nil
+}
+
+func (r *passRepository) Update(ctx context.Context, id pass.PassID, fn func(p *pass.Pass) error) (*pass.Pass, error) {
+	var updated *pass.Pass
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокируем строку абонемента, чтобы параллельные списания шли по очереди
+		var model models.PassGORM
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("pass_id = ?", string(id)).
+			First(&model).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pass.ErrPassNotFound
+			}
+			return err
+		}
+		if err := tx.Where("pass_id = ?", model.ID).Order("id ASC").Find(&model.Usages).Error; err != nil {
+			return err
+		}
+
+		p := toPass(&model)
+		if err := fn(&p); err != nil {
+			return err
+		}
+
+		// Синхронизируем списанные занятия: возвращенные удаляем, новые добавляем
+		kept := make(map[event.EventID]bool, len(p.Usages))
+		for _, u := range p.Usages {
+			kept[u.EventID] = true
+		}
+		existing := make(map[event.EventID]bool, len(model.Usages))
+		for _, u := range model.Usages {
+			existing[event.EventID(u.EventID)] = true
+			if kept[event.EventID(u.EventID)] {
+				continue
+			}
+			if err := tx.Delete(&models.PassUsageGORM{}, u.ID).Error; err != nil {
+				return err
+			}
+		}
+		for _, u := range p.Usages {
+			if existing[u.EventID] {
+				continue
+			}
+			if err := tx.Create(&models.PassUsageGORM{
+				PassID:     model.ID,
+				EventID:    string(u.EventID),
+				TelegramID: model.TelegramID,
+				CreatedAt:  u.CreatedAt,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		updated = &p
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (r *passRepository) Delete(ctx context.Context, id pass.PassID) error {
+	return r.db.WithContext(ctx).
+		Where("pass_id = ?", string(id)).
+		Delete(&models.PassGORM{}).Error
+}
+
+func toPass(m *models.PassGORM) pass.Pass {
+	p := pass.Pass{
+		ID:         pass.PassID(m.PassID),
+		UserID:     m.TelegramID,
+		Sessions:   m.Sessions,
+		ValidFrom:  m.ValidFrom,
+		ValidUntil: m.ValidUntil,
+		IssuedBy:   m.IssuedBy,
+		CreatedAt:  m.CreatedAt,
+	}
+	if m.EventTypes != "" {
+		for _, t := range strings.Split(m.EventTypes, ",") {
+			p.EventTypes = append(p.EventTypes, event.EventType(t))
+		}
+	}
+	if m.LocationIDs != "" {
+		for _, id := range strings.Split(m.LocationIDs, ",") {
+			p.LocationIDs = append(p.LocationIDs, location.LocationID(id))
+		}
+	}
+	for _, u := range m.Usages {
+		p.Usages = append(p.Usages, pass.Usage{EventID: event.EventID(u.EventID), CreatedAt: u.CreatedAt})
+	}
+	return p
+}
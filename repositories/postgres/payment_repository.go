@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/payment"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) payment.Repository {
+	return &paymentRepository{db: db}
+}
+
+func (r *paymentRepository) Save(ctx context.Context, p *payment.Payment) error {
+	var usr models.UserGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ?", p.UserID).
+		First(&usr).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return payment.ErrRegistrationNotFound
+		}
+		return err
+	}
+
+	// Unscoped: возврат возможен и по регистрации игрока, который уже отписался
+	var reg models.EventRegistrationGORM
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("event_id = ? AND user_id = ?", string(p.EventID), usr.ID).
+		First(&reg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return payment.ErrRegistrationNotFound
+		}
+		return err
+	}
+
+	model := &models.PaymentGORM{
+		PaymentID:       string(p.ID),
+		RegistrationID:  reg.ID,
+		EventID:         string(p.EventID),
+		TelegramID:      p.UserID,
+		GuestID:         string(p.GuestID),
+		PayerTelegramID: p.PayerID,
+		Kind:            string(p.Kind),
+		Method:          string(p.Method),
+		Amount:          p.Amount,
+		ConfirmedBy:     p.ConfirmedBy,
+		ChargeID:        p.ChargeID,
+		Comment:         p.Comment,
+		CreatedAt:       p.CreatedAt,
+	}
+	if p.ChargeID == "" {
+		return r.db.WithContext(ctx).Create(model).Error
+	}
+
+	// Telegram может прислать successful_payment повторно: уникальный индекс по charge_id не дает записать оплату дважды
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return payment.ErrDuplicateCharge
+	}
+	return nil
+}
+
+func (r *paymentRepository) ListByEvent(ctx context.Context, eventID event.EventID) ([]payment.Payment, error) {
+	var rows []models.PaymentGORM
+	if err := r.db.WithContext(ctx).
+		Where("event_id = ?", string(eventID)).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toPayments(rows), nil
+}
+
+func (r *paymentRepository) ListByUser(ctx context.Context, userID int64) ([]payment.Payment, error) {
+	var rows []models.PaymentGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ? OR payer_telegram_id = ?", userID, userID).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toPayments(rows), nil
+}
+
+func (r *paymentRepository) ListByEvents(ctx context.Context, eventIDs []event.EventID) ([]payment.Payment, error) {
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		ids = append(ids, string(id))
+	}
+
+	var rows []models.PaymentGORM
+	if err := r.db.WithContext(ctx).
+		Where("event_id IN ?", ids).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toPayments(rows), nil
+}
+
+func toPayments(rows []models.PaymentGORM) []payment.Payment {
+	payments := make([]payment.Payment, 0, len(rows))
+	for _, m := range rows {
+		payments = append(payments, payment.Payment{
+			ID:          payment.PaymentID(m.PaymentID),
+			EventID:     event.EventID(m.EventID),
+			UserID:      m.TelegramID,
+			GuestID:     event.GuestID(m.GuestID),
+			PayerID:     m.PayerTelegramID,
+			Kind:        payment.Kind(m.Kind),
+			Method:      payment.Method(m.Method),
+			Amount:      m.Amount,
+			ConfirmedBy: m.ConfirmedBy,
+			ChargeID:    m.ChargeID,
+			Comment:     m.Comment,
+			CreatedAt:   m.CreatedAt,
+		})
+	}
+	return payments
+}
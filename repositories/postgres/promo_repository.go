@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/promo"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type promoRepository struct {
+	db *gorm.DB
+}
+
+func NewPromoRepository(db *gorm.DB) promo.Repository {
+	return &promoRepository{db: db}
+}
+
+func (r *promoRepository) Save(ctx context.Context, c *promo.Code) error {
+	types := make([]string, 0, len(c.EventTypes))
+	for _, t := range c.EventTypes {
+		types = append(types, string(t))
+	}
+
+	model := &models.PromoCodeGORM{
+		CodeID:         string(c.ID),
+		Code:           c.Code,
+		Kind:           string(c.Kind),
+		Value:          c.Value,
+		MaxUses:        c.MaxUses,
+		MaxUsesPerUser: c.MaxUsesPerUser,
+		ExpiresAt:      timePtr(c.ExpiresAt),
+		EventTypes:     strings.Join(types, ","),
+		EventID:        string(c.EventID),
+		Active:         c.Active,
+		CreatedBy:      c.CreatedBy,
+		CreatedAt:      c.CreatedAt,
+	}
+
+	// Select("*") сохраняет и нулевые значения (например, отключенный промокод)
+	var existing models.PromoCodeGORM
+	err := r.db.WithContext(ctx).Where("code_id = ?", string(c.ID)).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return r.db.WithContext(ctx).Create(model).Error
+	}
+	if err != nil {
+		return err
+	}
+	model.ID = existing.ID
+	return r.db.WithContext(ctx).Select("*").Save(model).Error
+}
+
+func (r *promoRepository) Get(ctx context.Context, id promo.CodeID) (*promo.Code, error) {
+	return r.first(ctx, "code_id = ?", string(id))
+}
+
+func (r *promoRepository) GetByCode(ctx context.Context, code string) (*promo.Code, error) {
+	return r.first(ctx, "code = ?", code)
+}
+
+func (r *promoRepository) first(ctx context.Context, query string, arg interface{}) (*promo.Code, error) {
+	var model models.PromoCodeGORM
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var uses int64
+	if err := r.db.WithContext(ctx).Model(&models.PromoRedemptionGORM{}).
+		Where("promo_code_id = ?", model.ID).
+		Count(&uses).Error; err != nil {
+		return nil, err
+	}
+
+	c := toPromoCode(&model, int(uses))
+	return &c, nil
+}
+
+func (r *promoRepository) List(ctx context.Context) ([]promo.Code, error) {
+	var rows []models.PromoCodeGORM
+	if err := r.db.WithContext(ctx).Order("id DESC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		PromoCodeID uint
+		Uses        int
+	}
+	if err := r.db.WithContext(ctx).Model(&models.PromoRedemptionGORM{}).
+		Select("promo_code_id, COUNT(*) AS uses").
+		Group("promo_code_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	uses := make(map[uint]int, len(counts))
+	for _, c := range counts {
+		uses[c.PromoCodeID] = c.Uses
+	}
+
+	codes := make([]promo.Code, 0, len(rows))
+	for i := range rows {
+		codes = append(codes, toPromoCode(&rows[i], uses[rows[i].ID]))
+	}
+	return codes, nil
+}
+
+func (r *promoRepository) Redeem(ctx context.Context, id promo.CodeID, fn func(c *promo.Code, redemptions []promo.Redemption) (*promo.Redemption, error)) (*promo.Redemption, error) {
+	var created *promo.Redemption
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокируем промокод, чтобы параллельные погашения не превысили лимиты
+		var model models.PromoCodeGORM
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_id = ?", string(id)).
+			First(&model).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return promo.ErrCodeNotFound
+			}
+			return err
+		}
+
+		var rows []models.PromoRedemptionGORM
+		if err := tx.Where("promo_code_id = ?", model.ID).Order("id ASC").Find(&rows).Error; err != nil {
+			return err
+		}
+		redemptions := toRedemptions(rows, promo.CodeID(model.CodeID))
+
+		c := toPromoCode(&model, len(redemptions))
+		redemption, err := fn(&c, redemptions)
+		if err != nil || redemption == nil {
+			return err
+		}
+
+		// Другой промокод на эту же запись мог погаситься параллельно (он блокирует свою строку):
+		// уникальный индекс допускает одно действующее погашение на событие и игрока
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PromoRedemptionGORM{
+			RedemptionID: string(redemption.ID),
+			PromoCodeID:  model.ID,
+			EventID:      string(redemption.EventID),
+			TelegramID:   redemption.UserID,
+			Discount:     redemption.Discount,
+			CreatedAt:    redemption.CreatedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return promo.ErrAlreadyRedeemed
+		}
+		created = redemption
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *promoRepository) ListRedemptions(ctx context.Context, id promo.CodeID) ([]promo.Redemption, error) {
+	var rows []models.PromoRedemptionGORM
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN promo_codes ON promo_codes.id = promo_redemptions.promo_code_id").
+		Where("promo_codes.code_id = ?", string(id)).
+		Order("promo_redemptions.id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toRedemptions(rows, id), nil
+}
+
+func (r *promoRepository) Release(ctx context.Context, eventID event.EventID, userID int64) (*promo.Redemption, error) {
+	var model models.PromoRedemptionGORM
+	if err := r.db.WithContext(ctx).
+		Preload("PromoCode").
+		Where("event_id = ? AND telegram_id = ?", string(eventID), userID).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).Delete(&model).Error; err != nil {
+		return nil, err
+	}
+	redemption := toRedemptions([]models.PromoRedemptionGORM{model}, promo.CodeID(model.PromoCode.CodeID))[0]
+	return &redemption, nil
+}
+
+func toPromoCode(m *models.PromoCodeGORM, uses int) promo.Code {
+	c := promo.Code{
+		ID:             promo.CodeID(m.CodeID),
+		Code:           m.Code,
+		Kind:           promo.Kind(m.Kind),
+		Value:          m.Value,
+		MaxUses:        m.MaxUses,
+		MaxUsesPerUser: m.MaxUsesPerUser,
+		ExpiresAt:      timeValue(m.ExpiresAt),
+		EventID:        event.EventID(m.EventID),
+		Active:         m.Active,
+		CreatedBy:      m.CreatedBy,
+		CreatedAt:      m.CreatedAt,
+		Uses:           uses,
+	}
+	if m.EventTypes != "" {
+		for _, t := range strings.Split(m.EventTypes, ",") {
+			c.EventTypes = append(c.EventTypes, event.EventType(t))
+		}
+	}
+	return c
+}
+
+func toRedemptions(rows []models.PromoRedemptionGORM, codeID promo.CodeID) []promo.Redemption {
+	redemptions := make([]promo.Redemption, 0, len(rows))
+	for _, m := range rows {
+		redemptions = append(redemptions, promo.Redemption{
+			ID:        promo.RedemptionID(m.RedemptionID),
+			CodeID:    codeID,
+			EventID:   event.EventID(m.EventID),
+			UserID:    m.TelegramID,
+			Discount:  m.Discount,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return redemptions
+}
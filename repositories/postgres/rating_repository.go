@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/rating"
+	"pickletlgbot/internal/domain/tournament"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ratingRepository struct {
+	db *gorm.DB
+}
+
+func NewRatingRepository(db *gorm.DB) rating.Repository {
+	return &ratingRepository{db: db}
+}
+
+func (r *ratingRepository) ReplaceHistory(ctx context.Context, entries []rating.Entry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.RatingHistoryGORM{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		rows := make([]models.RatingHistoryGORM, 0, len(entries))
+		for _, e := range entries {
+			rows = append(rows, models.RatingHistoryGORM{
+				TelegramID:   e.TelegramID,
+				Seq:          e.Seq,
+				EventID:      string(e.EventID),
+				TournamentID: string(e.TournamentID),
+				MatchNo:      e.MatchID,
+				Won:          e.Won,
+				RatingBefore: e.Before,
+				RatingAfter:  e.After,
+				PlayedAt:     e.PlayedAt,
+			})
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+func (r *ratingRepository) ListHistory(ctx context.Context, telegramID int64) ([]rating.Entry, error) {
+	var rows []models.RatingHistoryGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ?", telegramID).
+		Order("seq ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toRatingEntries(rows), nil
+}
+
+func (r *ratingRepository) ListLatest(ctx context.Context) ([]rating.Entry, error) {
+	var rows []models.RatingHistoryGORM
+	if err := r.db.WithContext(ctx).
+		Select("DISTINCT ON (telegram_id) *").
+		Order("telegram_id, seq DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toRatingEntries(rows), nil
+}
+
+func toRatingEntries(rows []models.RatingHistoryGORM) []rating.Entry {
+	entries := make([]rating.Entry, 0, len(rows))
+	for _, m := range rows {
+		entries = append(entries, rating.Entry{
+			Seq:          m.Seq,
+			TelegramID:   m.TelegramID,
+			EventID:      event.EventID(m.EventID),
+			TournamentID: tournament.TournamentID(m.TournamentID),
+			MatchID:      m.MatchNo,
+			Won:          m.Won,
+			Before:       m.RatingBefore,
+			After:        m.RatingAfter,
+			PlayedAt:     m.PlayedAt,
+		})
+	}
+	return entries
+}
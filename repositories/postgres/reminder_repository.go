@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/reminder"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) reminder.Repository {
+	return &reminderRepository{db: db}
+}
+
+func (r *reminderRepository) MarkSent(ctx context.Context, eventID event.EventID, userID int64, kind reminder.Kind) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.EventReminderGORM{
+			EventID: string(eventID),
+			UserID:  userID,
+			Kind:    string(kind),
+			SentAt:  time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *reminderRepository) ListOptOuts(ctx context.Context, userID int64) ([]reminder.Kind, error) {
+	var models []models.ReminderOptOutGORM
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	kinds := make([]reminder.Kind, 0, len(models))
+	for _, m := range models {
+		kinds = append(kinds, reminder.Kind(m.Kind))
+	}
+	return kinds, nil
+}
+
+func (r *reminderRepository) SetOptOut(ctx context.Context, userID int64, kind reminder.Kind, optOut bool) error {
+	if !optOut {
+		return r.db.WithContext(ctx).
+			Where("user_id = ? AND kind = ?", userID, string(kind)).
+			Delete(&models.ReminderOptOutGORM{}).Error
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ReminderOptOutGORM{
+			UserID: userID,
+			Kind:   string(kind),
+		}).Error
+}
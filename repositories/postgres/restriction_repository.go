@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pickletlgbot/internal/domain/restriction"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type restrictionRepository struct {
+	db *gorm.DB
+}
+
+func NewRestrictionRepository(db *gorm.DB) restriction.Repository {
+	return &restrictionRepository{db: db}
+}
+
+func (r *restrictionRepository) Save(ctx context.Context, rs *restriction.Restriction) error {
+	model := &models.RestrictionGORM{
+		RestrictionID: string(rs.ID),
+		TelegramID:    rs.UserID,
+		Kind:          string(rs.Kind),
+		LocationIDs:   joinLocationIDs(rs.LocationIDs),
+		Reason:        rs.Reason,
+		CreatedBy:     rs.CreatedBy,
+		CreatedAt:     rs.CreatedAt,
+	}
+	if !rs.Until.IsZero() {
+		until := rs.Until
+		model.Until = &until
+	}
+	return r.db.WithContext(ctx).Create(model).Error
+}
+
+func (r *restrictionRepository) Get(ctx context.Context, id restriction.RestrictionID) (*restriction.Restriction, error) {
+	var model models.RestrictionGORM
+	if err := r.db.WithContext(ctx).
+		Where("restriction_id = ?", string(id)).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rs := toRestriction(&model)
+	return &rs, nil
+}
+
+func (r *restrictionRepository) ListByUser(ctx context.Context, userID int64) ([]restriction.Restriction, error) {
+	var rows []models.RestrictionGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ?", userID).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	restrictions := make([]restriction.Restriction, 0, len(rows))
+	for i := range rows {
+		restrictions = append(restrictions, toRestriction(&rows[i]))
+	}
+	return restrictions, nil
+}
+
+func (r *restrictionRepository) Lift(ctx context.Context, id restriction.RestrictionID, by int64, at time.Time) error {
+	res := r.db.WithContext(ctx).
+		Model(&models.RestrictionGORM{}).
+		Where("restriction_id = ? AND lifted_at IS NULL", string(id)).
+		Updates(map[string]interface{}{"lifted_by": by, "lifted_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return restriction.ErrAlreadyLifted
+	}
+	return nil
+}
+
+func toRestriction(m *models.RestrictionGORM) restriction.Restriction {
+	rs := restriction.Restriction{
+		ID:          restriction.RestrictionID(m.RestrictionID),
+		UserID:      m.TelegramID,
+		Kind:        restriction.Kind(m.Kind),
+		LocationIDs: splitLocationIDs(m.LocationIDs),
+		Reason:      m.Reason,
+		CreatedBy:   m.CreatedBy,
+		CreatedAt:   m.CreatedAt,
+		LiftedBy:    m.LiftedBy,
+	}
+	if m.Until != nil {
+		rs.Until = *m.Until
+	}
+	if m.LiftedAt != nil {
+		rs.LiftedAt = *m.LiftedAt
+	}
+	return rs
+}
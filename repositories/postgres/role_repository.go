@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/role"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) role.Repository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id role.GrantID) (*role.Grant, error) {
+	var model models.RoleGrantGORM
+	if err := r.db.WithContext(ctx).
+		Where("grant_id = ?", string(id)).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	grant := toRoleGrant(&model)
+	return &grant, nil
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]role.Grant, error) {
+	var rows []models.RoleGrantGORM
+	if err := r.db.WithContext(ctx).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toRoleGrants(rows), nil
+}
+
+func (r *roleRepository) ListByTelegramID(ctx context.Context, telegramID int64) ([]role.Grant, error) {
+	var rows []models.RoleGrantGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ?", telegramID).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toRoleGrants(rows), nil
+}
+
+func (r *roleRepository) Save(ctx context.Context, grant *role.Grant) error {
+	model := &models.RoleGrantGORM{
+		GrantID:    string(grant.ID),
+		TelegramID: grant.TelegramID,
+		Role:       string(grant.Role),
+		LocationID: string(grant.LocationID),
+		GrantedBy:  grant.GrantedBy,
+		CreatedAt:  grant.CreatedAt,
+	}
+
+	return r.db.WithContext(ctx).
+		Where("grant_id = ?", string(grant.ID)).
+		Assign(model).
+		FirstOrCreate(model).Error
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id role.GrantID) error {
+	return r.db.WithContext(ctx).
+		Where("grant_id = ?", string(id)).
+		Delete(&models.RoleGrantGORM{}).Error
+}
+
+func toRoleGrant(m *models.RoleGrantGORM) role.Grant {
+	return role.Grant{
+		ID:         role.GrantID(m.GrantID),
+		TelegramID: m.TelegramID,
+		Role:       role.Role(m.Role),
+		LocationID: location.LocationID(m.LocationID),
+		GrantedBy:  m.GrantedBy,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+func toRoleGrants(rows []models.RoleGrantGORM) []role.Grant {
+	grants := make([]role.Grant, 0, len(rows))
+	for i := range rows {
+		grants = append(grants, toRoleGrant(&rows[i]))
+	}
+	return grants
+}
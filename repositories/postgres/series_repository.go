@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/location"
+	"pickletlgbot/internal/domain/series"
+	"pickletlgbot/internal/domain/user"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type seriesRepository struct {
+	db *gorm.DB
+}
+
+func NewSeriesRepository(db *gorm.DB) series.Repository {
+	return &seriesRepository{db: db}
+}
+
+func (r *seriesRepository) GetByID(ctx context.Context, id series.SeriesID) (*series.EventSeries, error) {
+	var model models.EventSeriesGORM
+	if err := r.db.WithContext(ctx).
+		Where("series_id = ?", id).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return r.modelToDomain(&model)
+}
+
+func (r *seriesRepository) List(ctx context.Context) ([]series.EventSeries, error) {
+	var models []models.EventSeriesGORM
+	if err := r.db.WithContext(ctx).
+		Order("created_at").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]series.EventSeries, 0, len(models))
+	for _, m := range models {
+		s, err := r.modelToDomain(&m)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *s)
+	}
+	return result, nil
+}
+
+func (r *seriesRepository) Save(ctx context.Context, s *series.EventSeries) error {
+	model := r.domainToModel(s)
+
+	// Select("*") нужен, чтобы Assign обновлял и нулевые значения (например, снятое ограничение Until)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.EventSeriesGORM
+		err := tx.Where("series_id = ?", s.ID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(model).Error
+		}
+		if err != nil {
+			return err
+		}
+		model.ID = existing.ID
+		model.CreatedAt = existing.CreatedAt
+		return tx.Select("*").Omit("deleted_at").Save(model).Error
+	})
+}
+
+func (r *seriesRepository) Delete(ctx context.Context, id series.SeriesID) error {
+	return r.db.WithContext(ctx).
+		Where("series_id = ?", id).
+		Delete(&models.EventSeriesGORM{}).Error
+}
+
+func (r *seriesRepository) modelToDomain(m *models.EventSeriesGORM) (*series.EventSeries, error) {
+	weekdays, err := parseWeekdays(m.Weekdays)
+	if err != nil {
+		return nil, err
+	}
+
+	var startTime series.TimeOfDay
+	if _, err := fmt.Sscanf(m.StartTime, "%d:%d", &startTime.Hour, &startTime.Minute); err != nil {
+		return nil, fmt.Errorf("invalid series start time %q: %w", m.StartTime, err)
+	}
+
+	var excluded []string
+	if m.ExcludedDates != "" {
+		excluded = strings.Split(m.ExcludedDates, ",")
+	}
+
+	return &series.EventSeries{
+		ID:             series.SeriesID(m.SeriesID),
+		Name:           m.Name,
+		Type:           event.EventType(m.Type),
+		MaxPlayers:     m.MaxPlayers,
+		LocationID:     location.LocationID(m.LocationID),
+		Trainer:        m.Trainer,
+		Description:    m.Description,
+		PaymentPhone:   m.PaymentPhone,
+		Price:          m.Price,
+		PaymentMode:    event.PaymentMode(m.PaymentMode),
+		PendingTimeout: time.Duration(m.PendingTimeoutMinutes) * time.Minute,
+		MinLevel:       user.Level(m.MinLevel),
+		MaxLevel:       user.Level(m.MaxLevel),
+		Doubles:        m.Doubles,
+		Duration:       time.Duration(m.DurationMinutes) * time.Minute,
+		CreatedBy:      m.CreatedBy,
+		Recurrence: series.Recurrence{
+			Weekdays:  weekdays,
+			Time:      startTime,
+			StartDate: m.StartDate,
+			Until:     timeValue(m.Until),
+			Count:     m.Count,
+		},
+		WeeksAhead:    m.WeeksAhead,
+		ExcludedDates: excluded,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}, nil
+}
+
+func (r *seriesRepository) domainToModel(s *series.EventSeries) *models.EventSeriesGORM {
+	weekdays := make([]string, 0, len(s.Recurrence.Weekdays))
+	for _, wd := range s.Recurrence.Weekdays {
+		weekdays = append(weekdays, strconv.Itoa(int(wd)))
+	}
+
+	return &models.EventSeriesGORM{
+		SeriesID:              string(s.ID),
+		Name:                  s.Name,
+		Type:                  string(s.Type),
+		MaxPlayers:            s.MaxPlayers,
+		LocationID:            string(s.LocationID),
+		Trainer:               s.Trainer,
+		Description:           s.Description,
+		PaymentPhone:          s.PaymentPhone,
+		Price:                 s.Price,
+		PaymentMode:           string(s.PaymentMode),
+		PendingTimeoutMinutes: int(s.PendingTimeout / time.Minute),
+		MinLevel:              int(s.MinLevel),
+		MaxLevel:              int(s.MaxLevel),
+		Doubles:               s.Doubles,
+		DurationMinutes:       int(s.Duration / time.Minute),
+		CreatedBy:             s.CreatedBy,
+		Weekdays:              strings.Join(weekdays, ","),
+		StartTime:             fmt.Sprintf("%02d:%02d", s.Recurrence.Time.Hour, s.Recurrence.Time.Minute),
+		StartDate:             s.Recurrence.StartDate,
+		Until:                 timePtr(s.Recurrence.Until),
+		Count:                 s.Recurrence.Count,
+		WeeksAhead:            s.WeeksAhead,
+		ExcludedDates:         strings.Join(s.ExcludedDates, ","),
+		CreatedAt:             s.CreatedAt,
+		UpdatedAt:             s.UpdatedAt,
+	}
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	weekdays := make([]time.Weekday, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || n > 6 {
+			return nil, fmt.Errorf("invalid weekday %q", p)
+		}
+		weekdays = append(weekdays, time.Weekday(n))
+	}
+	return weekdays, nil
+}
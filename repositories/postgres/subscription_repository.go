@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/subscription"
+	"pickletlgbot/internal/domain/user"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type subscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) subscription.Repository {
+	return &subscriptionRepository{db: db}
+}
+
+func (r *subscriptionRepository) Get(ctx context.Context, userID int64) (*subscription.Subscription, error) {
+	var model models.SubscriptionGORM
+	if err := r.db.WithContext(ctx).
+		Where("telegram_id = ?", userID).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	s := toSubscription(&model)
+	return &s, nil
+}
+
+func (r *subscriptionRepository) Save(ctx context.Context, s *subscription.Subscription) error {
+	types := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		types = append(types, string(t))
+	}
+
+	model := &models.SubscriptionGORM{
+		TelegramID:  s.UserID,
+		Enabled:     s.Enabled,
+		LocationIDs: joinLocationIDs(s.LocationIDs),
+		EventTypes:  strings.Join(types, ","),
+		Trainers:    strings.Join(s.Trainers, "\n"),
+		MinLevel:    int(s.MinLevel),
+		MaxLevel:    int(s.MaxLevel),
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	// Одна подписка на игрока: повторное сохранение обновляет фильтры
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "telegram_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "location_ids", "event_types", "trainers", "min_level", "max_level", "updated_at"}),
+		}).
+		Create(model).Error
+}
+
+func (r *subscriptionRepository) ListEnabled(ctx context.Context) ([]subscription.Subscription, error) {
+	var rows []models.SubscriptionGORM
+	if err := r.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	subs := make([]subscription.Subscription, 0, len(rows))
+	for i := range rows {
+		subs = append(subs, toSubscription(&rows[i]))
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepository) Delete(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).
+		Where("telegram_id = ?", userID).
+		Delete(&models.SubscriptionGORM{}).Error
+}
+
+func toSubscription(m *models.SubscriptionGORM) subscription.Subscription {
+	s := subscription.Subscription{
+		UserID:      m.TelegramID,
+		Enabled:     m.Enabled,
+		LocationIDs: splitLocationIDs(m.LocationIDs),
+		MinLevel:    user.Level(m.MinLevel),
+		MaxLevel:    user.Level(m.MaxLevel),
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+	if m.EventTypes != "" {
+		for _, t := range strings.Split(m.EventTypes, ",") {
+			s.EventTypes = append(s.EventTypes, event.EventType(t))
+		}
+	}
+	if m.Trainers != "" {
+		s.Trainers = strings.Split(m.Trainers, "\n")
+	}
+	return s
+}
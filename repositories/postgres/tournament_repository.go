@@ -0,0 +1,289 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"pickletlgbot/internal/domain/event"
+	"pickletlgbot/internal/domain/tournament"
+	"pickletlgbot/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tournamentRepository struct {
+	db *gorm.DB
+}
+
+func NewTournamentRepository(db *gorm.DB) tournament.Repository {
+	return &tournamentRepository{db: db}
+}
+
+func (r *tournamentRepository) GetByID(ctx context.Context, id tournament.TournamentID) (*tournament.Tournament, error) {
+	var model models.TournamentGORM
+	if err := r.db.WithContext(ctx).
+		Where("tournament_id = ?", string(id)).
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.load(ctx, r.db, &model)
+}
+
+func (r *tournamentRepository) GetByEvent(ctx context.Context, eventID event.EventID) (*tournament.Tournament, error) {
+	var model models.TournamentGORM
+	if err := r.db.WithContext(ctx).
+		Where("event_id = ?", string(eventID)).
+		Order("created_at DESC").
+		First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.load(ctx, r.db, &model)
+}
+
+func (r *tournamentRepository) List(ctx context.Context) ([]tournament.Tournament, error) {
+	var rows []models.TournamentGORM
+	if err := r.db.WithContext(ctx).
+		Order("created_at").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	tournaments := make([]tournament.Tournament, 0, len(rows))
+	for i := range rows {
+		t, err := r.load(ctx, r.db, &rows[i])
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, *t)
+	}
+	return tournaments, nil
+}
+
+func (r *tournamentRepository) Create(ctx context.Context, t *tournament.Tournament) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.TournamentGORM{
+			TournamentID: string(t.ID),
+			EventID:      string(t.EventID),
+			Format:       string(t.Format),
+			Pools:        t.Pools,
+			Status:       string(t.Status),
+			CreatedAt:    t.CreatedAt,
+			UpdatedAt:    t.UpdatedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		participants := make([]models.TournamentParticipantGORM, 0, len(t.Participants))
+		for _, p := range t.Participants {
+			ids := make([]string, 0, len(p.UserIDs))
+			for _, id := range p.UserIDs {
+				ids = append(ids, strconv.FormatInt(id, 10))
+			}
+			participants = append(participants, models.TournamentParticipantGORM{
+				TournamentID: string(t.ID),
+				Seed:         p.ID,
+				Name:         p.Name,
+				UserIDs:      strings.Join(ids, ","),
+			})
+		}
+		if len(participants) > 0 {
+			if err := tx.Create(&participants).Error; err != nil {
+				return err
+			}
+		}
+
+		matches := make([]models.TournamentMatchGORM, 0, len(t.Matches))
+		for _, m := range t.Matches {
+			matches = append(matches, r.matchToModel(t.ID, &m))
+		}
+		if len(matches) > 0 {
+			if err := tx.Create(&matches).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *tournamentRepository) Update(ctx context.Context, id tournament.TournamentID, fn func(t *tournament.Tournament) error) (*tournament.Tournament, error) {
+	var t *tournament.Tournament
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Блокируем строку турнира, чтобы счета матчей вносились по очереди
+		var model models.TournamentGORM
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tournament_id = ?", string(id)).
+			First(&model).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return tournament.ErrTournamentNotFound
+			}
+			return err
+		}
+
+		loaded, err := r.load(ctx, tx, &model)
+		if err != nil {
+			return err
+		}
+		names := make([]string, len(loaded.Participants))
+		for i, p := range loaded.Participants {
+			names[i] = p.Name
+		}
+		if err := fn(loaded); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.TournamentGORM{}).
+			Where("tournament_id = ?", string(id)).
+			Updates(map[string]interface{}{
+				"status":     string(loaded.Status),
+				"updated_at": loaded.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		// Состав участников не меняется, меняться может только имя (например, после удаления данных игрока)
+		for i, p := range loaded.Participants {
+			if i < len(names) && p.Name == names[i] {
+				continue
+			}
+			if err := tx.Model(&models.TournamentParticipantGORM{}).
+				Where("tournament_id = ? AND seed = ?", string(id), p.ID).
+				Update("name", p.Name).Error; err != nil {
+				return err
+			}
+		}
+
+		// Структура сетки не меняется, обновляем только участников и результаты матчей.
+		// Через map, чтобы нулевые значения (например, сброшенный победитель) тоже сохранялись
+		for _, m := range loaded.Matches {
+			model := r.matchToModel(id, &m)
+			if err := tx.Model(&models.TournamentMatchGORM{}).
+				Where("tournament_id = ? AND match_no = ?", string(id), m.ID).
+				Updates(map[string]interface{}{
+					"side_a":  model.SideA,
+					"side_b":  model.SideB,
+					"score_a": model.ScoreA,
+					"score_b": model.ScoreB,
+					"winner":  model.Winner,
+					"bye":     model.Bye,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		t = loaded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *tournamentRepository) Delete(ctx context.Context, id tournament.TournamentID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tournament_id = ?", string(id)).Delete(&models.TournamentMatchGORM{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tournament_id = ?", string(id)).Delete(&models.TournamentParticipantGORM{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("tournament_id = ?", string(id)).Delete(&models.TournamentGORM{}).Error
+	})
+}
+
+// load собирает турнир с участниками и матчами
+func (r *tournamentRepository) load(ctx context.Context, db *gorm.DB, model *models.TournamentGORM) (*tournament.Tournament, error) {
+	var participants []models.TournamentParticipantGORM
+	if err := db.WithContext(ctx).
+		Where("tournament_id = ?", model.TournamentID).
+		Order("seed").
+		Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	var matches []models.TournamentMatchGORM
+	if err := db.WithContext(ctx).
+		Where("tournament_id = ?", model.TournamentID).
+		Order("match_no").
+		Find(&matches).Error; err != nil {
+		return nil, err
+	}
+
+	t := &tournament.Tournament{
+		ID:           tournament.TournamentID(model.TournamentID),
+		EventID:      event.EventID(model.EventID),
+		Format:       tournament.Format(model.Format),
+		Pools:        model.Pools,
+		Status:       tournament.Status(model.Status),
+		Participants: make([]tournament.Participant, 0, len(participants)),
+		Matches:      make([]tournament.Match, 0, len(matches)),
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+	}
+
+	for _, p := range participants {
+		var userIDs []int64
+		for _, s := range strings.Split(p.UserIDs, ",") {
+			if s == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			userIDs = append(userIDs, id)
+		}
+		t.Participants = append(t.Participants, tournament.Participant{
+			ID:      p.Seed,
+			Name:    p.Name,
+			UserIDs: userIDs,
+		})
+	}
+
+	for _, m := range matches {
+		t.Matches = append(t.Matches, tournament.Match{
+			ID:       m.MatchNo,
+			Stage:    tournament.Stage(m.Stage),
+			Round:    m.Round,
+			Pool:     m.Pool,
+			A:        m.SideA,
+			B:        m.SideB,
+			ScoreA:   m.ScoreA,
+			ScoreB:   m.ScoreB,
+			Winner:   m.Winner,
+			Bye:      m.Bye,
+			WinnerTo: tournament.Link{MatchID: m.WinnerToMatch, Side: m.WinnerToSide},
+			LoserTo:  tournament.Link{MatchID: m.LoserToMatch, Side: m.LoserToSide},
+		})
+	}
+	return t, nil
+}
+
+func (r *tournamentRepository) matchToModel(id tournament.TournamentID, m *tournament.Match) models.TournamentMatchGORM {
+	return models.TournamentMatchGORM{
+		TournamentID:  string(id),
+		MatchNo:       m.ID,
+		Stage:         string(m.Stage),
+		Round:         m.Round,
+		Pool:          m.Pool,
+		SideA:         m.A,
+		SideB:         m.B,
+		ScoreA:        m.ScoreA,
+		ScoreB:        m.ScoreB,
+		Winner:        m.Winner,
+		Bye:           m.Bye,
+		WinnerToMatch: m.WinnerTo.MatchID,
+		WinnerToSide:  m.WinnerTo.Side,
+		LoserToMatch:  m.LoserTo.MatchID,
+		LoserToSide:   m.LoserTo.Side,
+	}
+}
@@ -3,8 +3,11 @@ package postgres
 import (
 	"context"
 	"errors"
+	"pickletlgbot/internal/domain/location"
 	"pickletlgbot/internal/domain/user"
 	"pickletlgbot/internal/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -23,6 +26,19 @@ func (ur *userRepository) Save(ctx context.Context, usr *user.User) error {
 		Name:       usr.Name,
 		Surname:    usr.Surname,
 		TelegramID: usr.TelegramID,
+		Level:      int(usr.Level),
+		Phone:      usr.Phone,
+		Hand:       string(usr.Hand),
+		Locations:  joinLocationIDs(usr.PreferredLocations),
+		Language:   string(usr.Language),
+	}
+	if !usr.DeleteAfter.IsZero() {
+		deleteAfter := usr.DeleteAfter
+		model.DeleteAfter = &deleteAfter
+	}
+	if !usr.AnonymizedAt.IsZero() {
+		anonymizedAt := usr.AnonymizedAt
+		model.AnonymizedAt = &anonymizedAt
 	}
 
 	// Если ID = 0, создаем новую запись, иначе обновляем существующую
@@ -33,9 +49,11 @@ func (ur *userRepository) Save(ctx context.Context, usr *user.User) error {
 		// Обновляем ID в доменной модели после создания
 		usr.ID = model.ID
 	} else {
+		// Select, чтобы сброшенный уровень (0) и очищенные поля профиля тоже сохранялись
 		if err := ur.db.WithContext(ctx).
 			Model(&models.UserGORM{}).
 			Where("id = ?", usr.ID).
+			Select("name", "surname", "telegram_id", "level", "phone", "hand", "locations", "language", "delete_after", "anonymized_at").
 			Updates(model).Error; err != nil {
 			return err
 		}
@@ -129,12 +147,60 @@ func (ur *userRepository) ListByLocationID(ctx context.Context, locationID int64
 	return users, nil
 }
 
+func (ur *userRepository) ListDeletionsDue(ctx context.Context, before time.Time) ([]user.User, error) {
+	var rows []models.UserGORM
+	if err := ur.db.WithContext(ctx).
+		Where("delete_after IS NOT NULL AND delete_after <= ? AND anonymized_at IS NULL", before).
+		Order("delete_after ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	users := make([]user.User, 0, len(rows))
+	for i := range rows {
+		users = append(users, *ur.modelToDomain(&rows[i]))
+	}
+	return users, nil
+}
+
 // modelToDomain конвертирует GORM модель в доменную модель
 func (ur *userRepository) modelToDomain(model *models.UserGORM) *user.User {
-	return &user.User{
-		ID:         model.ID,
-		Name:       model.Name,
-		Surname:    model.Surname,
-		TelegramID: model.TelegramID,
+	usr := &user.User{
+		ID:                 model.ID,
+		Name:               model.Name,
+		Surname:            model.Surname,
+		TelegramID:         model.TelegramID,
+		Level:              user.Level(model.Level),
+		Phone:              model.Phone,
+		Hand:               user.Hand(model.Hand),
+		Language:           user.Language(model.Language),
+		PreferredLocations: splitLocationIDs(model.Locations),
+	}
+	if model.DeleteAfter != nil {
+		usr.DeleteAfter = *model.DeleteAfter
+	}
+	if model.AnonymizedAt != nil {
+		usr.AnonymizedAt = *model.AnonymizedAt
+	}
+	return usr
+}
+
+func joinLocationIDs(ids []location.LocationID) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = string(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitLocationIDs(s string) []location.LocationID {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	ids := make([]location.LocationID, len(parts))
+	for i, p := range parts {
+		ids[i] = location.LocationID(p)
 	}
+	return ids
 }